@@ -26,7 +26,7 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-// @description Provide the JWT as `Bearer <token>`.
+// @description Provide the JWT, or a personal API key, as `Bearer <token>`. API keys may be sent in the X-API-Key header instead.
 // @security BearerAuth
 
 func main() {
@@ -53,6 +53,16 @@ func run() error {
 		return fmt.Errorf("configuration initialization failed: %w", err)
 	}
 
+	tokenManager, err := app.NewTokenManager(cfg)
+	if err != nil {
+		return fmt.Errorf("signing key initialization failed: %w", err)
+	}
+
+	passwordPolicy, err := app.NewPasswordPolicy(cfg)
+	if err != nil {
+		return fmt.Errorf("password policy initialization failed: %w", err)
+	}
+
 	shutdownTracing, err := observability.SetupTracing(context.Background(), cfg.Tracing)
 	if err != nil {
 		return fmt.Errorf("tracing initialization failed: %w", err)
@@ -83,7 +93,7 @@ func run() error {
 	slog.Info("database is healthy")
 
 	repositories := persistence.NewGormRepositories(db.GetDB())
-	server := app.NewHTTPServer(cfg, db, repositories)
+	server := app.NewHTTPServer(cfg, db, repositories, tokenManager, passwordPolicy)
 	serverErrors := make(chan error, 1)
 
 	go func() {
@@ -96,6 +106,12 @@ func run() error {
 	shutdownContext, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go app.RunTokenCleanup(shutdownContext, cfg, repositories, tokenManager)
+
+	if cfg.Users.DeletionGracePeriod > 0 {
+		go app.RunUserDeletions(shutdownContext, cfg, repositories)
+	}
+
 	select {
 	case err := <-serverErrors:
 		return fmt.Errorf("server failed: %w", err)
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/oidc"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/persistence"
+	services "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services/default"
+)
+
+// newOIDCService sets up single sign-on with the configured provider. The
+// provider is only contacted on the first login, so a provider that is
+// down does not keep the app from starting.
+func newOIDCService(cfg config.OIDCConfig, repositories persistence.Repositories) *services.DefaultOIDCService {
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    cfg.IssuerURL,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	}, oidc.WithClockSkew(time.Minute))
+
+	return services.NewOIDCService(provider, repositories.OIDC, repositories.Users)
+}
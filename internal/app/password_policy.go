@@ -0,0 +1,28 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/passwords"
+	services "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services/default"
+)
+
+// NewPasswordPolicy builds the policy new passwords are checked against,
+// loading the breached password list when one is configured.
+func NewPasswordPolicy(cfg config.Config) (services.PasswordPolicy, error) {
+	policy := services.PasswordPolicy{
+		MinLength: cfg.Auth.PasswordMinLength,
+		MinScore:  cfg.Auth.PasswordMinScore,
+	}
+
+	if cfg.Auth.BreachedPasswordsPath != "" {
+		breached, err := passwords.LoadBreachedList(cfg.Auth.BreachedPasswordsPath)
+		if err != nil {
+			return services.PasswordPolicy{}, fmt.Errorf("load breached passwords: %w", err)
+		}
+		policy.Breached = breached
+	}
+
+	return policy, nil
+}
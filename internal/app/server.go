@@ -10,6 +10,7 @@ import (
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/controllers"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/handlers"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/mail"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/middleware"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/observability"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/persistence"
@@ -20,8 +21,8 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func NewHTTPServer(cfg config.Config, db database.Database, repositories persistence.Repositories) *http.Server {
-	router := newRouter(cfg, db, repositories)
+func NewHTTPServer(cfg config.Config, db database.Database, repositories persistence.Repositories, tokenManager *auth.JWTManager, passwordPolicy services.PasswordPolicy) *http.Server {
+	router := newRouter(cfg, db, repositories, tokenManager, passwordPolicy)
 
 	return &http.Server{
 		Addr:              cfg.Address(),
@@ -33,10 +34,13 @@ func NewHTTPServer(cfg config.Config, db database.Database, repositories persist
 	}
 }
 
-func newRouter(cfg config.Config, db database.Database, repositories persistence.Repositories) *gin.Engine {
+func newRouter(cfg config.Config, db database.Database, repositories persistence.Repositories, tokenManager *auth.JWTManager, passwordPolicy services.PasswordPolicy) *gin.Engine {
 	metrics := observability.NewHTTPMetrics()
+	authMetrics := observability.NewAuthMetrics(metrics.Registry())
 
 	router := gin.New()
+	// The proxies were validated when the config was loaded.
+	_ = router.SetTrustedProxies(cfg.HTTP.TrustedProxies)
 	router.Use(
 		middleware.RequestIDMiddleware(),
 		observability.TracingMiddleware(),
@@ -45,22 +49,88 @@ func newRouter(cfg config.Config, db database.Database, repositories persistence
 		middleware.RecoveryMiddleware(slog.Default()),
 	)
 
-	tokenManager := auth.NewJWTManager(cfg.JWTSecret, cfg.Auth.TokenTTL)
-	authMiddleware := middleware.AuthMiddleware(tokenManager)
+	tokenService := services.NewTokenService(
+		tokenManager,
+		repositories.RefreshTokens,
+		repositories.RevokedTokens,
+		repositories.Users,
+		cfg.Auth.RefreshTokenTTL,
+		services.WithRevocationCacheTTL(cfg.Auth.RevocationCacheTTL),
+	)
+	apiKeyService := services.NewAPIKeyService(repositories.APIKeys, repositories.Users)
+	authMiddleware := middleware.AuthMiddleware(tokenManager, tokenService, apiKeyService)
 
-	userService := services.NewUserService(repositories.Users)
-	transactionService := services.NewTransactionService(repositories.Transactions, repositories.Budgets)
+	loginThrottle := services.NewLoginThrottle(repositories.LoginThrottles, services.LoginThrottlePolicy{
+		MaxAccountFailures: cfg.Auth.LoginMaxFailures,
+		MaxAddressFailures: cfg.Auth.LoginMaxAddressFailures,
+		Lockout:            cfg.Auth.LoginLockout,
+		MaxLockout:         cfg.Auth.LoginMaxLockout,
+	}, services.WithLoginMetrics(authMetrics))
+	mfaService := services.NewMFAService(
+		repositories.Users,
+		repositories.MFA,
+		repositories.RevokedTokens,
+		tokenManager,
+		services.WithTOTPIssuer(cfg.Auth.TOTPIssuer),
+		services.WithMFALoginThrottle(loginThrottle),
+	)
+	userService := services.NewUserService(
+		repositories.Users,
+		services.WithDeletionGracePeriod(cfg.Users.DeletionGracePeriod),
+		services.WithLoginThrottle(loginThrottle),
+		services.WithPasswordPolicy(passwordPolicy),
+	)
+	// Emails are sent in the background so a request that sends one takes
+	// as long as one that does not, which keeps the password reset and
+	// verification endpoints from revealing who is registered.
+	mailer := mail.NewBackgroundMailer(newMailer(cfg.Mail), slog.Default())
+	accountEmailService := services.NewAccountEmailService(
+		repositories.Users,
+		repositories.RevokedTokens,
+		tokenManager,
+		mailer,
+		services.WithLinkBaseURL(cfg.Mail.AppBaseURL),
+		services.WithResetPasswordPolicy(passwordPolicy),
+	)
+	transactionService := services.NewTransactionService(
+		repositories.Transactions,
+		repositories.Budgets,
+		repositories.Accounts,
+		repositories.Tags,
+		services.WithDuplicateWindow(cfg.Transactions.DuplicateWindow),
+		services.WithRuleRepository(repositories.Rules),
+		services.WithClassifierRepository(repositories.Classifier),
+	)
 	budgetService := services.NewBudgetService(repositories.Budgets)
+	accountService := services.NewAccountService(repositories.Accounts)
+	tagService := services.NewTagService(repositories.Tags)
+	ruleService := services.NewRuleService(repositories.Rules)
+	importService := services.NewImportService(transactionService, repositories.Transactions, repositories.Archives)
+	exportService := services.NewExportService(repositories.Transactions, repositories.Accounts, repositories.Archives)
+	reportService := services.NewReportService(repositories.Transactions)
+	var oidcController *controllers.OIDCController
+	if cfg.OIDC.Enabled() {
+		oidcController = controllers.NewOIDCController(newOIDCService(cfg.OIDC, repositories), tokenService, mfaService)
+	}
 
-	userController := controllers.NewUserController(userService, tokenManager)
-	transactionController := controllers.NewTransactionController(transactionService)
-	budgetController := controllers.NewBudgetController(budgetService)
-
-	routes.SetupRoutes(router, authMiddleware, userController, transactionController, budgetController)
+	routes.SetupRoutes(router, authMiddleware, routes.Controllers{
+		User:        controllers.NewUserController(userService, tokenService, mfaService, accountEmailService),
+		Transaction: controllers.NewTransactionController(transactionService),
+		Budget:      controllers.NewBudgetController(budgetService),
+		Account:     controllers.NewAccountController(accountService),
+		Report:      controllers.NewReportController(reportService),
+		Tag:         controllers.NewTagController(tagService),
+		Import:      controllers.NewImportController(importService),
+		Rule:        controllers.NewRuleController(ruleService, transactionService),
+		Export:      controllers.NewExportController(exportService),
+		APIKey:      controllers.NewAPIKeyController(apiKeyService),
+		OIDC:        oidcController,
+	})
 
 	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 	router.GET("/health", handlers.HealthCheckHandler)
 	router.GET("/ready", handlers.ReadinessCheckHandler(db))
+	router.GET("/.well-known/jwks.json", handlers.JWKSHandler(tokenManager))
 	router.GET("/openapi.json", func(c *gin.Context) {
 		c.Data(http.StatusOK, "application/json; charset=utf-8", docs.SwaggerJSON)
 	})
@@ -71,3 +141,14 @@ func newRouter(cfg config.Config, db database.Database, repositories persistence
 
 	return router
 }
+
+func newMailer(cfg config.MailConfig) mail.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case "file":
+		return mail.NewFileMailer(cfg.FileDir, cfg.From)
+	default:
+		return mail.NewLogMailer(slog.Default())
+	}
+}
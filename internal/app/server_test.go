@@ -9,6 +9,7 @@ import (
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/persistence"
+	services "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services/default"
 )
 
 type stubDatabase struct{}
@@ -34,7 +35,12 @@ func TestNewRouterExposesMetrics(t *testing.T) {
 		},
 	}
 
-	router := newRouter(cfg, stubDatabase{}, persistence.Repositories{})
+	tokenManager, err := NewTokenManager(cfg)
+	if err != nil {
+		t.Fatalf("expected token manager, got error %v", err)
+	}
+
+	router := newRouter(cfg, stubDatabase{}, persistence.Repositories{}, tokenManager, services.PasswordPolicy{})
 
 	healthRequest := httptest.NewRequest(http.MethodGet, "/health", nil)
 	healthResponse := httptest.NewRecorder()
@@ -44,6 +50,14 @@ func TestNewRouterExposesMetrics(t *testing.T) {
 		t.Fatalf("expected /health status %d, got %d", http.StatusOK, healthResponse.Code)
 	}
 
+	jwksRequest := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	jwksResponse := httptest.NewRecorder()
+	router.ServeHTTP(jwksResponse, jwksRequest)
+
+	if jwksResponse.Code != http.StatusOK {
+		t.Fatalf("expected /.well-known/jwks.json status %d, got %d", http.StatusOK, jwksResponse.Code)
+	}
+
 	metricsRequest := httptest.NewRequest(http.MethodGet, "/metrics", nil)
 	metricsResponse := httptest.NewRecorder()
 	router.ServeHTTP(metricsResponse, metricsRequest)
@@ -65,6 +79,14 @@ func TestNewRouterExposesMetrics(t *testing.T) {
 		t.Fatal("expected request duration metric to be exposed")
 	}
 
+	if !strings.Contains(body, "personal_finance_tracker_auth_failed_logins_total") {
+		t.Fatal("expected failed login metric to be present")
+	}
+
+	if !strings.Contains(body, "personal_finance_tracker_auth_login_lockouts_total") {
+		t.Fatal("expected login lockout metric to be present")
+	}
+
 	if !strings.Contains(body, `route="/health"`) {
 		t.Fatal("expected /health route labels to be present in metrics output")
 	}
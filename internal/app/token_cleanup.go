@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/persistence"
+	services "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services/default"
+)
+
+// tokenCleanupInterval is how often revoked access tokens that have
+// expired are deleted.
+const tokenCleanupInterval = time.Hour
+
+// RunTokenCleanup deletes revoked access tokens that have expired anyway,
+// failed login counts that are no longer needed and single sign-on logins
+// that were never finished, once at start and then every
+// tokenCleanupInterval, until ctx is cancelled.
+func RunTokenCleanup(ctx context.Context, cfg config.Config, repositories persistence.Repositories, tokenManager auth.TokenManager) {
+	tokenService := services.NewTokenService(
+		tokenManager,
+		repositories.RefreshTokens,
+		repositories.RevokedTokens,
+		repositories.Users,
+		cfg.Auth.RefreshTokenTTL,
+	)
+	loginThrottle := services.NewLoginThrottle(repositories.LoginThrottles, services.LoginThrottlePolicy{
+		Lockout:    cfg.Auth.LoginLockout,
+		MaxLockout: cfg.Auth.LoginMaxLockout,
+	})
+	var oidcService *services.DefaultOIDCService
+	if cfg.OIDC.Enabled() {
+		oidcService = newOIDCService(cfg.OIDC, repositories)
+	}
+	ticker := time.NewTicker(tokenCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := tokenService.PurgeExpiredRevocations(ctx, time.Now().UTC())
+		if err != nil {
+			slog.Error("revoked token cleanup failed", "error", err)
+		} else if deleted > 0 {
+			slog.Info("deleted expired revoked tokens", "deleted", deleted)
+		}
+
+		deleted, err = loginThrottle.PurgeStaleEntries(ctx, time.Now().UTC())
+		if err != nil {
+			slog.Error("login throttle cleanup failed", "error", err)
+		} else if deleted > 0 {
+			slog.Info("deleted stale login throttles", "deleted", deleted)
+		}
+
+		if oidcService != nil {
+			deleted, err = oidcService.PurgeExpiredLoginRequests(ctx, time.Now().UTC())
+			if err != nil {
+				slog.Error("single sign-on login cleanup failed", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted expired single sign-on logins", "deleted", deleted)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
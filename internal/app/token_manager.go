@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
+)
+
+// NewTokenManager loads the configured signing key and the retired keys
+// whose tokens are still accepted, and pins the issuer and audience.
+func NewTokenManager(cfg config.Config) (*auth.JWTManager, error) {
+	signingKey := auth.NewHMACKey(cfg.JWTSecret)
+	if cfg.Auth.SigningAlgorithm != "" && cfg.Auth.SigningAlgorithm != auth.AlgorithmHS256 {
+		data, err := os.ReadFile(cfg.Auth.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read signing key: %w", err)
+		}
+
+		signingKey, err = auth.ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse signing key %s: %w", cfg.Auth.SigningKeyFile, err)
+		}
+		if signingKey.Algorithm != cfg.Auth.SigningAlgorithm {
+			return nil, fmt.Errorf("signing key %s is a %s key, not %s", cfg.Auth.SigningKeyFile, signingKey.Algorithm, cfg.Auth.SigningAlgorithm)
+		}
+	}
+
+	verificationKeys := make([]auth.SigningKey, 0, len(cfg.Auth.VerificationKeyFiles)+len(cfg.Auth.PreviousSecrets))
+	for _, path := range cfg.Auth.VerificationKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read verification key: %w", err)
+		}
+
+		key, err := auth.ParseVerificationKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse verification key %s: %w", path, err)
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+	for _, secret := range cfg.Auth.PreviousSecrets {
+		verificationKeys = append(verificationKeys, auth.NewHMACKey(secret))
+	}
+
+	return auth.NewJWTManagerWithKeys(signingKey, verificationKeys, cfg.Auth.TokenTTL,
+		auth.WithIssuer(cfg.Auth.Issuer),
+		auth.WithAudience(cfg.Auth.Audience),
+		auth.WithClockSkew(cfg.Auth.ClockSkew),
+	)
+}
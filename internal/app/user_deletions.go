@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/config"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/persistence"
+	services "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services/default"
+)
+
+// userDeletionInterval is how often users whose deletion grace period has
+// ended are erased.
+const userDeletionInterval = time.Hour
+
+// RunUserDeletions erases users whose scheduled deletion is due, once at
+// start and then every userDeletionInterval, until ctx is cancelled.
+func RunUserDeletions(ctx context.Context, cfg config.Config, repositories persistence.Repositories) {
+	userService := services.NewUserService(repositories.Users, services.WithDeletionGracePeriod(cfg.Users.DeletionGracePeriod))
+	ticker := time.NewTicker(userDeletionInterval)
+	defer ticker.Stop()
+
+	for {
+		erased, err := userService.EraseDueUsers(ctx, time.Now().UTC())
+		if err != nil {
+			slog.Error("scheduled user deletion failed", "error", err, "erased", erased)
+		} else if erased > 0 {
+			slog.Info("erased users scheduled for deletion", "erased", erased)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,17 +1,21 @@
 package apperrors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type Kind string
 
 const (
-	KindValidation   Kind = "validation"
-	KindUnauthorized Kind = "unauthorized"
-	KindForbidden    Kind = "forbidden"
-	KindNotFound     Kind = "not_found"
-	KindConflict     Kind = "conflict"
-	KindUnavailable  Kind = "unavailable"
-	KindInternal     Kind = "internal"
+	KindValidation      Kind = "validation"
+	KindUnauthorized    Kind = "unauthorized"
+	KindForbidden       Kind = "forbidden"
+	KindNotFound        Kind = "not_found"
+	KindConflict        Kind = "conflict"
+	KindUnavailable     Kind = "unavailable"
+	KindTooManyRequests Kind = "too_many_requests"
+	KindInternal        Kind = "internal"
 )
 
 type Error struct {
@@ -19,6 +23,9 @@ type Error struct {
 	Code    string
 	Message string
 	Err     error
+	// RetryAfter tells the client how long to wait before trying again.
+	// It is only set on too_many_requests errors.
+	RetryAfter time.Duration
 }
 
 func (e *Error) Error() string {
@@ -86,6 +93,14 @@ func Unavailable(code, message string) *Error {
 	return New(KindUnavailable, code, message)
 }
 
+// TooManyRequests reports that the client has to wait retryAfter before
+// the request can succeed.
+func TooManyRequests(code, message string, retryAfter time.Duration) *Error {
+	err := New(KindTooManyRequests, code, message)
+	err.RetryAfter = retryAfter
+	return err
+}
+
 func Internal(code, message string, err error) *Error {
 	return Wrap(KindInternal, code, message, err)
 }
@@ -0,0 +1,253 @@
+// Package archive reads and writes a user's data as a versioned zip of JSON
+// files, for backups and for moving between instances.
+//
+// Every section is a JSON array in its own file and manifest.json records
+// the format, version and record counts. Records keep the IDs they have on
+// the exporting instance so references between them survive; importing
+// assigns new IDs.
+package archive
+
+import (
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// FormatName identifies archives written by this package.
+const FormatName = "go-personal-finance-tracker-archive"
+
+// Version is the archive layout this package writes. Read accepts archives
+// up to this version.
+const Version = 1
+
+const (
+	manifestFile     = "manifest.json"
+	categoriesFile   = "categories.json"
+	accountsFile     = "accounts.json"
+	tagsFile         = "tags.json"
+	budgetsFile      = "budgets.json"
+	rulesFile        = "rules.json"
+	transfersFile    = "transfers.json"
+	transactionsFile = "transactions.json"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Counts     Summary   `json:"counts"`
+}
+
+// Summary counts the records of each kind in an archive.
+type Summary struct {
+	Categories   int `json:"categories"`
+	Accounts     int `json:"accounts"`
+	Tags         int `json:"tags"`
+	Budgets      int `json:"budgets"`
+	Rules        int `json:"rules"`
+	Transfers    int `json:"transfers"`
+	Transactions int `json:"transactions"`
+}
+
+// Summarize counts the records in data.
+func Summarize(data *models.ArchiveData) Summary {
+	return Summary{
+		Categories:   len(data.Categories),
+		Accounts:     len(data.Accounts),
+		Tags:         len(data.Tags),
+		Budgets:      len(data.Budgets),
+		Rules:        len(data.Rules),
+		Transfers:    len(data.Transfers),
+		Transactions: len(data.Transactions),
+	}
+}
+
+type category struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type account struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Currency       string    `json:"currency"`
+	OpeningBalance float64   `json:"opening_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type tag struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type budget struct {
+	ID         uint      `json:"id"`
+	CategoryID uint      `json:"category_id"`
+	Limit      float64   `json:"limit"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+}
+
+type rule struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Priority      int       `json:"priority"`
+	NoteContains  string    `json:"note_contains,omitempty"`
+	NotePattern   string    `json:"note_pattern,omitempty"`
+	PayeeContains string    `json:"payee_contains,omitempty"`
+	PayeePattern  string    `json:"payee_pattern,omitempty"`
+	MinAmount     *float64  `json:"min_amount,omitempty"`
+	MaxAmount     *float64  `json:"max_amount,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	CategoryID    *uint     `json:"category_id,omitempty"`
+	Tags          string    `json:"tags,omitempty"`
+	Payee         string    `json:"payee,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type transfer struct {
+	ID            uint      `json:"id"`
+	FromAccountID uint      `json:"from_account_id"`
+	ToAccountID   uint      `json:"to_account_id"`
+	Amount        float64   `json:"amount"`
+	Date          time.Time `json:"date"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type transaction struct {
+	ID         uint      `json:"id"`
+	Type       string    `json:"type"`
+	Amount     float64   `json:"amount"`
+	CategoryID uint      `json:"category_id"`
+	AccountID  *uint     `json:"account_id,omitempty"`
+	TransferID *uint     `json:"transfer_id,omitempty"`
+	Date       time.Time `json:"date"`
+	Note       string    `json:"note,omitempty"`
+	Payee      string    `json:"payee,omitempty"`
+	ExternalID string    `json:"external_id,omitempty"`
+	Splits     []split   `json:"splits,omitempty"`
+	TagIDs     []uint    `json:"tag_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type split struct {
+	CategoryID uint    `json:"category_id"`
+	Amount     float64 `json:"amount"`
+	Note       string  `json:"note,omitempty"`
+}
+
+func newCategory(c models.Category) category {
+	return category{ID: c.ID, Name: c.Name, Description: c.Description}
+}
+
+func (c category) model() models.Category {
+	return models.Category{ID: c.ID, Name: c.Name, Description: c.Description}
+}
+
+func newAccount(a models.Account) account {
+	return account{
+		ID: a.ID, Name: a.Name, Type: a.Type, Currency: a.Currency, OpeningBalance: a.OpeningBalance,
+		CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt,
+	}
+}
+
+func (a account) model() models.Account {
+	return models.Account{
+		ID: a.ID, Name: a.Name, Type: a.Type, Currency: a.Currency, OpeningBalance: a.OpeningBalance,
+		CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt,
+	}
+}
+
+func newTag(t models.Tag) tag {
+	return tag{ID: t.ID, Name: t.Name, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt}
+}
+
+func (t tag) model() models.Tag {
+	return models.Tag{ID: t.ID, Name: t.Name, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt}
+}
+
+func newBudget(b models.Budget) budget {
+	return budget{ID: b.ID, CategoryID: b.CategoryID, Limit: b.Limit, StartDate: b.StartDate, EndDate: b.EndDate}
+}
+
+func (b budget) model() models.Budget {
+	return models.Budget{ID: b.ID, CategoryID: b.CategoryID, Limit: b.Limit, StartDate: b.StartDate, EndDate: b.EndDate}
+}
+
+func newRule(r models.Rule) rule {
+	return rule{
+		ID: r.ID, Name: r.Name, Priority: r.Priority,
+		NoteContains: r.NoteContains, NotePattern: r.NotePattern,
+		PayeeContains: r.PayeeContains, PayeePattern: r.PayeePattern,
+		MinAmount: r.MinAmount, MaxAmount: r.MaxAmount, Type: r.Type,
+		CategoryID: r.CategoryID, Tags: r.Tags, Payee: r.Payee,
+		CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func (r rule) model() models.Rule {
+	return models.Rule{
+		ID: r.ID, Name: r.Name, Priority: r.Priority,
+		NoteContains: r.NoteContains, NotePattern: r.NotePattern,
+		PayeeContains: r.PayeeContains, PayeePattern: r.PayeePattern,
+		MinAmount: r.MinAmount, MaxAmount: r.MaxAmount, Type: r.Type,
+		CategoryID: r.CategoryID, Tags: r.Tags, Payee: r.Payee,
+		CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func newTransfer(t models.Transfer) transfer {
+	return transfer{
+		ID: t.ID, FromAccountID: t.FromAccountID, ToAccountID: t.ToAccountID, Amount: t.Amount,
+		Date: t.Date, Note: t.Note, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func (t transfer) model() models.Transfer {
+	return models.Transfer{
+		ID: t.ID, FromAccountID: t.FromAccountID, ToAccountID: t.ToAccountID, Amount: t.Amount,
+		Date: t.Date, Note: t.Note, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func newTransaction(t models.Transaction) transaction {
+	record := transaction{
+		ID: t.ID, Type: t.Type, Amount: t.Amount, CategoryID: t.CategoryID,
+		AccountID: t.AccountID, TransferID: t.TransferID, Date: t.Date,
+		Note: t.Note, Payee: t.Payee, ExternalID: t.ExternalID,
+		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+	for _, s := range t.Splits {
+		record.Splits = append(record.Splits, split{CategoryID: s.CategoryID, Amount: s.Amount, Note: s.Note})
+	}
+	for _, tag := range t.Tags {
+		record.TagIDs = append(record.TagIDs, tag.ID)
+	}
+	return record
+}
+
+func (t transaction) model() models.Transaction {
+	model := models.Transaction{
+		ID: t.ID, Type: t.Type, Amount: t.Amount, CategoryID: t.CategoryID,
+		AccountID: t.AccountID, TransferID: t.TransferID, Date: t.Date,
+		Note: t.Note, Payee: t.Payee, ExternalID: t.ExternalID,
+		CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt,
+	}
+	for _, s := range t.Splits {
+		model.Splits = append(model.Splits, models.TransactionSplit{CategoryID: s.CategoryID, Amount: s.Amount, Note: s.Note})
+	}
+	for _, id := range t.TagIDs {
+		model.Tags = append(model.Tags, models.Tag{ID: id})
+	}
+	return model
+}
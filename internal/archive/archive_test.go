@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func archiveData() *models.ArchiveData {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	checking, savings, transferID, groceries := uint(10), uint(11), uint(30), uint(4)
+
+	return &models.ArchiveData{
+		Categories: []models.Category{{ID: groceries, Name: "Groceries"}},
+		Accounts: []models.Account{
+			{ID: checking, Name: "Checking", Type: models.AccountTypeChecking, Currency: "EUR", OpeningBalance: 100},
+			{ID: savings, Name: "Savings", Type: models.AccountTypeSavings, Currency: "EUR"},
+		},
+		Tags:      []models.Tag{{ID: 20, Name: "food"}},
+		Budgets:   []models.Budget{{ID: 1, CategoryID: groceries, Limit: 300, StartDate: date, EndDate: date.AddDate(0, 1, 0)}},
+		Rules:     []models.Rule{{ID: 2, Name: "Rewe", PayeeContains: "rewe", CategoryID: &groceries, Tags: "food"}},
+		Transfers: []models.Transfer{{ID: transferID, FromAccountID: checking, ToAccountID: savings, Amount: 50, Date: date}},
+		Transactions: []models.Transaction{
+			{
+				ID: 40, Type: "expense", Amount: 42, CategoryID: groceries, AccountID: &checking, Date: date, Payee: "REWE",
+				Splits: []models.TransactionSplit{{CategoryID: groceries, Amount: 42}},
+				Tags:   []models.Tag{{ID: 20}},
+			},
+			{ID: 41, Type: "transfer", Amount: -50, AccountID: &checking, TransferID: &transferID, Date: date},
+			{ID: 42, Type: "transfer", Amount: 50, AccountID: &savings, TransferID: &transferID, Date: date},
+		},
+	}
+}
+
+func writeArchive(t *testing.T, data *models.ArchiveData) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	writer := NewWriter(&out)
+	assert.NoError(t, writer.WriteData(data))
+	assert.NoError(t, writer.WriteTransactions(func(fn func(models.Transaction) error) error {
+		for _, transaction := range data.Transactions {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	assert.NoError(t, writer.Close())
+	return out.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := archiveData()
+	content := writeArchive(t, data)
+
+	restored, err := Read(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	assert.Equal(t, data, restored)
+	assert.Equal(t, Summary{Categories: 1, Accounts: 2, Tags: 1, Budgets: 1, Rules: 1, Transfers: 1, Transactions: 3}, Summarize(restored))
+}
+
+func TestWriteEmptyTransactions(t *testing.T) {
+	data := archiveData()
+	data.Transactions = nil
+	content := writeArchive(t, data)
+
+	restored, err := Read(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	assert.Empty(t, restored.Transactions)
+}
+
+func TestReadRejectsInvalidArchives(t *testing.T) {
+	t.Run("Not a zip file", func(t *testing.T) {
+		_, err := Read(bytes.NewReader([]byte("plain text")), 10)
+		assert.Error(t, err)
+	})
+
+	t.Run("Newer version", func(t *testing.T) {
+		var out bytes.Buffer
+		writer := zip.NewWriter(&out)
+		file, _ := writer.Create(manifestFile)
+		_, _ = file.Write([]byte(`{"format":"` + FormatName + `","version":99}`))
+		assert.NoError(t, writer.Close())
+
+		_, err := Read(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+	})
+
+	t.Run("Unknown tag", func(t *testing.T) {
+		data := archiveData()
+		data.Transactions[0].Tags = []models.Tag{{ID: 99}}
+		content := writeArchive(t, data)
+
+		_, err := Read(bytes.NewReader(content), int64(len(content)))
+		assert.ErrorContains(t, err, "unknown tag 99")
+	})
+
+	t.Run("Unknown category", func(t *testing.T) {
+		for name, change := range map[string]func(*models.ArchiveData){
+			"transaction": func(data *models.ArchiveData) { data.Transactions[0].CategoryID = 99 },
+			"split":       func(data *models.ArchiveData) { data.Transactions[0].Splits[0].CategoryID = 99 },
+			"budget":      func(data *models.ArchiveData) { data.Budgets[0].CategoryID = 99 },
+			"uncategorised": func(data *models.ArchiveData) {
+				data.Transactions[0].CategoryID, data.Transactions[0].Splits = 0, nil
+			},
+		} {
+			data := archiveData()
+			change(data)
+			content := writeArchive(t, data)
+
+			_, err := Read(bytes.NewReader(content), int64(len(content)))
+			assert.ErrorContains(t, err, "unknown", name)
+			assert.ErrorContains(t, err, "category", name)
+		}
+	})
+
+	t.Run("Split transfer leg", func(t *testing.T) {
+		data := archiveData()
+		data.Transactions[1].Splits = []models.TransactionSplit{{CategoryID: 4, Amount: 50}}
+		content := writeArchive(t, data)
+
+		_, err := Read(bytes.NewReader(content), int64(len(content)))
+		assert.ErrorContains(t, err, "transfer legs cannot be split")
+	})
+
+	t.Run("Transfer leg without transfer", func(t *testing.T) {
+		data := archiveData()
+		data.Transfers = nil
+		content := writeArchive(t, data)
+
+		_, err := Read(bytes.NewReader(content), int64(len(content)))
+		assert.ErrorContains(t, err, "unknown transfer")
+	})
+}
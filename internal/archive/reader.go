@@ -0,0 +1,228 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/rules"
+)
+
+// ErrUnsupportedVersion is returned for archives written by a newer layout
+// than this package understands.
+var ErrUnsupportedVersion = errors.New("unsupported archive version")
+
+// maxSectionSize caps how far a single archive file may decompress, so a
+// small upload cannot expand into gigabytes.
+const maxSectionSize = 256 << 20
+
+type modeler[M any] interface {
+	model() M
+}
+
+// Read parses an archive and checks that every reference between its
+// records, including category IDs, points at a record in the archive.
+func Read(r io.ReaderAt, size int64) (*models.ArchiveData, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("file is not a zip archive")
+	}
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+
+	var manifest Manifest
+	if err := readJSON(files, manifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != FormatName {
+		return nil, fmt.Errorf("file is not a %s", FormatName)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, manifest.Version)
+	}
+
+	data := &models.ArchiveData{}
+	if data.Categories, err = readSection[category, models.Category](files, categoriesFile); err != nil {
+		return nil, err
+	}
+	if data.Accounts, err = readSection[account, models.Account](files, accountsFile); err != nil {
+		return nil, err
+	}
+	if data.Tags, err = readSection[tag, models.Tag](files, tagsFile); err != nil {
+		return nil, err
+	}
+	if data.Budgets, err = readSection[budget, models.Budget](files, budgetsFile); err != nil {
+		return nil, err
+	}
+	if data.Rules, err = readSection[rule, models.Rule](files, rulesFile); err != nil {
+		return nil, err
+	}
+	if data.Transfers, err = readSection[transfer, models.Transfer](files, transfersFile); err != nil {
+		return nil, err
+	}
+	if data.Transactions, err = readSection[transaction, models.Transaction](files, transactionsFile); err != nil {
+		return nil, err
+	}
+
+	if err := validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func readSection[R modeler[M], M any](files map[string]*zip.File, name string) ([]M, error) {
+	var records []R
+	if err := readJSON(files, name, &records); err != nil {
+		return nil, err
+	}
+
+	items := make([]M, 0, len(records))
+	for _, record := range records {
+		items = append(items, record.model())
+	}
+	return items, nil
+}
+
+func readJSON(files map[string]*zip.File, name string, value any) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("archive is missing %s", name)
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	defer content.Close()
+
+	if err := json.NewDecoder(io.LimitReader(content, maxSectionSize)).Decode(value); err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+	return nil
+}
+
+func validate(data *models.ArchiveData) error {
+	categoryNames := make(map[string]bool, len(data.Categories))
+	categories := make(map[uint]bool, len(data.Categories))
+	for _, category := range data.Categories {
+		name := strings.TrimSpace(category.Name)
+		if name == "" || len(name) > 100 {
+			return fmt.Errorf("category %d: name must be between 1 and 100 characters", category.ID)
+		}
+		if categories[category.ID] || categoryNames[name] {
+			return fmt.Errorf("category %d: listed twice", category.ID)
+		}
+		categories[category.ID] = true
+		categoryNames[name] = true
+	}
+
+	accounts := make(map[uint]bool, len(data.Accounts))
+	for _, account := range data.Accounts {
+		if accounts[account.ID] {
+			return fmt.Errorf("account %d: listed twice", account.ID)
+		}
+		if strings.TrimSpace(account.Name) == "" || len(account.Name) > 100 {
+			return fmt.Errorf("account %d: name must be between 1 and 100 characters", account.ID)
+		}
+		if !models.IsValidAccountType(account.Type) {
+			return fmt.Errorf("account %d: invalid type %q", account.ID, account.Type)
+		}
+		if len(account.Currency) != 3 {
+			return fmt.Errorf("account %d: invalid currency %q", account.ID, account.Currency)
+		}
+		accounts[account.ID] = true
+	}
+
+	tagNames := make(map[string]bool, len(data.Tags))
+	tags := make(map[uint]bool, len(data.Tags))
+	for _, tag := range data.Tags {
+		if tag.Name == "" || len(tag.Name) > 50 || tag.Name != strings.ToLower(strings.TrimSpace(tag.Name)) || strings.Contains(tag.Name, ",") {
+			return fmt.Errorf("tag %d: invalid name %q", tag.ID, tag.Name)
+		}
+		if tags[tag.ID] || tagNames[tag.Name] {
+			return fmt.Errorf("tag %d: listed twice", tag.ID)
+		}
+		tags[tag.ID] = true
+		tagNames[tag.Name] = true
+	}
+
+	for _, budget := range data.Budgets {
+		if !categories[budget.CategoryID] {
+			return fmt.Errorf("budget %d: unknown category %d", budget.ID, budget.CategoryID)
+		}
+	}
+
+	for _, rule := range data.Rules {
+		if rule.CategoryID != nil && !categories[*rule.CategoryID] {
+			return fmt.Errorf("rule %d: unknown category %d", rule.ID, *rule.CategoryID)
+		}
+		if strings.TrimSpace(rule.Name) == "" || len(rule.Name) > 100 {
+			return fmt.Errorf("rule %d: name must be between 1 and 100 characters", rule.ID)
+		}
+		if err := rules.Validate(rule); err != nil {
+			return fmt.Errorf("rule %d: %w", rule.ID, err)
+		}
+	}
+
+	transfers := make(map[uint]bool, len(data.Transfers))
+	for _, transfer := range data.Transfers {
+		if transfers[transfer.ID] {
+			return fmt.Errorf("transfer %d: listed twice", transfer.ID)
+		}
+		if !accounts[transfer.FromAccountID] || !accounts[transfer.ToAccountID] {
+			return fmt.Errorf("transfer %d: unknown account", transfer.ID)
+		}
+		transfers[transfer.ID] = true
+	}
+
+	transactions := make(map[uint]bool, len(data.Transactions))
+	for _, transaction := range data.Transactions {
+		if transactions[transaction.ID] {
+			return fmt.Errorf("transaction %d: listed twice", transaction.ID)
+		}
+		transactions[transaction.ID] = true
+
+		switch transaction.Type {
+		case "income", "expense":
+			if transaction.TransferID != nil {
+				return fmt.Errorf("transaction %d: only transfer legs can belong to a transfer", transaction.ID)
+			}
+		case "transfer":
+			if transaction.TransferID == nil || !transfers[*transaction.TransferID] {
+				return fmt.Errorf("transaction %d: unknown transfer", transaction.ID)
+			}
+			if len(transaction.Splits) > 0 {
+				return fmt.Errorf("transaction %d: transfer legs cannot be split", transaction.ID)
+			}
+		default:
+			return fmt.Errorf("transaction %d: invalid type %q", transaction.ID, transaction.Type)
+		}
+
+		// Transfer legs are the only transactions without a category.
+		if (transaction.Type != "transfer" || transaction.CategoryID != 0) && !categories[transaction.CategoryID] {
+			return fmt.Errorf("transaction %d: unknown category %d", transaction.ID, transaction.CategoryID)
+		}
+		for _, split := range transaction.Splits {
+			if !categories[split.CategoryID] {
+				return fmt.Errorf("transaction %d: unknown split category %d", transaction.ID, split.CategoryID)
+			}
+		}
+		if transaction.AccountID != nil && !accounts[*transaction.AccountID] {
+			return fmt.Errorf("transaction %d: unknown account", transaction.ID)
+		}
+		for _, tag := range transaction.Tags {
+			if !tags[tag.ID] {
+				return fmt.Errorf("transaction %d: unknown tag %d", transaction.ID, tag.ID)
+			}
+		}
+	}
+
+	return nil
+}
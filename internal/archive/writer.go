@@ -0,0 +1,109 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// Writer builds an archive section by section. Transactions are written as
+// they are streamed, so the archive never holds all of them in memory.
+type Writer struct {
+	zip     *zip.Writer
+	summary Summary
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zip: zip.NewWriter(w)}
+}
+
+// WriteData writes every section except the transactions, which are
+// written with WriteTransactions.
+func (w *Writer) WriteData(data *models.ArchiveData) error {
+	if err := writeSection(w, categoriesFile, data.Categories, newCategory); err != nil {
+		return err
+	}
+	if err := writeSection(w, accountsFile, data.Accounts, newAccount); err != nil {
+		return err
+	}
+	if err := writeSection(w, tagsFile, data.Tags, newTag); err != nil {
+		return err
+	}
+	if err := writeSection(w, budgetsFile, data.Budgets, newBudget); err != nil {
+		return err
+	}
+	if err := writeSection(w, rulesFile, data.Rules, newRule); err != nil {
+		return err
+	}
+	if err := writeSection(w, transfersFile, data.Transfers, newTransfer); err != nil {
+		return err
+	}
+
+	summary := Summarize(data)
+	summary.Transactions = w.summary.Transactions
+	w.summary = summary
+	return nil
+}
+
+// WriteTransactions writes the transactions that stream passes to its
+// callback, one at a time.
+func (w *Writer) WriteTransactions(stream func(fn func(models.Transaction) error) error) error {
+	section, err := w.zip.Create(transactionsFile)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", transactionsFile, err)
+	}
+
+	encoder := json.NewEncoder(section)
+	separator := "["
+	err = stream(func(t models.Transaction) error {
+		if _, err := io.WriteString(section, separator); err != nil {
+			return err
+		}
+		separator = ","
+		w.summary.Transactions++
+		return encoder.Encode(newTransaction(t))
+	})
+	if err != nil {
+		return fmt.Errorf("write %s: %w", transactionsFile, err)
+	}
+
+	if separator == "[" {
+		_, err = io.WriteString(section, "[]\n")
+	} else {
+		_, err = io.WriteString(section, "]\n")
+	}
+	return err
+}
+
+// Close writes the manifest and finishes the zip file. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	manifest := Manifest{Format: FormatName, Version: Version, ExportedAt: time.Now().UTC(), Counts: w.summary}
+	if err := writeJSON(w, manifestFile, manifest); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+func writeSection[M any, R any](w *Writer, name string, items []M, convert func(M) R) error {
+	records := make([]R, 0, len(items))
+	for _, item := range items {
+		records = append(records, convert(item))
+	}
+	return writeJSON(w, name, records)
+}
+
+func writeJSON(w *Writer, name string, value any) error {
+	section, err := w.zip.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	if err := json.NewEncoder(section).Encode(value); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// APIKeyPrefix starts every personal API key, so keys are told apart from
+// access tokens and are easy for secret scanners to spot.
+const APIKeyPrefix = "pft_"
+
+// APIKeyAuthenticator looks up the personal API key a request was sent
+// with.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error)
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenPair is what a successful login or refresh hands to the client: a
+// short-lived access token and the refresh token that renews it.
+type TokenPair struct {
+	AccessToken  string
+	ExpiresAt    time.Time
+	RefreshToken string
+}
+
+// NewOpaqueToken returns a random URL-safe token with 256 bits of entropy.
+func NewOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashOpaqueToken returns the hex-encoded SHA-256 of token. Opaque tokens
+// are random enough that a plain hash is safe to store and look up.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccountThrottleKey returns the key failed logins for the given email
+// address are counted against, whether or not an account uses it.
+func AccountThrottleKey(email string) string {
+	return HashOpaqueToken("account:" + strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,33 @@
+package auth
+
+// Scopes limit what an API key may do on the user's behalf.
+const (
+	ScopeAccountsRead      = "accounts:read"
+	ScopeAccountsWrite     = "accounts:write"
+	ScopeBudgetsRead       = "budgets:read"
+	ScopeBudgetsWrite      = "budgets:write"
+	ScopeReportsRead       = "reports:read"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+)
+
+// Scopes lists every scope a key can be given, in the order they are shown.
+var Scopes = []string{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeBudgetsRead,
+	ScopeBudgetsWrite,
+	ScopeReportsRead,
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+}
+
+// KnownScope reports whether scope is one of Scopes.
+func KnownScope(scope string) bool {
+	for _, known := range Scopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
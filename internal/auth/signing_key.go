@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signing algorithms supported for access tokens.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for RS256.
+const minRSAKeyBits = 2048
+
+// SigningKey is a key that signs or verifies access tokens. Its ID is the
+// RFC 7638 thumbprint of the key and is sent as the token's kid header, so
+// several keys can be accepted at once while one of them is rotated out.
+// Keys loaded from a public key can only verify.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+
+	secret     []byte
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// JSONWebKey is the public part of a signing key as published in a JWKS.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewHMACKey returns an HS256 key for a shared secret.
+func NewHMACKey(secret string) SigningKey {
+	key := SigningKey{Algorithm: AlgorithmHS256, secret: []byte(secret)}
+	key.ID = thumbprint(map[string]string{
+		"k":   base64.RawURLEncoding.EncodeToString(key.secret),
+		"kty": "oct",
+	})
+	return key
+}
+
+// ParsePrivateKey reads a PEM encoded Ed25519 or RSA private key, in PKCS #8
+// or, for RSA, PKCS #1 form. The algorithm follows from the key type.
+func ParsePrivateKey(data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("parse private key: %w", err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return SigningKey{}, errors.New("unsupported private key type")
+	}
+
+	key, err := newPublicSigningKey(signer.Public())
+	if err != nil {
+		return SigningKey{}, err
+	}
+	key.privateKey = signer
+	return key, nil
+}
+
+// ParseVerificationKey reads a PEM encoded Ed25519 or RSA public key, or a
+// private key of which only the public part is kept.
+func ParseVerificationKey(data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, errors.New("no PEM block found")
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		key.privateKey = nil
+		return key, nil
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("parse public key: %w", err)
+	}
+	return newPublicSigningKey(publicKey)
+}
+
+// PublicKey reads the key a JWKS publishes, such as one of an OpenID
+// Connect provider's keys. The key keeps the ID it was published under.
+func (k JSONWebKey) PublicKey() (SigningKey, error) {
+	var publicKey crypto.PublicKey
+	switch k.KeyType {
+	case "RSA":
+		modulus, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("decode RSA modulus: %w", err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+		if err != nil || len(exponent) == 0 || len(exponent) > 4 {
+			return SigningKey{}, errors.New("invalid RSA exponent")
+		}
+		publicKey = &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || k.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return SigningKey{}, errors.New("invalid Ed25519 key")
+		}
+		publicKey = ed25519.PublicKey(x)
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+
+	key, err := newPublicSigningKey(publicKey)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	if k.Algorithm != "" && k.Algorithm != key.Algorithm {
+		return SigningKey{}, fmt.Errorf("key algorithm %q does not match its type", k.Algorithm)
+	}
+	if k.KeyID != "" {
+		key.ID = k.KeyID
+	}
+	return key, nil
+}
+
+func newPublicSigningKey(publicKey crypto.PublicKey) (SigningKey, error) {
+	switch typed := publicKey.(type) {
+	case ed25519.PublicKey:
+		key := SigningKey{Algorithm: AlgorithmEdDSA, publicKey: typed}
+		key.ID = thumbprint(map[string]string{
+			"crv": "Ed25519",
+			"kty": "OKP",
+			"x":   base64.RawURLEncoding.EncodeToString(typed),
+		})
+		return key, nil
+	case *rsa.PublicKey:
+		if typed.N.BitLen() < minRSAKeyBits {
+			return SigningKey{}, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+		}
+		key := SigningKey{Algorithm: AlgorithmRS256, publicKey: typed}
+		key.ID = thumbprint(map[string]string{
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(typed.E)).Bytes()),
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(typed.N.Bytes()),
+		})
+		return key, nil
+	default:
+		return SigningKey{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// CanSign reports whether the key holds the secret or private part needed
+// to sign tokens.
+func (k SigningKey) CanSign() bool {
+	return k.secret != nil || k.privateKey != nil
+}
+
+// JWK returns the public part of the key. Shared secrets are never
+// published, so HMAC keys return false.
+func (k SigningKey) JWK() (JSONWebKey, bool) {
+	switch publicKey := k.publicKey.(type) {
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		}, true
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+			Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}, true
+	default:
+		return JSONWebKey{}, false
+	}
+}
+
+// Sign returns the key's signature of input. Keys loaded from a public
+// key cannot sign.
+func (k SigningKey) Sign(input string) ([]byte, error) {
+	switch {
+	case k.secret != nil:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(input))
+		return mac.Sum(nil), nil
+	case k.privateKey == nil:
+		return nil, errors.New("key cannot sign")
+	case k.Algorithm == AlgorithmEdDSA:
+		return k.privateKey.Sign(rand.Reader, []byte(input), crypto.Hash(0))
+	default:
+		digest := sha256.Sum256([]byte(input))
+		return k.privateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+}
+
+// Verify reports whether signature is the key's signature of input.
+func (k SigningKey) Verify(input string, signature []byte) bool {
+	if k.secret != nil {
+		expected, _ := k.Sign(input)
+		return hmac.Equal(signature, expected)
+	}
+
+	switch publicKey := k.publicKey.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(publicKey, []byte(input), signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256([]byte(input))
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// thumbprint computes an RFC 7638 JWK thumbprint from the key's required
+// members. encoding/json sorts map keys, which gives the canonical form.
+func thumbprint(members map[string]string) string {
+	canonical, _ := json.Marshal(members)
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -1,12 +1,12 @@
 package auth
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,56 +15,321 @@ import (
 
 const defaultJWTSecret = "development-secret-change-me"
 
+// Access tokens, MFA tokens and the tokens sent by email are told apart by
+// their typ header, so none of them can stand in for another.
+const (
+	tokenType                  = "JWT"
+	mfaTokenType               = "mfa+jwt"
+	emailVerificationTokenType = "email-verification+jwt"
+	passwordResetTokenType     = "password-reset+jwt"
+)
+
+// MFATokenTTL is how long a user has to enter their second factor once
+// their password was accepted.
+const MFATokenTTL = 5 * time.Minute
+
+// EmailVerificationTokenTTL and PasswordResetTokenTTL are how long the
+// links sent by email keep working.
+const (
+	EmailVerificationTokenTTL = 24 * time.Hour
+	PasswordResetTokenTTL     = time.Hour
+)
+
+// TokenError says why ParseToken rejected a token. Code is safe to return
+// to clients.
+type TokenError struct {
+	Code    string
+	Message string
+}
+
+func (e *TokenError) Error() string {
+	return e.Message
+}
+
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken       = &TokenError{Code: "invalid_token", Message: "invalid token"}
+	ErrInvalidTokenHeader = &TokenError{Code: "invalid_token_header", Message: "token algorithm or type is not accepted"}
+	ErrInvalidSignature   = &TokenError{Code: "invalid_token_signature", Message: "token signature is invalid"}
+	ErrInvalidIssuer      = &TokenError{Code: "invalid_token_issuer", Message: "token was issued by someone else"}
+	ErrInvalidAudience    = &TokenError{Code: "invalid_token_audience", Message: "token is meant for someone else"}
+	ErrInvalidIssuedAt    = &TokenError{Code: "invalid_token_issued_at", Message: "token issue time is missing or in the future"}
+	ErrTokenNotYetValid   = &TokenError{Code: "token_not_yet_valid", Message: "token is not valid yet"}
+	ErrExpiredToken       = &TokenError{Code: "token_expired", Message: "token has expired"}
 )
 
 type Claims struct {
-	UserID    uint   `json:"user_id"`
-	Email     string `json:"email"`
-	ExpiresAt int64  `json:"exp"`
+	UserID    uint     `json:"user_id"`
+	Email     string   `json:"email"`
+	ID        string   `json:"jti"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	// Scope lists the scopes of an access token, separated by spaces as
+	// in OAuth 2.0.
+	Scope string `json:"scope,omitempty"`
+}
+
+// Scopes returns what the token may be used for. A token without a scope
+// claim may not be used for anything that needs one.
+func (c *Claims) Scopes() []string {
+	if c.Scope == "" {
+		return nil
+	}
+	return strings.Fields(c.Scope)
+}
+
+// Audience is the aud claim, which a JWT may carry as a single string or
+// as a list.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Contains reports whether audience is one of the token's audiences.
+func (a Audience) Contains(audience string) bool {
+	for _, value := range a {
+		if value == audience {
+			return true
+		}
+	}
+	return false
 }
 
 type TokenManager interface {
-	GenerateToken(user *models.User) (string, error)
+	GenerateToken(user *models.User, scopes []string) (string, error)
 	ParseToken(token string) (*Claims, error)
 }
 
+// MFATokenManager issues and checks the short-lived tokens that stand for
+// a login waiting for its second factor.
+type MFATokenManager interface {
+	GenerateMFAToken(user *models.User) (string, error)
+	ParseMFAToken(token string) (*Claims, error)
+}
+
+// EmailTokenManager issues and checks the tokens sent by email to verify
+// an address or to reset a password.
+type EmailTokenManager interface {
+	GenerateEmailVerificationToken(user *models.User) (string, error)
+	ParseEmailVerificationToken(token string) (*Claims, error)
+	GeneratePasswordResetToken(user *models.User) (string, error)
+	ParsePasswordResetToken(token string) (*Claims, error)
+}
+
+// RevocationChecker reports whether a token that parsed successfully has
+// since been revoked, by logging out or by a per-user watermark.
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
 type JWTManager struct {
-	secret []byte
-	ttl    time.Duration
+	signingKey SigningKey
+	keys       map[string]SigningKey
+	ttl        time.Duration
+	issuer     string
+	audience   string
+	clockSkew  time.Duration
+}
+
+// JWTManagerOption configures the claims a token manager issues and
+// requires.
+type JWTManagerOption func(*JWTManager)
+
+// WithIssuer sets the iss claim of new tokens and rejects tokens with any
+// other issuer.
+func WithIssuer(issuer string) JWTManagerOption {
+	return func(m *JWTManager) {
+		m.issuer = issuer
+	}
+}
+
+// WithAudience sets the aud claim of new tokens and rejects tokens that
+// are not meant for it.
+func WithAudience(audience string) JWTManagerOption {
+	return func(m *JWTManager) {
+		m.audience = audience
+	}
+}
+
+// WithClockSkew sets how far the exp, nbf and iat claims may be off
+// because the issuing instance's clock differs from this one's.
+func WithClockSkew(skew time.Duration) JWTManagerOption {
+	return func(m *JWTManager) {
+		if skew >= 0 {
+			m.clockSkew = skew
+		}
+	}
 }
 
-func NewJWTManager(secret string, ttl time.Duration) *JWTManager {
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// NewJWTManager returns a manager that signs and verifies with one shared
+// HS256 secret.
+func NewJWTManager(secret string, ttl time.Duration, options ...JWTManagerOption) *JWTManager {
 	if secret == "" {
 		secret = defaultJWTSecret
 	}
 
-	return &JWTManager{
-		secret: []byte(secret),
-		ttl:    ttl,
+	manager, _ := NewJWTManagerWithKeys(NewHMACKey(secret), nil, ttl, options...)
+	return manager
+}
+
+// NewJWTManagerWithKeys returns a manager that signs with signingKey and
+// also accepts tokens signed by any of the verification keys, which lets a
+// key be rotated without logging everyone out.
+func NewJWTManagerWithKeys(signingKey SigningKey, verificationKeys []SigningKey, ttl time.Duration, options ...JWTManagerOption) (*JWTManager, error) {
+	if !signingKey.CanSign() {
+		return nil, errors.New("signing key has no private part")
+	}
+
+	keys := map[string]SigningKey{signingKey.ID: signingKey}
+	for _, key := range verificationKeys {
+		if _, exists := keys[key.ID]; !exists {
+			keys[key.ID] = key
+		}
+	}
+
+	manager := &JWTManager{signingKey: signingKey, keys: keys, ttl: ttl}
+	for _, option := range options {
+		option(manager)
+	}
+
+	return manager, nil
+}
+
+// JWKS returns the public keys tokens can be verified with. Shared secrets
+// are not included.
+func (m *JWTManager) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range m.sortedKeys() {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// sortedKeys lists the signing key first and the others by ID, so the
+// published key set is stable.
+func (m *JWTManager) sortedKeys() []SigningKey {
+	keys := []SigningKey{m.signingKey}
+	ids := make([]string, 0, len(m.keys))
+	for id := range m.keys {
+		if id != m.signingKey.ID {
+			ids = append(ids, id)
+		}
 	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys = append(keys, m.keys[id])
+	}
+	return keys
+}
+
+// GenerateToken returns an access token for a logged-in user that may be
+// used for the given scopes.
+func (m *JWTManager) GenerateToken(user *models.User, scopes []string) (string, error) {
+	return m.generate(user, tokenType, m.ttl, scopes)
+}
+
+func (m *JWTManager) ParseToken(token string) (*Claims, error) {
+	return m.parse(token, tokenType)
+}
+
+// GenerateMFAToken returns a token that can only be exchanged for an
+// access token together with a second factor.
+func (m *JWTManager) GenerateMFAToken(user *models.User) (string, error) {
+	return m.generate(user, mfaTokenType, MFATokenTTL, nil)
+}
+
+// ParseMFAToken checks a token from GenerateMFAToken.
+func (m *JWTManager) ParseMFAToken(token string) (*Claims, error) {
+	return m.parse(token, mfaTokenType)
+}
+
+// GenerateEmailVerificationToken returns a token that proves the user can
+// read mail sent to their current email address.
+func (m *JWTManager) GenerateEmailVerificationToken(user *models.User) (string, error) {
+	return m.generate(user, emailVerificationTokenType, EmailVerificationTokenTTL, nil)
 }
 
-func (m *JWTManager) GenerateToken(user *models.User) (string, error) {
+// ParseEmailVerificationToken checks a token from
+// GenerateEmailVerificationToken.
+func (m *JWTManager) ParseEmailVerificationToken(token string) (*Claims, error) {
+	return m.parse(token, emailVerificationTokenType)
+}
+
+// GeneratePasswordResetToken returns a token that lets the user set a new
+// password without knowing the current one.
+func (m *JWTManager) GeneratePasswordResetToken(user *models.User) (string, error) {
+	return m.generate(user, passwordResetTokenType, PasswordResetTokenTTL, nil)
+}
+
+// ParsePasswordResetToken checks a token from GeneratePasswordResetToken.
+func (m *JWTManager) ParsePasswordResetToken(token string) (*Claims, error) {
+	return m.parse(token, passwordResetTokenType)
+}
+
+func (m *JWTManager) generate(user *models.User, typ string, ttl time.Duration, scopes []string) (string, error) {
 	if user == nil || user.ID == 0 {
 		return "", errors.New("user is required")
 	}
 
-	headerJSON, err := json.Marshal(map[string]string{
-		"alg": "HS256",
-		"typ": "JWT",
+	headerJSON, err := json.Marshal(tokenHeader{
+		Algorithm: m.signingKey.Algorithm,
+		Type:      typ,
+		KeyID:     m.signingKey.ID,
 	})
 	if err != nil {
 		return "", fmt.Errorf("marshal header: %w", err)
 	}
 
-	claimsJSON, err := json.Marshal(Claims{
+	tokenID, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := Claims{
 		UserID:    user.ID,
 		Email:     user.Email,
-		ExpiresAt: time.Now().Add(m.ttl).Unix(),
-	})
+		ID:        tokenID,
+		Issuer:    m.issuer,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		Scope:     strings.Join(scopes, " "),
+	}
+	if m.audience != "" {
+		claims.Audience = Audience{m.audience}
+	}
+
+	claimsJSON, err := json.Marshal(claims)
 	if err != nil {
 		return "", fmt.Errorf("marshal claims: %w", err)
 	}
@@ -72,28 +337,43 @@ func (m *JWTManager) GenerateToken(user *models.User) (string, error) {
 	header := base64.RawURLEncoding.EncodeToString(headerJSON)
 	payload := base64.RawURLEncoding.EncodeToString(claimsJSON)
 	signingInput := header + "." + payload
-	signature := base64.RawURLEncoding.EncodeToString(m.sign(signingInput))
+	signature, err := m.signingKey.Sign(signingInput)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
 
-	return signingInput + "." + signature, nil
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
 }
 
-func (m *JWTManager) ParseToken(token string) (*Claims, error) {
+func (m *JWTManager) parse(token, typ string) (*Claims, error) {
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
 		return nil, ErrInvalidToken
 	}
 
-	signingInput := parts[0] + "." + parts[1]
-	expectedSignature := m.sign(signingInput)
-	receivedSignature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
 
-	if !hmac.Equal(receivedSignature, expectedSignature) {
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
 		return nil, ErrInvalidToken
 	}
 
+	receivedSignature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !strings.EqualFold(header.Type, typ) {
+		return nil, ErrInvalidTokenHeader
+	}
+
+	if err := m.verify(header, parts[0]+"."+parts[1], receivedSignature); err != nil {
+		return nil, err
+	}
+
 	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -108,15 +388,64 @@ func (m *JWTManager) ParseToken(token string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if time.Now().Unix() >= claims.ExpiresAt {
-		return nil, ErrExpiredToken
+	if err := m.validateClaims(&claims, time.Now()); err != nil {
+		return nil, err
 	}
 
 	return &claims, nil
 }
 
-func (m *JWTManager) sign(input string) []byte {
-	mac := hmac.New(sha256.New, m.secret)
-	mac.Write([]byte(input))
-	return mac.Sum(nil)
+// validateClaims checks the registered claims. Times are compared with
+// the configured clock skew in the token's favour.
+func (m *JWTManager) validateClaims(claims *Claims, now time.Time) error {
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return ErrInvalidIssuer
+	}
+	if m.audience != "" && !claims.Audience.Contains(m.audience) {
+		return ErrInvalidAudience
+	}
+
+	earliest := now.Add(-m.clockSkew)
+	latest := now.Add(m.clockSkew)
+	if claims.IssuedAt == 0 || time.Unix(claims.IssuedAt, 0).After(latest) {
+		return ErrInvalidIssuedAt
+	}
+	if claims.NotBefore != 0 && time.Unix(claims.NotBefore, 0).After(latest) {
+		return ErrTokenNotYetValid
+	}
+	if !earliest.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return ErrExpiredToken
+	}
+
+	return nil
+}
+
+// verify checks the signature with the key named by the header. Tokens
+// from before keys had IDs carry no kid and are tried against the shared
+// secrets. The header's algorithm must be the key's, so a token cannot pick
+// a weaker way to be checked.
+func (m *JWTManager) verify(header tokenHeader, signingInput string, signature []byte) error {
+	if header.KeyID != "" {
+		key, ok := m.keys[header.KeyID]
+		if !ok {
+			return ErrInvalidSignature
+		}
+		if key.Algorithm != header.Algorithm {
+			return ErrInvalidTokenHeader
+		}
+		if !key.Verify(signingInput, signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	if header.Algorithm != AlgorithmHS256 {
+		return ErrInvalidTokenHeader
+	}
+	for _, key := range m.sortedKeys() {
+		if key.Algorithm == AlgorithmHS256 && key.Verify(signingInput, signature) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
 }
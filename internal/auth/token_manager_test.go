@@ -0,0 +1,486 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTManagerSigningAlgorithms(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		key       SigningKey
+		algorithm string
+	}{
+		{name: "HS256", key: NewHMACKey("secret"), algorithm: AlgorithmHS256},
+		{name: "EdDSA", key: parsePrivateKeyForTest(t, edKey), algorithm: AlgorithmEdDSA},
+		{name: "RS256", key: parsePrivateKeyForTest(t, rsaKey), algorithm: AlgorithmRS256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := NewJWTManagerWithKeys(tt.key, nil, time.Minute)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			token, err := manager.GenerateToken(&models.User{ID: 7, Email: "user@example.com"}, Scopes)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			header := decodeHeaderForTest(t, token)
+			assert.Equal(t, tt.algorithm, header.Algorithm)
+			assert.Equal(t, tt.key.ID, header.KeyID)
+
+			claims, err := manager.ParseToken(token)
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint(7), claims.UserID)
+			}
+		})
+	}
+}
+
+func TestJWTManagerKeyRotation(t *testing.T) {
+	_, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oldKey := parsePrivateKeyForTest(t, oldPrivate)
+	oldManager, err := NewJWTManagerWithKeys(oldKey, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldToken, err := oldManager.GenerateToken(&models.User{ID: 1}, Scopes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(oldPrivate.Public())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retiredKey, err := ParseVerificationKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, oldKey.ID, retiredKey.ID)
+	assert.False(t, retiredKey.CanSign())
+
+	newKey := parsePrivateKeyForTest(t, newPrivate)
+	rotated, err := NewJWTManagerWithKeys(newKey, []SigningKey{retiredKey}, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("should accept tokens signed with a retired key", func(t *testing.T) {
+		claims, err := rotated.ParseToken(oldToken)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(1), claims.UserID)
+		}
+	})
+
+	t.Run("should reject tokens once the key is dropped", func(t *testing.T) {
+		manager, err := NewJWTManagerWithKeys(newKey, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = manager.ParseToken(oldToken)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("should publish both public keys with the signing key first", func(t *testing.T) {
+		keySet := rotated.JWKS()
+		if assert.Len(t, keySet.Keys, 2) {
+			assert.Equal(t, newKey.ID, keySet.Keys[0].KeyID)
+			assert.Equal(t, oldKey.ID, keySet.Keys[1].KeyID)
+			assert.Equal(t, "OKP", keySet.Keys[0].KeyType)
+			assert.Equal(t, "Ed25519", keySet.Keys[0].Curve)
+		}
+	})
+
+	t.Run("should refuse a key that cannot sign", func(t *testing.T) {
+		_, err := NewJWTManagerWithKeys(retiredKey, nil, time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+func TestJWTManagerRejectsForgedHeaders(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key := parsePrivateKeyForTest(t, private)
+	manager, err := NewJWTManagerWithKeys(key, []SigningKey{NewHMACKey("previous")}, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := manager.GenerateToken(&models.User{ID: 1}, Scopes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("should reject an unknown kid", func(t *testing.T) {
+		forged := replaceHeaderForTest(t, token, tokenHeader{Algorithm: AlgorithmEdDSA, Type: "JWT", KeyID: "unknown"})
+
+		_, err := manager.ParseToken(forged)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("should reject an algorithm that does not match the key", func(t *testing.T) {
+		forged := replaceHeaderForTest(t, token, tokenHeader{Algorithm: AlgorithmHS256, Type: "JWT", KeyID: key.ID})
+
+		_, err := manager.ParseToken(forged)
+		assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+	})
+
+	t.Run("should reject the none algorithm", func(t *testing.T) {
+		forged := replaceHeaderForTest(t, token, tokenHeader{Algorithm: "none", Type: "JWT"})
+
+		_, err := manager.ParseToken(forged)
+		assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+	})
+
+	t.Run("should reject a token type other than JWT", func(t *testing.T) {
+		forged := replaceHeaderForTest(t, token, tokenHeader{Algorithm: AlgorithmEdDSA, Type: "dpop+jwt", KeyID: key.ID})
+
+		_, err := manager.ParseToken(forged)
+		assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+	})
+
+	t.Run("should not publish shared secrets", func(t *testing.T) {
+		keySet := manager.JWKS()
+		if assert.Len(t, keySet.Keys, 1) {
+			assert.Equal(t, key.ID, keySet.Keys[0].KeyID)
+		}
+	})
+}
+
+func TestJWTManagerAcceptsLegacyTokensWithoutKeyID(t *testing.T) {
+	legacySecret := NewHMACKey("legacy")
+	legacy, err := NewJWTManagerWithKeys(legacySecret, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := legacy.GenerateToken(&models.User{ID: 3}, Scopes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parts := strings.Split(replaceHeaderForTest(t, token, tokenHeader{Algorithm: AlgorithmHS256, Type: "JWT"}), ".")
+	signature, err := legacySecret.Sign(parts[0] + "." + parts[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	legacyToken := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	manager, err := NewJWTManagerWithKeys(NewHMACKey("current"), []SigningKey{legacySecret}, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := manager.ParseToken(legacyToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(3), claims.UserID)
+	}
+}
+
+func TestParsePrivateKeyRejectsShortRSAKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	assert.Error(t, err)
+}
+
+func TestJSONWebKeyPublicKey(t *testing.T) {
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, private := range []crypto.Signer{rsaPrivate, edPrivate} {
+		signingKey := parsePrivateKeyForTest(t, private)
+		jwk, ok := signingKey.JWK()
+		if !ok {
+			t.Fatal("expected a JWK")
+		}
+		jwk.KeyID = "provider-key"
+
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assert.Equal(t, "provider-key", publicKey.ID)
+		assert.Equal(t, signingKey.Algorithm, publicKey.Algorithm)
+		assert.False(t, publicKey.CanSign())
+
+		signature, err := signingKey.Sign("header.payload")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assert.True(t, publicKey.Verify("header.payload", signature))
+		assert.False(t, publicKey.Verify("header.other", signature))
+	}
+
+	_, err = JSONWebKey{KeyType: "EC", Curve: "P-256"}.PublicKey()
+	assert.Error(t, err)
+
+	jwk, _ := parsePrivateKeyForTest(t, rsaPrivate).JWK()
+	jwk.Algorithm = AlgorithmEdDSA
+	_, err = jwk.PublicKey()
+	assert.Error(t, err)
+}
+
+func parsePrivateKeyForTest(t *testing.T, private crypto.Signer) SigningKey {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+
+	key, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	return key
+}
+
+func decodeHeaderForTest(t *testing.T, token string) tokenHeader {
+	t.Helper()
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatalf("decode header: %v", err)
+	}
+
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		t.Fatalf("unmarshal header: %v", err)
+	}
+	return header
+}
+
+func replaceHeaderForTest(t *testing.T, token string, header tokenHeader) string {
+	t.Helper()
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("marshal header: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	parts[0] = base64.RawURLEncoding.EncodeToString(headerJSON)
+	return strings.Join(parts, ".")
+}
+
+func TestJWTManagerValidatesClaims(t *testing.T) {
+	key := NewHMACKey("secret")
+	manager, err := NewJWTManagerWithKeys(key, nil, time.Minute,
+		WithIssuer("https://finance.example.com"),
+		WithAudience("finance-api"),
+		WithClockSkew(30*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	valid := func() Claims {
+		return Claims{
+			UserID:    1,
+			Issuer:    "https://finance.example.com",
+			Audience:  Audience{"finance-api"},
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(time.Minute).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Claims)
+		wantErr error
+	}{
+		{name: "accepts valid claims", modify: func(*Claims) {}},
+		{name: "accepts an audience list", modify: func(c *Claims) { c.Audience = Audience{"other", "finance-api"} }},
+		{name: "accepts an expiry within the clock skew", modify: func(c *Claims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }},
+		{name: "accepts a not-before within the clock skew", modify: func(c *Claims) { c.NotBefore = now.Add(10 * time.Second).Unix() }},
+		{name: "rejects another issuer", modify: func(c *Claims) { c.Issuer = "https://evil.example.com" }, wantErr: ErrInvalidIssuer},
+		{name: "rejects a missing audience", modify: func(c *Claims) { c.Audience = nil }, wantErr: ErrInvalidAudience},
+		{name: "rejects another audience", modify: func(c *Claims) { c.Audience = Audience{"other"} }, wantErr: ErrInvalidAudience},
+		{name: "rejects a missing issue time", modify: func(c *Claims) { c.IssuedAt = 0 }, wantErr: ErrInvalidIssuedAt},
+		{name: "rejects an issue time in the future", modify: func(c *Claims) { c.IssuedAt = now.Add(time.Minute).Unix() }, wantErr: ErrInvalidIssuedAt},
+		{name: "rejects a token that is not valid yet", modify: func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() }, wantErr: ErrTokenNotYetValid},
+		{name: "rejects an expired token", modify: func(c *Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }, wantErr: ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(&claims)
+
+			_, err := manager.ParseToken(signClaimsForTest(t, key, claims))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestJWTManagerIssuesRegisteredClaims(t *testing.T) {
+	manager := NewJWTManager("secret", time.Minute, WithIssuer("issuer"), WithAudience("audience"))
+
+	token, err := manager.GenerateToken(&models.User{ID: 1}, Scopes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	assert.Equal(t, "issuer", claims["iss"])
+	assert.Equal(t, "audience", claims["aud"])
+	assert.Equal(t, claims["iat"], claims["nbf"])
+	assert.Equal(t, strings.Join(Scopes, " "), claims["scope"])
+}
+
+func TestClaimsScopes(t *testing.T) {
+	assert.Equal(t, []string{ScopeReportsRead, ScopeBudgetsRead}, (&Claims{Scope: "reports:read budgets:read"}).Scopes())
+	assert.Nil(t, (&Claims{}).Scopes(), "a token without a scope claim gets no scopes")
+
+	manager := NewJWTManager("secret", time.Minute)
+	token, err := manager.GenerateMFAToken(&models.User{ID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims, err := manager.ParseMFAToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Empty(t, claims.Scope)
+}
+
+func signClaimsForTest(t *testing.T, key SigningKey, claims Claims) string {
+	t.Helper()
+
+	headerJSON, err := json.Marshal(tokenHeader{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		t.Fatalf("marshal header: %v", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature, err := key.Sign(signingInput)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTManagerKeepsMFATokensApart(t *testing.T) {
+	manager := NewJWTManager("secret", time.Minute)
+	user := &models.User{ID: 1}
+
+	accessToken, err := manager.GenerateToken(user, Scopes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mfaToken, err := manager.GenerateMFAToken(user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := manager.ParseMFAToken(mfaToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(1), claims.UserID)
+		assert.LessOrEqual(t, claims.ExpiresAt-claims.IssuedAt, int64(MFATokenTTL/time.Second))
+	}
+
+	_, err = manager.ParseToken(mfaToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+
+	_, err = manager.ParseMFAToken(accessToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+}
+
+func TestJWTManagerKeepsEmailTokensApart(t *testing.T) {
+	manager := NewJWTManager("secret", time.Minute)
+	user := &models.User{ID: 1, Email: "jane@example.com"}
+
+	verificationToken, err := manager.GenerateEmailVerificationToken(user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resetToken, err := manager.GeneratePasswordResetToken(user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := manager.ParseEmailVerificationToken(verificationToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "jane@example.com", claims.Email)
+		assert.NotEmpty(t, claims.ID)
+		assert.Equal(t, int64(EmailVerificationTokenTTL/time.Second), claims.ExpiresAt-claims.IssuedAt)
+	}
+
+	claims, err = manager.ParsePasswordResetToken(resetToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint(1), claims.UserID)
+		assert.Equal(t, int64(PasswordResetTokenTTL/time.Second), claims.ExpiresAt-claims.IssuedAt)
+	}
+
+	_, err = manager.ParsePasswordResetToken(verificationToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+
+	_, err = manager.ParseEmailVerificationToken(resetToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+
+	_, err = manager.ParseToken(resetToken)
+	assert.ErrorIs(t, err, ErrInvalidTokenHeader)
+}
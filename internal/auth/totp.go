@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters from RFC 6238 that authenticator apps assume when a
+// provisioning URI does not say otherwise.
+const (
+	totpPeriod      = 30
+	totpDigits      = 6
+	totpModulus     = 1_000_000
+	totpSecretBytes = 20
+	// totpDriftSteps is how many periods either side of the current one a
+	// code is still accepted, for phones whose clocks are slightly off.
+	totpDriftSteps = 1
+)
+
+// Recovery codes are ten characters from the base32 alphabet, shown in
+// two groups of five.
+const (
+	RecoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPEnrolment is a secret waiting to be confirmed with a first code,
+// together with the URI an authenticator app reads from a QR code.
+type TOTPEnrolment struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// MFAChallenge stands for a login whose password was accepted but whose
+// second factor has not been checked yet.
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// NewTOTPSecret returns a random base32 secret for an authenticator app.
+func NewTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPProvisioningURI returns the otpauth:// URI for a secret, labelled
+// with the issuer and the user's account name.
+func TOTPProvisioningURI(issuer, account, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + query.Encode()
+}
+
+// ValidateTOTP checks a code against the secret for the period of now and
+// the periods next to it. It returns the time step the code belongs to, so
+// the caller can refuse to accept the same code twice.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpDriftSteps; step <= current+totpDriftSteps; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateTOTPCode returns the code an authenticator app shows for the
+// secret at the given time.
+func GenerateTOTPCode(secret string, at time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decode totp secret: %w", err)
+	}
+	return totpCode(key, at.Unix()/totpPeriod), nil
+}
+
+// totpCode computes the RFC 4226 HOTP value for a time step.
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulus)
+}
+
+// NewRecoveryCodes returns a set of random one-time recovery codes.
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		var code strings.Builder
+		for j, b := range raw {
+			if j == recoveryCodeLength/2 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeChars[int(b)%len(recoveryCodeChars)])
+		}
+		codes[i] = code.String()
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored under. Case,
+// spaces and dashes are ignored so codes can be typed as they were shown.
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+	return HashOpaqueToken(normalized)
+}
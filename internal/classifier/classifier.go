@@ -0,0 +1,156 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// maxTokenLength mirrors the size of the classifier_tokens.token column.
+const maxTokenLength = 50
+
+// Tokenize splits texts such as a note and a payee into the distinct
+// lowercase words the classifier learns from. Single characters and pure
+// numbers, which are mostly dates, amounts and card digits, are dropped.
+func Tokenize(texts ...string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+
+	for _, text := range texts {
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for _, word := range words {
+			runes := []rune(word)
+			if len(runes) < 2 || isNumber(word) {
+				continue
+			}
+			if len(runes) > maxTokenLength {
+				word = string(runes[:maxTokenLength])
+			}
+			if !seen[word] {
+				seen[word] = true
+				tokens = append(tokens, word)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// Example turns a stored transaction into a training example with the given
+// weight. Transfers, split transactions and transactions without words to
+// learn from are not examples.
+func Example(transaction models.Transaction, weight int) (models.ClassifierExample, bool) {
+	if transaction.Type == "transfer" || transaction.CategoryID == 0 || len(transaction.Splits) > 0 {
+		return models.ClassifierExample{}, false
+	}
+
+	tokens := Tokenize(transaction.Note, transaction.Payee)
+	if len(tokens) == 0 {
+		return models.ClassifierExample{}, false
+	}
+
+	return models.ClassifierExample{CategoryID: transaction.CategoryID, Tokens: tokens, Weight: weight}, true
+}
+
+// Rank scores every learned category for the given tokens with multinomial
+// naive Bayes and Laplace smoothing, and returns up to limit categories with
+// their normalised probabilities, most likely first. It returns nothing when
+// none of the tokens has been seen before, since the prior alone says nothing
+// about this transaction.
+func Rank(categories []models.ClassifierCategory, tokenCounts []models.ClassifierToken, vocabulary int64, tokens []string, limit int) []models.CategorySuggestion {
+	totalDocuments := 0
+	for _, category := range categories {
+		totalDocuments += category.Documents
+	}
+	if totalDocuments == 0 || vocabulary == 0 {
+		return nil
+	}
+
+	counts := make(map[string]map[uint]int)
+	for _, tokenCount := range tokenCounts {
+		if counts[tokenCount.Token] == nil {
+			counts[tokenCount.Token] = make(map[uint]int)
+		}
+		counts[tokenCount.Token][tokenCount.CategoryID] += tokenCount.Occurrences
+	}
+
+	known := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if counts[token] != nil {
+			known = append(known, token)
+		}
+	}
+	if len(known) == 0 {
+		return nil
+	}
+
+	scores := make([]float64, 0, len(categories))
+	candidates := make([]models.ClassifierCategory, 0, len(categories))
+	for _, category := range categories {
+		if category.Documents <= 0 {
+			continue
+		}
+
+		score := math.Log(float64(category.Documents) / float64(totalDocuments))
+		denominator := float64(category.Tokens) + float64(vocabulary)
+		for _, token := range known {
+			score += math.Log((float64(counts[token][category.CategoryID]) + 1) / denominator)
+		}
+
+		scores = append(scores, score)
+		candidates = append(candidates, category)
+	}
+
+	suggestions := normalise(candidates, scores)
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].CategoryID < suggestions[j].CategoryID
+	})
+
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// normalise turns log scores into probabilities that add up to one,
+// subtracting the highest score first so the exponentials cannot underflow.
+func normalise(categories []models.ClassifierCategory, scores []float64) []models.CategorySuggestion {
+	highest := math.Inf(-1)
+	for _, score := range scores {
+		highest = math.Max(highest, score)
+	}
+
+	var total float64
+	weights := make([]float64, len(scores))
+	for i, score := range scores {
+		weights[i] = math.Exp(score - highest)
+		total += weights[i]
+	}
+
+	suggestions := make([]models.CategorySuggestion, 0, len(categories))
+	for i, category := range categories {
+		suggestions = append(suggestions, models.CategorySuggestion{
+			CategoryID: category.CategoryID,
+			Confidence: weights[i] / total,
+		})
+	}
+	return suggestions
+}
+
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
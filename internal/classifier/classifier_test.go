@@ -0,0 +1,65 @@
+package classifier
+
+import (
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("REWE Markt 1234 / Card *5678 on 2026-03-01", "Rewe Markt GmbH")
+	assert.Equal(t, []string{"rewe", "markt", "card", "on", "gmbh"}, tokens)
+
+	assert.Empty(t, Tokenize("", "a 1 - 42"))
+}
+
+func TestExample(t *testing.T) {
+	example, ok := Example(models.Transaction{Type: "expense", CategoryID: 3, Note: "Coffee", Payee: "Bean Bar"}, 1)
+	assert.True(t, ok)
+	assert.Equal(t, models.ClassifierExample{CategoryID: 3, Tokens: []string{"coffee", "bean", "bar"}, Weight: 1}, example)
+
+	_, ok = Example(models.Transaction{Type: "transfer", CategoryID: 3, Note: "Savings"}, 1)
+	assert.False(t, ok)
+
+	_, ok = Example(models.Transaction{Type: "expense", CategoryID: 3, Note: "Shop", Splits: []models.TransactionSplit{{CategoryID: 3}}}, 1)
+	assert.False(t, ok)
+
+	_, ok = Example(models.Transaction{Type: "expense", CategoryID: 3, Note: "12345"}, 1)
+	assert.False(t, ok)
+}
+
+func TestRank(t *testing.T) {
+	// Groceries (1) learned "rewe markt" twice, transport (2) learned "db ticket" once.
+	categories := []models.ClassifierCategory{
+		{CategoryID: 1, Documents: 2, Tokens: 4},
+		{CategoryID: 2, Documents: 1, Tokens: 2},
+	}
+	tokenCounts := []models.ClassifierToken{
+		{CategoryID: 1, Token: "rewe", Occurrences: 2},
+		{CategoryID: 1, Token: "markt", Occurrences: 2},
+		{CategoryID: 2, Token: "ticket", Occurrences: 1},
+	}
+
+	t.Run("Rank the most likely category first", func(t *testing.T) {
+		suggestions := Rank(categories, tokenCounts, 4, []string{"rewe", "city"}, 5)
+		assert.Len(t, suggestions, 2)
+		assert.Equal(t, uint(1), suggestions[0].CategoryID)
+		assert.Greater(t, suggestions[0].Confidence, 0.8)
+		assert.InDelta(t, 1.0, suggestions[0].Confidence+suggestions[1].Confidence, 1e-9)
+	})
+
+	t.Run("Limit the number of suggestions", func(t *testing.T) {
+		suggestions := Rank(categories, tokenCounts, 4, []string{"ticket"}, 1)
+		assert.Len(t, suggestions, 1)
+		assert.Equal(t, uint(2), suggestions[0].CategoryID)
+	})
+
+	t.Run("Suggest nothing for unseen words", func(t *testing.T) {
+		assert.Empty(t, Rank(categories, tokenCounts, 4, []string{"cinema"}, 5))
+	})
+
+	t.Run("Suggest nothing without training data", func(t *testing.T) {
+		assert.Empty(t, Rank(nil, nil, 0, []string{"rewe"}, 5))
+	})
+}
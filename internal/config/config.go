@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -16,18 +17,41 @@ const (
 	defaultWriteTimeout      = 10 * time.Second
 	defaultIdleTimeout       = 60 * time.Second
 	defaultShutdownTimeout   = 10 * time.Second
-	defaultTokenTTL          = 24 * time.Hour
+	defaultTokenTTL          = 15 * time.Minute
+	defaultRefreshTokenTTL   = 30 * 24 * time.Hour
+	defaultRevocationTTL     = 30 * time.Second
+	defaultSigningAlgorithm  = "HS256"
+	defaultClockSkew         = 30 * time.Second
+	defaultTOTPIssuer        = "Personal Finance Tracker"
+	defaultLoginMaxFailures  = 5
+	defaultLoginMaxAddress   = 20
+	defaultLoginLockout      = time.Minute
+	defaultLoginMaxLockout   = time.Hour
+	defaultPasswordMinLength = 8
+	maxPasswordMinLength     = 72
+	defaultPasswordMinScore  = 2
+	maxPasswordScore         = 4
+	defaultDuplicateWindow   = 72 * time.Hour
+	defaultMailDriver        = "log"
+	defaultMailFrom          = "Personal Finance Tracker <no-reply@localhost>"
+	defaultSMTPPort          = 587
+	defaultAppBaseURL        = "http://localhost:8080"
+	defaultOIDCScopes        = "openid,email,profile"
 	defaultServiceName       = "go-personal-finance-tracker"
 	defaultTraceSampleRatio  = 1.0
 )
 
 type Config struct {
-	DatabaseURL string
-	JWTSecret   string
-	Port        string
-	HTTP        HTTPConfig
-	Auth        AuthConfig
-	Tracing     TracingConfig
+	DatabaseURL  string
+	JWTSecret    string
+	Port         string
+	HTTP         HTTPConfig
+	Auth         AuthConfig
+	Users        UsersConfig
+	Transactions TransactionsConfig
+	Mail         MailConfig
+	OIDC         OIDCConfig
+	Tracing      TracingConfig
 }
 
 type HTTPConfig struct {
@@ -36,10 +60,93 @@ type HTTPConfig struct {
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
 	ShutdownTimeout   time.Duration
+	// TrustedProxies are the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is believed. Without any, the client
+	// address is the address of the connection.
+	TrustedProxies []string
 }
 
 type AuthConfig struct {
-	TokenTTL time.Duration
+	TokenTTL        time.Duration
+	RefreshTokenTTL time.Duration
+	// RevocationCacheTTL is how long each instance trusts what it looked up
+	// about logged-out tokens before asking the database again.
+	RevocationCacheTTL time.Duration
+	// SigningAlgorithm is HS256, which signs with JWTSecret, or RS256 or
+	// EdDSA, which sign with the private key in SigningKeyFile.
+	SigningAlgorithm string
+	SigningKeyFile   string
+	// VerificationKeyFiles and PreviousSecrets hold retired keys whose
+	// tokens are still accepted until they expire.
+	VerificationKeyFiles []string
+	PreviousSecrets      []string
+	// Issuer and Audience are written to the iss and aud claims of new
+	// tokens and must match on every token presented.
+	Issuer   string
+	Audience string
+	// ClockSkew is how far apart the clocks of the instances issuing and
+	// checking a token may be when its exp, nbf and iat claims are checked.
+	ClockSkew time.Duration
+	// TOTPIssuer is the name authenticator apps list accounts under.
+	TOTPIssuer string
+	// LoginMaxFailures and LoginMaxAddressFailures are how many failed
+	// logins an account or a client address gets before it is locked out
+	// for LoginLockout. Every further failure doubles the lockout, up to
+	// LoginMaxLockout.
+	LoginMaxFailures        int
+	LoginMaxAddressFailures int
+	LoginLockout            time.Duration
+	LoginMaxLockout         time.Duration
+	// PasswordMinLength and PasswordMinScore are the fewest characters and
+	// the lowest strength score, from 0 to 4, a new password may have.
+	PasswordMinLength int
+	PasswordMinScore  int
+	// BreachedPasswordsPath is a file or directory of SHA-1 hashes of
+	// breached passwords that new passwords are checked against.
+	BreachedPasswordsPath string
+}
+
+type UsersConfig struct {
+	// DeletionGracePeriod delays account deletion so the user can cancel
+	// it. Zero erases accounts as soon as deletion is requested.
+	DeletionGracePeriod time.Duration
+}
+
+type TransactionsConfig struct {
+	DuplicateWindow time.Duration
+}
+
+type MailConfig struct {
+	// Driver is smtp, which sends through SMTPHost, file, which writes each
+	// message to FileDir, or log, which writes messages to the log.
+	Driver  string
+	From    string
+	FileDir string
+	// SMTPUsername and SMTPPassword are left empty for relays that do not
+	// ask to authenticate.
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// AppBaseURL is where the links in emails point.
+	AppBaseURL string
+}
+
+// OIDCConfig sets up logging in with an OpenID Connect provider. It is
+// off unless IssuerURL is set.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the user back to, the
+	// /api/v1/oidc/callback endpoint as the provider can reach it.
+	RedirectURL string
+	Scopes      []string
+}
+
+// Enabled reports whether OIDC login is configured.
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != ""
 }
 
 type TracingConfig struct {
@@ -57,7 +164,27 @@ func Load() (Config, error) {
 		errs = append(errs, err)
 	}
 
-	jwtSecret, err := requiredEnv("JWT_SECRET")
+	signingAlgorithm, err := signingAlgorithmEnv("AUTH_SIGNING_ALGORITHM", defaultSigningAlgorithm)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var jwtSecret, signingKeyFile string
+	if signingAlgorithm == defaultSigningAlgorithm {
+		jwtSecret, err = requiredEnv("JWT_SECRET")
+	} else {
+		signingKeyFile, err = requiredEnv("AUTH_SIGNING_KEY_FILE")
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	verificationKeyFiles, err := listEnv("AUTH_VERIFICATION_KEY_FILES")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	previousSecrets, err := listEnv("JWT_PREVIOUS_SECRETS")
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -92,11 +219,159 @@ func Load() (Config, error) {
 		errs = append(errs, err)
 	}
 
+	trustedProxies, err := proxyListEnv("HTTP_TRUSTED_PROXIES")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	tokenTTL, err := durationEnv("AUTH_TOKEN_TTL", defaultTokenTTL)
 	if err != nil {
 		errs = append(errs, err)
 	}
 
+	refreshTokenTTL, err := durationEnv("AUTH_REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	revocationCacheTTL, err := durationEnv("AUTH_REVOCATION_CACHE_TTL", defaultRevocationTTL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	tokenIssuer, err := stringValueEnv("AUTH_TOKEN_ISSUER", defaultServiceName)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	tokenAudience, err := stringValueEnv("AUTH_TOKEN_AUDIENCE", defaultServiceName)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	clockSkew, err := optionalDurationEnv("AUTH_CLOCK_SKEW", defaultClockSkew)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	totpIssuer, err := stringValueEnv("AUTH_TOTP_ISSUER", defaultTOTPIssuer)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	loginMaxFailures, err := positiveIntEnv("AUTH_LOGIN_MAX_FAILURES", defaultLoginMaxFailures)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	loginMaxAddressFailures, err := positiveIntEnv("AUTH_LOGIN_MAX_ADDRESS_FAILURES", defaultLoginMaxAddress)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	loginLockout, err := durationEnv("AUTH_LOGIN_LOCKOUT", defaultLoginLockout)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	loginMaxLockout, err := durationEnv("AUTH_LOGIN_MAX_LOCKOUT", defaultLoginMaxLockout)
+	if err != nil {
+		errs = append(errs, err)
+	} else if loginMaxLockout < loginLockout {
+		errs = append(errs, errors.New("AUTH_LOGIN_MAX_LOCKOUT must not be shorter than AUTH_LOGIN_LOCKOUT"))
+	}
+
+	passwordMinLength, err := intRangeEnv("AUTH_PASSWORD_MIN_LENGTH", defaultPasswordMinLength, 1, maxPasswordMinLength)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	passwordMinScore, err := intRangeEnv("AUTH_PASSWORD_MIN_SCORE", defaultPasswordMinScore, 0, maxPasswordScore)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	breachedPasswordsPath, err := optionalStringEnv("AUTH_BREACHED_PASSWORDS_PATH")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	deletionGracePeriod, err := optionalDurationEnv("USER_DELETION_GRACE_PERIOD", 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	duplicateWindow, err := durationEnv("DUPLICATE_WINDOW", defaultDuplicateWindow)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	mailDriver, err := mailDriverEnv("MAIL_DRIVER", defaultMailDriver)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	mailFrom, err := stringValueEnv("MAIL_FROM", defaultMailFrom)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var mailFileDir, smtpHost string
+	switch mailDriver {
+	case "file":
+		mailFileDir, err = requiredEnv("MAIL_FILE_DIR")
+	case "smtp":
+		smtpHost, err = requiredEnv("SMTP_HOST")
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	smtpPort, err := positiveIntEnv("SMTP_PORT", defaultSMTPPort)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	smtpUsername, err := optionalStringEnv("SMTP_USERNAME")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	smtpPassword, err := optionalStringEnv("SMTP_PASSWORD")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	appBaseURL, err := stringValueEnv("APP_BASE_URL", defaultAppBaseURL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	oidcIssuerURL, err := optionalStringEnv("OIDC_ISSUER_URL")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	var oidcClientID, oidcRedirectURL string
+	if oidcIssuerURL != "" {
+		if oidcClientID, err = requiredEnv("OIDC_CLIENT_ID"); err != nil {
+			errs = append(errs, err)
+		}
+		if oidcRedirectURL, err = requiredEnv("OIDC_REDIRECT_URL"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	oidcClientSecret, err := optionalStringEnv("OIDC_CLIENT_SECRET")
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	oidcScopes, err := oidcScopesEnv("OIDC_SCOPES", defaultOIDCScopes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	serviceName, err := stringValueEnv("OTEL_SERVICE_NAME", defaultServiceName)
 	if err != nil {
 		errs = append(errs, err)
@@ -131,9 +406,50 @@ func Load() (Config, error) {
 			WriteTimeout:      writeTimeout,
 			IdleTimeout:       idleTimeout,
 			ShutdownTimeout:   shutdownTimeout,
+			TrustedProxies:    trustedProxies,
 		},
 		Auth: AuthConfig{
-			TokenTTL: tokenTTL,
+			TokenTTL:                tokenTTL,
+			RefreshTokenTTL:         refreshTokenTTL,
+			RevocationCacheTTL:      revocationCacheTTL,
+			SigningAlgorithm:        signingAlgorithm,
+			SigningKeyFile:          signingKeyFile,
+			VerificationKeyFiles:    verificationKeyFiles,
+			PreviousSecrets:         previousSecrets,
+			Issuer:                  tokenIssuer,
+			Audience:                tokenAudience,
+			ClockSkew:               clockSkew,
+			TOTPIssuer:              totpIssuer,
+			LoginMaxFailures:        loginMaxFailures,
+			LoginMaxAddressFailures: loginMaxAddressFailures,
+			LoginLockout:            loginLockout,
+			LoginMaxLockout:         loginMaxLockout,
+			PasswordMinLength:       passwordMinLength,
+			PasswordMinScore:        passwordMinScore,
+			BreachedPasswordsPath:   breachedPasswordsPath,
+		},
+		Users: UsersConfig{
+			DeletionGracePeriod: deletionGracePeriod,
+		},
+		Transactions: TransactionsConfig{
+			DuplicateWindow: duplicateWindow,
+		},
+		Mail: MailConfig{
+			Driver:       mailDriver,
+			From:         mailFrom,
+			FileDir:      mailFileDir,
+			SMTPHost:     smtpHost,
+			SMTPPort:     smtpPort,
+			SMTPUsername: smtpUsername,
+			SMTPPassword: smtpPassword,
+			AppBaseURL:   appBaseURL,
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:    oidcIssuerURL,
+			ClientID:     oidcClientID,
+			ClientSecret: oidcClientSecret,
+			RedirectURL:  oidcRedirectURL,
+			Scopes:       oidcScopes,
 		},
 		Tracing: TracingConfig{
 			ServiceName: serviceName,
@@ -183,6 +499,81 @@ func optionalStringEnv(key string) (string, error) {
 	return strings.TrimSpace(os.Getenv(key)), nil
 }
 
+func listEnv(key string) ([]string, error) {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
+
+func oidcScopesEnv(key, fallback string) ([]string, error) {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return strings.Split(fallback, ","), nil
+	}
+
+	scopes, err := listEnv(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if scope == "openid" {
+			return scopes, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s must include openid", key)
+}
+
+func proxyListEnv(key string) ([]string, error) {
+	values, err := listEnv(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		if net.ParseIP(value) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return nil, fmt.Errorf("%s must list IP addresses or CIDR ranges: %q is neither", key, value)
+		}
+	}
+
+	return values, nil
+}
+
+func signingAlgorithmEnv(key, fallback string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+
+	switch value {
+	case "HS256", "RS256", "EdDSA":
+		return value, nil
+	default:
+		return "", fmt.Errorf("%s must be one of HS256, RS256 or EdDSA", key)
+	}
+}
+
+func mailDriverEnv(key, fallback string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+
+	switch value {
+	case "log", "file", "smtp":
+		return value, nil
+	default:
+		return "", fmt.Errorf("%s must be one of log, file or smtp", key)
+	}
+}
+
 func boolEnv(key string, fallback bool) (bool, error) {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -215,6 +606,42 @@ func floatEnv(key string, fallback float64) (float64, error) {
 	return parsed, nil
 }
 
+func positiveIntEnv(key string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a valid integer: %w", key, err)
+	}
+
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be greater than zero", key)
+	}
+
+	return parsed, nil
+}
+
+func intRangeEnv(key string, fallback, minValue, maxValue int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a valid integer: %w", key, err)
+	}
+
+	if parsed < minValue || parsed > maxValue {
+		return 0, fmt.Errorf("%s must be between %d and %d", key, minValue, maxValue)
+	}
+
+	return parsed, nil
+}
+
 func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -232,3 +659,21 @@ func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
 
 	return duration, nil
 }
+
+func optionalDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a valid duration: %w", key, err)
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("%s must not be negative", key)
+	}
+
+	return duration, nil
+}
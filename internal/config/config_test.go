@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -12,6 +13,15 @@ func TestLoad(t *testing.T) {
 	t.Run("fails when required env vars are missing", testLoadMissingRequiredEnv)
 	t.Run("fails when duration env vars are invalid", testLoadInvalidDuration)
 	t.Run("fails when tracing sample ratio is invalid", testLoadInvalidTraceRatio)
+	t.Run("loads asymmetric signing keys", testLoadAsymmetricSigningKeys)
+	t.Run("fails when signing algorithm is invalid", testLoadInvalidSigningAlgorithm)
+	t.Run("fails when signing key file is missing", testLoadMissingSigningKeyFile)
+	t.Run("fails when login protection settings are invalid", testLoadInvalidLoginProtection)
+	t.Run("loads the password policy", testLoadPasswordPolicy)
+	t.Run("loads the mail settings", testLoadMail)
+	t.Run("fails when mail settings are invalid", testLoadInvalidMail)
+	t.Run("loads the OIDC settings", testLoadOIDC)
+	t.Run("fails when OIDC settings are invalid", testLoadInvalidOIDC)
 }
 
 func testLoadDefaults(t *testing.T) {
@@ -23,6 +33,24 @@ func testLoadDefaults(t *testing.T) {
 	t.Setenv("HTTP_IDLE_TIMEOUT", "")
 	t.Setenv("HTTP_SHUTDOWN_TIMEOUT", "")
 	t.Setenv("AUTH_TOKEN_TTL", "")
+	t.Setenv("AUTH_REFRESH_TOKEN_TTL", "")
+	t.Setenv("AUTH_REVOCATION_CACHE_TTL", "")
+	t.Setenv("AUTH_SIGNING_ALGORITHM", "")
+	t.Setenv("JWT_PREVIOUS_SECRETS", "")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "")
+	t.Setenv("AUTH_LOGIN_MAX_FAILURES", "")
+	t.Setenv("AUTH_LOGIN_MAX_ADDRESS_FAILURES", "")
+	t.Setenv("AUTH_LOGIN_LOCKOUT", "")
+	t.Setenv("AUTH_LOGIN_MAX_LOCKOUT", "")
+	t.Setenv("AUTH_PASSWORD_MIN_LENGTH", "")
+	t.Setenv("AUTH_PASSWORD_MIN_SCORE", "")
+	t.Setenv("AUTH_BREACHED_PASSWORDS_PATH", "")
+	t.Setenv("USER_DELETION_GRACE_PERIOD", "")
+	t.Setenv("DUPLICATE_WINDOW", "")
+	t.Setenv("MAIL_DRIVER", "")
+	t.Setenv("MAIL_FROM", "")
+	t.Setenv("SMTP_PORT", "")
+	t.Setenv("APP_BASE_URL", "")
 	t.Setenv("OTEL_SERVICE_NAME", "")
 	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
 	t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "")
@@ -53,6 +81,70 @@ func testLoadDefaults(t *testing.T) {
 		t.Fatalf("expected default token ttl %v, got %v", defaultTokenTTL, cfg.Auth.TokenTTL)
 	}
 
+	if cfg.Auth.RefreshTokenTTL != defaultRefreshTokenTTL {
+		t.Fatalf("expected default refresh token ttl %v, got %v", defaultRefreshTokenTTL, cfg.Auth.RefreshTokenTTL)
+	}
+
+	if cfg.Auth.RevocationCacheTTL != defaultRevocationTTL {
+		t.Fatalf("expected default revocation cache ttl %v, got %v", defaultRevocationTTL, cfg.Auth.RevocationCacheTTL)
+	}
+
+	if cfg.Auth.SigningAlgorithm != defaultSigningAlgorithm {
+		t.Fatalf("expected default signing algorithm %q, got %q", defaultSigningAlgorithm, cfg.Auth.SigningAlgorithm)
+	}
+
+	if cfg.Auth.Issuer != defaultServiceName || cfg.Auth.Audience != defaultServiceName {
+		t.Fatalf("expected default issuer and audience %q, got %q and %q", defaultServiceName, cfg.Auth.Issuer, cfg.Auth.Audience)
+	}
+
+	if cfg.Auth.TOTPIssuer != defaultTOTPIssuer {
+		t.Fatalf("expected default totp issuer %q, got %q", defaultTOTPIssuer, cfg.Auth.TOTPIssuer)
+	}
+
+	if cfg.Auth.ClockSkew != defaultClockSkew {
+		t.Fatalf("expected default clock skew %v, got %v", defaultClockSkew, cfg.Auth.ClockSkew)
+	}
+
+	if cfg.Auth.LoginMaxFailures != defaultLoginMaxFailures || cfg.Auth.LoginMaxAddressFailures != defaultLoginMaxAddress {
+		t.Fatalf("expected default login failure limits, got %d and %d", cfg.Auth.LoginMaxFailures, cfg.Auth.LoginMaxAddressFailures)
+	}
+
+	if cfg.Auth.LoginLockout != defaultLoginLockout || cfg.Auth.LoginMaxLockout != defaultLoginMaxLockout {
+		t.Fatalf("expected default login lockouts, got %v and %v", cfg.Auth.LoginLockout, cfg.Auth.LoginMaxLockout)
+	}
+
+	if cfg.Auth.PasswordMinLength != defaultPasswordMinLength || cfg.Auth.PasswordMinScore != defaultPasswordMinScore {
+		t.Fatalf("expected default password policy, got length %d and score %d", cfg.Auth.PasswordMinLength, cfg.Auth.PasswordMinScore)
+	}
+
+	if cfg.Auth.BreachedPasswordsPath != "" {
+		t.Fatalf("expected no breached password list, got %q", cfg.Auth.BreachedPasswordsPath)
+	}
+
+	if len(cfg.HTTP.TrustedProxies) != 0 {
+		t.Fatalf("expected no trusted proxies, got %v", cfg.HTTP.TrustedProxies)
+	}
+
+	if len(cfg.Auth.PreviousSecrets) != 0 {
+		t.Fatalf("expected no previous secrets, got %v", cfg.Auth.PreviousSecrets)
+	}
+
+	if cfg.Users.DeletionGracePeriod != 0 {
+		t.Fatalf("expected no account deletion grace period by default, got %v", cfg.Users.DeletionGracePeriod)
+	}
+
+	if cfg.Transactions.DuplicateWindow != defaultDuplicateWindow {
+		t.Fatalf("expected default duplicate window %v, got %v", defaultDuplicateWindow, cfg.Transactions.DuplicateWindow)
+	}
+
+	if cfg.Mail.Driver != defaultMailDriver || cfg.Mail.From != defaultMailFrom || cfg.Mail.SMTPPort != defaultSMTPPort {
+		t.Fatalf("expected default mail settings, got %+v", cfg.Mail)
+	}
+
+	if cfg.Mail.AppBaseURL != defaultAppBaseURL {
+		t.Fatalf("expected default app base url %q, got %q", defaultAppBaseURL, cfg.Mail.AppBaseURL)
+	}
+
 	if cfg.Tracing.ServiceName != defaultServiceName {
 		t.Fatalf("expected default service name %q, got %q", defaultServiceName, cfg.Tracing.ServiceName)
 	}
@@ -74,7 +166,19 @@ func testLoadCustomValues(t *testing.T) {
 	t.Setenv("HTTP_WRITE_TIMEOUT", "12s")
 	t.Setenv("HTTP_IDLE_TIMEOUT", "75s")
 	t.Setenv("HTTP_SHUTDOWN_TIMEOUT", "15s")
-	t.Setenv("AUTH_TOKEN_TTL", "48h")
+	t.Setenv("AUTH_TOKEN_TTL", "5m")
+	t.Setenv("AUTH_REFRESH_TOKEN_TTL", "168h")
+	t.Setenv("AUTH_REVOCATION_CACHE_TTL", "5s")
+	t.Setenv("AUTH_TOKEN_ISSUER", "https://finance.example.com")
+	t.Setenv("AUTH_TOKEN_AUDIENCE", "finance-api")
+	t.Setenv("AUTH_CLOCK_SKEW", "0s")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	t.Setenv("AUTH_LOGIN_MAX_FAILURES", "3")
+	t.Setenv("AUTH_LOGIN_MAX_ADDRESS_FAILURES", "50")
+	t.Setenv("AUTH_LOGIN_LOCKOUT", "30s")
+	t.Setenv("AUTH_LOGIN_MAX_LOCKOUT", "24h")
+	t.Setenv("USER_DELETION_GRACE_PERIOD", "720h")
+	t.Setenv("DUPLICATE_WINDOW", "24h")
 	t.Setenv("OTEL_SERVICE_NAME", "finance-api")
 	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
 	t.Setenv("OTEL_EXPORTER_OTLP_INSECURE", "true")
@@ -97,10 +201,46 @@ func testLoadCustomValues(t *testing.T) {
 		t.Fatalf("expected custom shutdown timeout, got %v", cfg.HTTP.ShutdownTimeout)
 	}
 
-	if cfg.Auth.TokenTTL != 48*time.Hour {
+	if cfg.Auth.TokenTTL != 5*time.Minute {
 		t.Fatalf("expected custom token ttl, got %v", cfg.Auth.TokenTTL)
 	}
 
+	if cfg.Auth.RefreshTokenTTL != 168*time.Hour {
+		t.Fatalf("expected custom refresh token ttl, got %v", cfg.Auth.RefreshTokenTTL)
+	}
+
+	if cfg.Auth.RevocationCacheTTL != 5*time.Second {
+		t.Fatalf("expected custom revocation cache ttl, got %v", cfg.Auth.RevocationCacheTTL)
+	}
+
+	if cfg.Auth.Issuer != "https://finance.example.com" || cfg.Auth.Audience != "finance-api" {
+		t.Fatalf("expected custom issuer and audience, got %q and %q", cfg.Auth.Issuer, cfg.Auth.Audience)
+	}
+
+	if cfg.Auth.ClockSkew != 0 {
+		t.Fatalf("expected clock skew to be disabled, got %v", cfg.Auth.ClockSkew)
+	}
+
+	if len(cfg.HTTP.TrustedProxies) != 2 || cfg.HTTP.TrustedProxies[0] != "10.0.0.0/8" {
+		t.Fatalf("expected custom trusted proxies, got %v", cfg.HTTP.TrustedProxies)
+	}
+
+	if cfg.Auth.LoginMaxFailures != 3 || cfg.Auth.LoginMaxAddressFailures != 50 {
+		t.Fatalf("expected custom login failure limits, got %d and %d", cfg.Auth.LoginMaxFailures, cfg.Auth.LoginMaxAddressFailures)
+	}
+
+	if cfg.Auth.LoginLockout != 30*time.Second || cfg.Auth.LoginMaxLockout != 24*time.Hour {
+		t.Fatalf("expected custom login lockouts, got %v and %v", cfg.Auth.LoginLockout, cfg.Auth.LoginMaxLockout)
+	}
+
+	if cfg.Users.DeletionGracePeriod != 720*time.Hour {
+		t.Fatalf("expected custom account deletion grace period, got %v", cfg.Users.DeletionGracePeriod)
+	}
+
+	if cfg.Transactions.DuplicateWindow != 24*time.Hour {
+		t.Fatalf("expected custom duplicate window, got %v", cfg.Transactions.DuplicateWindow)
+	}
+
 	if cfg.Tracing.ServiceName != "finance-api" {
 		t.Fatalf("expected custom service name, got %q", cfg.Tracing.ServiceName)
 	}
@@ -148,6 +288,201 @@ func testLoadInvalidTraceRatio(t *testing.T) {
 	}
 }
 
+func testLoadAsymmetricSigningKeys(t *testing.T) {
+	setBaseEnv(t)
+	unsetEnv(t, "JWT_SECRET")
+	t.Setenv("AUTH_SIGNING_ALGORITHM", "EdDSA")
+	t.Setenv("AUTH_SIGNING_KEY_FILE", "/keys/current.pem")
+	t.Setenv("AUTH_VERIFICATION_KEY_FILES", "/keys/previous.pem, /keys/older.pem")
+	t.Setenv("JWT_PREVIOUS_SECRETS", "old-secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected config to load, got error %v", err)
+	}
+
+	if cfg.Auth.SigningAlgorithm != "EdDSA" || cfg.Auth.SigningKeyFile != "/keys/current.pem" {
+		t.Fatalf("expected EdDSA signing key, got %q from %q", cfg.Auth.SigningAlgorithm, cfg.Auth.SigningKeyFile)
+	}
+
+	if len(cfg.Auth.VerificationKeyFiles) != 2 || cfg.Auth.VerificationKeyFiles[1] != "/keys/older.pem" {
+		t.Fatalf("expected two verification key files, got %v", cfg.Auth.VerificationKeyFiles)
+	}
+
+	if len(cfg.Auth.PreviousSecrets) != 1 || cfg.Auth.PreviousSecrets[0] != "old-secret" {
+		t.Fatalf("expected previous secret, got %v", cfg.Auth.PreviousSecrets)
+	}
+}
+
+func testLoadInvalidSigningAlgorithm(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("AUTH_SIGNING_ALGORITHM", "none")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected invalid signing algorithm error")
+	}
+}
+
+func testLoadMissingSigningKeyFile(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("AUTH_SIGNING_ALGORITHM", "RS256")
+	unsetEnv(t, "AUTH_SIGNING_KEY_FILE")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected missing signing key file error")
+	}
+}
+
+func testLoadInvalidLoginProtection(t *testing.T) {
+	setBaseEnv(t)
+
+	t.Setenv("AUTH_LOGIN_MAX_FAILURES", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid login failure limit error")
+	}
+
+	t.Setenv("AUTH_LOGIN_MAX_FAILURES", "")
+	t.Setenv("HTTP_TRUSTED_PROXIES", "proxy.internal")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid trusted proxies error")
+	}
+
+	t.Setenv("HTTP_TRUSTED_PROXIES", "")
+	t.Setenv("AUTH_LOGIN_LOCKOUT", "10m")
+	t.Setenv("AUTH_LOGIN_MAX_LOCKOUT", "5m")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected max lockout shorter than lockout error")
+	}
+}
+
+func testLoadPasswordPolicy(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("AUTH_PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("AUTH_PASSWORD_MIN_SCORE", "0")
+	t.Setenv("AUTH_BREACHED_PASSWORDS_PATH", "/var/lib/pwned")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected config to load, got error %v", err)
+	}
+
+	if cfg.Auth.PasswordMinLength != 12 || cfg.Auth.PasswordMinScore != 0 || cfg.Auth.BreachedPasswordsPath != "/var/lib/pwned" {
+		t.Fatalf("expected custom password policy, got %+v", cfg.Auth)
+	}
+
+	t.Setenv("AUTH_PASSWORD_MIN_SCORE", "5")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid password score error")
+	}
+
+	t.Setenv("AUTH_PASSWORD_MIN_SCORE", "")
+	t.Setenv("AUTH_PASSWORD_MIN_LENGTH", "100")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid password length error")
+	}
+}
+
+func testLoadMail(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("MAIL_DRIVER", "smtp")
+	t.Setenv("MAIL_FROM", "Finance <finance@example.com>")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("SMTP_USERNAME", "finance")
+	t.Setenv("SMTP_PASSWORD", "password")
+	t.Setenv("APP_BASE_URL", "https://finance.example.com")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected config to load, got error %v", err)
+	}
+
+	want := MailConfig{
+		Driver:       "smtp",
+		From:         "Finance <finance@example.com>",
+		SMTPHost:     "smtp.example.com",
+		SMTPPort:     2525,
+		SMTPUsername: "finance",
+		SMTPPassword: "password",
+		AppBaseURL:   "https://finance.example.com",
+	}
+	if cfg.Mail != want {
+		t.Fatalf("expected mail settings %+v, got %+v", want, cfg.Mail)
+	}
+}
+
+func testLoadInvalidMail(t *testing.T) {
+	setBaseEnv(t)
+
+	t.Setenv("MAIL_DRIVER", "sendmail")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected invalid mail driver error")
+	}
+
+	t.Setenv("MAIL_DRIVER", "smtp")
+	unsetEnv(t, "SMTP_HOST")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected missing smtp host error")
+	}
+
+	t.Setenv("MAIL_DRIVER", "file")
+	unsetEnv(t, "MAIL_FILE_DIR")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected missing mail file dir error")
+	}
+}
+
+func testLoadOIDC(t *testing.T) {
+	setBaseEnv(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected config to load, got error %v", err)
+	}
+	if cfg.OIDC.Enabled() {
+		t.Fatal("expected OIDC login to be off by default")
+	}
+
+	t.Setenv("OIDC_ISSUER_URL", "https://id.example.com/realms/finance")
+	t.Setenv("OIDC_CLIENT_ID", "finance")
+	t.Setenv("OIDC_CLIENT_SECRET", "secret")
+	t.Setenv("OIDC_REDIRECT_URL", "https://finance.example.com/api/v1/oidc/callback")
+	t.Setenv("OIDC_SCOPES", "openid, email")
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected config to load, got error %v", err)
+	}
+	if !cfg.OIDC.Enabled() {
+		t.Fatal("expected OIDC login to be on")
+	}
+	if cfg.OIDC.ClientID != "finance" || cfg.OIDC.ClientSecret != "secret" || cfg.OIDC.RedirectURL != "https://finance.example.com/api/v1/oidc/callback" {
+		t.Fatalf("unexpected OIDC settings %+v", cfg.OIDC)
+	}
+	if strings.Join(cfg.OIDC.Scopes, " ") != "openid email" {
+		t.Fatalf("expected scopes openid and email, got %v", cfg.OIDC.Scopes)
+	}
+}
+
+func testLoadInvalidOIDC(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("OIDC_ISSUER_URL", "https://id.example.com")
+	t.Setenv("OIDC_REDIRECT_URL", "https://finance.example.com/api/v1/oidc/callback")
+
+	unsetEnv(t, "OIDC_CLIENT_ID")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected missing OIDC client id error")
+	}
+
+	t.Setenv("OIDC_CLIENT_ID", "finance")
+	t.Setenv("OIDC_SCOPES", "email,profile")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected OIDC scopes without openid to be refused")
+	}
+}
+
 func setBaseEnv(t *testing.T) {
 	t.Helper()
 
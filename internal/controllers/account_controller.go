@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	accountService services.AccountService
+}
+
+func NewAccountController(accountService services.AccountService) *AccountController {
+	return &AccountController{accountService: accountService}
+}
+
+type createAccountRequest struct {
+	Name           string  `json:"name" binding:"required"`
+	Type           string  `json:"type" binding:"required"`
+	Currency       string  `json:"currency" binding:"required"`
+	OpeningBalance float64 `json:"opening_balance"`
+}
+
+// CreateAccount adds a new account
+// @Summary Create an account
+// @Description Create a checking, savings, credit card or cash account for the authenticated user.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body createAccountRequest true "Account payload"
+// @Success 201 {object} accountResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/accounts [post]
+func (ac *AccountController) CreateAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req createAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	account := models.Account{
+		UserID:         userID,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+	}
+
+	if err := ac.accountService.CreateAccount(ctx, &account); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newAccountResponse(account))
+}
+
+// GetAccounts fetches all accounts for a user.
+// @Summary List accounts
+// @Description List the authenticated user's accounts.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} accountListResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/accounts [get]
+func (ac *AccountController) GetAccounts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := ac.accountService.GetAccountsByUser(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[accountResponse]{Data: newAccountResponses(accounts)})
+}
+
+// GetAccountBalances computes the current balance of every account a user owns.
+// @Summary List account balances
+// @Description Compute each account's balance from its opening balance and transactions, optionally as of a past date.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param as_of query string false "Balance date/time (RFC3339 or YYYY-MM-DD), defaults to now"
+// @Success 200 {object} accountBalanceListResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/accounts/balances [get]
+func (ac *AccountController) GetAccountBalances(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	asOf := time.Now().UTC()
+	if rawAsOf := c.Query("as_of"); rawAsOf != "" {
+		parsedAsOf, err := parseTransactionFilterTime(rawAsOf, true)
+		if err != nil {
+			httpapi.WriteError(c, apperrors.Validation("invalid_as_of", "as_of must be RFC3339 or YYYY-MM-DD"))
+			return
+		}
+		asOf = parsedAsOf
+	}
+
+	balances, err := ac.accountService.GetAccountBalancesByUser(ctx, userID, asOf)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[accountBalanceResponse]{Data: newAccountBalanceResponses(balances)})
+}
+
+// DeleteAccount removes an account
+// @Summary Delete an account
+// @Description Delete one of the authenticated user's accounts. Accounts that still have transactions cannot be deleted.
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Account ID" minimum(1)
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/accounts/{id} [delete]
+func (ac *AccountController) DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_account_id", "invalid account id"))
+		return
+	}
+
+	if err := ac.accountService.DeleteAccountForUser(ctx, userID, uint(accountID)); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
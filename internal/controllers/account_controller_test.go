@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountService implements services.AccountService
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) CreateAccount(ctx context.Context, account *models.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) GetAccountsByUser(ctx context.Context, userID uint) ([]models.Account, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Account), args.Error(1)
+}
+
+func (m *MockAccountService) GetAccountBalancesByUser(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error) {
+	args := m.Called(ctx, userID, asOf)
+	return args.Get(0).([]models.AccountBalance), args.Error(1)
+}
+
+func (m *MockAccountService) DeleteAccountForUser(ctx context.Context, userID, accountID uint) error {
+	args := m.Called(ctx, userID, accountID)
+	return args.Error(0)
+}
+
+func TestCreateAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		controller := NewAccountController(mockService)
+
+		payload := map[string]interface{}{
+			"name":            "Checking",
+			"type":            "checking",
+			"currency":        "EUR",
+			"opening_balance": 1500.25,
+		}
+
+		mockService.On("CreateAccount", mock.Anything, mock.MatchedBy(func(a *models.Account) bool {
+			return a.UserID == 1 &&
+				a.Name == "Checking" &&
+				a.Type == "checking" &&
+				a.Currency == "EUR" &&
+				a.OpeningBalance == 1500.25
+		})).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		jsonBody, err := json.Marshal(payload)
+		assert.NoError(t, err)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateAccount(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"opening_balance":1500.25`)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		controller := NewAccountController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(`{"name":"Checking"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateAccount(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_request"`)
+	})
+}
+
+func TestGetAccountBalances(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Balances as of date", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		controller := NewAccountController(mockService)
+
+		asOf := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC).Add(24*time.Hour - time.Nanosecond)
+		balances := []models.AccountBalance{
+			{Account: models.Account{ID: 3, Name: "Savings", Type: "savings", Currency: "EUR", OpeningBalance: 100}, Balance: 350, AsOf: asOf},
+		}
+
+		mockService.On("GetAccountBalancesByUser", mock.Anything, uint(1), asOf).Return(balances, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/accounts/balances?as_of=2026-03-31", nil)
+
+		controller.GetAccountBalances(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"account_id":3`)
+		assert.Contains(t, w.Body.String(), `"balance":350`)
+	})
+
+	t.Run("Invalid as_of", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		controller := NewAccountController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/accounts/balances?as_of=yesterday", nil)
+
+		controller.GetAccountBalances(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_as_of"`)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Conflict", func(t *testing.T) {
+		mockService := new(MockAccountService)
+		controller := NewAccountController(mockService)
+
+		mockService.On("DeleteAccountForUser", mock.Anything, uint(1), uint(4)).
+			Return(apperrors.Conflict("account_has_transactions", "account still has transactions")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "4"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/accounts/4", nil)
+
+		controller.DeleteAccount(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"account_has_transactions"`)
+	})
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyService) *APIKeyController {
+	return &APIKeyController{apiKeyService: apiKeyService}
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKey issues a personal API key
+// @Summary Create an API key
+// @Description Create a personal API key for scripts and integrations, limited to the given scopes and optionally expiring. A key cannot have scopes the session creating it was not granted. The key is in the response only this once; store it straight away. Send it in the X-API-Key header or as a Bearer token.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body createAPIKeyRequest true "API key payload"
+// @Success 201 {object} createdAPIKeyResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/api-keys [post]
+func (kc *APIKeyController) CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	apiKey, key, err := kc.apiKeyService.CreateAPIKey(ctx, claims.UserID, claims.Scopes(), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdAPIKeyResponse{apiKeyResponse: newAPIKeyResponse(*apiKey), Key: key})
+}
+
+// GetAPIKeys lists the user's API keys
+// @Summary List API keys
+// @Description List the authenticated user's API keys that have not been revoked, newest first, with when each was last used. Keys themselves are never shown again, only their prefix.
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} apiKeyListResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/api-keys [get]
+func (kc *APIKeyController) GetAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := kc.apiKeyService.ListAPIKeys(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[apiKeyResponse]{Data: newAPIKeyResponses(keys)})
+}
+
+// RevokeAPIKey revokes an API key
+// @Summary Revoke an API key
+// @Description Revoke one of the authenticated user's API keys. Requests made with it are refused from then on.
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID" minimum(1)
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/api-keys/{id} [delete]
+func (kc *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_api_key_id", "invalid API key id"))
+		return
+	}
+
+	if err := kc.apiKeyService.RevokeAPIKey(ctx, userID, uint(keyID)); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyService implements services.APIKeyService
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, userID uint, granted []string, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	args := m.Called(ctx, userID, granted, name, scopes, expiresAt)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.APIKey), args.String(1), args.Error(2)
+	}
+	return nil, "", args.Error(2)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uint) error {
+	args := m.Called(ctx, userID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		controller := NewAPIKeyController(mockService)
+
+		mockService.On("CreateAPIKey", mock.Anything, uint(1), auth.Scopes, "Sync", []string{"transactions:read"}, mock.MatchedBy(func(expiresAt *time.Time) bool {
+			return expiresAt != nil && expiresAt.Year() == 2030
+		})).Return(&models.APIKey{ID: 4, Name: "Sync", Prefix: "pft_abcdefgh", Scopes: "transactions:read"}, "pft_abcdefghsecret", nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: strings.Join(auth.Scopes, " ")})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBufferString(`{"name":"Sync","scopes":["transactions:read"],"expires_at":"2030-01-01T00:00:00Z"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateAPIKey(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"key":"pft_abcdefghsecret"`)
+		assert.Contains(t, w.Body.String(), `"scopes":["transactions:read"]`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Scopes", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		controller := NewAPIKeyController(mockService)
+
+		mockService.On("CreateAPIKey", mock.Anything, uint(1), auth.Scopes, "Sync", []string{"everything"}, (*time.Time)(nil)).
+			Return(nil, "", apperrors.Validation("invalid_api_key_scopes", "unknown scope everything")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: strings.Join(auth.Scopes, " ")})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewBufferString(`{"name":"Sync","scopes":["everything"]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_api_key_scopes")
+	})
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockAPIKeyService)
+	controller := NewAPIKeyController(mockService)
+
+	mockService.On("ListAPIKeys", mock.Anything, uint(1)).Return([]models.APIKey{{ID: 4, Name: "Sync", Prefix: "pft_abcdefgh", KeyHash: "stored-hash", Scopes: "reports:read"}}, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil)
+
+	controller.GetAPIKeys(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"prefix":"pft_abcdefgh"`)
+	assert.NotContains(t, w.Body.String(), "stored-hash")
+	assert.NotContains(t, w.Body.String(), `"key"`)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		controller := NewAPIKeyController(mockService)
+
+		mockService.On("RevokeAPIKey", mock.Anything, uint(1), uint(4)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "4"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/4", nil)
+
+		controller.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "API key revoked")
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		controller := NewAPIKeyController(new(MockAPIKeyService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/abc", nil)
+
+		controller.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
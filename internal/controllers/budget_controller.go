@@ -39,6 +39,7 @@ type createBudgetRequest struct {
 // @Success 201 {object} messageResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/budgets [post]
 func (bc *BudgetController) CreateBudget(c *gin.Context) {
@@ -100,6 +101,7 @@ func (bc *BudgetController) GetBudgets(c *gin.Context) {
 // @Success 200 {object} budgetPageResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/budgets [get]
 func (bc *BudgetController) GetBudgetsPage(c *gin.Context) {
@@ -138,6 +140,7 @@ func (bc *BudgetController) GetBudgetsPage(c *gin.Context) {
 // @Success 200 {object} messageResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 404 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/budgets/{id} [delete]
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
 	"github.com/gin-gonic/gin"
 )
@@ -21,3 +22,21 @@ func currentUserID(c *gin.Context) (uint, bool) {
 
 	return userID, true
 }
+
+// currentClaims returns the claims of the access token the request was
+// authenticated with.
+func currentClaims(c *gin.Context) (*auth.Claims, bool) {
+	claims, ok := c.Get("tokenClaims")
+	if !ok {
+		httpapi.WriteError(c, apperrors.Unauthorized("authentication_required", "authentication required"))
+		return nil, false
+	}
+
+	tokenClaims, ok := claims.(*auth.Claims)
+	if !ok || tokenClaims.UserID == 0 {
+		httpapi.WriteError(c, apperrors.Unauthorized("invalid_authentication_context", "invalid authentication context"))
+		return nil, false
+	}
+
+	return tokenClaims, true
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	exportService services.ExportService
+}
+
+func NewExportController(exportService services.ExportService) *ExportController {
+	return &ExportController{exportService: exportService}
+}
+
+// ExportTransactions streams the user's transactions as a file
+// @Summary Export transactions
+// @Description Download the authenticated user's transactions matching the same filters as the transaction list, oldest first. csv writes one row per transaction with tags and category_id:amount split lines separated by semicolons; jsonl writes one JSON object per line; ofx writes an OFX 2.2 bank statement that the OFX import can read back. The file is streamed while it is read from the database, so an error after the first bytes ends the download early instead of returning an error response.
+// @Tags exports
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/x-ofx
+// @Security BearerAuth
+// @Param format query string false "Export format" Enums(csv, jsonl, ofx) default(csv)
+// @Param type query string false "Transaction type" Enums(income, expense, transfer)
+// @Param category_id query int false "Category ID" minimum(1)
+// @Param account_id query int false "Account ID" minimum(1)
+// @Param tag query []string false "Tag names; repeat or comma-separate for several" collectionFormat(multi)
+// @Param tag_match query string false "Whether transactions need any or all of the tags" Enums(any, all)
+// @Param from query string false "Start date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "End date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/exports/transactions [get]
+func (ec *ExportController) ExportTransactions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	format, err := exports.ParseFormat(c.DefaultQuery("format", string(exports.FormatCSV)))
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_export_format", "format must be one of csv, jsonl or ofx"))
+		return
+	}
+
+	transactionFilters, err := parseTransactionFilters(c)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="transactions.`+string(format)+`"`)
+	c.Status(http.StatusOK)
+
+	if err := ec.exportService.ExportTransactions(ctx, userID, format, transactionFilters, c.Writer); err != nil {
+		if c.Writer.Written() {
+			httpapi.AbortStream(c, err)
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		httpapi.WriteError(c, err)
+	}
+}
+
+// ExportArchive streams a full archive of the user's data
+// @Summary Export an account archive
+// @Description Download a versioned zip archive of everything the authenticated user owns: accounts, tags, budgets, rules, transfers and transactions with their splits and tags, plus the categories they use. A manifest records the format version and the number of records in each file. The archive can be restored into an empty account with the archive import. Transactions are streamed while they are read from the database, so an error after the first bytes ends the download early instead of returning an error response.
+// @Tags exports
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/exports/archive [get]
+func (ec *ExportController) ExportArchive(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="finance-archive-`+time.Now().UTC().Format("20060102")+`.zip"`)
+	c.Status(http.StatusOK)
+
+	if err := ec.exportService.ExportArchive(ctx, userID, c.Writer); err != nil {
+		if c.Writer.Written() {
+			httpapi.AbortStream(c, err)
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		httpapi.WriteError(c, err)
+	}
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportService implements services.ExportService
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) ExportTransactions(ctx context.Context, userID uint, format exports.Format, transactionFilters filters.TransactionFilters, w io.Writer) error {
+	args := m.Called(ctx, userID, format, transactionFilters, w)
+	if content, ok := args.Get(0).(string); ok {
+		_, _ = io.WriteString(w, content)
+	}
+	return args.Error(1)
+}
+
+func (m *MockExportService) ExportArchive(ctx context.Context, userID uint, w io.Writer) error {
+	args := m.Called(ctx, userID, w)
+	if content, ok := args.Get(0).(string); ok {
+		_, _ = io.WriteString(w, content)
+	}
+	return args.Error(1)
+}
+
+func TestExportTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		mockService.On("ExportTransactions", mock.Anything, uint(1), exports.FormatJSONL, mock.MatchedBy(func(transactionFilters filters.TransactionFilters) bool {
+			return transactionFilters.Type == "expense"
+		}), mock.Anything).Return("{\"id\":1}\n", nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/transactions?format=jsonl&type=expense", nil)
+
+		controller.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="transactions.jsonl"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "{\"id\":1}\n", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Default to CSV", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		mockService.On("ExportTransactions", mock.Anything, uint(1), exports.FormatCSV, filters.TransactionFilters{}, mock.Anything).Return("id\n", nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/transactions", nil)
+
+		controller.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/transactions?format=xlsx", nil)
+
+		controller.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_export_format")
+		mockService.AssertNotCalled(t, "ExportTransactions")
+	})
+
+	t.Run("Error Before Streaming", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		mockService.On("ExportTransactions", mock.Anything, uint(1), exports.FormatOFX, filters.TransactionFilters{}, mock.Anything).
+			Return(nil, apperrors.Internal("transactions_export_failed", "failed to export transactions", errors.New("db down"))).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/transactions?format=ofx", nil)
+
+		controller.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "transactions_export_failed")
+	})
+}
+
+func TestExportArchive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		mockService.On("ExportArchive", mock.Anything, uint(1), mock.Anything).Return("PK", nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/archive", nil)
+
+		controller.ExportArchive(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="finance-archive-`)
+		assert.Equal(t, "PK", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Error Before Streaming", func(t *testing.T) {
+		mockService := new(MockExportService)
+		controller := NewExportController(mockService)
+
+		mockService.On("ExportArchive", mock.Anything, uint(1), mock.Anything).
+			Return(nil, apperrors.Internal("archive_export_failed", "failed to export archive", errors.New("db down"))).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/archive", nil)
+
+		controller.ExportArchive(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "archive_export_failed")
+	})
+}
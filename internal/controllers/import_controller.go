@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize caps uploaded statement files at 5 MiB.
+const maxImportFileSize = 5 << 20
+
+// maxArchiveFileSize caps uploaded account archives at 100 MiB.
+const maxArchiveFileSize = 100 << 20
+
+// multipartOverhead is what a request body may hold on top of the file:
+// the other form fields and the multipart boundaries.
+const multipartOverhead = 1 << 20
+
+type ImportController struct {
+	importService services.ImportService
+}
+
+func NewImportController(importService services.ImportService) *ImportController {
+	return &ImportController{importService: importService}
+}
+
+type csvImportRequest struct {
+	DateColumn        string `form:"date_column" binding:"required"`
+	DateFormat        string `form:"date_format"`
+	AmountColumn      string `form:"amount_column"`
+	DebitColumn       string `form:"debit_column"`
+	CreditColumn      string `form:"credit_column"`
+	DescriptionColumn string `form:"description_column"`
+	DecimalSeparator  string `form:"decimal_separator"`
+	Delimiter         string `form:"delimiter"`
+}
+
+type confirmImportRequest struct {
+	CategoryID uint               `json:"category_id"` // Fallback for rows no rule categorises
+	AccountID  *uint              `json:"account_id"`
+	Tags       []string           `json:"tags"`
+	Rows       []importRowRequest `json:"rows" binding:"required,min=1,max=5000,dive"`
+}
+
+type importRowRequest struct {
+	Date        time.Time `json:"date" binding:"required"`
+	Amount      float64   `json:"amount" binding:"required"`
+	Type        string    `json:"type" binding:"required"`
+	Description string    `json:"description"`
+	Payee       string    `json:"payee"`
+	ExternalID  string    `json:"external_id" binding:"max=255"`
+	CategoryID  *uint     `json:"category_id"`
+}
+
+// PreviewCSVImport parses an uploaded CSV statement
+// @Summary Preview a CSV import
+// @Description Parse a CSV bank statement using the given column mapping and return the parsed rows and per-row errors. Nothing is stored; send the rows to the confirm endpoint to import them.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV statement"
+// @Param date_column formData string true "Header of the booking date column"
+// @Param date_format formData string false "Date format such as DD/MM/YYYY, defaults to YYYY-MM-DD"
+// @Param amount_column formData string false "Header of the signed amount column"
+// @Param debit_column formData string false "Header of the debit column, used with credit_column"
+// @Param credit_column formData string false "Header of the credit column, used with debit_column"
+// @Param description_column formData string false "Header of the description column"
+// @Param decimal_separator formData string false "Decimal separator, . (default) or ,"
+// @Param delimiter formData string false "Field delimiter, , (default), ;, | or a tab"
+// @Success 200 {object} importPreviewResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/csv [post]
+func (ic *ImportController) PreviewCSVImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req csvImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	file, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	mapping := imports.CSVMapping{
+		DateColumn:        req.DateColumn,
+		DateFormat:        req.DateFormat,
+		AmountColumn:      req.AmountColumn,
+		DebitColumn:       req.DebitColumn,
+		CreditColumn:      req.CreditColumn,
+		DescriptionColumn: req.DescriptionColumn,
+		DecimalSeparator:  req.DecimalSeparator,
+		Delimiter:         req.Delimiter,
+	}
+
+	preview, err := ic.importService.PreviewCSV(ctx, userID, file, mapping)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newImportPreviewResponse(preview))
+}
+
+// PreviewOFXImport parses an uploaded OFX or QFX statement
+// @Summary Preview an OFX/QFX import
+// @Description Parse an OFX 1.x (SGML) or 2.x (XML) bank statement, including QFX files, and return the parsed rows and per-row errors. Rows whose FITID was imported before are listed under skipped. Nothing is stored; send the rows, with their external_id, to the confirm endpoint to import them.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "OFX or QFX statement"
+// @Success 200 {object} importPreviewResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/ofx [post]
+func (ic *ImportController) PreviewOFXImport(c *gin.Context) {
+	ic.previewStatement(c, imports.FormatOFX)
+}
+
+// PreviewCAMT053Import parses an uploaded ISO 20022 camt.053 statement
+// @Summary Preview a camt.053 import
+// @Description Parse an ISO 20022 camt.053 bank-to-customer statement and return the booked entries with booking date, amount, counterparty as payee and remittance information as description. Entries whose account servicer reference was imported before are listed under skipped. Nothing is stored; send the rows to the confirm endpoint to import them.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "camt.053 XML statement"
+// @Success 200 {object} importPreviewResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/camt053 [post]
+func (ic *ImportController) PreviewCAMT053Import(c *gin.Context) {
+	ic.previewStatement(c, imports.FormatCAMT053)
+}
+
+// PreviewMT940Import parses an uploaded SWIFT MT940 statement
+// @Summary Preview an MT940 import
+// @Description Parse a SWIFT MT940 statement and return each statement line with booking date, amount, counterparty as payee and remittance information as description. Entries whose bank reference was imported before are listed under skipped. Nothing is stored; send the rows to the confirm endpoint to import them.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "MT940 statement"
+// @Success 200 {object} importPreviewResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/mt940 [post]
+func (ic *ImportController) PreviewMT940Import(c *gin.Context) {
+	ic.previewStatement(c, imports.FormatMT940)
+}
+
+func (ic *ImportController) previewStatement(c *gin.Context, format imports.Format) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	file, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := ic.importService.PreviewStatement(ctx, userID, format, file)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newImportPreviewResponse(preview))
+}
+
+// ConfirmImport stores previewed statement rows
+// @Summary Confirm an import
+// @Description Create transactions from previewed statement rows in a single database transaction. Every row goes through the usual transaction validations; if any row fails, nothing is imported. Rows with an external_id that was imported before are skipped. Rows without a category_id are categorised by the user's rules, falling back to the request's category_id. Imported transactions that look like copies of stored ones are listed under duplicates.
+// @Tags imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body confirmImportRequest true "Rows to import"
+// @Success 201 {object} importResultResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/confirm [post]
+func (ic *ImportController) ConfirmImport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req confirmImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	transactions := make([]models.Transaction, 0, len(req.Rows))
+	for _, row := range req.Rows {
+		transaction := models.Transaction{
+			UserID:     userID,
+			Type:       row.Type,
+			Amount:     row.Amount,
+			AccountID:  req.AccountID,
+			Date:       row.Date,
+			Note:       row.Description,
+			Payee:      row.Payee,
+			ExternalID: row.ExternalID,
+		}
+		if row.CategoryID != nil {
+			transaction.CategoryID = *row.CategoryID
+		}
+		for _, name := range req.Tags {
+			transaction.Tags = append(transaction.Tags, models.Tag{Name: name})
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	result, err := ic.importService.ConfirmImport(ctx, userID, req.CategoryID, transactions)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, importResultResponse{
+		Imported:   result.Imported,
+		Skipped:    result.Skipped,
+		Duplicates: newDuplicateMatchResponses(result.Duplicates),
+	})
+}
+
+// ImportArchive restores an account archive
+// @Summary Import an account archive
+// @Description Restore a zip archive written by the archive export into the authenticated user's account, which must not hold any accounts, tags, budgets, rules, transfers or transactions yet. Every record gets a new ID and references between them are remapped; categories are matched by name and created when missing. Everything is stored in a single database transaction, so a failed import leaves the account empty. Archives of an unknown format version are rejected.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Account archive (zip)"
+// @Success 201 {object} archiveSummaryResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/imports/archive [post]
+func (ic *ImportController) ImportArchive(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, ok := uploadedFile(c, maxArchiveFileSize, "an archive file is required", "archive files must not exceed 100 MiB")
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("missing_import_file", "an archive file is required"))
+		return
+	}
+	defer file.Close()
+
+	summary, err := ic.importService.ImportArchive(ctx, userID, file, fileHeader.Size)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newArchiveSummaryResponse(summary))
+}
+
+// openImportFile opens the uploaded statement, writing an error response when
+// it is missing or too large.
+func openImportFile(c *gin.Context) (multipart.File, bool) {
+	fileHeader, ok := uploadedFile(c, maxImportFileSize, "a statement file is required", "statement files must not exceed 5 MiB")
+	if !ok {
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("missing_import_file", "a statement file is required"))
+		return nil, false
+	}
+
+	return file, true
+}
+
+// uploadedFile returns the uploaded file, writing an error response when it
+// is missing or larger than maxSize. The request body is capped before the
+// form is parsed, so an oversized upload is cut off instead of being
+// buffered in memory or on disk first.
+func uploadedFile(c *gin.Context, maxSize int64, missingMessage, tooLargeMessage string) (*multipart.FileHeader, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		httpapi.WriteError(c, apperrors.Validation("import_file_too_large", tooLargeMessage))
+		return nil, false
+	case err != nil:
+		httpapi.WriteError(c, apperrors.Validation("missing_import_file", missingMessage))
+		return nil, false
+	case fileHeader.Size > maxSize:
+		httpapi.WriteError(c, apperrors.Validation("import_file_too_large", tooLargeMessage))
+		return nil, false
+	}
+
+	return fileHeader, true
+}
@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockImportService implements services.ImportService
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) PreviewCSV(ctx context.Context, userID uint, file io.Reader, mapping imports.CSVMapping) (*imports.Preview, error) {
+	args := m.Called(ctx, userID, file, mapping)
+	if args.Get(0) != nil {
+		return args.Get(0).(*imports.Preview), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockImportService) PreviewStatement(ctx context.Context, userID uint, format imports.Format, file io.Reader) (*imports.Preview, error) {
+	args := m.Called(ctx, userID, format, file)
+	if args.Get(0) != nil {
+		return args.Get(0).(*imports.Preview), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockImportService) ConfirmImport(ctx context.Context, userID, fallbackCategoryID uint, transactions []models.Transaction) (*imports.Result, error) {
+	args := m.Called(ctx, userID, fallbackCategoryID, transactions)
+	if args.Get(0) != nil {
+		return args.Get(0).(*imports.Result), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockImportService) ImportArchive(ctx context.Context, userID uint, file io.ReaderAt, size int64) (*archive.Summary, error) {
+	args := m.Called(ctx, userID, file, size)
+	if args.Get(0) != nil {
+		return args.Get(0).(*archive.Summary), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newMultipartRequest(t *testing.T, target string, fields map[string]string, fileName string, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("file", fileName)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	request := httptest.NewRequest(http.MethodPost, target, &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestPreviewCSVImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		preview := &imports.Preview{
+			Rows:   []imports.ParsedRow{{Line: 2, Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 42.5, Type: "expense", Description: "Supermarket"}},
+			Errors: []imports.RowError{{Line: 3, Message: `invalid amount "abc"`}},
+		}
+		expectedMapping := imports.CSVMapping{DateColumn: "Date", AmountColumn: "Amount", DescriptionColumn: "Text", DecimalSeparator: ","}
+		mockService.On("PreviewCSV", mock.Anything, uint(1), mock.Anything, expectedMapping).Return(preview, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/csv", map[string]string{
+			"date_column":        "Date",
+			"amount_column":      "Amount",
+			"description_column": "Text",
+			"decimal_separator":  ",",
+		}, "statement.csv", "Date,Amount,Text\n")
+
+		controller.PreviewCSVImport(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"description":"Supermarket"`)
+		assert.Contains(t, w.Body.String(), `"line":3`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/csv", map[string]string{"date_column": "Date", "amount_column": "Amount"}, "", "")
+
+		controller.PreviewCSVImport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing_import_file")
+		mockService.AssertNotCalled(t, "PreviewCSV")
+	})
+
+	t.Run("File Too Large", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/csv", map[string]string{"date_column": "Date", "amount_column": "Amount"},
+			"statement.csv", strings.Repeat("x", maxImportFileSize+multipartOverhead))
+
+		controller.PreviewCSVImport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "import_file_too_large")
+		mockService.AssertNotCalled(t, "PreviewCSV")
+	})
+}
+
+func TestPreviewOFXImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		preview := &imports.Preview{
+			Rows:    []imports.ParsedRow{{Line: 12, Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 42.5, Type: "expense", Description: "Supermarket", ExternalID: "FIT-1"}},
+			Skipped: []imports.ParsedRow{{Line: 20, Date: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), Amount: 10, Type: "expense", Description: "Bakery", ExternalID: "FIT-0"}},
+		}
+		mockService.On("PreviewStatement", mock.Anything, uint(1), imports.FormatOFX, mock.Anything).Return(preview, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/ofx", nil, "statement.qfx", "<OFX></OFX>")
+
+		controller.PreviewOFXImport(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"external_id":"FIT-1"`)
+		assert.Contains(t, w.Body.String(), `"skipped":[{"line":20`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Statement", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		mockService.On("PreviewStatement", mock.Anything, uint(1), imports.FormatOFX, mock.Anything).
+			Return(nil, apperrors.Validation("invalid_ofx", "file is not an ofx statement")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/ofx", nil, "statement.csv", "Date,Amount\n")
+
+		controller.PreviewOFXImport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_ofx")
+	})
+}
+
+func TestPreviewMT940Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockImportService)
+	controller := NewImportController(mockService)
+
+	preview := &imports.Preview{
+		Rows: []imports.ParsedRow{{Line: 5, Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Amount: 42.5, Type: "expense", Description: "Strom Januar", Payee: "Stadtwerke", ExternalID: "BANKREF1"}},
+	}
+	mockService.On("PreviewStatement", mock.Anything, uint(1), imports.FormatMT940, mock.Anything).Return(preview, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = newMultipartRequest(t, "/api/v1/imports/mt940", nil, "statement.sta", ":20:STARTUMS\n")
+
+	controller.PreviewMT940Import(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"payee":"Stadtwerke"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestConfirmImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		// Rows without a category are left for the rules and the fallback.
+		mockService.On("ConfirmImport", mock.Anything, uint(1), uint(4), mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 2 &&
+				transactions[0].CategoryID == 0 &&
+				transactions[1].CategoryID == 9 &&
+				transactions[0].Note == "Supermarket" &&
+				transactions[1].ExternalID == "FIT-2" &&
+				transactions[1].Payee == "Employer AG" &&
+				len(transactions[1].Tags) == 1
+		})).Return(&imports.Result{Imported: 2}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		body := `{"category_id":4,"tags":["imported"],"rows":[` +
+			`{"date":"2026-03-01T00:00:00Z","amount":42.5,"type":"expense","description":"Supermarket"},` +
+			`{"date":"2026-03-02T00:00:00Z","amount":1000,"type":"income","description":"Salary","payee":"Employer AG","external_id":"FIT-2","category_id":9}]}`
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/imports/confirm", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ConfirmImport(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"imported":2`)
+	})
+
+	t.Run("Validation Failure", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		mockService.On("ConfirmImport", mock.Anything, uint(1), uint(4), mock.Anything).
+			Return(nil, apperrors.Validation("budget_limit_exceeded", "transaction 1: transaction exceeds budget limit")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		body := `{"category_id":4,"rows":[{"date":"2026-03-01T00:00:00Z","amount":4200,"type":"expense"}]}`
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/imports/confirm", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ConfirmImport(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "transaction 1")
+	})
+}
+
+func TestImportArchive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		mockService.On("ImportArchive", mock.Anything, uint(1), mock.Anything, int64(7)).
+			Return(&archive.Summary{Accounts: 2, Transactions: 40}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/archive", nil, "archive.zip", "PK\x03\x04...")
+
+		controller.ImportArchive(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"accounts":2`)
+		assert.Contains(t, w.Body.String(), `"transactions":40`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/archive", nil, "", "")
+
+		controller.ImportArchive(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing_import_file")
+		mockService.AssertNotCalled(t, "ImportArchive")
+	})
+
+	t.Run("Account Not Empty", func(t *testing.T) {
+		mockService := new(MockImportService)
+		controller := NewImportController(mockService)
+
+		mockService.On("ImportArchive", mock.Anything, uint(1), mock.Anything, mock.Anything).
+			Return(nil, apperrors.Conflict("account_not_empty", "archives can only be imported into an account without data")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = newMultipartRequest(t, "/api/v1/imports/archive", nil, "archive.zip", "PK")
+
+		controller.ImportArchive(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "account_not_empty")
+	})
+}
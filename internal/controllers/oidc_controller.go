@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie ties a single sign-on login to the browser that started
+// it, so nobody can finish their own login in someone else's browser.
+const oidcStateCookie = "oidc_state"
+
+// oidcStateCookieMaxAge matches how long a started login is kept.
+const oidcStateCookieMaxAge = 10 * time.Minute
+
+type OIDCController struct {
+	oidcService  services.OIDCService
+	tokenService services.TokenService
+	mfaService   services.MFAService
+}
+
+func NewOIDCController(oidcService services.OIDCService, tokenService services.TokenService, mfaService services.MFAService) *OIDCController {
+	return &OIDCController{oidcService: oidcService, tokenService: tokenService, mfaService: mfaService}
+}
+
+// Login sends the user to the identity provider
+// @Summary Log in with single sign-on
+// @Description Redirect to the OpenID Connect provider's login page. After logging in there, the provider sends the user back to /api/v1/oidc/callback, which has to be reached from the same browser. The login has to be finished within 10 minutes.
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Header 302 {string} Location "Login page of the identity provider"
+// @Header 302 {string} Set-Cookie "oidc_state cookie that ties the login to the browser"
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 503 {object} httpapi.ErrorResponse
+// @Router /api/v1/oidc/login [get]
+func (oc *OIDCController) Login(c *gin.Context) {
+	authURL, state, err := oc.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, auth.HashOpaqueToken(state), int(oidcStateCookieMaxAge.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback finishes a single sign-on login
+// @Summary Finish a single sign-on login
+// @Description Where the identity provider sends the user back to. Checks that the login was started in the same browser, exchanges the authorization code for an ID token and logs in the user it names: the account linked to the provider account, else the account with the same verified email address, else a new account. When the user has two-factor authentication enabled, respond with 202 and an MFA token instead, to be sent with a code to /api/v1/login/mfa.
+// @Tags auth
+// @Produce json
+// @Param code query string false "Authorization code from the identity provider"
+// @Param state query string true "State sent to the identity provider"
+// @Param error query string false "Error from the identity provider"
+// @Success 200 {object} authResponse
+// @Success 202 {object} mfaChallengeResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 503 {object} httpapi.ErrorResponse
+// @Router /api/v1/oidc/callback [get]
+func (oc *OIDCController) Callback(c *gin.Context) {
+	stateHash, cookieErr := c.Cookie(oidcStateCookie)
+	// Each login is finished once, however it ends.
+	setOIDCStateCookie(c, "", -1)
+
+	// RFC 6749 section 4.1.2.1: the provider reports a refused or failed
+	// login in the error parameter instead of sending a code. The value
+	// comes from the query string, so it is only logged.
+	if providerError := c.Query("error"); providerError != "" {
+		httpapi.WriteError(c, apperrors.Wrap(apperrors.KindUnauthorized, "oidc_login_failed", "identity provider did not log in the user",
+			fmt.Errorf("identity provider returned error %q", providerError)))
+		return
+	}
+
+	state := c.Query("state")
+	if cookieErr != nil || subtle.ConstantTimeCompare([]byte(stateHash), []byte(auth.HashOpaqueToken(state))) != 1 {
+		httpapi.WriteError(c, apperrors.Unauthorized("invalid_oidc_state", "login was not started in this browser"))
+		return
+	}
+
+	user, err := oc.oidcService.CompleteLogin(c.Request.Context(), c.Query("code"), state)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	if user.MFAEnabled() {
+		writeMFAChallenge(c, oc.mfaService, user)
+		return
+	}
+
+	writeLogin(c, oc.tokenService, user, "")
+}
+
+// setOIDCStateCookie sets the state cookie for maxAge seconds, or removes
+// it when maxAge is negative. Lax lets the cookie come along on the
+// provider's top-level redirect back.
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOIDCService implements services.OIDCService
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) StartLogin(ctx context.Context) (string, string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockOIDCService) CompleteLogin(ctx context.Context, code, state string) (*models.User, error) {
+	args := m.Called(ctx, code, state)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// oidcCallbackRequest is the provider's redirect back, from a browser that
+// holds the state cookie for browserState.
+func oidcCallbackRequest(query, browserState string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/callback?"+query, nil)
+	if browserState != "" {
+		req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: auth.HashOpaqueToken(browserState)})
+	}
+	return req
+}
+
+func TestOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Redirect to the provider", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		controller := NewOIDCController(mockService, new(MockTokenService), new(MockMFAService))
+		mockService.On("StartLogin", mock.Anything).Return("https://idp.example.com/authorize?state=abc", "abc", nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/oidc/login", nil)
+
+		controller.Login(c)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://idp.example.com/authorize?state=abc", w.Header().Get("Location"))
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, oidcStateCookie, cookies[0].Name)
+			assert.Equal(t, auth.HashOpaqueToken("abc"), cookies[0].Value)
+			assert.Equal(t, 600, cookies[0].MaxAge)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		}
+	})
+
+	t.Run("Provider Unavailable", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		controller := NewOIDCController(mockService, new(MockTokenService), new(MockMFAService))
+		mockService.On("StartLogin", mock.Anything).
+			Return("", "", apperrors.Unavailable("oidc_provider_unavailable", "identity provider is unavailable")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/oidc/login", nil)
+
+		controller.Login(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "oidc_provider_unavailable")
+		assert.Empty(t, w.Result().Cookies())
+	})
+}
+
+func TestOIDCCallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		mockTokenService := new(MockTokenService)
+		controller := NewOIDCController(mockService, mockTokenService, new(MockMFAService))
+		user := &models.User{ID: 7, Name: "Ann", Email: "ann@example.com"}
+		mockService.On("CompleteLogin", mock.Anything, "code-1", "state-1").Return(user, nil).Once()
+		mockTokenService.On("IssueTokens", mock.Anything, user, "").
+			Return(&auth.TokenPair{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), RefreshToken: "refresh"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = oidcCallbackRequest("code=code-1&state=state-1", "state-1")
+
+		controller.Callback(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"access"`)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"refresh"`)
+		assert.Contains(t, w.Body.String(), `"email":"ann@example.com"`)
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, oidcStateCookie, cookies[0].Name)
+			assert.Negative(t, cookies[0].MaxAge, "the state cookie is removed")
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Ask for the second factor", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		mockTokenService := new(MockTokenService)
+		mockMFAService := new(MockMFAService)
+		controller := NewOIDCController(mockService, mockTokenService, mockMFAService)
+		enabledAt := time.Now()
+		user := &models.User{ID: 7, Email: "ann@example.com", TOTPEnabledAt: &enabledAt}
+		mockService.On("CompleteLogin", mock.Anything, "code-1", "state-1").Return(user, nil).Once()
+		mockMFAService.On("StartMFAChallenge", mock.Anything, user).
+			Return(&auth.MFAChallenge{Token: "mfa-token", ExpiresAt: time.Now().Add(auth.MFATokenTTL)}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = oidcCallbackRequest("code=code-1&state=state-1", "state-1")
+
+		controller.Callback(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"mfa_token":"mfa-token"`)
+		mockTokenService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Provider Refused", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		controller := NewOIDCController(mockService, new(MockTokenService), new(MockMFAService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = oidcCallbackRequest("error=Call+support+at+example.net&state=state-1", "state-1")
+
+		controller.Callback(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "oidc_login_failed")
+		assert.NotContains(t, w.Body.String(), "example.net")
+		mockService.AssertNotCalled(t, "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Started In Another Browser", func(t *testing.T) {
+		for name, browserState := range map[string]string{"no cookie": "", "other login": "state-2"} {
+			mockService := new(MockOIDCService)
+			controller := NewOIDCController(mockService, new(MockTokenService), new(MockMFAService))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = oidcCallbackRequest("code=code-1&state=state-1", browserState)
+
+			controller.Callback(c)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+			assert.Contains(t, w.Body.String(), "invalid_oidc_state", name)
+			mockService.AssertNotCalled(t, "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Invalid State", func(t *testing.T) {
+		mockService := new(MockOIDCService)
+		mockTokenService := new(MockTokenService)
+		controller := NewOIDCController(mockService, mockTokenService, new(MockMFAService))
+		mockService.On("CompleteLogin", mock.Anything, "code-1", "expired").
+			Return(nil, apperrors.Unauthorized("invalid_oidc_state", "login request is unknown or has expired")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = oidcCallbackRequest("code=code-1&state=expired", "expired")
+
+		controller.Callback(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_oidc_state")
+		mockTokenService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
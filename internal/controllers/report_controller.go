@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReportController struct {
+	reportService services.ReportService
+}
+
+func NewReportController(reportService services.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
+}
+
+// GetSummary reports income and expense totals for a user.
+// @Summary Income and expense summary
+// @Description Total the authenticated user's income and expenses overall, per category and per tag. Transfers between the user's own accounts are excluded.
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query int false "Category ID" minimum(1)
+// @Param account_id query int false "Account ID" minimum(1)
+// @Param tag query []string false "Tag names; repeat or comma-separate for several" collectionFormat(multi)
+// @Param tag_match query string false "Whether transactions need any or all of the tags" Enums(any, all)
+// @Param from query string false "Start date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "End date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} summaryResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/reports/summary [get]
+func (rc *ReportController) GetSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	transactionFilters, err := parseTransactionFilters(c)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	summary, err := rc.reportService.GetSummaryByUser(ctx, userID, transactionFilters)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSummaryResponse(summary))
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportService implements services.ReportService
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) GetSummaryByUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) (*models.TransactionSummary, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.TransactionSummary), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestGetSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockReportService)
+		controller := NewReportController(mockService)
+
+		summary := &models.TransactionSummary{
+			Income:  300,
+			Expense: 50,
+			Categories: []models.CategoryTotal{
+				{CategoryID: 1, Type: "expense", Total: 50},
+				{CategoryID: 2, Type: "income", Total: 300},
+			},
+		}
+		mockService.On("GetSummaryByUser", mock.Anything, uint(1), filters.TransactionFilters{}).Return(summary, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/reports/summary", nil)
+
+		controller.GetSummary(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"income":300`)
+		assert.Contains(t, w.Body.String(), `"category_id":2`)
+	})
+
+	t.Run("Invalid Filter", func(t *testing.T) {
+		mockService := new(MockReportService)
+		controller := NewReportController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/reports/summary?from=yesterday", nil)
+
+		controller.GetSummary(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetSummaryByUser")
+	})
+}
@@ -3,20 +3,35 @@ package controllers
 import (
 	"time"
 
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/pagination"
 )
 
 type transactionResponse struct {
-	ID         uint      `json:"id"`
-	UserID     uint      `json:"user_id"`
-	Type       string    `json:"type"`
-	Amount     float64   `json:"amount"`
-	CategoryID uint      `json:"category_id"`
-	Date       time.Time `json:"date"`
-	Note       string    `json:"note"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         uint                       `json:"id"`
+	UserID     uint                       `json:"user_id"`
+	Type       string                     `json:"type"`
+	Amount     float64                    `json:"amount"`
+	CategoryID uint                       `json:"category_id"`
+	AccountID  *uint                      `json:"account_id"`
+	TransferID *uint                      `json:"transfer_id,omitempty"`
+	Date       time.Time                  `json:"date"`
+	Note       string                     `json:"note"`
+	Payee      string                     `json:"payee,omitempty"`
+	ExternalID string                     `json:"external_id,omitempty"`
+	Splits     []transactionSplitResponse `json:"splits,omitempty"`
+	Tags       []string                   `json:"tags,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	UpdatedAt  time.Time                  `json:"updated_at"`
+}
+
+type transactionSplitResponse struct {
+	ID         uint    `json:"id"`
+	CategoryID uint    `json:"category_id"`
+	Amount     float64 `json:"amount"`
+	Note       string  `json:"note"`
 }
 
 type budgetResponse struct {
@@ -28,6 +43,180 @@ type budgetResponse struct {
 	EndDate    time.Time `json:"end_date"`
 }
 
+type accountResponse struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Currency       string    `json:"currency"`
+	OpeningBalance float64   `json:"opening_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type accountBalanceResponse struct {
+	AccountID      uint      `json:"account_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Currency       string    `json:"currency"`
+	OpeningBalance float64   `json:"opening_balance"`
+	Balance        float64   `json:"balance"`
+	AsOf           time.Time `json:"as_of"`
+}
+
+type transferResponse struct {
+	ID            uint                  `json:"id"`
+	FromAccountID uint                  `json:"from_account_id"`
+	ToAccountID   uint                  `json:"to_account_id"`
+	Amount        float64               `json:"amount"`
+	Date          time.Time             `json:"date"`
+	Note          string                `json:"note"`
+	Legs          []transactionResponse `json:"legs"`
+}
+
+type tagResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type apiKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// createdAPIKeyResponse is the only response that carries the key itself.
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+type ruleResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Priority      int       `json:"priority"`
+	NoteContains  string    `json:"note_contains,omitempty"`
+	NotePattern   string    `json:"note_pattern,omitempty"`
+	PayeeContains string    `json:"payee_contains,omitempty"`
+	PayeePattern  string    `json:"payee_pattern,omitempty"`
+	MinAmount     *float64  `json:"min_amount,omitempty"`
+	MaxAmount     *float64  `json:"max_amount,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	CategoryID    *uint     `json:"category_id,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Payee         string    `json:"payee,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type applyRulesResponse struct {
+	Updated int `json:"updated"`
+}
+
+type tagTotalResponse struct {
+	TagID uint    `json:"tag_id"`
+	Name  string  `json:"name"`
+	Type  string  `json:"type"`
+	Total float64 `json:"total"`
+}
+
+type categoryTotalResponse struct {
+	CategoryID uint    `json:"category_id"`
+	Type       string  `json:"type"`
+	Total      float64 `json:"total"`
+}
+
+type summaryResponse struct {
+	Income     float64                 `json:"income"`
+	Expense    float64                 `json:"expense"`
+	Net        float64                 `json:"net"`
+	Categories []categoryTotalResponse `json:"categories"`
+	Tags       []tagTotalResponse      `json:"tags"`
+}
+
+type importRowResponse struct {
+	Line        int       `json:"line"`
+	Date        time.Time `json:"date"`
+	Amount      float64   `json:"amount"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Payee       string    `json:"payee,omitempty"`
+	ExternalID  string    `json:"external_id,omitempty"`
+}
+
+type importRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type importPreviewResponse struct {
+	Rows    []importRowResponse      `json:"rows"`
+	Errors  []importRowErrorResponse `json:"errors"`
+	Skipped []importRowResponse      `json:"skipped"`
+}
+
+type importResultResponse struct {
+	Imported   int                      `json:"imported"`
+	Skipped    int                      `json:"skipped"`
+	Duplicates []duplicateMatchResponse `json:"duplicates"`
+}
+
+type userDeletionResponse struct {
+	Message             string     `json:"message"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+type mfaChallengeResponse struct {
+	Message     string    `json:"message"`
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type totpEnrolmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type recoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type archiveSummaryResponse struct {
+	Categories   int `json:"categories"`
+	Accounts     int `json:"accounts"`
+	Tags         int `json:"tags"`
+	Budgets      int `json:"budgets"`
+	Rules        int `json:"rules"`
+	Transfers    int `json:"transfers"`
+	Transactions int `json:"transactions"`
+}
+
+type createTransactionResponse struct {
+	Message            string                `json:"message"`
+	PossibleDuplicates []transactionResponse `json:"possible_duplicates,omitempty"`
+}
+
+type duplicateMatchResponse struct {
+	Transaction transactionResponse   `json:"transaction"`
+	Candidates  []transactionResponse `json:"candidates"`
+}
+
+type duplicateGroupResponse struct {
+	Transactions []transactionResponse `json:"transactions"`
+}
+
+type categorySuggestionResponse struct {
+	CategoryID uint    `json:"category_id"`
+	Confidence float64 `json:"confidence"`
+}
+
 type paginationResponse struct {
 	Page       int   `json:"page"`
 	PageSize   int   `json:"page_size"`
@@ -40,6 +229,10 @@ type paginatedResponse[T any] struct {
 	Pagination paginationResponse `json:"pagination"`
 }
 
+type listResponse[T any] struct {
+	Data []T `json:"data"`
+}
+
 func newTransactionResponse(transaction models.Transaction) transactionResponse {
 	return transactionResponse{
 		ID:         transaction.ID,
@@ -47,13 +240,58 @@ func newTransactionResponse(transaction models.Transaction) transactionResponse
 		Type:       transaction.Type,
 		Amount:     transaction.Amount,
 		CategoryID: transaction.CategoryID,
+		AccountID:  transaction.AccountID,
+		TransferID: transaction.TransferID,
 		Date:       transaction.Date,
 		Note:       transaction.Note,
+		Payee:      transaction.Payee,
+		ExternalID: transaction.ExternalID,
+		Splits:     newTransactionSplitResponses(transaction.Splits),
+		Tags:       tagNames(transaction.Tags),
 		CreatedAt:  transaction.CreatedAt,
 		UpdatedAt:  transaction.UpdatedAt,
 	}
 }
 
+func newDuplicateMatchResponses(matches []models.DuplicateMatch) []duplicateMatchResponse {
+	responses := make([]duplicateMatchResponse, 0, len(matches))
+	for _, match := range matches {
+		responses = append(responses, duplicateMatchResponse{
+			Transaction: newTransactionResponse(match.Transaction),
+			Candidates:  newTransactionResponses(match.Candidates),
+		})
+	}
+	return responses
+}
+
+func newCategorySuggestionResponses(suggestions []models.CategorySuggestion) []categorySuggestionResponse {
+	responses := make([]categorySuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		responses = append(responses, categorySuggestionResponse{
+			CategoryID: suggestion.CategoryID,
+			Confidence: suggestion.Confidence,
+		})
+	}
+	return responses
+}
+
+func newTransactionSplitResponses(splits []models.TransactionSplit) []transactionSplitResponse {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	responses := make([]transactionSplitResponse, 0, len(splits))
+	for _, split := range splits {
+		responses = append(responses, transactionSplitResponse{
+			ID:         split.ID,
+			CategoryID: split.CategoryID,
+			Amount:     split.Amount,
+			Note:       split.Note,
+		})
+	}
+	return responses
+}
+
 func newBudgetResponse(budget models.Budget) budgetResponse {
 	return budgetResponse{
 		ID:         budget.ID,
@@ -65,6 +303,104 @@ func newBudgetResponse(budget models.Budget) budgetResponse {
 	}
 }
 
+func newAccountResponse(account models.Account) accountResponse {
+	return accountResponse{
+		ID:             account.ID,
+		UserID:         account.UserID,
+		Name:           account.Name,
+		Type:           account.Type,
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+		CreatedAt:      account.CreatedAt,
+		UpdatedAt:      account.UpdatedAt,
+	}
+}
+
+func newAccountBalanceResponse(balance models.AccountBalance) accountBalanceResponse {
+	return accountBalanceResponse{
+		AccountID:      balance.Account.ID,
+		Name:           balance.Account.Name,
+		Type:           balance.Account.Type,
+		Currency:       balance.Account.Currency,
+		OpeningBalance: balance.Account.OpeningBalance,
+		Balance:        balance.Balance,
+		AsOf:           balance.AsOf,
+	}
+}
+
+func tagNames(tags []models.Tag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func newTagResponse(tag models.Tag) tagResponse {
+	return tagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+func newAPIKeyResponse(key models.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func newTransferResponse(transfer models.Transfer) transferResponse {
+	return transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Date:          transfer.Date,
+		Note:          transfer.Note,
+		Legs:          newTransactionResponses(transfer.Legs),
+	}
+}
+
+func newSummaryResponse(summary *models.TransactionSummary) summaryResponse {
+	categories := make([]categoryTotalResponse, 0, len(summary.Categories))
+	for _, categoryTotal := range summary.Categories {
+		categories = append(categories, categoryTotalResponse{
+			CategoryID: categoryTotal.CategoryID,
+			Type:       categoryTotal.Type,
+			Total:      categoryTotal.Total,
+		})
+	}
+
+	tags := make([]tagTotalResponse, 0, len(summary.Tags))
+	for _, tagTotal := range summary.Tags {
+		tags = append(tags, tagTotalResponse{
+			TagID: tagTotal.TagID,
+			Name:  tagTotal.Name,
+			Type:  tagTotal.Type,
+			Total: tagTotal.Total,
+		})
+	}
+
+	return summaryResponse{
+		Income:     summary.Income,
+		Expense:    summary.Expense,
+		Net:        summary.Income - summary.Expense,
+		Categories: categories,
+		Tags:       tags,
+	}
+}
+
 func newTransactionResponses(transactions []models.Transaction) []transactionResponse {
 	responses := make([]transactionResponse, 0, len(transactions))
 	for _, transaction := range transactions {
@@ -81,6 +417,115 @@ func newBudgetResponses(budgets []models.Budget) []budgetResponse {
 	return responses
 }
 
+func newAccountResponses(accounts []models.Account) []accountResponse {
+	responses := make([]accountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		responses = append(responses, newAccountResponse(account))
+	}
+	return responses
+}
+
+func newAccountBalanceResponses(balances []models.AccountBalance) []accountBalanceResponse {
+	responses := make([]accountBalanceResponse, 0, len(balances))
+	for _, balance := range balances {
+		responses = append(responses, newAccountBalanceResponse(balance))
+	}
+	return responses
+}
+
+func newRuleResponse(rule models.Rule) ruleResponse {
+	return ruleResponse{
+		ID:            rule.ID,
+		Name:          rule.Name,
+		Priority:      rule.Priority,
+		NoteContains:  rule.NoteContains,
+		NotePattern:   rule.NotePattern,
+		PayeeContains: rule.PayeeContains,
+		PayeePattern:  rule.PayeePattern,
+		MinAmount:     rule.MinAmount,
+		MaxAmount:     rule.MaxAmount,
+		Type:          rule.Type,
+		CategoryID:    rule.CategoryID,
+		Tags:          rule.TagNames(),
+		Payee:         rule.Payee,
+		CreatedAt:     rule.CreatedAt,
+		UpdatedAt:     rule.UpdatedAt,
+	}
+}
+
+func newRuleResponses(rules []models.Rule) []ruleResponse {
+	responses := make([]ruleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, newRuleResponse(rule))
+	}
+	return responses
+}
+
+func newTagResponses(tags []models.Tag) []tagResponse {
+	responses := make([]tagResponse, 0, len(tags))
+	for _, tag := range tags {
+		responses = append(responses, newTagResponse(tag))
+	}
+	return responses
+}
+
+func newAPIKeyResponses(keys []models.APIKey) []apiKeyResponse {
+	responses := make([]apiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, newAPIKeyResponse(key))
+	}
+	return responses
+}
+
+func newArchiveSummaryResponse(summary *archive.Summary) archiveSummaryResponse {
+	return archiveSummaryResponse{
+		Categories:   summary.Categories,
+		Accounts:     summary.Accounts,
+		Tags:         summary.Tags,
+		Budgets:      summary.Budgets,
+		Rules:        summary.Rules,
+		Transfers:    summary.Transfers,
+		Transactions: summary.Transactions,
+	}
+}
+
+func newImportPreviewResponse(preview *imports.Preview) importPreviewResponse {
+	response := importPreviewResponse{
+		Rows:    make([]importRowResponse, 0, len(preview.Rows)),
+		Errors:  make([]importRowErrorResponse, 0, len(preview.Errors)),
+		Skipped: make([]importRowResponse, 0, len(preview.Skipped)),
+	}
+
+	for _, row := range preview.Rows {
+		response.Rows = append(response.Rows, newImportRowResponse(row))
+	}
+
+	for _, row := range preview.Skipped {
+		response.Skipped = append(response.Skipped, newImportRowResponse(row))
+	}
+
+	for _, rowError := range preview.Errors {
+		response.Errors = append(response.Errors, importRowErrorResponse{
+			Line:    rowError.Line,
+			Message: rowError.Message,
+		})
+	}
+
+	return response
+}
+
+func newImportRowResponse(row imports.ParsedRow) importRowResponse {
+	return importRowResponse{
+		Line:        row.Line,
+		Date:        row.Date,
+		Amount:      row.Amount,
+		Type:        row.Type,
+		Description: row.Description,
+		Payee:       row.Payee,
+		ExternalID:  row.ExternalID,
+	}
+}
+
 func newPaginationResponse(params pagination.Params, total int64) paginationResponse {
 	return paginationResponse{
 		Page:       params.Page,
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RuleController struct {
+	ruleService        services.RuleService
+	transactionService services.TransactionService
+}
+
+func NewRuleController(ruleService services.RuleService, transactionService services.TransactionService) *RuleController {
+	return &RuleController{ruleService: ruleService, transactionService: transactionService}
+}
+
+type ruleRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Priority      int      `json:"priority"`
+	NoteContains  string   `json:"note_contains" binding:"max=255"`
+	NotePattern   string   `json:"note_pattern" binding:"max=255"`
+	PayeeContains string   `json:"payee_contains" binding:"max=255"`
+	PayeePattern  string   `json:"payee_pattern" binding:"max=255"`
+	MinAmount     *float64 `json:"min_amount"`
+	MaxAmount     *float64 `json:"max_amount"`
+	Type          string   `json:"type"`
+	CategoryID    *uint    `json:"category_id" binding:"omitempty,min=1"`
+	Tags          []string `json:"tags"`
+	Payee         string   `json:"payee" binding:"max=255"`
+}
+
+// CreateRule adds a new categorisation rule
+// @Summary Create a rule
+// @Description Create a categorisation rule for the authenticated user. Every condition that is set must match: note or payee substring (case-insensitive) or regular expression, amount range and type. Matching rules set the category and payee of new and imported transactions that have none, and add their tags. Rules run by ascending priority; the first rule that sets a category or payee wins.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body ruleRequest true "Rule payload"
+// @Success 201 {object} ruleResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/rules [post]
+func (rc *RuleController) CreateRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := bindRuleRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := rc.ruleService.CreateRule(ctx, &rule); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newRuleResponse(rule))
+}
+
+// GetRules fetches all rules for a user.
+// @Summary List rules
+// @Description List the authenticated user's categorisation rules in evaluation order.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ruleListResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/rules [get]
+func (rc *RuleController) GetRules(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := rc.ruleService.GetRulesByUser(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[ruleResponse]{Data: newRuleResponses(rules)})
+}
+
+// UpdateRule replaces a rule's conditions and actions
+// @Summary Update a rule
+// @Description Replace one of the authenticated user's categorisation rules. Existing transactions are not changed; use the apply endpoint for that.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID" minimum(1)
+// @Param payload body ruleRequest true "Rule payload"
+// @Success 200 {object} ruleResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/rules/{id} [put]
+func (rc *RuleController) UpdateRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_rule_id", "invalid rule id"))
+		return
+	}
+
+	rule, ok := bindRuleRequest(c, userID)
+	if !ok {
+		return
+	}
+	rule.ID = uint(ruleID)
+
+	if err := rc.ruleService.UpdateRuleForUser(ctx, userID, &rule); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRuleResponse(rule))
+}
+
+// DeleteRule removes a rule
+// @Summary Delete a rule
+// @Description Delete one of the authenticated user's categorisation rules. Transactions it already changed keep their values.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rule ID" minimum(1)
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/rules/{id} [delete]
+func (rc *RuleController) DeleteRule(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_rule_id", "invalid rule id"))
+		return
+	}
+
+	if err := rc.ruleService.DeleteRuleForUser(ctx, userID, uint(ruleID)); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rule deleted"})
+}
+
+// ApplyRules re-applies rules to existing transactions
+// @Summary Re-apply rules
+// @Description Run the authenticated user's rules over their stored transactions matching the filters. Matching rules replace the category and payee and add their tags; transfers and split lines are left alone. All changes are saved in a single database transaction.
+// @Tags rules
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Transaction type" Enums(income, expense, transfer)
+// @Param category_id query int false "Category ID" minimum(1)
+// @Param account_id query int false "Account ID" minimum(1)
+// @Param tag query []string false "Tag names; repeat or comma-separate for several" collectionFormat(multi)
+// @Param tag_match query string false "Whether transactions need any or all of the tags" Enums(any, all)
+// @Param from query string false "Start date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "End date/time filter (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} applyRulesResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/rules/apply [post]
+func (rc *RuleController) ApplyRules(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	transactionFilters, err := parseTransactionFilters(c)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	updated, err := rc.transactionService.ReapplyRulesForUser(ctx, userID, transactionFilters)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applyRulesResponse{Updated: updated})
+}
+
+// bindRuleRequest decodes a rule payload, writing an error response when it is invalid.
+func bindRuleRequest(c *gin.Context, userID uint) (models.Rule, bool) {
+	var req ruleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return models.Rule{}, false
+	}
+
+	// Rules store their tags comma-separated, which tag names cannot contain.
+	for _, name := range req.Tags {
+		if strings.Contains(name, ",") {
+			httpapi.WriteError(c, apperrors.Validation("invalid_tag_name", "tag names cannot contain commas"))
+			return models.Rule{}, false
+		}
+	}
+
+	return models.Rule{
+		UserID:        userID,
+		Name:          req.Name,
+		Priority:      req.Priority,
+		NoteContains:  req.NoteContains,
+		NotePattern:   req.NotePattern,
+		PayeeContains: req.PayeeContains,
+		PayeePattern:  req.PayeePattern,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		Type:          req.Type,
+		CategoryID:    req.CategoryID,
+		Tags:          strings.Join(req.Tags, ","),
+		Payee:         req.Payee,
+	}, true
+}
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRuleService implements services.RuleService
+type MockRuleService struct {
+	mock.Mock
+}
+
+func (m *MockRuleService) CreateRule(ctx context.Context, rule *models.Rule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockRuleService) GetRulesByUser(ctx context.Context, userID uint) ([]models.Rule, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Rule), args.Error(1)
+}
+
+func (m *MockRuleService) UpdateRuleForUser(ctx context.Context, userID uint, rule *models.Rule) error {
+	args := m.Called(ctx, userID, rule)
+	return args.Error(0)
+}
+
+func (m *MockRuleService) DeleteRuleForUser(ctx context.Context, userID, ruleID uint) error {
+	args := m.Called(ctx, userID, ruleID)
+	return args.Error(0)
+}
+
+func TestCreateRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockRuleService)
+		controller := NewRuleController(mockService, new(MockTransactionService))
+
+		mockService.On("CreateRule", mock.Anything, mock.MatchedBy(func(rule *models.Rule) bool {
+			return rule.UserID == 1 &&
+				rule.NoteContains == "supermarket" &&
+				rule.Tags == "food,weekly" &&
+				rule.CategoryID != nil && *rule.CategoryID == 4 &&
+				rule.MinAmount != nil && *rule.MinAmount == 5
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Rule).ID = 3
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		body := `{"name":"Groceries","note_contains":"supermarket","min_amount":5,"category_id":4,"tags":["food","weekly"]}`
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateRule(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":3`)
+		assert.Contains(t, w.Body.String(), `"tags":["food","weekly"]`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Reject comma in tag name", func(t *testing.T) {
+		mockService := new(MockRuleService)
+		controller := NewRuleController(mockService, new(MockTransactionService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/rules", bytes.NewBufferString(`{"name":"Bad","note_contains":"x","tags":["a,b"]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateRule(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_tag_name")
+		mockService.AssertNotCalled(t, "CreateRule")
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		mockService := new(MockRuleService)
+		controller := NewRuleController(mockService, new(MockTransactionService))
+
+		mockService.On("CreateRule", mock.Anything, mock.AnythingOfType("*models.Rule")).
+			Return(apperrors.Validation("invalid_rule_pattern", "invalid note pattern")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/rules", bytes.NewBufferString(`{"name":"Bad","note_pattern":"(","payee":"Acme"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateRule(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_rule_pattern")
+	})
+}
+
+func TestGetRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRuleService)
+	controller := NewRuleController(mockService, new(MockTransactionService))
+
+	mockService.On("GetRulesByUser", mock.Anything, uint(1)).Return([]models.Rule{{ID: 1, UserID: 1, Name: "Rent", NoteContains: "rent", Payee: "Landlord"}}, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/rules", nil)
+
+	controller.GetRules(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"payee":"Landlord"`)
+}
+
+func TestUpdateRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockRuleService)
+		controller := NewRuleController(mockService, new(MockTransactionService))
+
+		mockService.On("UpdateRuleForUser", mock.Anything, uint(1), mock.MatchedBy(func(rule *models.Rule) bool {
+			return rule.ID == 5 && rule.Priority == 2
+		})).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "5"}}
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/rules/5", bytes.NewBufferString(`{"name":"Rent","priority":2,"note_contains":"rent","payee":"Landlord"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateRule(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService := new(MockRuleService)
+		controller := NewRuleController(mockService, new(MockTransactionService))
+
+		mockService.On("UpdateRuleForUser", mock.Anything, uint(1), mock.AnythingOfType("*models.Rule")).
+			Return(apperrors.NotFound("rule_not_found", "rule not found")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "9"}}
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/rules/9", bytes.NewBufferString(`{"name":"Rent","note_contains":"rent","payee":"Landlord"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateRule(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockRuleService)
+	controller := NewRuleController(mockService, new(MockTransactionService))
+
+	mockService.On("DeleteRuleForUser", mock.Anything, uint(1), uint(2)).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/rules/2", nil)
+
+	controller.DeleteRule(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Rule deleted")
+}
+
+func TestApplyRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockTransactionService := new(MockTransactionService)
+		controller := NewRuleController(new(MockRuleService), mockTransactionService)
+
+		mockTransactionService.On("ReapplyRulesForUser", mock.Anything, uint(1), mock.MatchedBy(func(transactionFilters filters.TransactionFilters) bool {
+			return transactionFilters.Type == "expense" && transactionFilters.From != nil
+		})).Return(4, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/rules/apply?type=expense&from=2026-01-01", nil)
+
+		controller.ApplyRules(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"updated":4`)
+		mockTransactionService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Filter", func(t *testing.T) {
+		mockTransactionService := new(MockTransactionService)
+		controller := NewRuleController(new(MockRuleService), mockTransactionService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/rules/apply?from=yesterday", nil)
+
+		controller.ApplyRules(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTransactionService.AssertNotCalled(t, "ReapplyRulesForUser")
+	})
+}
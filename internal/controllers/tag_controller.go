@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct {
+	tagService services.TagService
+}
+
+func NewTagController(tagService services.TagService) *TagController {
+	return &TagController{tagService: tagService}
+}
+
+type createTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateTag adds a new tag
+// @Summary Create a tag
+// @Description Create a tag for the authenticated user. Tag names are case-insensitive and stored in lowercase.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body createTagRequest true "Tag payload"
+// @Success 201 {object} tagResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/tags [post]
+func (tc *TagController) CreateTag(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req createTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	tag := models.Tag{UserID: userID, Name: req.Name}
+	if err := tc.tagService.CreateTag(ctx, &tag); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTagResponse(tag))
+}
+
+// GetTags fetches all tags for a user.
+// @Summary List tags
+// @Description List the authenticated user's tags.
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} tagListResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/tags [get]
+func (tc *TagController) GetTags(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := tc.tagService.GetTagsByUser(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[tagResponse]{Data: newTagResponses(tags)})
+}
+
+// DeleteTag removes a tag
+// @Summary Delete a tag
+// @Description Delete one of the authenticated user's tags and remove it from every transaction.
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID" minimum(1)
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/tags/{id} [delete]
+func (tc *TagController) DeleteTag(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_tag_id", "invalid tag id"))
+		return
+	}
+
+	if err := tc.tagService.DeleteTagForUser(ctx, userID, uint(tagID)); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted"})
+}
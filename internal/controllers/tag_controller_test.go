@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTagService implements services.TagService
+type MockTagService struct {
+	mock.Mock
+}
+
+func (m *MockTagService) CreateTag(ctx context.Context, tag *models.Tag) error {
+	args := m.Called(ctx, tag)
+	return args.Error(0)
+}
+
+func (m *MockTagService) GetTagsByUser(ctx context.Context, userID uint) ([]models.Tag, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagService) DeleteTagForUser(ctx context.Context, userID, tagID uint) error {
+	args := m.Called(ctx, userID, tagID)
+	return args.Error(0)
+}
+
+func TestCreateTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTagService)
+		controller := NewTagController(mockService)
+
+		mockService.On("CreateTag", mock.Anything, mock.MatchedBy(func(tag *models.Tag) bool {
+			return tag.UserID == 1 && tag.Name == "tax-deductible"
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Tag).ID = 3
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/tags", bytes.NewBufferString(`{"name":"tax-deductible"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTag(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":3`)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		mockService := new(MockTagService)
+		controller := NewTagController(mockService)
+
+		mockService.On("CreateTag", mock.Anything, mock.AnythingOfType("*models.Tag")).
+			Return(apperrors.Conflict("tag_exists", "a tag with this name already exists")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/tags", bytes.NewBufferString(`{"name":"tax-deductible"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTag(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestGetTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockTagService)
+	controller := NewTagController(mockService)
+
+	mockService.On("GetTagsByUser", mock.Anything, uint(1)).Return([]models.Tag{{ID: 1, UserID: 1, Name: "reimbursable"}}, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil)
+
+	controller.GetTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"reimbursable"`)
+}
+
+func TestDeleteTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTagService)
+		controller := NewTagController(mockService)
+
+		mockService.On("DeleteTagForUser", mock.Anything, uint(1), uint(2)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/tags/2", nil)
+
+		controller.DeleteTag(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Tag deleted")
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		mockService := new(MockTagService)
+		controller := NewTagController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/tags/abc", nil)
+
+		controller.DeleteTag(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "DeleteTagForUser")
+	})
+}
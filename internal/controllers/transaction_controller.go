@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
@@ -21,24 +22,48 @@ func NewTransactionController(transactionService services.TransactionService) *T
 }
 
 type createTransactionRequest struct {
-	Type       string    `json:"type" binding:"required"`
-	Amount     float64   `json:"amount" binding:"required"`
-	CategoryID uint      `json:"category_id" binding:"required"`
-	Date       time.Time `json:"date" binding:"required"`
-	Note       string    `json:"note"`
+	Type       string                    `json:"type" binding:"required"`
+	Amount     float64                   `json:"amount" binding:"required"`
+	CategoryID uint                      `json:"category_id"`
+	AccountID  *uint                     `json:"account_id"`
+	Date       time.Time                 `json:"date" binding:"required"`
+	Note       string                    `json:"note"`
+	Payee      string                    `json:"payee" binding:"max=255"`
+	Splits     []transactionSplitRequest `json:"splits" binding:"omitempty,dive"`
+	Tags       []string                  `json:"tags"`
+}
+
+type transactionSplitRequest struct {
+	CategoryID uint    `json:"category_id" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required"`
+	Note       string  `json:"note"`
+}
+
+type mergeDuplicatesRequest struct {
+	KeepID       uint   `json:"keep_id" binding:"required"`
+	DuplicateIDs []uint `json:"duplicate_ids" binding:"required,min=1,max=100"`
+}
+
+type createTransferRequest struct {
+	FromAccountID uint      `json:"from_account_id" binding:"required"`
+	ToAccountID   uint      `json:"to_account_id" binding:"required"`
+	Amount        float64   `json:"amount" binding:"required"`
+	Date          time.Time `json:"date" binding:"required"`
+	Note          string    `json:"note"`
 }
 
 // CreateTransaction adds a new transaction
 // @Summary Create a transaction
-// @Description Create a transaction for the authenticated user.
+// @Description Create a transaction for the authenticated user. Provide `splits` instead of `category_id` to spread the amount over several categories; split amounts must add up to the transaction amount. Without either, the user's rules must set a category. Tags are referenced by name and created on first use. Stored transactions with the same type, amount and normalised note dated within the duplicate window are returned as possible_duplicates.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param payload body createTransactionRequest true "Transaction payload"
-// @Success 201 {object} messageResponse
+// @Success 201 {object} createTransactionResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/transactions [post]
 func (tc *TransactionController) CreateTransaction(c *gin.Context) {
@@ -55,14 +80,7 @@ func (tc *TransactionController) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	transaction := models.Transaction{
-		UserID:     userID,
-		Type:       req.Type,
-		Amount:     req.Amount,
-		CategoryID: req.CategoryID,
-		Date:       req.Date,
-		Note:       req.Note,
-	}
+	transaction := req.toModel(userID)
 
 	err := tc.transactionService.AddTransaction(ctx, &transaction)
 	if err != nil {
@@ -70,7 +88,124 @@ func (tc *TransactionController) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Transaction added"})
+	response := createTransactionResponse{Message: "Transaction added"}
+
+	// Duplicate detection is advisory: the transaction is already stored, so a
+	// failed lookup only means no candidates are reported.
+	matches, err := tc.transactionService.FindDuplicates(ctx, userID, []models.Transaction{transaction})
+	if err == nil && len(matches) > 0 {
+		response.PossibleDuplicates = newTransactionResponses(matches[0].Candidates)
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetDuplicates lists suspected duplicate transactions
+// @Summary List suspected duplicates
+// @Description Group the authenticated user's transactions that share type, amount and normalised note and are dated within the duplicate window of each other. Newest groups come first.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} duplicateGroupListResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transactions/duplicates [get]
+func (tc *TransactionController) GetDuplicates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	groups, err := tc.transactionService.GetDuplicateGroupsByUser(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	responses := make([]duplicateGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		responses = append(responses, duplicateGroupResponse{Transactions: newTransactionResponses(group.Transactions)})
+	}
+
+	c.JSON(http.StatusOK, listResponse[duplicateGroupResponse]{Data: responses})
+}
+
+// SuggestCategories ranks likely categories for a transaction
+// @Summary Suggest categories
+// @Description Rank the authenticated user's categories for a transaction with the given note and payee, most likely first, with confidences that add up to one. Suggestions come from a classifier trained only on the user's own categorised transactions and updated as they change; transfers and split transactions are not learned. The list is empty when none of the words has been seen before.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param note query string false "Transaction note; note or payee is required"
+// @Param payee query string false "Transaction payee; note or payee is required"
+// @Success 200 {object} categorySuggestionListResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transactions/suggest-category [get]
+func (tc *TransactionController) SuggestCategories(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	note, payee := strings.TrimSpace(c.Query("note")), strings.TrimSpace(c.Query("payee"))
+	if note == "" && payee == "" {
+		httpapi.WriteError(c, apperrors.Validation("missing_note", "note or payee is required"))
+		return
+	}
+
+	suggestions, err := tc.transactionService.SuggestCategories(ctx, userID, note, payee)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, listResponse[categorySuggestionResponse]{Data: newCategorySuggestionResponses(suggestions)})
+}
+
+// MergeDuplicates keeps one transaction and deletes its duplicates
+// @Summary Merge duplicates
+// @Description Keep one transaction and delete the given duplicates in a single database transaction. The kept transaction gains the duplicates' tags, and their external_id, payee and account when it has none. All transactions must have the same type and amount; transfer legs cannot be merged.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body mergeDuplicatesRequest true "Transaction to keep and duplicates to delete"
+// @Success 200 {object} transactionResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transactions/duplicates/merge [post]
+func (tc *TransactionController) MergeDuplicates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req mergeDuplicatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	transaction, err := tc.transactionService.MergeDuplicatesForUser(ctx, userID, req.KeepID, req.DuplicateIDs)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTransactionResponse(*transaction))
 }
 
 // GetTransactions fetches all transactions for a user
@@ -105,13 +240,17 @@ func (tc *TransactionController) GetTransactions(c *gin.Context) {
 // @Security BearerAuth
 // @Param page query int false "Page number" minimum(1)
 // @Param page_size query int false "Items per page" minimum(1) maximum(100)
-// @Param type query string false "Transaction type" Enums(income, expense)
+// @Param type query string false "Transaction type" Enums(income, expense, transfer)
 // @Param category_id query int false "Category ID" minimum(1)
+// @Param account_id query int false "Account ID" minimum(1)
+// @Param tag query []string false "Tag names; repeat or comma-separate for several" collectionFormat(multi)
+// @Param tag_match query string false "Whether transactions need any or all of the tags" Enums(any, all)
 // @Param from query string false "Start date/time filter (RFC3339 or YYYY-MM-DD)"
 // @Param to query string false "End date/time filter (RFC3339 or YYYY-MM-DD)"
 // @Success 200 {object} transactionPageResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/transactions [get]
 func (tc *TransactionController) GetTransactionsPage(c *gin.Context) {
@@ -146,9 +285,56 @@ func (tc *TransactionController) GetTransactionsPage(c *gin.Context) {
 	})
 }
 
+// UpdateTransaction replaces a transaction's fields, split lines and tags
+// @Summary Update a transaction
+// @Description Replace one of the authenticated user's transactions, including its split lines and tags. Transfer legs cannot be edited.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID" minimum(1)
+// @Param payload body createTransactionRequest true "Transaction payload"
+// @Success 200 {object} transactionResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transactions/{id} [put]
+func (tc *TransactionController) UpdateTransaction(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	transactionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_transaction_id", "invalid transaction id"))
+		return
+	}
+
+	var req createTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	transaction := req.toModel(userID)
+	transaction.ID = uint(transactionID)
+
+	if err := tc.transactionService.UpdateTransactionForUser(ctx, userID, &transaction); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTransactionResponse(transaction))
+}
+
 // DeleteTransaction removes a transaction
 // @Summary Delete a transaction
-// @Description Delete one of the authenticated user's transactions.
+// @Description Delete one of the authenticated user's transactions. Deleting a transfer leg deletes both legs.
 // @Tags transactions
 // @Produce json
 // @Security BearerAuth
@@ -156,6 +342,7 @@ func (tc *TransactionController) GetTransactionsPage(c *gin.Context) {
 // @Success 200 {object} messageResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
 // @Failure 404 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/transactions/{id} [delete]
@@ -181,3 +368,120 @@ func (tc *TransactionController) DeleteTransaction(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted"})
 }
+
+// CreateTransfer moves money between two of the user's accounts
+// @Summary Create a transfer
+// @Description Atomically book a linked debit and credit transaction between two of the authenticated user's accounts. Transfers are excluded from income/expense reports and budget checks.
+// @Tags transfers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body createTransferRequest true "Transfer payload"
+// @Success 201 {object} transferResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transfers [post]
+func (tc *TransactionController) CreateTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req createTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	transfer := models.Transfer{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Date:          req.Date,
+		Note:          req.Note,
+	}
+
+	if err := tc.transactionService.CreateTransfer(ctx, &transfer); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTransferResponse(transfer))
+}
+
+// DeleteTransfer removes a transfer and both of its legs
+// @Summary Delete a transfer
+// @Description Delete one of the authenticated user's transfers together with both legs.
+// @Tags transfers
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transfer ID" minimum(1)
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/transfers/{id} [delete]
+func (tc *TransactionController) DeleteTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	transferID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_transfer_id", "invalid transfer id"))
+		return
+	}
+
+	if err := tc.transactionService.DeleteTransferForUser(ctx, userID, uint(transferID)); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer deleted"})
+}
+
+func (req createTransactionRequest) toModel(userID uint) models.Transaction {
+	transaction := models.Transaction{
+		UserID:     userID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		CategoryID: req.CategoryID,
+		AccountID:  req.AccountID,
+		Date:       req.Date,
+		Note:       req.Note,
+		Payee:      req.Payee,
+		Splits:     newTransactionSplits(req.Splits),
+	}
+
+	for _, name := range req.Tags {
+		transaction.Tags = append(transaction.Tags, models.Tag{Name: name})
+	}
+
+	return transaction
+}
+
+func newTransactionSplits(requests []transactionSplitRequest) []models.TransactionSplit {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	splits := make([]models.TransactionSplit, 0, len(requests))
+	for _, request := range requests {
+		splits = append(splits, models.TransactionSplit{
+			CategoryID: request.CategoryID,
+			Amount:     request.Amount,
+			Note:       request.Note,
+		})
+	}
+	return splits
+}
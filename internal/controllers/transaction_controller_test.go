@@ -38,11 +38,67 @@ func (m *MockTransactionService) GetTransactionsPageByUser(ctx context.Context,
 	return args.Get(0).([]models.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockTransactionService) AddTransactions(ctx context.Context, transactions []models.Transaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) UpdateTransactionForUser(ctx context.Context, userID uint, transaction *models.Transaction) error {
+	args := m.Called(ctx, userID, transaction)
+	return args.Error(0)
+}
+
 func (m *MockTransactionService) DeleteTransactionForUser(ctx context.Context, userID, transactionID uint) error {
 	args := m.Called(ctx, userID, transactionID)
 	return args.Error(0)
 }
 
+func (m *MockTransactionService) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) DeleteTransferForUser(ctx context.Context, userID, transferID uint) error {
+	args := m.Called(ctx, userID, transferID)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) FindDuplicates(ctx context.Context, userID uint, transactions []models.Transaction) ([]models.DuplicateMatch, error) {
+	args := m.Called(ctx, userID, transactions)
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.DuplicateMatch), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) GetDuplicateGroupsByUser(ctx context.Context, userID uint) ([]models.DuplicateGroup, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.DuplicateGroup), args.Error(1)
+}
+
+func (m *MockTransactionService) MergeDuplicatesForUser(ctx context.Context, userID, keepID uint, duplicateIDs []uint) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, keepID, duplicateIDs)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) ApplyRules(ctx context.Context, userID uint, transactions []models.Transaction) error {
+	args := m.Called(ctx, userID, transactions)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) ReapplyRulesForUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) (int, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionService) SuggestCategories(ctx context.Context, userID uint, note, payee string) ([]models.CategorySuggestion, error) {
+	args := m.Called(ctx, userID, note, payee)
+	return args.Get(0).([]models.CategorySuggestion), args.Error(1)
+}
+
 func TestCreateTransaction(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -67,6 +123,10 @@ func TestCreateTransaction(t *testing.T) {
 				t.Note == "Lunch" &&
 				t.Date.Equal(now)
 		})).Return(nil).Once()
+		mockService.On("FindDuplicates", mock.Anything, uint(1), mock.Anything).Return([]models.DuplicateMatch{{
+			Transaction: models.Transaction{ID: 8, Note: "Lunch"},
+			Candidates:  []models.Transaction{{ID: 7, UserID: 1, Type: "expense", Amount: 50, Note: "lunch"}},
+		}}, nil).Once()
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -81,6 +141,55 @@ func TestCreateTransaction(t *testing.T) {
 
 		assert.Equal(t, http.StatusCreated, w.Code)
 		assert.Contains(t, w.Body.String(), "Transaction added")
+		assert.Contains(t, w.Body.String(), `"possible_duplicates":[{"id":7`)
+	})
+
+	t.Run("Split Lines", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("AddTransaction", mock.Anything, mock.MatchedBy(func(t *models.Transaction) bool {
+			return len(t.Splits) == 2 &&
+				t.Splits[0].CategoryID == 3 &&
+				t.Splits[1].Amount == 30.0
+		})).Return(nil).Once()
+		mockService.On("FindDuplicates", mock.Anything, uint(1), mock.Anything).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		body := `{"type":"expense","amount":80,"date":"2026-03-01T00:00:00Z","splits":[{"category_id":3,"amount":50},{"category_id":4,"amount":30}]}`
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTransaction(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing Category Without Splits", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		// The category is optional in the payload because a rule may set it.
+		mockService.On("AddTransaction", mock.Anything, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.CategoryID == 0
+		})).Return(apperrors.Validation("missing_category", "a category is required when no split lines are given and no rule sets one")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		c.Request = httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewBufferString(`{"type":"expense","amount":80,"date":"2026-03-01T00:00:00Z"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTransaction(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing_category")
+		mockService.AssertExpectations(t)
 	})
 
 	t.Run("Exceeds Budget", func(t *testing.T) {
@@ -236,6 +345,39 @@ func TestGetTransactionsPage(t *testing.T) {
 		assert.Contains(t, w.Body.String(), `"type":"expense"`)
 	})
 
+	t.Run("Tag Filters", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		transactionFilters := filters.TransactionFilters{Tags: []string{"vacation-2026", "reimbursable", "tax"}, TagMatch: filters.TagMatchAll}
+		mockService.On("GetTransactionsByUser", mock.Anything, uint(1), transactionFilters).Return([]models.Transaction{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/transactions?tag=Vacation-2026,reimbursable&tag=tax&tag_match=all", nil)
+
+		controller.GetTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Tag Match", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/transactions?tag=tax&tag_match=some", nil)
+
+		controller.GetTransactions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_tag_match")
+	})
+
 	t.Run("Invalid Page", func(t *testing.T) {
 		mockService := new(MockTransactionService)
 		controller := NewTransactionController(mockService)
@@ -258,7 +400,7 @@ func TestGetTransactionsPage(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Set("userID", uint(1))
-		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions?type=transfer", nil)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions?type=refund", nil)
 
 		controller.GetTransactionsPage(c)
 
@@ -325,3 +467,256 @@ func TestDeleteTransaction(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "transaction not found")
 	})
 }
+
+func TestCreateTransfer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		now := time.Now().UTC().Round(time.Second)
+		payload := map[string]interface{}{
+			"from_account_id": 1,
+			"to_account_id":   2,
+			"amount":          75.0,
+			"date":            now.Format(time.RFC3339),
+		}
+
+		mockService.On("CreateTransfer", mock.Anything, mock.MatchedBy(func(transfer *models.Transfer) bool {
+			return transfer.UserID == 1 &&
+				transfer.FromAccountID == 1 &&
+				transfer.ToAccountID == 2 &&
+				transfer.Amount == 75.0
+		})).Run(func(args mock.Arguments) {
+			transfer := args.Get(1).(*models.Transfer)
+			transfer.ID = 9
+		}).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		jsonBody, err := json.Marshal(payload)
+		assert.NoError(t, err)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/transfers", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTransfer(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":9`)
+	})
+
+	t.Run("Currency Mismatch", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("CreateTransfer", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+			Return(apperrors.Validation("transfer_currency_mismatch", "transfer accounts must use the same currency")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/transfers", bytes.NewBufferString(`{"from_account_id":1,"to_account_id":2,"amount":10,"date":"2026-03-01T00:00:00Z"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.CreateTransfer(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "transfer_currency_mismatch")
+	})
+}
+
+func TestDeleteTransfer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+
+	mockService.On("DeleteTransferForUser", mock.Anything, uint(1), uint(4)).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+	c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/transfers/4", nil)
+
+	controller.DeleteTransfer(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Transfer deleted")
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("UpdateTransactionForUser", mock.Anything, uint(1), mock.MatchedBy(func(t *models.Transaction) bool {
+			return t.ID == 6 &&
+				t.Amount == 45.0 &&
+				len(t.Tags) == 1 &&
+				t.Tags[0].Name == "reimbursable"
+		})).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "6"}}
+
+		body := `{"type":"expense","amount":45,"category_id":2,"date":"2026-03-01T00:00:00Z","tags":["reimbursable"]}`
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/transactions/6", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateTransaction(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"tags":["reimbursable"]`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("UpdateTransactionForUser", mock.Anything, uint(1), mock.AnythingOfType("*models.Transaction")).
+			Return(apperrors.NotFound("transaction_not_found", "transaction not found")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+		body := `{"type":"expense","amount":45,"category_id":2,"date":"2026-03-01T00:00:00Z"}`
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/transactions/99", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateTransaction(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetDuplicates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockTransactionService)
+	controller := NewTransactionController(mockService)
+
+	mockService.On("GetDuplicateGroupsByUser", mock.Anything, uint(1)).Return([]models.DuplicateGroup{{
+		Transactions: []models.Transaction{{ID: 3, UserID: 1}, {ID: 4, UserID: 1}},
+	}}, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions/duplicates", nil)
+
+	controller.GetDuplicates(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":[{"transactions":[{"id":3`)
+	mockService.AssertExpectations(t)
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("MergeDuplicatesForUser", mock.Anything, uint(1), uint(3), []uint{4, 5}).
+			Return(&models.Transaction{ID: 3, UserID: 1, ExternalID: "FIT-4"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/transactions/duplicates/merge", bytes.NewBufferString(`{"keep_id":3,"duplicate_ids":[4,5]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.MergeDuplicates(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"external_id":"FIT-4"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing Duplicates", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/transactions/duplicates/merge", bytes.NewBufferString(`{"keep_id":3,"duplicate_ids":[]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.MergeDuplicates(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "MergeDuplicatesForUser")
+	})
+}
+
+func TestSuggestCategories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("SuggestCategories", mock.Anything, uint(1), "REWE Markt", "").
+			Return([]models.CategorySuggestion{{CategoryID: 2, Confidence: 0.75}, {CategoryID: 5, Confidence: 0.25}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions/suggest-category?note=REWE+Markt", nil)
+
+		controller.SuggestCategories(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[{"category_id":2,"confidence":0.75},{"category_id":5,"confidence":0.25}]}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("No Suggestions", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		mockService.On("SuggestCategories", mock.Anything, uint(1), "", "Unknown Shop").
+			Return([]models.CategorySuggestion(nil), nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions/suggest-category?payee=Unknown+Shop", nil)
+
+		controller.SuggestCategories(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":[]}`, w.Body.String())
+	})
+
+	t.Run("Missing Note", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		controller := NewTransactionController(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/transactions/suggest-category?note=+", nil)
+
+		controller.SuggestCategories(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "missing_note")
+		mockService.AssertNotCalled(t, "SuggestCategories")
+	})
+}
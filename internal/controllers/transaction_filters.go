@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
@@ -13,8 +14,8 @@ func parseTransactionFilters(c *gin.Context) (filters.TransactionFilters, error)
 	transactionFilters := filters.TransactionFilters{}
 
 	if transactionType := c.Query("type"); transactionType != "" {
-		if transactionType != "income" && transactionType != "expense" {
-			return filters.TransactionFilters{}, apperrors.Validation("invalid_transaction_type", "type must be either income or expense")
+		if transactionType != "income" && transactionType != "expense" && transactionType != "transfer" {
+			return filters.TransactionFilters{}, apperrors.Validation("invalid_transaction_type", "type must be one of income, expense or transfer")
 		}
 		transactionFilters.Type = transactionType
 	}
@@ -29,6 +30,31 @@ func parseTransactionFilters(c *gin.Context) (filters.TransactionFilters, error)
 		transactionFilters.CategoryID = &parsedCategoryID
 	}
 
+	if rawAccountID := c.Query("account_id"); rawAccountID != "" {
+		accountID, err := strconv.ParseUint(rawAccountID, 10, 64)
+		if err != nil || accountID == 0 {
+			return filters.TransactionFilters{}, apperrors.Validation("invalid_account_id", "account_id must be a positive integer")
+		}
+
+		parsedAccountID := uint(accountID)
+		transactionFilters.AccountID = &parsedAccountID
+	}
+
+	for _, rawTags := range c.QueryArray("tag") {
+		for _, tag := range strings.Split(rawTags, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				transactionFilters.Tags = append(transactionFilters.Tags, tag)
+			}
+		}
+	}
+
+	if tagMatch := c.Query("tag_match"); tagMatch != "" {
+		if tagMatch != filters.TagMatchAny && tagMatch != filters.TagMatchAll {
+			return filters.TransactionFilters{}, apperrors.Validation("invalid_tag_match", "tag_match must be either any or all")
+		}
+		transactionFilters.TagMatch = tagMatch
+	}
+
 	if rawFrom := c.Query("from"); rawFrom != "" {
 		from, err := parseTransactionFilterTime(rawFrom, false)
 		if err != nil {
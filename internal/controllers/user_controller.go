@@ -2,44 +2,106 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
-	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/httpapi"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/observability"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
 type UserController struct {
 	userService  services.UserService
-	tokenManager auth.TokenManager
+	tokenService services.TokenService
+	mfaService   services.MFAService
+	emailService services.AccountEmailService
 }
 
 type registerRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 type loginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	Scope    string `json:"scope"` // Space-separated scopes; all when empty
+}
+
+type mfaLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	Scope    string `json:"scope"` // Space-separated scopes; all when empty
+}
+
+type beginTOTPRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type updateUserRequest struct {
+	Name            *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Email           *string `json:"email" binding:"omitempty,email"`
+	CurrentPassword string  `json:"current_password"` // needed to change the email
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type emailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type deleteUserRequest struct {
+	Password string `json:"password" binding:"required"`
 }
 
 type userResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID                  uint       `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	MFAEnabled          bool       `json:"mfa_enabled"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
-func NewUserController(userService services.UserService, tokenManager auth.TokenManager) *UserController {
-	return &UserController{userService: userService, tokenManager: tokenManager}
+func NewUserController(userService services.UserService, tokenService services.TokenService, mfaService services.MFAService, emailService services.AccountEmailService) *UserController {
+	return &UserController{userService: userService, tokenService: tokenService, mfaService: mfaService, emailService: emailService}
 }
 
 // Register handles user registration
 // @Summary Register a new user
-// @Description Create a user account and return a signed auth token.
+// @Description Create a user account and return a short-lived signed access token and a refresh token. A link to verify the email address is sent to it. Passwords that are too short, too easy to guess or known from data breaches are refused with 400 and the codes password_too_short, password_too_long, password_too_weak or password_breached.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -64,29 +126,39 @@ func (uc *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := uc.tokenManager.GenerateToken(createdUser)
+	tokens, err := uc.tokenService.IssueTokens(ctx, createdUser, "")
 	if err != nil {
-		httpapi.WriteError(c, apperrors.Internal("token_generation_failed", "failed to generate token", err))
+		httpapi.WriteError(c, err)
 		return
 	}
 
+	// The account works without a verified email, and the link can be
+	// requested again, so a failed email does not fail the registration.
+	if err := uc.emailService.RequestEmailVerification(ctx, createdUser.Email); err != nil {
+		observability.LoggerFromGinContext(c).Warn("failed to send verification email", "user_id", createdUser.ID, "error", err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered",
-		"token":   token,
-		"user":    newUserResponse(createdUser),
+		"message":       "User registered",
+		"token":         tokens.AccessToken,
+		"expires_at":    tokens.ExpiresAt,
+		"refresh_token": tokens.RefreshToken,
+		"user":          newUserResponse(createdUser),
 	})
 }
 
 // Login handles user authentication
 // @Summary Log in a user
-// @Description Authenticate a user and return a signed auth token.
+// @Description Authenticate a user and return a short-lived signed access token and a refresh token. The tokens carry the scopes listed in scope, separated by spaces, or every scope when it is left out; refreshed tokens keep them. When the user has two-factor authentication enabled, respond with 202 and an MFA token instead, to be sent with a code to /api/v1/login/mfa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param payload body loginRequest true "Login payload"
 // @Success 200 {object} authResponse
+// @Success 202 {object} mfaChallengeResponse
 // @Failure 400 {object} httpapi.ErrorResponse
 // @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 429 {object} httpapi.ErrorResponse
 // @Failure 500 {object} httpapi.ErrorResponse
 // @Router /api/v1/login [post]
 func (uc *UserController) Login(c *gin.Context) {
@@ -98,29 +170,645 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := uc.userService.AuthenticateUser(ctx, req.Email, req.Password)
+	user, err := uc.userService.AuthenticateUser(ctx, req.Email, req.Password, c.ClientIP())
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	if user.MFAEnabled() {
+		writeMFAChallenge(c, uc.mfaService, user)
+		return
+	}
+
+	writeLogin(c, uc.tokenService, user, req.Scope)
+}
+
+// VerifyMFALogin completes a login that needs a second factor
+// @Summary Complete a two-factor login
+// @Description Exchange the MFA token from /api/v1/login and a code from the authenticator app, or one of the recovery codes, for an access token and a refresh token. Each code works once. The tokens carry the scopes listed in scope, as for /api/v1/login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body mfaLoginRequest true "MFA token and code"
+// @Success 200 {object} authResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 429 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/login/mfa [post]
+func (uc *UserController) VerifyMFALogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req mfaLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	user, err := uc.mfaService.VerifyMFAChallenge(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	writeLogin(c, uc.tokenService, user, req.Scope)
+}
+
+// writeMFAChallenge asks a user who passed the first login step for their
+// second factor.
+func writeMFAChallenge(c *gin.Context, mfaService services.MFAService, user *models.User) {
+	challenge, err := mfaService.StartMFAChallenge(c.Request.Context(), user)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, mfaChallengeResponse{
+		Message:     "Authentication code required",
+		MFARequired: true,
+		MFAToken:    challenge.Token,
+		ExpiresAt:   challenge.ExpiresAt,
+	})
+}
+
+// writeLogin starts a session limited to scope for a user who passed every
+// login check, however they logged in.
+func writeLogin(c *gin.Context, tokenService services.TokenService, user *models.User, scope string) {
+	tokens, err := tokenService.IssueTokens(c.Request.Context(), user, scope)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"expires_at":    tokens.ExpiresAt,
+		"refresh_token": tokens.RefreshToken,
+		"user":          newUserResponse(user),
+	})
+}
+
+// RefreshToken exchanges a refresh token for a new token pair
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; presenting one that was already used revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body refreshTokenRequest true "Refresh token payload"
+// @Success 200 {object} tokenResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/token/refresh [post]
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req refreshTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	tokens, err := uc.tokenService.RefreshTokens(ctx, req.RefreshToken)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"expires_at":    tokens.ExpiresAt,
+		"refresh_token": tokens.RefreshToken,
+	})
+}
+
+// Logout revokes the current access token
+// @Summary Log out
+// @Description Revoke the access token used for this request. When the refresh token from the same login is sent too, it and every token rotated from it are revoked as well.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body logoutRequest false "Refresh token to revoke"
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/logout [post]
+func (uc *UserController) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	var req logoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+			return
+		}
+	}
+
+	if err := uc.tokenService.Logout(ctx, claims, req.RefreshToken); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAll revokes every token of the user
+// @Summary Log out everywhere
+// @Description Revoke every access and refresh token issued to the authenticated user so far, including the one used for this request.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} messageResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/logout/all [post]
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+
+	if err := uc.tokenService.LogoutAll(ctx, claims.UserID); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	// Tokens issued in the same second as the watermark stay valid, so
+	// revoke the current one explicitly.
+	if err := uc.tokenService.Logout(ctx, claims, ""); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out everywhere"})
+}
+
+// RequestEmailVerification sends a new email verification link
+// @Summary Request an email verification link
+// @Description Send a link to verify the email address to it. The response is the same whether or not the address belongs to an account that still needs verifying.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body emailRequest true "Email address"
+// @Success 202 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/email/verification [post]
+func (uc *UserController) RequestEmailVerification(c *gin.Context) {
+	var req emailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	if err := uc.emailService.RequestEmailVerification(c.Request.Context(), req.Email); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address needs verifying, a link has been sent to it"})
+}
+
+// VerifyEmail completes email verification
+// @Summary Verify an email address
+// @Description Mark the email address as verified with the token from the emailed link. Each link works once, expires after a day and stops working when the email address changes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body verifyEmailRequest true "Token from the link"
+// @Success 200 {object} userResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/email/verify [post]
+func (uc *UserController) VerifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	user, err := uc.emailService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// ForgotPassword sends a password reset link
+// @Summary Request a password reset link
+// @Description Send a link to reset the password to the email address. The response is the same whether or not the address belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body emailRequest true "Email address"
+// @Success 202 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/password/forgot [post]
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var req emailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	if err := uc.emailService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the address belongs to an account, a link has been sent to it"})
+}
+
+// ResetPassword sets a new password with a reset link
+// @Summary Reset the password
+// @Description Set a new password with the token from the emailed link. Each link works once and expires after an hour. Every access and refresh token issued so far is revoked, logging the user out on all devices. The new password must meet the same policy as at registration; a refused password leaves the link usable.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body resetPasswordRequest true "Token from the link and the new password"
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/password/reset [post]
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req resetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	user, err := uc.emailService.ResetPassword(ctx, req.Token, req.NewPassword)
 	if err != nil {
 		httpapi.WriteError(c, err)
 		return
 	}
 
-	token, err := uc.tokenManager.GenerateToken(user)
+	if err := uc.tokenService.LogoutAll(ctx, user.ID); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset"})
+}
+
+// GetCurrentUser returns the user's profile
+// @Summary Get the current user
+// @Description Return the authenticated user's profile, including when the account will be deleted if its deletion is scheduled.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} userResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Router /api/v1/me [get]
+func (uc *UserController) GetCurrentUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := uc.userService.GetUser(ctx, userID)
 	if err != nil {
-		httpapi.WriteError(c, apperrors.Internal("token_generation_failed", "failed to generate token", err))
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// UpdateCurrentUser changes the user's name or email
+// @Summary Update the current user
+// @Description Change the authenticated user's name, email or both. Fields that are left out keep their value. Changing the email needs the current password and the new address has to be verified again. Emails must stay unique across users.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body updateUserRequest true "Profile changes"
+// @Success 200 {object} userResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me [patch]
+func (uc *UserController) UpdateCurrentUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req updateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	user, err := uc.userService.UpdateUser(ctx, userID, req.Name, req.Email, req.CurrentPassword)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// ChangePassword replaces the user's password
+// @Summary Change the password
+// @Description Replace the authenticated user's password after confirming the current one. Every access and refresh token issued so far is revoked, logging the user out on all devices, and a new token pair is returned for the current session. The new password must meet the same policy as at registration.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body changePasswordRequest true "Current and new password"
+// @Success 200 {object} passwordChangeResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me/password [post]
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	claims, ok := currentClaims(c)
+	if !ok {
+		return
+	}
+	userID := claims.UserID
+
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	if err := uc.userService.ChangePassword(ctx, userID, req.CurrentPassword, req.NewPassword); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	if err := uc.tokenService.LogoutAll(ctx, userID); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+	// LogoutAll only goes down to the second, so the token that made this
+	// request is revoked by its ID as well.
+	if err := uc.tokenService.Logout(ctx, claims, ""); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	user, err := uc.userService.GetUser(ctx, userID)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	// The new session keeps the scopes of the one it replaces.
+	tokens, err := uc.tokenService.IssueTokens(ctx, user, claims.Scope)
+	if err != nil {
+		httpapi.WriteError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
-		"user":    newUserResponse(user),
+		"message":       "Password changed",
+		"token":         tokens.AccessToken,
+		"expires_at":    tokens.ExpiresAt,
+		"refresh_token": tokens.RefreshToken,
 	})
 }
 
+// DeleteCurrentUser erases the user's account and data
+// @Summary Delete the current account
+// @Description Erase the authenticated user's account together with their transactions, transfers, budgets, accounts, tags, rules and classifier data in a single database transaction. The current password must be confirmed. Categories are shared between users and are kept. When the server has a deletion grace period the account is only scheduled for deletion, responding with 202 and the time it will be erased; until then the deletion can be cancelled.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body deleteUserRequest true "Password confirmation"
+// @Success 200 {object} userDeletionResponse
+// @Success 202 {object} userDeletionResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me [delete]
+func (uc *UserController) DeleteCurrentUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req deleteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	scheduledAt, err := uc.userService.DeleteUser(ctx, userID, req.Password)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	if scheduledAt != nil {
+		c.JSON(http.StatusAccepted, userDeletionResponse{Message: "Account deletion scheduled", DeletionScheduledAt: scheduledAt})
+		return
+	}
+
+	// Revocation lookups are cached, so without a new watermark the erased
+	// user's access tokens would keep working here until the cache expires.
+	// The account is gone either way, so a failure is not reported.
+	_ = uc.tokenService.LogoutAll(ctx, userID)
+
+	c.JSON(http.StatusOK, userDeletionResponse{Message: "Account deleted"})
+}
+
+// BeginTOTPEnrolment starts setting up an authenticator app
+// @Summary Start TOTP enrolment
+// @Description Create a new TOTP secret for the authenticated user after confirming the current password and return it with an otpauth:// provisioning URI to show as a QR code. Two-factor authentication is only enabled once a code from the app is confirmed; starting again replaces an unconfirmed secret.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body beginTOTPRequest true "Current password"
+// @Success 200 {object} totpEnrolmentResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me/mfa/totp [post]
+func (uc *UserController) BeginTOTPEnrolment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req beginTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	enrolment, err := uc.mfaService.BeginTOTPEnrolment(ctx, userID, req.CurrentPassword)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, totpEnrolmentResponse{Secret: enrolment.Secret, ProvisioningURI: enrolment.ProvisioningURI})
+}
+
+// ConfirmTOTPEnrolment enables two-factor authentication
+// @Summary Confirm TOTP enrolment
+// @Description Enable two-factor authentication with a code from the newly set up authenticator app. The response lists one-time recovery codes for when the app is lost; they are only stored hashed and are not shown again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body confirmTOTPRequest true "Code from the authenticator app"
+// @Success 200 {object} recoveryCodesResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me/mfa/totp/confirm [post]
+func (uc *UserController) ConfirmTOTPEnrolment(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req confirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	codes, err := uc.mfaService.ConfirmTOTPEnrolment(ctx, userID, req.Code)
+	if err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{Message: "Two-factor authentication enabled", RecoveryCodes: codes})
+}
+
+// DisableTOTP turns two-factor authentication off
+// @Summary Disable TOTP
+// @Description Turn two-factor authentication off after confirming the password and a code from the authenticator app or a recovery code. The remaining recovery codes are deleted.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body disableTOTPRequest true "Password and code"
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me/mfa/totp [delete]
+func (uc *UserController) DisableTOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req disableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpapi.WriteError(c, apperrors.Validation("invalid_request", "invalid request payload"))
+		return
+	}
+
+	if err := uc.mfaService.DisableTOTP(ctx, userID, req.Password, req.Code); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// CancelCurrentUserDeletion keeps an account scheduled for deletion
+// @Summary Cancel account deletion
+// @Description Cancel the scheduled deletion of the authenticated user's account during the grace period.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} messageResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Router /api/v1/me/deletion [delete]
+func (uc *UserController) CancelCurrentUserDeletion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := uc.userService.CancelUserDeletion(ctx, userID); err != nil {
+		httpapi.WriteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
+
 func newUserResponse(user *models.User) userResponse {
 	return userResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
+		ID:                  user.ID,
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified(),
+		MFAEnabled:          user.MFAEnabled(),
+		DeletionScheduledAt: user.DeletionScheduledAt,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
@@ -16,19 +17,106 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-type MockTokenManager struct {
+// MockTokenService implements services.TokenService
+type MockTokenService struct {
 	mock.Mock
 }
 
-func (m *MockTokenManager) GenerateToken(user *models.User) (string, error) {
-	args := m.Called(user)
-	return args.String(0), args.Error(1)
+func (m *MockTokenService) IssueTokens(ctx context.Context, user *models.User, scope string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, user, scope)
+	if args.Get(0) != nil {
+		return args.Get(0).(*auth.TokenPair), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenService) RefreshTokens(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) != nil {
+		return args.Get(0).(*auth.TokenPair), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	args := m.Called(ctx, claims, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) LogoutAll(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// MockAccountEmailService implements services.AccountEmailService
+type MockAccountEmailService struct {
+	mock.Mock
+}
+
+func (m *MockAccountEmailService) RequestEmailVerification(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountEmailService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAccountEmailService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountEmailService) ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error) {
+	args := m.Called(ctx, token, newPassword)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// MockMFAService implements services.MFAService
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) BeginTOTPEnrolment(ctx context.Context, userID uint, password string) (*auth.TOTPEnrolment, error) {
+	args := m.Called(ctx, userID, password)
+	if args.Get(0) != nil {
+		return args.Get(0).(*auth.TOTPEnrolment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMFAService) ConfirmTOTPEnrolment(ctx context.Context, userID uint, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	if args.Get(0) != nil {
+		return args.Get(0).([]string), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockMFAService) DisableTOTP(ctx context.Context, userID uint, password, code string) error {
+	args := m.Called(ctx, userID, password, code)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) StartMFAChallenge(ctx context.Context, user *models.User) (*auth.MFAChallenge, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) != nil {
+		return args.Get(0).(*auth.MFAChallenge), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *MockTokenManager) ParseToken(token string) (*auth.Claims, error) {
-	args := m.Called(token)
+func (m *MockMFAService) VerifyMFAChallenge(ctx context.Context, mfaToken, code string) (*models.User, error) {
+	args := m.Called(ctx, mfaToken, code)
 	if args.Get(0) != nil {
-		return args.Get(0).(*auth.Claims), args.Error(1)
+		return args.Get(0).(*models.User), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
@@ -46,21 +134,56 @@ func (m *MockUserService) RegisterUser(ctx context.Context, name, email, passwor
 	return nil, args.Error(1)
 }
 
-func (m *MockUserService) AuthenticateUser(ctx context.Context, email, password string) (*models.User, error) {
-	args := m.Called(ctx, email, password)
+func (m *MockUserService) AuthenticateUser(ctx context.Context, email, password, clientAddress string) (*models.User, error) {
+	args := m.Called(ctx, email, password, clientAddress)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserService) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserService) UpdateUser(ctx context.Context, userID uint, name, email *string, currentPassword string) (*models.User, error) {
+	args := m.Called(ctx, userID, name, email, currentPassword)
 	if args.Get(0) != nil {
 		return args.Get(0).(*models.User), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *MockUserService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserService) DeleteUser(ctx context.Context, userID uint, password string) (*time.Time, error) {
+	args := m.Called(ctx, userID, password)
+	if args.Get(0) != nil {
+		return args.Get(0).(*time.Time), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserService) CancelUserDeletion(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func TestRegister(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("Success", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), mockEmailService)
 
 		input := map[string]string{
 			"name":     "Alice",
@@ -75,7 +198,10 @@ func TestRegister(t *testing.T) {
 
 		mockService.On("RegisterUser", mock.Anything, "Alice", "alice@example.com", "secure123").
 			Return(expected, nil).Once()
-		mockTokenManager.On("GenerateToken", expected).Return("token-123", nil).Once()
+		mockTokenService.On("IssueTokens", mock.Anything, expected, "").
+			Return(&auth.TokenPair{AccessToken: "token-123", RefreshToken: "refresh-123"}, nil).Once()
+		mockEmailService.On("RequestEmailVerification", mock.Anything, "alice@example.com").
+			Return(apperrors.Internal("email_verification_failed", "failed to send verification email", nil)).Once()
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -91,13 +217,15 @@ func TestRegister(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "User registered")
 		assert.Contains(t, w.Body.String(), `"email":"alice@example.com"`)
 		assert.Contains(t, w.Body.String(), `"token":"token-123"`)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"refresh-123"`)
 		assert.NotContains(t, w.Body.String(), "Password")
+		mockEmailService.AssertExpectations(t)
 	})
 
 	t.Run("Invalid JSON", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -114,8 +242,8 @@ func TestRegister(t *testing.T) {
 
 	t.Run("Service Error", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
 
 		input := map[string]string{
 			"name":     "Bob",
@@ -147,20 +275,22 @@ func TestLogin(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
 
 		email := "alice@example.com"
 		password := "secure123"
 		expected := &models.User{ID: 1, Name: "Alice", Email: email}
 
-		mockService.On("AuthenticateUser", mock.Anything, email, password).
+		mockService.On("AuthenticateUser", mock.Anything, email, password, "192.0.2.1").
 			Return(expected, nil).Once()
-		mockTokenManager.On("GenerateToken", expected).Return("token-abc", nil).Once()
+		mockTokenService.On("IssueTokens", mock.Anything, expected, "transactions:read").
+			Return(&auth.TokenPair{AccessToken: "token-abc", RefreshToken: "refresh-abc"}, nil).Once()
 
 		payload := map[string]string{
 			"email":    email,
 			"password": password,
+			"scope":    "transactions:read",
 		}
 		jsonBody, err := json.Marshal(payload)
 		assert.NoError(t, err)
@@ -177,13 +307,14 @@ func TestLogin(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Login successful")
 		assert.Contains(t, w.Body.String(), `"email":"alice@example.com"`)
 		assert.Contains(t, w.Body.String(), `"token":"token-abc"`)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"refresh-abc"`)
 		assert.NotContains(t, w.Body.String(), "Password")
 	})
 
 	t.Run("Invalid JSON", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -200,13 +331,13 @@ func TestLogin(t *testing.T) {
 
 	t.Run("Authentication Failure", func(t *testing.T) {
 		mockService := new(MockUserService)
-		mockTokenManager := new(MockTokenManager)
-		controller := NewUserController(mockService, mockTokenManager)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
 
 		email := "bob@example.com"
 		password := "wrongpass"
 
-		mockService.On("AuthenticateUser", mock.Anything, email, password).
+		mockService.On("AuthenticateUser", mock.Anything, email, password, "192.0.2.1").
 			Return((*models.User)(nil), apperrors.Unauthorized("invalid_credentials", "invalid credentials")).Once()
 
 		body := map[string]string{"email": email, "password": password}
@@ -225,4 +356,700 @@ func TestLogin(t *testing.T) {
 		assert.Contains(t, w.Body.String(), `"code":"invalid_credentials"`)
 		assert.Contains(t, w.Body.String(), "invalid credentials")
 	})
+
+	t.Run("Locked Out", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("AuthenticateUser", mock.Anything, "bob@example.com", "guess", "192.0.2.1").
+			Return((*models.User)(nil), apperrors.TooManyRequests("too_many_login_attempts", "too many failed login attempts; try again in 2 minutes", 2*time.Minute)).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"bob@example.com","password":"guess"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.Login(c)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "120", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), `"code":"too_many_login_attempts"`)
+		assert.Contains(t, w.Body.String(), `"retry_after":120`)
+	})
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	enabledAt := time.Now()
+	user := &models.User{ID: 1, Email: "alice@example.com", TOTPEnabledAt: &enabledAt}
+
+	t.Run("Password returns an MFA challenge", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokenService := new(MockTokenService)
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(mockService, mockTokenService, mockMFAService, new(MockAccountEmailService))
+
+		mockService.On("AuthenticateUser", mock.Anything, "alice@example.com", "secure123", "192.0.2.1").Return(user, nil).Once()
+		mockMFAService.On("StartMFAChallenge", mock.Anything, user).
+			Return(&auth.MFAChallenge{Token: "mfa-token", ExpiresAt: time.Now().Add(auth.MFATokenTTL)}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"alice@example.com","password":"secure123"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.Login(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"mfa_required":true`)
+		assert.Contains(t, w.Body.String(), `"mfa_token":"mfa-token"`)
+		assert.NotContains(t, w.Body.String(), "refresh_token")
+		mockTokenService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Code completes the login", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(new(MockUserService), mockTokenService, mockMFAService, new(MockAccountEmailService))
+
+		mockMFAService.On("VerifyMFAChallenge", mock.Anything, "mfa-token", "123456").Return(user, nil).Once()
+		mockTokenService.On("IssueTokens", mock.Anything, user, "reports:read").
+			Return(&auth.TokenPair{AccessToken: "token-abc", RefreshToken: "refresh-abc"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/login/mfa", bytes.NewBufferString(`{"mfa_token":"mfa-token","code":"123456","scope":"reports:read"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.VerifyMFALogin(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"token-abc"`)
+		assert.Contains(t, w.Body.String(), `"mfa_enabled":true`)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(new(MockUserService), mockTokenService, mockMFAService, new(MockAccountEmailService))
+
+		mockMFAService.On("VerifyMFAChallenge", mock.Anything, "mfa-token", "000000").
+			Return(nil, apperrors.Unauthorized("invalid_mfa_code", "invalid authentication code")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/login/mfa", bytes.NewBufferString(`{"mfa_token":"mfa-token","code":"000000"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.VerifyMFALogin(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_mfa_code"`)
+		mockTokenService.AssertNotCalled(t, "IssueTokens", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTOTPEnrolment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Begin", func(t *testing.T) {
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), mockMFAService, new(MockAccountEmailService))
+
+		mockMFAService.On("BeginTOTPEnrolment", mock.Anything, uint(1), "mypassword").
+			Return(&auth.TOTPEnrolment{Secret: "SECRET", ProvisioningURI: "otpauth://totp/x"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/me/mfa/totp", bytes.NewBufferString(`{"current_password":"mypassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.BeginTOTPEnrolment(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"secret":"SECRET","provisioning_uri":"otpauth://totp/x"}`, w.Body.String())
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), mockMFAService, new(MockAccountEmailService))
+
+		mockMFAService.On("ConfirmTOTPEnrolment", mock.Anything, uint(1), "123456").Return([]string{"abcde-fghij"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/me/mfa/totp/confirm", bytes.NewBufferString(`{"code":"123456"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ConfirmTOTPEnrolment(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"recovery_codes":["abcde-fghij"]`)
+	})
+
+	t.Run("Disable with a wrong password", func(t *testing.T) {
+		mockMFAService := new(MockMFAService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), mockMFAService, new(MockAccountEmailService))
+
+		mockMFAService.On("DisableTOTP", mock.Anything, uint(1), "wrong", "123456").
+			Return(apperrors.Forbidden("invalid_password", "password is incorrect")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me/mfa/totp", bytes.NewBufferString(`{"password":"wrong","code":"123456"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.DisableTOTP(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockTokenService.On("RefreshTokens", mock.Anything, "refresh-abc").
+			Return(&auth.TokenPair{AccessToken: "token-def", RefreshToken: "refresh-def"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBufferString(`{"refresh_token":"refresh-abc"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.RefreshToken(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"token-def"`)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"refresh-def"`)
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("Missing Token", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBufferString(`{}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.RefreshToken(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockTokenService.AssertNotCalled(t, "RefreshTokens")
+	})
+
+	t.Run("Reused Token", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockTokenService.On("RefreshTokens", mock.Anything, "refresh-abc").
+			Return(nil, apperrors.Unauthorized("refresh_token_reused", "refresh token was already used; log in again")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBufferString(`{"refresh_token":"refresh-abc"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.RefreshToken(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"refresh_token_reused"`)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	claims := &auth.Claims{UserID: 1, ID: "jti-1"}
+
+	t.Run("Revoke the token and refresh token", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockTokenService.On("Logout", mock.Anything, claims, "refresh-abc").Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", claims)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/logout", bytes.NewBufferString(`{"refresh_token":"refresh-abc"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Logged out")
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("Without a body", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockTokenService.On("Logout", mock.Anything, claims, "").Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", claims)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/logout", nil)
+
+		controller.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/logout", nil)
+
+		controller.Logout(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockTokenService.AssertNotCalled(t, "Logout")
+	})
+}
+
+func TestLogoutAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockTokenService := new(MockTokenService)
+	controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+	claims := &auth.Claims{UserID: 1, ID: "jti-1"}
+
+	mockTokenService.On("LogoutAll", mock.Anything, uint(1)).Return(nil).Once()
+	mockTokenService.On("Logout", mock.Anything, claims, "").Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("tokenClaims", claims)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/logout/all", nil)
+
+	controller.LogoutAll(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Logged out everywhere")
+	mockTokenService.AssertExpectations(t)
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockUserService)
+	controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+	mockService.On("GetUser", mock.Anything, uint(1)).Return(&models.User{ID: 1, Name: "John Doe", Email: "john@example.com", Password: "hash"}, nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", uint(1))
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/me", nil)
+
+	controller.GetCurrentUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"name":"John Doe","email":"john@example.com","email_verified":false,"mfa_enabled":false}`, w.Body.String())
+}
+
+func TestUpdateCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("UpdateUser", mock.Anything, uint(1), (*string)(nil), mock.MatchedBy(func(email *string) bool {
+			return email != nil && *email == "new@example.com"
+		}), "mypassword").Return(&models.User{ID: 1, Name: "John Doe", Email: "new@example.com"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/me", bytes.NewBufferString(`{"email":"new@example.com","current_password":"mypassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateCurrentUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"email":"new@example.com"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Email", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/me", bytes.NewBufferString(`{"email":"not-an-email"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateCurrentUser(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateUser")
+	})
+
+	t.Run("Email Taken", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("UpdateUser", mock.Anything, uint(1), mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, apperrors.Conflict("email_already_registered", "email already registered")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/v1/me", bytes.NewBufferString(`{"email":"jane@example.com"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.UpdateCurrentUser(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "email_already_registered")
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+		user := &models.User{ID: 1, Email: "john@example.com"}
+		claims := &auth.Claims{UserID: 1, ID: "current-token", Scope: auth.ScopeReportsRead}
+
+		mockService.On("ChangePassword", mock.Anything, uint(1), "oldpassword", "newpassword").Return(nil).Once()
+		mockTokenService.On("LogoutAll", mock.Anything, uint(1)).Return(nil).Once()
+		mockTokenService.On("Logout", mock.Anything, claims, "").Return(nil).Once()
+		mockService.On("GetUser", mock.Anything, uint(1)).Return(user, nil).Once()
+		mockTokenService.On("IssueTokens", mock.Anything, user, auth.ScopeReportsRead).
+			Return(&auth.TokenPair{AccessToken: "token-new", RefreshToken: "refresh-new"}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", claims)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/me/password", bytes.NewBufferString(`{"current_password":"oldpassword","new_password":"newpassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ChangePassword(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Password changed")
+		assert.Contains(t, w.Body.String(), `"token":"token-new"`)
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("New Password Too Short", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("ChangePassword", mock.Anything, uint(1), "oldpassword", "short").
+			Return(apperrors.Validation("password_too_short", "password must be at least 8 characters long")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: auth.ScopeReportsRead})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/me/password", bytes.NewBufferString(`{"current_password":"oldpassword","new_password":"short"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "password_too_short")
+		mockTokenService.AssertNotCalled(t, "LogoutAll")
+	})
+
+	t.Run("Wrong Current Password", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("ChangePassword", mock.Anything, uint(1), "guess", "newpassword").
+			Return(apperrors.Forbidden("invalid_password", "password is incorrect")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: auth.ScopeReportsRead})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/me/password", bytes.NewBufferString(`{"current_password":"guess","new_password":"newpassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ChangePassword(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeleteCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Deleted", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("DeleteUser", mock.Anything, uint(1), "mypassword").Return(nil, nil).Once()
+		mockTokenService.On("LogoutAll", mock.Anything, uint(1)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me", bytes.NewBufferString(`{"password":"mypassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.DeleteCurrentUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Account deleted")
+		assert.NotContains(t, w.Body.String(), "deletion_scheduled_at")
+		mockService.AssertExpectations(t)
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("Scheduled", func(t *testing.T) {
+		mockService := new(MockUserService)
+		mockTokenService := new(MockTokenService)
+		controller := NewUserController(mockService, mockTokenService, new(MockMFAService), new(MockAccountEmailService))
+		scheduledAt := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+
+		mockService.On("DeleteUser", mock.Anything, uint(1), "mypassword").Return(&scheduledAt, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me", bytes.NewBufferString(`{"password":"mypassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.DeleteCurrentUser(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"deletion_scheduled_at":"2026-04-01T12:00:00Z"`)
+		mockTokenService.AssertNotCalled(t, "LogoutAll", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Missing Password", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me", bytes.NewBufferString(`{}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.DeleteCurrentUser(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "DeleteUser")
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("DeleteUser", mock.Anything, uint(1), "guess").
+			Return(nil, apperrors.Forbidden("invalid_password", "password is incorrect")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me", bytes.NewBufferString(`{"password":"guess"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.DeleteCurrentUser(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_password")
+	})
+}
+
+func TestCancelCurrentUserDeletion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("CancelUserDeletion", mock.Anything, uint(1)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me/deletion", nil)
+
+		controller.CancelCurrentUserDeletion(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Account deletion cancelled")
+	})
+
+	t.Run("Not Scheduled", func(t *testing.T) {
+		mockService := new(MockUserService)
+		controller := NewUserController(mockService, new(MockTokenService), new(MockMFAService), new(MockAccountEmailService))
+
+		mockService.On("CancelUserDeletion", mock.Anything, uint(1)).
+			Return(apperrors.Conflict("user_deletion_not_scheduled", "account deletion is not scheduled")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", uint(1))
+		c.Request = httptest.NewRequest(http.MethodDelete, "/api/v1/me/deletion", nil)
+
+		controller.CancelCurrentUserDeletion(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestRequestEmailVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Accepted", func(t *testing.T) {
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), new(MockMFAService), mockEmailService)
+
+		mockEmailService.On("RequestEmailVerification", mock.Anything, "alice@example.com").Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/email/verification", bytes.NewBufferString(`{"email":"alice@example.com"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockEmailService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Email", func(t *testing.T) {
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), new(MockMFAService), mockEmailService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/email/verification", bytes.NewBufferString(`{"email":"alice"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.RequestEmailVerification(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockEmailService.AssertNotCalled(t, "RequestEmailVerification")
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), new(MockMFAService), mockEmailService)
+		verifiedAt := time.Now()
+
+		mockEmailService.On("VerifyEmail", mock.Anything, "verify-token").
+			Return(&models.User{ID: 1, Email: "alice@example.com", EmailVerifiedAt: &verifiedAt}, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/email/verify", bytes.NewBufferString(`{"token":"verify-token"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"email_verified":true`)
+	})
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), new(MockTokenService), new(MockMFAService), mockEmailService)
+
+		mockEmailService.On("VerifyEmail", mock.Anything, "used-token").
+			Return(nil, apperrors.Unauthorized("invalid_verification_token", "invalid, expired or already used verification link")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/email/verify", bytes.NewBufferString(`{"token":"used-token"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.VerifyEmail(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_verification_token")
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockEmailService := new(MockAccountEmailService)
+	controller := NewUserController(new(MockUserService), new(MockTokenService), new(MockMFAService), mockEmailService)
+
+	mockEmailService.On("RequestPasswordReset", mock.Anything, "nobody@example.com").Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/password/forgot", bytes.NewBufferString(`{"email":"nobody@example.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	controller.ForgotPassword(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockEmailService.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), mockEmailService)
+
+		mockEmailService.On("ResetPassword", mock.Anything, "reset-token", "newpassword").
+			Return(&models.User{ID: 1}, nil).Once()
+		mockTokenService.On("LogoutAll", mock.Anything, uint(1)).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/password/reset", bytes.NewBufferString(`{"token":"reset-token","new_password":"newpassword"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ResetPassword(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Password reset")
+		mockTokenService.AssertExpectations(t)
+	})
+
+	t.Run("Weak Password", func(t *testing.T) {
+		mockTokenService := new(MockTokenService)
+		mockEmailService := new(MockAccountEmailService)
+		controller := NewUserController(new(MockUserService), mockTokenService, new(MockMFAService), mockEmailService)
+
+		mockEmailService.On("ResetPassword", mock.Anything, "reset-token", "short").
+			Return(nil, apperrors.Validation("password_too_weak", "password is too easy to guess")).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/password/reset", bytes.NewBufferString(`{"token":"reset-token","new_password":"short"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		controller.ResetPassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "password_too_weak")
+		mockTokenService.AssertNotCalled(t, "LogoutAll")
+	})
 }
@@ -142,6 +142,572 @@ var migrations = []migration{
 			return executeStatements(db, statements, err)
 		},
 	},
+	{
+		version: "0005_create_accounts",
+		name:    "create accounts table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS accounts (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(100) NOT NULL,
+						"type" VARCHAR(20) NOT NULL,
+						currency VARCHAR(3) NOT NULL,
+						opening_balance DOUBLE PRECISION NOT NULL DEFAULT 0,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS accounts (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						name TEXT NOT NULL,
+						"type" TEXT NOT NULL,
+						currency TEXT NOT NULL,
+						opening_balance REAL NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0006_add_transaction_account",
+		name:    "add account reference to transactions",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account_id BIGINT`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions (account_id)`,
+				},
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN account_id INTEGER`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions (account_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0007_create_transfers",
+		name:    "create transfers table and link transfer legs",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS transfers (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						from_account_id BIGINT NOT NULL,
+						to_account_id BIGINT NOT NULL,
+						amount DOUBLE PRECISION NOT NULL,
+						date TIMESTAMPTZ NOT NULL,
+						note VARCHAR(255),
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transfers_user_id ON transfers (user_id)`,
+					`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transfer_id BIGINT`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions (transfer_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS transfers (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						from_account_id INTEGER NOT NULL,
+						to_account_id INTEGER NOT NULL,
+						amount REAL NOT NULL,
+						date DATETIME NOT NULL,
+						note TEXT,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transfers_user_id ON transfers (user_id)`,
+					`ALTER TABLE transactions ADD COLUMN transfer_id INTEGER`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions (transfer_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0008_create_transaction_splits",
+		name:    "create transaction splits table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS transaction_splits (
+						id BIGSERIAL PRIMARY KEY,
+						transaction_id BIGINT NOT NULL,
+						category_id BIGINT NOT NULL,
+						amount DOUBLE PRECISION NOT NULL,
+						note VARCHAR(255),
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits (transaction_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_splits_category_id ON transaction_splits (category_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS transaction_splits (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						transaction_id INTEGER NOT NULL,
+						category_id INTEGER NOT NULL,
+						amount REAL NOT NULL,
+						note TEXT,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits (transaction_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_splits_category_id ON transaction_splits (category_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0009_create_tags",
+		name:    "create tags and transaction tags tables",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS tags (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(50) NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, name)`,
+					`CREATE TABLE IF NOT EXISTS transaction_tags (
+						transaction_id BIGINT NOT NULL,
+						tag_id BIGINT NOT NULL,
+						PRIMARY KEY (transaction_id, tag_id)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag_id ON transaction_tags (tag_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS tags (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						name TEXT NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, name)`,
+					`CREATE TABLE IF NOT EXISTS transaction_tags (
+						transaction_id INTEGER NOT NULL,
+						tag_id INTEGER NOT NULL,
+						PRIMARY KEY (transaction_id, tag_id)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag_id ON transaction_tags (tag_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0010_add_transaction_external_id",
+		name:    "add external id to transactions",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id VARCHAR(255) NOT NULL DEFAULT ''`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_user_external_id ON transactions (user_id, external_id)`,
+				},
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN external_id TEXT NOT NULL DEFAULT ''`,
+					`CREATE INDEX IF NOT EXISTS idx_transactions_user_external_id ON transactions (user_id, external_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0011_add_transaction_payee",
+		name:    "add payee to transactions",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payee VARCHAR(255) NOT NULL DEFAULT ''`,
+				},
+				[]string{
+					`ALTER TABLE transactions ADD COLUMN payee TEXT NOT NULL DEFAULT ''`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0012_create_rules",
+		name:    "create rules table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS rules (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(100) NOT NULL,
+						priority INTEGER NOT NULL DEFAULT 0,
+						note_contains VARCHAR(255) NOT NULL DEFAULT '',
+						note_pattern VARCHAR(255) NOT NULL DEFAULT '',
+						payee_contains VARCHAR(255) NOT NULL DEFAULT '',
+						payee_pattern VARCHAR(255) NOT NULL DEFAULT '',
+						min_amount DOUBLE PRECISION,
+						max_amount DOUBLE PRECISION,
+						type VARCHAR(10) NOT NULL DEFAULT '',
+						category_id BIGINT,
+						tags VARCHAR(500) NOT NULL DEFAULT '',
+						payee VARCHAR(255) NOT NULL DEFAULT '',
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_rules_user_priority ON rules (user_id, priority)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS rules (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						name TEXT NOT NULL,
+						priority INTEGER NOT NULL DEFAULT 0,
+						note_contains TEXT NOT NULL DEFAULT '',
+						note_pattern TEXT NOT NULL DEFAULT '',
+						payee_contains TEXT NOT NULL DEFAULT '',
+						payee_pattern TEXT NOT NULL DEFAULT '',
+						min_amount REAL,
+						max_amount REAL,
+						type TEXT NOT NULL DEFAULT '',
+						category_id INTEGER,
+						tags TEXT NOT NULL DEFAULT '',
+						payee TEXT NOT NULL DEFAULT '',
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_rules_user_priority ON rules (user_id, priority)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0013_create_classifier",
+		name:    "create category classifier tables",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS classifier_categories (
+						user_id BIGINT NOT NULL,
+						category_id BIGINT NOT NULL,
+						documents INTEGER NOT NULL DEFAULT 0,
+						tokens INTEGER NOT NULL DEFAULT 0,
+						PRIMARY KEY (user_id, category_id)
+					)`,
+					`CREATE TABLE IF NOT EXISTS classifier_tokens (
+						user_id BIGINT NOT NULL,
+						category_id BIGINT NOT NULL,
+						token VARCHAR(50) NOT NULL,
+						occurrences INTEGER NOT NULL DEFAULT 0,
+						PRIMARY KEY (user_id, category_id, token)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_classifier_tokens_user_token ON classifier_tokens (user_id, token)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS classifier_categories (
+						user_id INTEGER NOT NULL,
+						category_id INTEGER NOT NULL,
+						documents INTEGER NOT NULL DEFAULT 0,
+						tokens INTEGER NOT NULL DEFAULT 0,
+						PRIMARY KEY (user_id, category_id)
+					)`,
+					`CREATE TABLE IF NOT EXISTS classifier_tokens (
+						user_id INTEGER NOT NULL,
+						category_id INTEGER NOT NULL,
+						token TEXT NOT NULL,
+						occurrences INTEGER NOT NULL DEFAULT 0,
+						PRIMARY KEY (user_id, category_id, token)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_classifier_tokens_user_token ON classifier_tokens (user_id, token)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0014_add_user_deletion_schedule",
+		name:    "add deletion schedule to users",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ`,
+				},
+				[]string{
+					`ALTER TABLE users ADD COLUMN deletion_scheduled_at DATETIME`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0015_create_refresh_tokens",
+		name:    "create refresh tokens table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS refresh_tokens (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						family_id VARCHAR(64) NOT NULL,
+						token_hash VARCHAR(64) NOT NULL UNIQUE,
+						expires_at TIMESTAMPTZ NOT NULL,
+						used_at TIMESTAMPTZ,
+						revoked_at TIMESTAMPTZ,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS refresh_tokens (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						family_id TEXT NOT NULL,
+						token_hash TEXT NOT NULL UNIQUE,
+						expires_at DATETIME NOT NULL,
+						used_at DATETIME,
+						revoked_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0016_create_revoked_tokens",
+		name:    "create revoked tokens table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS revoked_tokens (
+						id BIGSERIAL PRIMARY KEY,
+						token_id VARCHAR(64) NOT NULL UNIQUE,
+						user_id BIGINT NOT NULL,
+						expires_at TIMESTAMPTZ NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at)`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_invalid_before TIMESTAMPTZ`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS revoked_tokens (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						token_id TEXT NOT NULL UNIQUE,
+						user_id INTEGER NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at)`,
+					`ALTER TABLE users ADD COLUMN tokens_invalid_before DATETIME`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0017_add_totp_mfa",
+		name:    "add totp columns and recovery codes table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64) NOT NULL DEFAULT ''`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0`,
+					`CREATE TABLE IF NOT EXISTS recovery_codes (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						code_hash VARCHAR(64) NOT NULL,
+						used_at TIMESTAMPTZ,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id)`,
+				},
+				[]string{
+					`ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE users ADD COLUMN totp_enabled_at DATETIME`,
+					`ALTER TABLE users ADD COLUMN totp_last_step INTEGER NOT NULL DEFAULT 0`,
+					`CREATE TABLE IF NOT EXISTS recovery_codes (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						code_hash TEXT NOT NULL,
+						used_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0018_create_login_throttles",
+		name:    "create login throttles table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS login_throttles (
+						id BIGSERIAL PRIMARY KEY,
+						key_hash VARCHAR(64) NOT NULL UNIQUE,
+						failures INTEGER NOT NULL DEFAULT 0,
+						last_failure_at TIMESTAMPTZ NOT NULL,
+						locked_until TIMESTAMPTZ,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure_at ON login_throttles (last_failure_at)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS login_throttles (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						key_hash TEXT NOT NULL UNIQUE,
+						failures INTEGER NOT NULL DEFAULT 0,
+						last_failure_at DATETIME NOT NULL,
+						locked_until DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure_at ON login_throttles (last_failure_at)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0019_add_email_verification",
+		name:    "add email verified column to users",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ`,
+				},
+				[]string{
+					`ALTER TABLE users ADD COLUMN email_verified_at DATETIME`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0020_create_api_keys",
+		name:    "create api keys table",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS api_keys (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(100) NOT NULL,
+						prefix VARCHAR(16) NOT NULL,
+						key_hash VARCHAR(64) NOT NULL UNIQUE,
+						scopes VARCHAR(500) NOT NULL,
+						expires_at TIMESTAMPTZ,
+						last_used_at TIMESTAMPTZ,
+						revoked_at TIMESTAMPTZ,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS api_keys (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						name TEXT NOT NULL,
+						prefix TEXT NOT NULL,
+						key_hash TEXT NOT NULL UNIQUE,
+						scopes TEXT NOT NULL,
+						expires_at DATETIME,
+						last_used_at DATETIME,
+						revoked_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0021_create_oidc_tables",
+		name:    "create oidc login request and user identity tables",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`CREATE TABLE IF NOT EXISTS oidc_login_requests (
+						id BIGSERIAL PRIMARY KEY,
+						state_hash VARCHAR(64) NOT NULL UNIQUE,
+						nonce VARCHAR(64) NOT NULL,
+						code_verifier VARCHAR(128) NOT NULL,
+						expires_at TIMESTAMPTZ NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_oidc_login_requests_expires_at ON oidc_login_requests (expires_at)`,
+					`CREATE TABLE IF NOT EXISTS user_identities (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						issuer VARCHAR(255) NOT NULL,
+						subject VARCHAR(255) NOT NULL,
+						created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_issuer_subject ON user_identities (issuer, subject)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id)`,
+				},
+				[]string{
+					`CREATE TABLE IF NOT EXISTS oidc_login_requests (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						state_hash TEXT NOT NULL UNIQUE,
+						nonce TEXT NOT NULL,
+						code_verifier TEXT NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_oidc_login_requests_expires_at ON oidc_login_requests (expires_at)`,
+					`CREATE TABLE IF NOT EXISTS user_identities (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						issuer TEXT NOT NULL,
+						subject TEXT NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_issuer_subject ON user_identities (issuer, subject)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id)`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
+	{
+		version: "0022_add_refresh_token_scope",
+		name:    "add scope to refresh tokens",
+		up: func(db *gorm.DB) error {
+			statements, err := statementsForDialect(db,
+				[]string{
+					`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS scope VARCHAR(255) NOT NULL DEFAULT ''`,
+					`UPDATE refresh_tokens SET scope = 'accounts:read accounts:write budgets:read budgets:write reports:read transactions:read transactions:write' WHERE scope = ''`,
+				},
+				[]string{
+					`ALTER TABLE refresh_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT ''`,
+					`UPDATE refresh_tokens SET scope = 'accounts:read accounts:write budgets:read budgets:write reports:read transactions:read transactions:write' WHERE scope = ''`,
+				},
+			)
+			return executeStatements(db, statements, err)
+		},
+	},
 }
 
 func ApplyMigrations(db *gorm.DB) error {
@@ -88,6 +88,9 @@ func TestMigrate(t *testing.T) {
 		assert.True(t, pgDB.db.Migrator().HasTable("transactions"), "transactions table should exist")
 		assert.True(t, pgDB.db.Migrator().HasTable("categories"), "categories table should exist")
 		assert.True(t, pgDB.db.Migrator().HasTable("budgets"), "budgets table should exist")
+		assert.True(t, pgDB.db.Migrator().HasTable("accounts"), "accounts table should exist")
+		assert.True(t, pgDB.db.Migrator().HasTable("transfers"), "transfers table should exist")
+		assert.True(t, pgDB.db.Migrator().HasTable("tags"), "tags table should exist")
 		assert.True(t, pgDB.db.Migrator().HasTable("schema_migrations"), "schema_migrations table should exist")
 	})
 
@@ -0,0 +1,85 @@
+package exports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// csvHeader lists the exported columns. Split lines are written as
+// category_id:amount pairs and tags as names, each separated by semicolons.
+var csvHeader = []string{
+	"id", "date", "type", "amount", "category_id", "account_id", "transfer_id",
+	"payee", "note", "tags", "splits", "external_id",
+}
+
+// CSVWriter writes one transaction per CSV record under a header row.
+type CSVWriter struct {
+	writer *csv.Writer
+}
+
+// NewCSVWriter writes the header row and returns a writer for the records.
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, fmt.Errorf("write csv header: %w", err)
+	}
+	return &CSVWriter{writer: writer}, nil
+}
+
+func (w *CSVWriter) Write(transaction models.Transaction) error {
+	tags := make([]string, 0, len(transaction.Tags))
+	for _, tag := range transaction.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	splits := make([]string, 0, len(transaction.Splits))
+	for _, split := range transaction.Splits {
+		splits = append(splits, strconv.FormatUint(uint64(split.CategoryID), 10)+":"+formatAmount(split.Amount))
+	}
+
+	return w.writer.Write([]string{
+		strconv.FormatUint(uint64(transaction.ID), 10),
+		transaction.Date.UTC().Format(time.RFC3339),
+		transaction.Type,
+		formatAmount(transaction.Amount),
+		strconv.FormatUint(uint64(transaction.CategoryID), 10),
+		formatOptionalID(transaction.AccountID),
+		formatOptionalID(transaction.TransferID),
+		spreadsheetText(transaction.Payee),
+		spreadsheetText(transaction.Note),
+		spreadsheetText(strings.Join(tags, ";")),
+		strings.Join(splits, ";"),
+		spreadsheetText(transaction.ExternalID),
+	})
+}
+
+func (w *CSVWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatOptionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// spreadsheetText keeps spreadsheet programs from evaluating free text that
+// happens to start like a formula, such as a note reading "=1+1".
+func spreadsheetText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package exports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTransactions() []models.Transaction {
+	accountID := uint(3)
+	return []models.Transaction{
+		{
+			ID: 1, Type: "expense", Amount: 42.5, CategoryID: 4, AccountID: &accountID,
+			Date: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), Note: "=HYPERLINK(\"x\")", Payee: "Corner Shop & Deli",
+			ExternalID: "FIT-1",
+			Splits:     []models.TransactionSplit{{CategoryID: 4, Amount: 30}, {CategoryID: 5, Amount: 12.5}},
+			Tags:       []models.Tag{{Name: "food"}, {Name: "weekly"}},
+		},
+		{ID: 2, Type: "income", Amount: 1250, CategoryID: 1, Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Note: "Salary"},
+	}
+}
+
+func writeAll(t *testing.T, format Format, statement Statement) string {
+	t.Helper()
+	var out bytes.Buffer
+	writer, err := NewWriter(format, &out, statement)
+	assert.NoError(t, err)
+	for _, transaction := range exportTransactions() {
+		assert.NoError(t, writer.Write(transaction))
+	}
+	assert.NoError(t, writer.Close())
+	return out.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSONL, format)
+
+	_, err = ParseFormat("xlsx")
+	assert.Error(t, err)
+}
+
+func TestCSVWriter(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(writeAll(t, FormatCSV, Statement{}))).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{
+		"1", "2026-03-01T12:00:00Z", "expense", "42.50", "4", "3", "",
+		"Corner Shop & Deli", "'=HYPERLINK(\"x\")", "food;weekly", "4:30.00;5:12.50", "FIT-1",
+	}, records[1])
+	assert.Equal(t, "1250.00", records[2][3])
+}
+
+func TestJSONLWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeAll(t, FormatJSONL, Statement{})), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"splits":[{"category_id":4,"amount":30},{"category_id":5,"amount":12.5}]`)
+	assert.Contains(t, lines[0], `"tags":["food","weekly"]`)
+	assert.Contains(t, lines[1], `"tags":[]`)
+	assert.NotContains(t, lines[1], `"account_id"`)
+}
+
+func TestOFXWriter(t *testing.T) {
+	statement := Statement{
+		From:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		AccountID: "3",
+		Currency:  "USD",
+	}
+	document := writeAll(t, FormatOFX, statement)
+
+	assert.Contains(t, document, "<DTSTART>20260301000000</DTSTART><DTEND>20260331000000</DTEND>")
+	assert.Contains(t, document, "<CURDEF>USD</CURDEF>")
+	assert.Contains(t, document, "<ACCTID>3</ACCTID>")
+	assert.Contains(t, document, "<BALAMT>1207.50</BALAMT>")
+
+	// The export must read back through the OFX import.
+	preview, err := imports.ParseOFX(strings.NewReader(document))
+	assert.NoError(t, err)
+	assert.Empty(t, preview.Errors)
+	if assert.Len(t, preview.Rows, 2) {
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 42.5, preview.Rows[0].Amount)
+		assert.Equal(t, "FIT-1", preview.Rows[0].ExternalID)
+		assert.Equal(t, "Corner Shop & Deli", preview.Rows[0].Payee)
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.Equal(t, "2", preview.Rows[1].ExternalID)
+	}
+}
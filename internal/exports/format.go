@@ -0,0 +1,72 @@
+package exports
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// Format names a transaction export format.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatOFX   Format = "ofx"
+)
+
+// Writer encodes transactions one at a time, so an export never has to hold
+// more than one of them.
+type Writer interface {
+	Write(transaction models.Transaction) error
+	// Close writes any trailer and flushes buffered output. It does not close
+	// the underlying writer.
+	Close() error
+}
+
+// Statement describes what an export covers. OFX statements declare it up
+// front; the other formats ignore it.
+type Statement struct {
+	From      time.Time
+	To        time.Time
+	AccountID string // Account identifier, when the export is limited to one account
+	Currency  string // ISO 4217 code of the account's currency
+}
+
+// ParseFormat checks that a requested format is supported.
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatCSV, FormatJSONL, FormatOFX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", value)
+	}
+}
+
+// NewWriter starts an export in the given format.
+func NewWriter(format Format, w io.Writer, statement Statement) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	case FormatOFX:
+		return NewOFXWriter(w, statement)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ContentType is the media type of an export in this format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
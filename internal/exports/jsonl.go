@@ -0,0 +1,73 @@
+package exports
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// jsonlTransaction mirrors the API's transaction representation.
+type jsonlTransaction struct {
+	ID         uint         `json:"id"`
+	Type       string       `json:"type"`
+	Amount     float64      `json:"amount"`
+	CategoryID uint         `json:"category_id"`
+	AccountID  *uint        `json:"account_id,omitempty"`
+	TransferID *uint        `json:"transfer_id,omitempty"`
+	Date       time.Time    `json:"date"`
+	Note       string       `json:"note"`
+	Payee      string       `json:"payee,omitempty"`
+	ExternalID string       `json:"external_id,omitempty"`
+	Splits     []jsonlSplit `json:"splits,omitempty"`
+	Tags       []string     `json:"tags"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+type jsonlSplit struct {
+	CategoryID uint    `json:"category_id"`
+	Amount     float64 `json:"amount"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// JSONLWriter writes one JSON object per line.
+type JSONLWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{encoder: json.NewEncoder(w)}
+}
+
+func (w *JSONLWriter) Write(transaction models.Transaction) error {
+	record := jsonlTransaction{
+		ID:         transaction.ID,
+		Type:       transaction.Type,
+		Amount:     transaction.Amount,
+		CategoryID: transaction.CategoryID,
+		AccountID:  transaction.AccountID,
+		TransferID: transaction.TransferID,
+		Date:       transaction.Date,
+		Note:       transaction.Note,
+		Payee:      transaction.Payee,
+		ExternalID: transaction.ExternalID,
+		Tags:       make([]string, 0, len(transaction.Tags)),
+		CreatedAt:  transaction.CreatedAt,
+		UpdatedAt:  transaction.UpdatedAt,
+	}
+	for _, split := range transaction.Splits {
+		record.Splits = append(record.Splits, jsonlSplit{CategoryID: split.CategoryID, Amount: split.Amount, Note: split.Note})
+	}
+	for _, tag := range transaction.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+
+	// Encode terminates every value with a newline.
+	return w.encoder.Encode(record)
+}
+
+func (w *JSONLWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,146 @@
+package exports
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+const ofxDateLayout = "20060102150405"
+
+// ofxNameLength is the longest NAME the OFX specification allows.
+const ofxNameLength = 32
+
+// Statements that are not limited to one account have no real account
+// number or currency to declare.
+const (
+	defaultOFXAccountID = "EXPORT"
+	defaultOFXCurrency  = "EUR"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%[1]s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%[4]s</CURDEF>
+<BANKACCTFROM><BANKID>0</BANKID><ACCTID>%[5]s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%[2]s</DTSTART><DTEND>%[3]s</DTEND>
+`
+
+const ofxFooter = `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+// OFXWriter writes an OFX 2.2 bank statement with one STMTTRN per
+// transaction, which finance tools and this API's own OFX import can read.
+// Expenses are written as negative amounts, and a transaction's bank
+// reference is reused as its FITID so re-importing the file is recognised.
+// Since an export can span several accounts, the ledger balance is the net
+// of the exported transactions rather than an account balance.
+type OFXWriter struct {
+	writer *bufio.Writer
+	to     time.Time
+	net    float64
+}
+
+// NewOFXWriter writes the statement header. A zero From starts the statement
+// at the Unix epoch and a zero To ends it now.
+func NewOFXWriter(w io.Writer, statement Statement) (*OFXWriter, error) {
+	if statement.From.IsZero() {
+		statement.From = time.Unix(0, 0)
+	}
+	if statement.To.IsZero() {
+		statement.To = time.Now()
+	}
+	if statement.AccountID == "" {
+		statement.AccountID = defaultOFXAccountID
+	}
+	if statement.Currency == "" {
+		statement.Currency = defaultOFXCurrency
+	}
+
+	writer := bufio.NewWriter(w)
+	_, err := fmt.Fprintf(writer, ofxHeader,
+		formatOFXDate(time.Now()),
+		formatOFXDate(statement.From),
+		formatOFXDate(statement.To),
+		escapeOFX(statement.Currency),
+		escapeOFX(statement.AccountID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("write ofx header: %w", err)
+	}
+
+	return &OFXWriter{writer: writer, to: statement.To}, nil
+}
+
+func (w *OFXWriter) Write(transaction models.Transaction) error {
+	amount := transaction.Amount
+	transactionType := "CREDIT"
+	switch transaction.Type {
+	case "expense":
+		amount = -amount
+		transactionType = "DEBIT"
+	case "transfer":
+		transactionType = "XFER"
+	}
+	w.net += amount
+
+	fitID := transaction.ExternalID
+	if fitID == "" {
+		fitID = strconv.FormatUint(uint64(transaction.ID), 10)
+	}
+
+	name := []rune(transaction.Payee)
+	if len(name) > ofxNameLength {
+		name = name[:ofxNameLength]
+	}
+
+	var record strings.Builder
+	record.WriteString("<STMTTRN>")
+	writeOFXElement(&record, "TRNTYPE", transactionType)
+	writeOFXElement(&record, "DTPOSTED", formatOFXDate(transaction.Date))
+	writeOFXElement(&record, "TRNAMT", formatAmount(amount))
+	writeOFXElement(&record, "FITID", fitID)
+	if len(name) > 0 {
+		writeOFXElement(&record, "NAME", string(name))
+	}
+	if transaction.Note != "" {
+		writeOFXElement(&record, "MEMO", transaction.Note)
+	}
+	record.WriteString("</STMTTRN>\n")
+
+	_, err := w.writer.WriteString(record.String())
+	return err
+}
+
+func (w *OFXWriter) Close() error {
+	if _, err := fmt.Fprintf(w.writer, ofxFooter, formatAmount(w.net), formatOFXDate(w.to)); err != nil {
+		return err
+	}
+	return w.writer.Flush()
+}
+
+func writeOFXElement(b *strings.Builder, name, value string) {
+	b.WriteString("<" + name + ">" + escapeOFX(value) + "</" + name + ">")
+}
+
+func escapeOFX(value string) string {
+	var escaped strings.Builder
+	// EscapeText cannot fail when writing to a strings.Builder.
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+func formatOFXDate(date time.Time) string {
+	return date.UTC().Format(ofxDateLayout)
+}
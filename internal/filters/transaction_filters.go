@@ -2,9 +2,19 @@ package filters
 
 import "time"
 
+const (
+	// TagMatchAny keeps transactions carrying at least one of the requested tags.
+	TagMatchAny = "any"
+	// TagMatchAll keeps transactions carrying every requested tag.
+	TagMatchAll = "all"
+)
+
 type TransactionFilters struct {
 	Type       string
 	CategoryID *uint
+	AccountID  *uint
+	Tags       []string
+	TagMatch   string // TagMatchAny (default) or TagMatchAll
 	From       *time.Time
 	To         *time.Time
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler publishes the public keys access tokens can be verified with
+// @Summary JSON Web Key Set
+// @Description Public keys that verify access tokens, identified by the kid in the token header. Keys being rotated out stay listed until their tokens expire. Tokens signed with a shared HS256 secret cannot be verified from this set.
+// @Tags system
+// @Produce json
+// @Success 200 {object} auth.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(tokenManager *auth.JWTManager) gin.HandlerFunc {
+	keySet := tokenManager.JWKS()
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keySet)
+	}
+}
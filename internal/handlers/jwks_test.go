@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should return an empty key set for shared secrets", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/.well-known/jwks.json", JWKSHandler(auth.NewJWTManager("secret", time.Minute)))
+
+		req, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"keys": []}`, w.Body.String())
+	})
+}
@@ -1,7 +1,9 @@
 package httpapi
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/observability"
@@ -15,20 +17,33 @@ type ErrorResponse struct {
 type ErrorDetail struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	// RetryAfter is the number of seconds to wait before trying again,
+	// also sent as the Retry-After header.
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
 func WriteError(c *gin.Context, err error) {
 	status, payload := buildErrorResponse(err)
 	logError(c, err, status)
+	setRetryAfter(c, payload)
 	c.JSON(status, payload)
 }
 
 func AbortWithError(c *gin.Context, err error) {
 	status, payload := buildErrorResponse(err)
 	logError(c, err, status)
+	setRetryAfter(c, payload)
 	c.AbortWithStatusJSON(status, payload)
 }
 
+// AbortStream logs err and stops a response whose body has already been
+// partly written, when an error status can no longer be sent.
+func AbortStream(c *gin.Context, err error) {
+	status, _ := buildErrorResponse(err)
+	logError(c, err, status)
+	c.Abort()
+}
+
 func buildErrorResponse(err error) (int, ErrorResponse) {
 	appErr, ok := apperrors.As(err)
 	if !ok {
@@ -42,12 +57,29 @@ func buildErrorResponse(err error) (int, ErrorResponse) {
 
 	return statusCode(appErr.Kind), ErrorResponse{
 		Error: ErrorDetail{
-			Code:    appErr.Code,
-			Message: appErr.Message,
+			Code:       appErr.Code,
+			Message:    appErr.Message,
+			RetryAfter: retryAfterSeconds(appErr),
 		},
 	}
 }
 
+// retryAfterSeconds rounds the error's retry delay up to whole seconds,
+// so a client that waits that long is not turned away again.
+func retryAfterSeconds(appErr *apperrors.Error) int {
+	if appErr.RetryAfter <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(appErr.RetryAfter.Seconds()))
+}
+
+func setRetryAfter(c *gin.Context, payload ErrorResponse) {
+	if payload.Error.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(payload.Error.RetryAfter))
+	}
+}
+
 func statusCode(kind apperrors.Kind) int {
 	switch kind {
 	case apperrors.KindValidation:
@@ -62,6 +94,8 @@ func statusCode(kind apperrors.Kind) int {
 		return http.StatusConflict
 	case apperrors.KindUnavailable:
 		return http.StatusServiceUnavailable
+	case apperrors.KindTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
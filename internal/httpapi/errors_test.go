@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/observability"
@@ -50,6 +51,33 @@ func TestBuildErrorResponse(t *testing.T) {
 	})
 }
 
+func TestWriteErrorSetsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/login", nil)
+
+	WriteError(c, apperrors.TooManyRequests("too_many_login_attempts", "try again in 2 minutes", 90500*time.Millisecond))
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, rec.Code)
+	}
+
+	if rec.Header().Get("Retry-After") != "91" {
+		t.Fatalf("expected Retry-After 91, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	var response ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("expected valid JSON, got error %v", err)
+	}
+
+	if response.Error.Code != "too_many_login_attempts" || response.Error.RetryAfter != 91 {
+		t.Fatalf("unexpected error body %+v", response.Error)
+	}
+}
+
 func TestWriteErrorLogsRequestScopedFields(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -96,3 +124,30 @@ func TestWriteErrorLogsRequestScopedFields(t *testing.T) {
 		t.Fatalf("expected invalid_request code, got %v", entry["error_code"])
 	}
 }
+
+func TestAbortStreamKeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logBuffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/exports/transactions", nil)
+	observability.SetLoggerOnGinContext(c, logger)
+
+	c.String(http.StatusOK, "id,date\n")
+	AbortStream(c, apperrors.Internal("transactions_export_failed", "failed to export transactions", errors.New("connection reset")))
+
+	if !c.IsAborted() {
+		t.Fatal("expected the request to be aborted")
+	}
+
+	if rec.Body.String() != "id,date\n" {
+		t.Fatalf("expected the written body to be left alone, got %q", rec.Body.String())
+	}
+
+	if !strings.Contains(logBuffer.String(), `"error_code":"transactions_export_failed"`) {
+		t.Fatalf("expected the error to be logged, got %s", logBuffer.String())
+	}
+}
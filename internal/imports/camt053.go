@@ -0,0 +1,228 @@
+package imports
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// camtEntry holds the parts of an ISO 20022 camt.053 <Ntry> the importer
+// uses. Element names are matched without their namespace so every camt.053
+// version is accepted.
+type camtEntry struct {
+	Amount      string         `xml:"Amt"`
+	Indicator   string         `xml:"CdtDbtInd"`
+	Status      camtStatus     `xml:"Sts"`
+	BookingDate camtDate       `xml:"BookgDt"`
+	ValueDate   camtDate       `xml:"ValDt"`
+	Reference   string         `xml:"AcctSvcrRef"`
+	EntryRef    string         `xml:"NtryRef"`
+	Details     []camtTxDetail `xml:"NtryDtls>TxDtls"`
+	Info        string         `xml:"AddtlNtryInf"`
+}
+
+// camtStatus is a bare code up to camt.053.001.04 and wrapped in <Cd> since.
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTxDetail struct {
+	Reference     string    `xml:"Refs>AcctSvcrRef"`
+	Debtor        camtParty `xml:"RltdPties>Dbtr"`
+	Creditor      camtParty `xml:"RltdPties>Cdtr"`
+	Unstructured  []string  `xml:"RmtInf>Ustrd"`
+	CreditorRef   string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	AdditionalInf string    `xml:"AddtlTxInf"`
+}
+
+// camtParty has the name directly below the party up to camt.053.001.07
+// and inside <Pty> from .08 on.
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"`
+}
+
+// ParseCAMT053 reads an ISO 20022 camt.053 bank-to-customer statement and
+// turns each booked <Ntry> into a parsed row or a row error. The entry's
+// account servicer reference becomes the row's ExternalID. An error is
+// returned only when the file is not a camt.053 document.
+func ParseCAMT053(r io.Reader) (*Preview, error) {
+	decoder := xml.NewDecoder(r)
+	preview := &Preview{Rows: []ParsedRow{}, Errors: []RowError{}}
+	seen := make(map[string]bool)
+	isStatement := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read camt.053 file: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "BkToCstmrStmt":
+			isStatement = true
+		case "Ntry":
+			line, _ := decoder.InputPos()
+
+			var entry camtEntry
+			if err := decoder.DecodeElement(&entry, &start); err != nil {
+				return nil, fmt.Errorf("read camt.053 entry: %w", err)
+			}
+
+			if !entry.isBooked() {
+				continue
+			}
+
+			row, err := parseCAMTEntry(entry)
+			if err != nil {
+				preview.Errors = append(preview.Errors, RowError{Line: line, Message: err.Error()})
+				continue
+			}
+
+			if row.ExternalID != "" {
+				if seen[row.ExternalID] {
+					continue
+				}
+				seen[row.ExternalID] = true
+			}
+
+			row.Line = line
+			preview.Rows = append(preview.Rows, row)
+		}
+	}
+
+	if !isStatement {
+		return nil, fmt.Errorf("file is not a camt.053 statement")
+	}
+
+	return preview, nil
+}
+
+// isBooked skips pending and informational entries, which banks report
+// again once they are booked.
+func (e camtEntry) isBooked() bool {
+	status := strings.TrimSpace(e.Status.Code)
+	if status == "" {
+		status = strings.TrimSpace(e.Status.Value)
+	}
+	return status == "" || strings.EqualFold(status, "BOOK")
+}
+
+func parseCAMTEntry(entry camtEntry) (ParsedRow, error) {
+	date, err := entry.BookingDate.parse()
+	if err != nil {
+		date, err = entry.ValueDate.parse()
+	}
+	if err != nil {
+		return ParsedRow{}, fmt.Errorf("entry has no valid booking date")
+	}
+
+	raw := strings.TrimSpace(entry.Amount)
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return ParsedRow{}, fmt.Errorf("invalid amount %q", raw)
+	}
+	if amount == 0 {
+		return ParsedRow{}, fmt.Errorf("amount must not be zero")
+	}
+
+	var debit bool
+	switch strings.ToUpper(strings.TrimSpace(entry.Indicator)) {
+	case "DBIT":
+		debit = true
+	case "CRDT":
+	default:
+		return ParsedRow{}, fmt.Errorf("invalid credit/debit indicator %q", entry.Indicator)
+	}
+
+	amount = absolute(amount)
+	if debit {
+		amount = -amount
+	}
+
+	var (
+		detail    camtTxDetail
+		payee     string
+		reference = strings.TrimSpace(entry.Reference)
+	)
+	if len(entry.Details) > 0 {
+		detail = entry.Details[0]
+		// The counterparty is whoever is on the other side of the booking.
+		counterparty := detail.Debtor
+		if debit {
+			counterparty = detail.Creditor
+		}
+		payee = counterparty.name()
+
+		if reference == "" {
+			reference = strings.TrimSpace(detail.Reference)
+		}
+	}
+	if reference == "" {
+		reference = strings.TrimSpace(entry.EntryRef)
+	}
+
+	row := NewParsedRow(date, amount, firstNonEmpty(
+		strings.Join(trimAll(detail.Unstructured), " "),
+		detail.CreditorRef,
+		detail.AdditionalInf,
+		entry.Info,
+	))
+	row.Payee = payee
+	row.ExternalID = reference
+	return row, nil
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	if value := strings.TrimSpace(d.Date); value != "" {
+		return time.Parse("2006-01-02", value)
+	}
+
+	value := strings.TrimSpace(d.DateTime)
+	if len(value) < len("2006-01-02") {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	// Keep the calendar day the bank booked on rather than converting zones.
+	return time.Parse("2006-01-02", value[:len("2006-01-02")])
+}
+
+func (p camtParty) name() string {
+	return firstNonEmpty(p.PartyName, p.Name)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
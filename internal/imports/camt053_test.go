@@ -0,0 +1,94 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCAMT053(t *testing.T) {
+	t.Run("Booked entries", func(t *testing.T) {
+		statement := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <Amt Ccy="EUR">42.50</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2026-03-01</Dt></BookgDt>
+        <AcctSvcrRef>REF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties>
+            <Dbtr><Pty><Nm>Jane Doe</Nm></Pty></Dbtr>
+            <Cdtr><Pty><Nm>Supermarkt GmbH</Nm></Pty></Cdtr>
+          </RltdPties>
+          <RmtInf><Ustrd>Einkauf</Ustrd><Ustrd>Filiale 12</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">2000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2026-03-02T23:30:00+01:00</DtTm></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><AcctSvcrRef>REF-2</AcctSvcrRef></Refs>
+          <RltdPties><Dbtr><Nm>Employer AG</Nm></Dbtr></RltdPties>
+        </TxDtls></NtryDtls>
+        <AddtlNtryInf>Gehalt Maerz</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">2000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2026-03-02</Dt></BookgDt>
+        <AcctSvcrRef>REF-2</AcctSvcrRef>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <BookgDt><Dt>2026-03-03</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>XXXX</CdtDbtInd>
+        <BookgDt><Dt>2026-03-03</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+		preview, err := ParseCAMT053(strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 2)
+
+		assert.Equal(t, 5, preview.Rows[0].Line)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), preview.Rows[0].Date)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 42.50, preview.Rows[0].Amount)
+		assert.Equal(t, "Supermarkt GmbH", preview.Rows[0].Payee)
+		assert.Equal(t, "Einkauf Filiale 12", preview.Rows[0].Description)
+		assert.Equal(t, "REF-1", preview.Rows[0].ExternalID)
+
+		assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), preview.Rows[1].Date)
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.Equal(t, "Employer AG", preview.Rows[1].Payee)
+		assert.Equal(t, "Gehalt Maerz", preview.Rows[1].Description)
+		assert.Equal(t, "REF-2", preview.Rows[1].ExternalID)
+
+		assert.Len(t, preview.Errors, 1)
+		assert.Contains(t, preview.Errors[0].Message, "credit/debit indicator")
+	})
+
+	t.Run("Not a camt.053 file", func(t *testing.T) {
+		_, err := ParseCAMT053(strings.NewReader(`<Document><BkToCstmrDbtCdtNtfctn/></Document>`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Malformed XML", func(t *testing.T) {
+		_, err := ParseCAMT053(strings.NewReader("Date,Amount\n"))
+		assert.Error(t, err)
+	})
+}
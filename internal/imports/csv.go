@@ -0,0 +1,301 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultCSVDateFormat = "YYYY-MM-DD"
+
+// CSVMapping describes how the columns of a bank's CSV export map onto
+// transaction fields. Columns are referenced by their header name.
+type CSVMapping struct {
+	DateColumn        string
+	DateFormat        string // e.g. "DD/MM/YYYY"; Go reference layouts are accepted too
+	AmountColumn      string // Signed amount: negative values are expenses
+	DebitColumn       string // Used together with CreditColumn instead of AmountColumn
+	CreditColumn      string
+	DescriptionColumn string
+	DecimalSeparator  string // "." (default) or ","
+	Delimiter         string // "," (default), ";", "|" or "\t"
+}
+
+// ParseCSV reads a CSV statement and turns each data row into a parsed row or
+// a row error. An error is returned only when the file or mapping is unusable.
+func ParseCSV(r io.Reader, mapping CSVMapping) (*Preview, error) {
+	if err := mapping.normalize(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = []rune(mapping.Delimiter)[0]
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	columns, err := mapping.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := dateLayout(mapping.DateFormat)
+	preview := &Preview{Rows: []ParsedRow{}, Errors: []RowError{}}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			// The reader may not have recorded any field of a malformed
+			// row, so the line comes from the error and not from FieldPos.
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("read csv: %w", err)
+			}
+			preview.Errors = append(preview.Errors, RowError{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row, err := parseCSVRecord(record, columns, layout, mapping.DecimalSeparator)
+		if err != nil {
+			preview.Errors = append(preview.Errors, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		row.Line = line
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	return preview, nil
+}
+
+type csvColumns struct {
+	date        int
+	amount      int
+	debit       int
+	credit      int
+	description int
+}
+
+func (m *CSVMapping) normalize() error {
+	if strings.TrimSpace(m.DateColumn) == "" {
+		return fmt.Errorf("date column is required")
+	}
+
+	hasAmount := strings.TrimSpace(m.AmountColumn) != ""
+	hasDebitCredit := strings.TrimSpace(m.DebitColumn) != "" && strings.TrimSpace(m.CreditColumn) != ""
+	if hasAmount == hasDebitCredit {
+		return fmt.Errorf("provide either an amount column or both debit and credit columns")
+	}
+
+	if m.DateFormat == "" {
+		m.DateFormat = DefaultCSVDateFormat
+	}
+
+	switch m.DecimalSeparator {
+	case "":
+		m.DecimalSeparator = "."
+	case ".", ",":
+	default:
+		return fmt.Errorf("decimal separator must be . or ,")
+	}
+
+	switch m.Delimiter {
+	case "":
+		m.Delimiter = ","
+	case ",", ";", "\t", "|":
+	default:
+		return fmt.Errorf("delimiter must be one of , ; | or a tab")
+	}
+
+	if m.Delimiter == m.DecimalSeparator {
+		return fmt.Errorf("delimiter and decimal separator must differ")
+	}
+
+	return nil
+}
+
+func (m CSVMapping) resolveColumns(header []string) (csvColumns, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	find := func(name string) (int, error) {
+		if strings.TrimSpace(name) == "" {
+			return -1, nil
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return -1, fmt.Errorf("column %q not found in csv header", name)
+		}
+		return i, nil
+	}
+
+	var (
+		columns csvColumns
+		err     error
+	)
+	if columns.date, err = find(m.DateColumn); err != nil {
+		return csvColumns{}, err
+	}
+	if columns.amount, err = find(m.AmountColumn); err != nil {
+		return csvColumns{}, err
+	}
+	if columns.debit, err = find(m.DebitColumn); err != nil {
+		return csvColumns{}, err
+	}
+	if columns.credit, err = find(m.CreditColumn); err != nil {
+		return csvColumns{}, err
+	}
+	if columns.description, err = find(m.DescriptionColumn); err != nil {
+		return csvColumns{}, err
+	}
+
+	return columns, nil
+}
+
+func parseCSVRecord(record []string, columns csvColumns, layout, decimalSeparator string) (ParsedRow, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	date, err := time.Parse(layout, field(columns.date))
+	if err != nil {
+		return ParsedRow{}, fmt.Errorf("invalid date %q", field(columns.date))
+	}
+
+	var amount float64
+	if columns.amount >= 0 {
+		amount, err = parseAmount(field(columns.amount), decimalSeparator)
+		if err != nil {
+			return ParsedRow{}, err
+		}
+	} else {
+		debit, credit := field(columns.debit), field(columns.credit)
+		switch {
+		case debit != "" && credit != "":
+			return ParsedRow{}, fmt.Errorf("row has both a debit and a credit amount")
+		case debit != "":
+			amount, err = parseAmount(debit, decimalSeparator)
+			amount = -absolute(amount)
+		case credit != "":
+			amount, err = parseAmount(credit, decimalSeparator)
+			amount = absolute(amount)
+		default:
+			return ParsedRow{}, fmt.Errorf("row has neither a debit nor a credit amount")
+		}
+		if err != nil {
+			return ParsedRow{}, err
+		}
+	}
+
+	if amount == 0 {
+		return ParsedRow{}, fmt.Errorf("amount must not be zero")
+	}
+
+	return NewParsedRow(date, amount, field(columns.description)), nil
+}
+
+// parseAmount parses a localized amount such as "1.234,56" or "-1,234.56".
+// Thousands separators are only taken as such between groups of three
+// digits, so "1,5" read with "." as the decimal separator is refused
+// rather than read as 15.
+func parseAmount(raw, decimalSeparator string) (float64, error) {
+	value := strings.ReplaceAll(raw, " ", "")
+	value = strings.ReplaceAll(value, "\u00a0", "")
+
+	thousandsSeparator := ","
+	if decimalSeparator == "," {
+		thousandsSeparator = "."
+	}
+
+	integer, fraction, hasFraction := strings.Cut(value, decimalSeparator)
+	if strings.Contains(fraction, thousandsSeparator) || !validDigitGroups(integer, thousandsSeparator) {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+	value = strings.ReplaceAll(integer, thousandsSeparator, "")
+	if hasFraction {
+		value += "." + fraction
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || value == "" {
+		return 0, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	return amount, nil
+}
+
+// validDigitGroups reports whether the thousands separators in the integer
+// part of an amount, if any, leave one to three digits in front and
+// exactly three between each pair.
+func validDigitGroups(integer, separator string) bool {
+	groups := strings.Split(strings.TrimLeft(integer, "+-"), separator)
+	if len(groups) == 1 {
+		return true
+	}
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// dateLayout converts a format such as "DD.MM.YYYY" into a Go time layout.
+func dateLayout(format string) string {
+	replacer := strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MM", "01",
+		"DD", "02",
+		"hh", "15",
+		"mm", "04",
+		"ss", "05",
+	)
+	return replacer.Replace(format)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func absolute(value float64) float64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("Signed amount column", func(t *testing.T) {
+		statement := "Date,Amount,Description\n" +
+			"2026-03-01,-42.50,Supermarket\n" +
+			"2026-03-02,\"1,250.00\",Salary\n"
+
+		preview, err := ParseCSV(strings.NewReader(statement), CSVMapping{
+			DateColumn:        "Date",
+			AmountColumn:      "Amount",
+			DescriptionColumn: "Description",
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, preview.Errors)
+		assert.Len(t, preview.Rows, 2)
+
+		assert.Equal(t, 2, preview.Rows[0].Line)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), preview.Rows[0].Date)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 42.50, preview.Rows[0].Amount)
+		assert.Equal(t, "Supermarket", preview.Rows[0].Description)
+
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.Equal(t, 1250.0, preview.Rows[1].Amount)
+	})
+
+	t.Run("Misplaced thousands separators become row errors", func(t *testing.T) {
+		statement := "Date,Amount,Description\n" +
+			"2026-03-01,\"1,5\",Decimal comma\n" +
+			"2026-03-02,\"12,345,678.90\",Big\n" +
+			"2026-03-03,\"1,23.00\",Short group\n" +
+			"2026-03-04,\"-1.234,5\",Comma in the fraction\n"
+
+		preview, err := ParseCSV(strings.NewReader(statement), CSVMapping{
+			DateColumn:        "Date",
+			AmountColumn:      "Amount",
+			DescriptionColumn: "Description",
+		})
+		if assert.NoError(t, err) {
+			if assert.Len(t, preview.Rows, 1) {
+				assert.InDelta(t, 12345678.90, preview.Rows[0].Amount, 0.001)
+			}
+			if assert.Len(t, preview.Errors, 3) {
+				assert.Equal(t, 2, preview.Errors[0].Line)
+				assert.Contains(t, preview.Errors[0].Message, `invalid amount "1,5"`)
+				assert.Equal(t, 4, preview.Errors[1].Line)
+				assert.Equal(t, 5, preview.Errors[2].Line)
+			}
+		}
+	})
+
+	t.Run("Malformed quotes become row errors", func(t *testing.T) {
+		statement := "Date,Amount,Description\n" +
+			"2026-03-01,-42.50,Supermarket\n" +
+			"a\"b,1,Bare quote\n" +
+			"2026-03-02,-3.20,Coffee\n" +
+			"2026-03-03,\"5.00,Unterminated\n"
+
+		preview, err := ParseCSV(strings.NewReader(statement), CSVMapping{
+			DateColumn:        "Date",
+			AmountColumn:      "Amount",
+			DescriptionColumn: "Description",
+		})
+		if assert.NoError(t, err) {
+			assert.Len(t, preview.Rows, 2)
+			assert.Len(t, preview.Errors, 2)
+			assert.Equal(t, 3, preview.Errors[0].Line)
+			assert.Contains(t, preview.Errors[0].Message, "bare \"")
+			assert.Equal(t, 5, preview.Errors[1].Line)
+		}
+	})
+
+	t.Run("Debit and credit columns with European formatting", func(t *testing.T) {
+		statement := "Buchungstag;Soll;Haben;Verwendungszweck\n" +
+			"01.03.2026;1.234,56;;Miete\n" +
+			"02.03.2026;;2.000,00;Gehalt\n" +
+			"\n" +
+			"03.03.2026;;;Leer\n" +
+			"31.02.2026;5,00;;Falsches Datum\n"
+
+		preview, err := ParseCSV(strings.NewReader(statement), CSVMapping{
+			DateColumn:        "Buchungstag",
+			DateFormat:        "DD.MM.YYYY",
+			DebitColumn:       "Soll",
+			CreditColumn:      "Haben",
+			DescriptionColumn: "Verwendungszweck",
+			DecimalSeparator:  ",",
+			Delimiter:         ";",
+		})
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 2)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.InDelta(t, 1234.56, preview.Rows[0].Amount, 0.001)
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.InDelta(t, 2000, preview.Rows[1].Amount, 0.001)
+
+		assert.Len(t, preview.Errors, 2)
+		assert.Equal(t, 5, preview.Errors[0].Line)
+		assert.Contains(t, preview.Errors[0].Message, "neither a debit nor a credit")
+		assert.Equal(t, 6, preview.Errors[1].Line)
+		assert.Contains(t, preview.Errors[1].Message, "invalid date")
+	})
+
+	t.Run("Unknown column", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("Date,Amount\n"), CSVMapping{DateColumn: "Booking date", AmountColumn: "Amount"})
+		assert.ErrorContains(t, err, "Booking date")
+	})
+
+	t.Run("Ambiguous amount mapping", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader("Date,Amount\n"), CSVMapping{DateColumn: "Date", AmountColumn: "Amount", DebitColumn: "Debit", CreditColumn: "Credit"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		_, err := ParseCSV(strings.NewReader(""), CSVMapping{DateColumn: "Date", AmountColumn: "Amount"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,29 @@
+package imports
+
+import (
+	"fmt"
+	"io"
+)
+
+// Format names a bank statement format that needs no column mapping.
+type Format string
+
+const (
+	FormatOFX     Format = "ofx"
+	FormatCAMT053 Format = "camt053"
+	FormatMT940   Format = "mt940"
+)
+
+// Parse reads a statement in the given format.
+func Parse(format Format, r io.Reader) (*Preview, error) {
+	switch format {
+	case FormatOFX:
+		return ParseOFX(r)
+	case FormatCAMT053:
+		return ParseCAMT053(r)
+	case FormatMT940:
+		return ParseMT940(r)
+	default:
+		return nil, fmt.Errorf("unsupported statement format %q", format)
+	}
+}
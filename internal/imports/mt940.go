@@ -0,0 +1,265 @@
+package imports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mt940StatementLine matches the fixed part of a :61: field: value date,
+// optional entry date, debit/credit mark, optional funds code, amount,
+// transaction type and the references that follow it.
+var mt940StatementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NFS][A-Z0-9]{3})(.*)$`)
+
+// mt940Tag matches the start of a field such as ":61:" or ":60F:".
+var mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+// maxMT940LineLength is far above the 65 characters SWIFT allows per line
+// but keeps a binary upload from growing the scanner buffer without bound.
+const maxMT940LineLength = 1 << 20
+
+// mt940Entry pairs a :61: statement line with the :86: information to the
+// account owner that follows it.
+type mt940Entry struct {
+	line        int
+	statement   string
+	information string
+}
+
+// ParseMT940 reads a SWIFT MT940 customer statement and turns each :61:
+// statement line into a parsed row or a row error. The bank reference after
+// "//" becomes the row's ExternalID, falling back to the customer reference.
+// An error is returned only when the file is not an MT940 statement.
+func ParseMT940(r io.Reader) (*Preview, error) {
+	entries, err := mt940Entries(r)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{Rows: []ParsedRow{}, Errors: []RowError{}}
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		row, err := parseMT940Entry(entry)
+		if err != nil {
+			preview.Errors = append(preview.Errors, RowError{Line: entry.line, Message: err.Error()})
+			continue
+		}
+
+		if row.ExternalID != "" {
+			if seen[row.ExternalID] {
+				continue
+			}
+			seen[row.ExternalID] = true
+		}
+
+		row.Line = entry.line
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	return preview, nil
+}
+
+func mt940Entries(r io.Reader) ([]mt940Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMT940LineLength)
+
+	var (
+		entries     []mt940Entry
+		current     *mt940Entry
+		field       string
+		isStatement bool
+	)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// SWIFT envelopes wrap the text block in {4: ... -}.
+		if i := strings.Index(line, "{4:"); i >= 0 {
+			line = line[i+len("{4:"):]
+		}
+		if strings.TrimSpace(line) == "-}" || strings.TrimSpace(line) == "-" {
+			field = ""
+			continue
+		}
+
+		if match := mt940Tag.FindStringSubmatch(line); match != nil {
+			field = match[1]
+			value := line[len(match[0]):]
+
+			switch field {
+			case "20":
+				isStatement = true
+			case "61":
+				entries = append(entries, mt940Entry{line: lineNumber, statement: value})
+				current = &entries[len(entries)-1]
+			case "86":
+				if current != nil {
+					current.information = value
+				}
+			default:
+				current = nil
+			}
+			continue
+		}
+
+		// Continuation lines belong to the field opened last.
+		if current == nil {
+			continue
+		}
+		switch field {
+		case "61":
+			current.statement += "\n" + line
+		case "86":
+			current.information += "\n" + line
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read mt940 file: %w", err)
+	}
+
+	if !isStatement {
+		return nil, fmt.Errorf("file is not an mt940 statement")
+	}
+
+	return entries, nil
+}
+
+func parseMT940Entry(entry mt940Entry) (ParsedRow, error) {
+	first, supplementary, _ := strings.Cut(entry.statement, "\n")
+
+	match := mt940StatementLine.FindStringSubmatch(strings.TrimSpace(first))
+	if match == nil {
+		return ParsedRow{}, fmt.Errorf("invalid statement line %q", first)
+	}
+
+	date, err := mt940BookingDate(match[1], match[2])
+	if err != nil {
+		return ParsedRow{}, err
+	}
+
+	amount, err := parseAmount(match[5], ",")
+	if err != nil {
+		return ParsedRow{}, err
+	}
+	if amount == 0 {
+		return ParsedRow{}, fmt.Errorf("amount must not be zero")
+	}
+
+	// A reversal of a credit takes money out; a reversal of a debit puts it back.
+	if match[3] == "D" || match[3] == "RC" {
+		amount = -amount
+	}
+
+	customerReference, bankReference, _ := strings.Cut(match[7], "//")
+	reference := strings.TrimSpace(bankReference)
+	if reference == "" && !strings.EqualFold(strings.TrimSpace(customerReference), "NONREF") {
+		reference = strings.TrimSpace(customerReference)
+	}
+
+	payee, remittance := parseMT940Information(entry.information)
+	row := NewParsedRow(date, amount, firstNonEmpty(remittance, supplementary))
+	row.Payee = payee
+	row.ExternalID = reference
+	return row, nil
+}
+
+// mt940BookingDate prefers the optional entry date (MMDD) over the value date
+// (YYMMDD); the entry date borrows the value date's year, adjusted when the
+// two straddle a new year.
+func mt940BookingDate(valueDate, entryDate string) (time.Time, error) {
+	value, err := time.Parse("060102", valueDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", valueDate)
+	}
+	if entryDate == "" {
+		return value, nil
+	}
+
+	booked, err := time.Parse("0102", entryDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", entryDate)
+	}
+
+	year := value.Year()
+	switch {
+	case value.Month() == time.December && booked.Month() == time.January:
+		year++
+	case value.Month() == time.January && booked.Month() == time.December:
+		year--
+	}
+
+	return time.Date(year, booked.Month(), booked.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// parseMT940Information extracts the counterparty name and remittance
+// information from a :86: field. It understands the German structured layout
+// ("166?00...?20...?32..."), the SEPA slash-code layout ("/NAME/.../REMI/...")
+// and otherwise treats the field as free text.
+func parseMT940Information(information string) (payee, remittance string) {
+	text := strings.TrimSpace(information)
+	if text == "" {
+		return "", ""
+	}
+
+	switch {
+	case len(text) > 3 && isDigits(text[:3]) && text[3] == '?':
+		return parseMT940Subfields(strings.ReplaceAll(text, "\n", ""))
+	case strings.HasPrefix(text, "/"):
+		return parseMT940SlashCodes(strings.ReplaceAll(text, "\n", ""))
+	default:
+		return "", strings.Join(strings.Fields(text), " ")
+	}
+}
+
+func parseMT940Subfields(text string) (payee, remittance string) {
+	var names, purpose []string
+	for _, part := range strings.Split(text, "?")[1:] {
+		if len(part) < 2 || !isDigits(part[:2]) {
+			continue
+		}
+		code, value := part[:2], strings.TrimSpace(part[2:])
+		switch {
+		case code == "32" || code == "33":
+			names = append(names, value)
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			purpose = append(purpose, value)
+		}
+	}
+
+	return strings.Join(trimAll(names), " "), strings.Join(trimAll(purpose), " ")
+}
+
+func parseMT940SlashCodes(text string) (payee, remittance string) {
+	parts := strings.Split(text, "/")
+	for i := 1; i+1 < len(parts); i++ {
+		switch parts[i] {
+		case "NAME":
+			payee = parts[i+1]
+		case "REMI":
+			// REMI may be structured as /REMI/USTD//text/ or plain /REMI/text/.
+			value := parts[i+1]
+			if (value == "USTD" || value == "STRD") && i+3 < len(parts) {
+				value = parts[i+3]
+			}
+			remittance = value
+		}
+	}
+
+	return strings.TrimSpace(payee), strings.TrimSpace(remittance)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,77 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMT940(t *testing.T) {
+	t.Run("German structured information", func(t *testing.T) {
+		statement := ":20:STARTUMS\r\n" +
+			":25:10020030/1234567\r\n" +
+			":28C:00001/001\r\n" +
+			":60F:C251231EUR1000,00\r\n" +
+			":61:2512310102DR42,50NDDTNONREF//BANKREF1\r\n" +
+			":86:105?00SEPA-LASTSCHRIFT?20EREF+123?21Strom Januar\r\n" +
+			"?32Stadtwerke?33Musterstadt\r\n" +
+			":61:2601020102CR2000,00NTRFNONREF//BANKREF2\r\n" +
+			":86:166?00GUTSCHRIFT?20Gehalt?32Employer AG\r\n" +
+			":61:2601020102CR2000,00NTRFNONREF//BANKREF2\r\n" +
+			":61:260103D5,00NMSC\r\n" +
+			":86:Card fee\r\n" +
+			":61:26AB03D5,00NMSC\r\n" +
+			":62F:C260103EUR2952,50\r\n" +
+			"-\r\n"
+
+		preview, err := ParseMT940(strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 3)
+
+		assert.Equal(t, 5, preview.Rows[0].Line)
+		assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), preview.Rows[0].Date)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 42.50, preview.Rows[0].Amount)
+		assert.Equal(t, "Stadtwerke Musterstadt", preview.Rows[0].Payee)
+		assert.Equal(t, "EREF+123 Strom Januar", preview.Rows[0].Description)
+		assert.Equal(t, "BANKREF1", preview.Rows[0].ExternalID)
+
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.Equal(t, 2000.0, preview.Rows[1].Amount)
+		assert.Equal(t, "Employer AG", preview.Rows[1].Payee)
+		assert.Equal(t, "BANKREF2", preview.Rows[1].ExternalID)
+
+		assert.Equal(t, time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), preview.Rows[2].Date)
+		assert.Equal(t, "Card fee", preview.Rows[2].Description)
+		assert.Empty(t, preview.Rows[2].ExternalID)
+
+		assert.Len(t, preview.Errors, 1)
+		assert.Equal(t, 13, preview.Errors[0].Line)
+	})
+
+	t.Run("SEPA slash codes inside a SWIFT envelope", func(t *testing.T) {
+		statement := "{1:F01BANKNL2AXXXX0000000000}{2:O9400000000000BANKNL2AXXXX00000000000000000000N}{4:\n" +
+			":20:940S260301\n" +
+			":25:NL00BANK0123456789\n" +
+			":61:260301C15,00NTRFEREF-77\n" +
+			":86:/TRTP/SEPA OVERBOEKING/IBAN/NL00BANK0000000001/BIC/BANKNL2A/NAME/J. Jansen/REMI/USTD//Lunch\n" +
+			"share/EREF/EREF-77\n" +
+			"-}"
+
+		preview, err := ParseMT940(strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Empty(t, preview.Errors)
+		assert.Len(t, preview.Rows, 1)
+		assert.Equal(t, "income", preview.Rows[0].Type)
+		assert.Equal(t, "J. Jansen", preview.Rows[0].Payee)
+		assert.Equal(t, "Lunchshare", preview.Rows[0].Description)
+		assert.Equal(t, "EREF-77", preview.Rows[0].ExternalID)
+	})
+
+	t.Run("Not an MT940 file", func(t *testing.T) {
+		_, err := ParseMT940(strings.NewReader("Date,Amount\n2026-03-01,10\n"))
+		assert.Error(t, err)
+	})
+}
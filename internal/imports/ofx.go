@@ -0,0 +1,170 @@
+package imports
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ofxDateLayouts lists the accepted DTPOSTED prefixes, most precise first.
+// Fractional seconds and the trailing [offset:TZ] are ignored.
+var ofxDateLayouts = []struct {
+	length int
+	layout string
+}{
+	{14, "20060102150405"},
+	{12, "200601021504"},
+	{8, "20060102"},
+}
+
+// ParseOFX reads an OFX 1.x (SGML) or 2.x (XML) statement, which includes
+// QFX files, and turns each STMTTRN record into a parsed row or a row error.
+// Rows carry the record's FITID as ExternalID so repeated imports can be
+// detected. An error is returned only when the file is not an OFX statement.
+func ParseOFX(r io.Reader) (*Preview, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read ofx file: %w", err)
+	}
+
+	document := string(content)
+	if !strings.Contains(strings.ToUpper(document), "<OFX>") {
+		return nil, fmt.Errorf("file is not an ofx statement")
+	}
+
+	preview := &Preview{Rows: []ParsedRow{}, Errors: []RowError{}}
+	seen := make(map[string]bool)
+
+	for _, record := range ofxRecords(document) {
+		row, err := parseOFXRecord(record.fields)
+		if err != nil {
+			preview.Errors = append(preview.Errors, RowError{Line: record.line, Message: err.Error()})
+			continue
+		}
+
+		// Some banks repeat a record when a statement spans several accounts
+		// or periods; the FITID identifies it either way.
+		if seen[row.ExternalID] {
+			continue
+		}
+		seen[row.ExternalID] = true
+
+		row.Line = record.line
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	return preview, nil
+}
+
+type ofxRecord struct {
+	line   int
+	fields map[string]string
+}
+
+// ofxRecords collects the leaf elements of every STMTTRN aggregate. OFX 1.x
+// leaves elements unclosed, so a value runs until the next tag; the same rule
+// reads OFX 2.x, where the next tag is the element's own closing tag.
+func ofxRecords(document string) []ofxRecord {
+	var (
+		records []ofxRecord
+		current *ofxRecord
+	)
+
+	line := 1
+	for position := 0; position < len(document); {
+		start := strings.IndexByte(document[position:], '<')
+		if start < 0 {
+			break
+		}
+		line += strings.Count(document[position:position+start], "\n")
+		position += start
+
+		end := strings.IndexByte(document[position:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(document[position+1 : position+end]))
+		tagLine := line
+		line += strings.Count(document[position:position+end], "\n")
+		position += end + 1
+
+		switch {
+		case tag == "STMTTRN":
+			current = &ofxRecord{line: tagLine, fields: make(map[string]string)}
+		case tag == "/STMTTRN":
+			if current != nil {
+				records = append(records, *current)
+				current = nil
+			}
+		case current != nil && tag != "" && !strings.HasPrefix(tag, "/") && !strings.HasPrefix(tag, "?") && !strings.HasPrefix(tag, "!"):
+			next := strings.IndexByte(document[position:], '<')
+			if next < 0 {
+				next = len(document) - position
+			}
+			value := strings.TrimSpace(html.UnescapeString(document[position : position+next]))
+			if value != "" {
+				current.fields[tag] = value
+			}
+		}
+	}
+
+	return records
+}
+
+func parseOFXRecord(fields map[string]string) (ParsedRow, error) {
+	externalID := fields["FITID"]
+	if externalID == "" {
+		return ParsedRow{}, fmt.Errorf("transaction has no FITID")
+	}
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return ParsedRow{}, err
+	}
+
+	raw := fields["TRNAMT"]
+	amount, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+	if err != nil {
+		return ParsedRow{}, fmt.Errorf("invalid amount %q", raw)
+	}
+	if amount == 0 {
+		return ParsedRow{}, fmt.Errorf("amount must not be zero")
+	}
+
+	// The sign of TRNAMT is authoritative, but some banks export unsigned
+	// amounts and rely on TRNTYPE alone.
+	switch strings.ToUpper(fields["TRNTYPE"]) {
+	case "DEBIT", "PAYMENT", "FEE", "SRVCHG", "ATM", "POS", "CHECK", "DIRECTDEBIT":
+		amount = -absolute(amount)
+	case "CREDIT", "DEP", "DIRECTDEP", "INT", "DIV":
+		amount = absolute(amount)
+	}
+
+	name := fields["NAME"]
+	row := NewParsedRow(date, amount, firstNonEmpty(fields["MEMO"], name))
+	row.Payee = name
+	row.ExternalID = externalID
+	return row, nil
+}
+
+func parseOFXDate(raw string) (time.Time, error) {
+	value := raw
+	if i := strings.IndexAny(value, ".["); i >= 0 {
+		value = value[:i]
+	}
+
+	for _, candidate := range ofxDateLayouts {
+		if len(value) != candidate.length {
+			continue
+		}
+		date, err := time.Parse(candidate.layout, value)
+		if err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
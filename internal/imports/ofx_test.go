@@ -0,0 +1,105 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOFX(t *testing.T) {
+	t.Run("OFX 1.x SGML", func(t *testing.T) {
+		statement := "OFXHEADER:100\n" +
+			"DATA:OFXSGML\n" +
+			"VERSION:102\n" +
+			"\n" +
+			"<OFX>\n" +
+			"<BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>\n" +
+			"<STMTTRN>\n" +
+			"<TRNTYPE>DEBIT\n" +
+			"<DTPOSTED>20260301120000.000[-5:EST]\n" +
+			"<TRNAMT>-42.50\n" +
+			"<FITID>2026030101\n" +
+			"<NAME>Corner Shop &amp; Deli\n" +
+			"<MEMO>Card payment\n" +
+			"</STMTTRN>\n" +
+			"<STMTTRN>\n" +
+			"<TRNTYPE>CREDIT\n" +
+			"<DTPOSTED>20260302\n" +
+			"<TRNAMT>1250.00\n" +
+			"<FITID>2026030201\n" +
+			"<NAME>Salary\n" +
+			"</STMTTRN>\n" +
+			"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1>\n" +
+			"</OFX>\n"
+
+		preview, err := ParseOFX(strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Empty(t, preview.Errors)
+		assert.Len(t, preview.Rows, 2)
+
+		assert.Equal(t, 7, preview.Rows[0].Line)
+		assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), preview.Rows[0].Date)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 42.50, preview.Rows[0].Amount)
+		assert.Equal(t, "Card payment", preview.Rows[0].Description)
+		assert.Equal(t, "Corner Shop & Deli", preview.Rows[0].Payee)
+		assert.Equal(t, "2026030101", preview.Rows[0].ExternalID)
+
+		assert.Equal(t, "income", preview.Rows[1].Type)
+		assert.Equal(t, 1250.0, preview.Rows[1].Amount)
+		assert.Equal(t, "Salary", preview.Rows[1].Description)
+		assert.Equal(t, "2026030201", preview.Rows[1].ExternalID)
+	})
+
+	t.Run("OFX 2.x XML", func(t *testing.T) {
+		statement := `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+    <STMTTRN>
+      <TRNTYPE>DEBIT</TRNTYPE>
+      <DTPOSTED>20260305</DTPOSTED>
+      <TRNAMT>19.99</TRNAMT>
+      <FITID>A-1</FITID>
+      <MEMO>Streaming subscription</MEMO>
+    </STMTTRN>
+    <STMTTRN>
+      <TRNTYPE>DEBIT</TRNTYPE>
+      <DTPOSTED>20260305</DTPOSTED>
+      <TRNAMT>19.99</TRNAMT>
+      <FITID>A-1</FITID>
+    </STMTTRN>
+    <STMTTRN>
+      <TRNTYPE>DEBIT</TRNTYPE>
+      <DTPOSTED>20260306</DTPOSTED>
+      <TRNAMT>-5.00</TRNAMT>
+    </STMTTRN>
+    <STMTTRN>
+      <TRNTYPE>DEBIT</TRNTYPE>
+      <DTPOSTED>yesterday</DTPOSTED>
+      <TRNAMT>-5.00</TRNAMT>
+      <FITID>A-3</FITID>
+    </STMTTRN>
+  </BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+		preview, err := ParseOFX(strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 1)
+		assert.Equal(t, "expense", preview.Rows[0].Type)
+		assert.Equal(t, 19.99, preview.Rows[0].Amount)
+		assert.Equal(t, "Streaming subscription", preview.Rows[0].Description)
+
+		assert.Len(t, preview.Errors, 2)
+		assert.Equal(t, 18, preview.Errors[0].Line)
+		assert.Contains(t, preview.Errors[0].Message, "FITID")
+		assert.Contains(t, preview.Errors[1].Message, "invalid date")
+	})
+
+	t.Run("Not an OFX file", func(t *testing.T) {
+		_, err := ParseOFX(strings.NewReader("Date,Amount\n2026-03-01,10\n"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package imports
+
+import (
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// ParsedRow is one statement entry ready to be turned into a transaction.
+// Amount is always positive; Type carries the direction of the money.
+type ParsedRow struct {
+	Line        int
+	Date        time.Time
+	Amount      float64
+	Type        string // "income" or "expense"
+	Description string // Remittance information or memo
+	Payee       string // Counterparty name, when the format carries one
+	ExternalID  string // Bank-assigned identifier, e.g. the OFX FITID
+}
+
+// RowError reports a statement entry that could not be parsed.
+type RowError struct {
+	Line    int
+	Message string
+}
+
+// Preview is the result of parsing a statement before anything is stored.
+type Preview struct {
+	Rows    []ParsedRow
+	Errors  []RowError
+	Skipped []ParsedRow // Rows whose external ID was imported before
+}
+
+// Result summarises a confirmed import. Skipped counts rows whose external
+// ID had already been imported; Duplicates lists imported transactions that
+// look like copies of ones already stored.
+type Result struct {
+	Imported   int
+	Skipped    int
+	Duplicates []models.DuplicateMatch
+}
+
+// NewParsedRow builds a row from a signed amount: money going out of the
+// account becomes an expense and money coming in becomes income.
+func NewParsedRow(date time.Time, signedAmount float64, description string) ParsedRow {
+	row := ParsedRow{
+		Date:        date,
+		Amount:      signedAmount,
+		Type:        "income",
+		Description: strings.TrimSpace(description),
+	}
+
+	if signedAmount < 0 {
+		row.Amount = -signedAmount
+		row.Type = "expense"
+	}
+
+	return row
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// LogMailer writes every message to the log instead of sending it, for
+// local development.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(_ context.Context, message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	m.logger.Info("email", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
+
+// FileMailer writes every message to its own .eml file in a directory
+// instead of sending it, so a developer can open them in a mail client.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("create mail directory: %w", err)
+	}
+
+	now := time.Now()
+	file, err := os.CreateTemp(m.dir, now.UTC().Format("20060102T150405")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("create mail file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(message.format(m.from, now)); err != nil {
+		return fmt.Errorf("write mail file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageValidate(t *testing.T) {
+	valid := Message{To: "jane@example.com", Subject: "Hello", Body: "Hi"}
+	if err := valid.validate(); err != nil {
+		t.Fatalf("expected valid message, got %v", err)
+	}
+
+	injected := Message{To: "jane@example.com\r\nBcc: eve@example.com", Subject: "Hello"}
+	if err := injected.validate(); err == nil {
+		t.Fatal("expected line breaks in headers to be rejected")
+	}
+
+	if err := (Message{Subject: "Hello"}).validate(); err == nil {
+		t.Fatal("expected a message without recipient to be rejected")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir, "Finance <no-reply@example.com>")
+
+	err := mailer.Send(context.Background(), Message{To: "jane@example.com", Subject: "Reset your password", Body: "line one\nline two"})
+	if err != nil {
+		t.Fatalf("expected message to be written, got %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read mail file: %v", err)
+	}
+	for _, want := range []string{"From: Finance <no-reply@example.com>\r\n", "To: jane@example.com\r\n", "Subject: Reset your password\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("expected mail file to contain %q, got %q", want, content)
+		}
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var logs bytes.Buffer
+	mailer := NewLogMailer(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	if err := mailer.Send(context.Background(), Message{To: "jane@example.com", Subject: "Hello", Body: "token abc"}); err != nil {
+		t.Fatalf("expected message to be logged, got %v", err)
+	}
+
+	if !strings.Contains(logs.String(), `"to":"jane@example.com"`) || !strings.Contains(logs.String(), "token abc") {
+		t.Fatalf("expected message in log, got %s", logs.String())
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveOneSMTPMessage(listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTPMailer("127.0.0.1", addr.Port, "", "", "Finance <no-reply@example.com>")
+
+	err = mailer.Send(context.Background(), Message{To: "Jane <jane@example.com>", Subject: "Verify your email", Body: "Hello"})
+	if err != nil {
+		t.Fatalf("expected message to be sent, got %v", err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{"MAIL FROM:<no-reply@example.com>", "RCPT TO:<jane@example.com>", "Subject: Verify your email", "Hello"} {
+			if !strings.Contains(transcript, want) {
+				t.Fatalf("expected SMTP session to contain %q, got %q", want, transcript)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP server did not receive the message")
+	}
+}
+
+// serveOneSMTPMessage plays the server side of a single SMTP session and
+// reports everything the client sent.
+func serveOneSMTPMessage(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	var transcript strings.Builder
+	_ = text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		transcript.WriteString(line + "\n")
+
+		switch command := strings.ToUpper(strings.Fields(line + " x")[0]); command {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, err := text.ReadDotLines()
+			if err != nil {
+				return
+			}
+			transcript.WriteString(strings.Join(data, "\n"))
+			_ = text.PrintfLine("250 queued")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			received <- transcript.String()
+			return
+		default:
+			_ = text.PrintfLine("250 OK")
+		}
+	}
+}
+
+type failingMailer struct {
+	sent chan Message
+}
+
+func (m failingMailer) Send(_ context.Context, message Message) error {
+	m.sent <- message
+	return errors.New("connection refused")
+}
+
+func TestBackgroundMailer(t *testing.T) {
+	var logs bytes.Buffer
+	next := failingMailer{sent: make(chan Message, 1)}
+	mailer := NewBackgroundMailer(next, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := mailer.Send(ctx, Message{To: "jane@example.com", Subject: "Hello"}); err != nil {
+		t.Fatalf("expected message to be accepted, got %v", err)
+	}
+	cancel()
+
+	select {
+	case message := <-next.sent:
+		if message.Subject != "Hello" {
+			t.Fatalf("unexpected message %+v", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected message to be handed on")
+	}
+
+	if err := mailer.Send(context.Background(), Message{To: "a@example.com\nb@example.com"}); err == nil {
+		t.Fatal("expected invalid message to be rejected straight away")
+	}
+}
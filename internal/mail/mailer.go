@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// validate refuses messages whose headers could be used to inject more
+// headers or recipients.
+func (m Message) validate() error {
+	if strings.TrimSpace(m.To) == "" {
+		return errors.New("message has no recipient")
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("message headers must not contain line breaks")
+	}
+	return nil
+}
+
+// format renders the message with its headers, as it is sent over SMTP.
+func (m Message) format(from string, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// backgroundSendTimeout bounds how long a message handed to a
+// BackgroundMailer may take to send.
+const backgroundSendTimeout = 30 * time.Second
+
+// BackgroundMailer hands messages to another mailer without waiting for
+// them to be sent. Failures are logged. Requests that send mail then take
+// as long whether or not a message goes out, which keeps endpoints from
+// revealing which email addresses are registered.
+type BackgroundMailer struct {
+	next   Mailer
+	logger *slog.Logger
+}
+
+func NewBackgroundMailer(next Mailer, logger *slog.Logger) *BackgroundMailer {
+	return &BackgroundMailer{next: next, logger: logger}
+}
+
+// Send checks the message and sends it in the background. The request's
+// cancellation does not stop a message that was accepted.
+func (m *BackgroundMailer) Send(ctx context.Context, message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundSendTimeout)
+	go func() {
+		defer cancel()
+		if err := m.next.Send(ctx, message); err != nil {
+			m.logger.Error("sending email failed", "subject", message.Subject, "error", err)
+		}
+	}()
+	return nil
+}
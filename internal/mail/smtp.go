@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailer sends email through an SMTP server. The connection is
+// upgraded with STARTTLS when the server offers it, and credentials, when
+// set, are only sent over TLS or to localhost.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		from: from,
+	}
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+// Send delivers the message. net/smtp does not take a context, so ctx is
+// only checked before connecting.
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := message.validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sender, err := envelopeAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("parse sender: %w", err)
+	}
+	recipient, err := envelopeAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("parse recipient: %w", err)
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, sender, []string{recipient}, message.format(m.from, time.Now())); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
+
+// envelopeAddress returns the bare address of "Name <address>" or of a
+// plain address, as the SMTP envelope expects it.
+func envelopeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Address, nil
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
@@ -10,8 +13,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(tokenManager auth.TokenManager) gin.HandlerFunc {
+// APIKeyHeader carries a personal API key. Keys are also accepted as a
+// Bearer token, for clients that can only set Authorization.
+const APIKeyHeader = "X-API-Key"
+
+func AuthMiddleware(tokenManager auth.TokenManager, revocations auth.RevocationChecker, apiKeys auth.APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			httpapi.AbortWithError(c, apperrors.Unauthorized("missing_authorization_header", "missing authorization header"))
@@ -24,16 +36,99 @@ func AuthMiddleware(tokenManager auth.TokenManager) gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(parts[1], auth.APIKeyPrefix) {
+			authenticateAPIKey(c, apiKeys, parts[1])
+			return
+		}
+
 		claims, err := tokenManager.ParseToken(parts[1])
 		if err != nil {
-			httpapi.AbortWithError(c, apperrors.Unauthorized("invalid_token", "invalid or expired token"))
+			httpapi.AbortWithError(c, invalidTokenError(err))
+			return
+		}
+
+		revoked, err := revocations.IsTokenRevoked(c.Request.Context(), claims)
+		if err != nil {
+			httpapi.AbortWithError(c, err)
+			return
+		}
+		if revoked {
+			httpapi.AbortWithError(c, apperrors.Unauthorized("token_revoked", "token has been revoked"))
 			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
-		observability.SetLoggerOnGinContext(c, observability.LoggerFromGinContext(c).With("user_id", claims.UserID))
-		observability.SetAuthenticatedUser(c.Request.Context(), claims.UserID)
+		c.Set("tokenClaims", claims)
+		c.Set("scopes", claims.Scopes())
+		setAuthenticatedUser(c, claims.UserID)
+		c.Next()
+	}
+}
+
+// RequireSession refuses requests made with an API key, for routes that
+// manage the account itself and so need the user to have logged in.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("tokenClaims"); !ok {
+			httpapi.AbortWithError(c, apperrors.Forbidden("session_required", "this endpoint cannot be used with an API key"))
+			return
+		}
 		c.Next()
 	}
 }
+
+// RequireScopes refuses requests whose token or API key lacks any of the
+// given scopes. It runs after AuthMiddleware.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := c.GetStringSlice("scopes")
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				// RFC 6750 names the scopes the request would need.
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+				httpapi.AbortWithError(c, insufficientScopeError(scopes))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeys auth.APIKeyAuthenticator, key string) {
+	apiKey, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		httpapi.AbortWithError(c, err)
+		return
+	}
+
+	c.Set("userID", apiKey.UserID)
+	c.Set("apiKey", apiKey)
+	c.Set("scopes", apiKey.ScopeList())
+	setAuthenticatedUser(c, apiKey.UserID)
+	c.Next()
+}
+
+func setAuthenticatedUser(c *gin.Context, userID uint) {
+	observability.SetLoggerOnGinContext(c, observability.LoggerFromGinContext(c).With("user_id", userID))
+	observability.SetAuthenticatedUser(c.Request.Context(), userID)
+}
+
+func insufficientScopeError(scopes []string) error {
+	message := "this request needs the " + scopes[0] + " scope"
+	if len(scopes) > 1 {
+		message = "this request needs the scopes " + strings.Join(scopes, ", ")
+	}
+	return apperrors.Forbidden("insufficient_scope", message)
+}
+
+// invalidTokenError turns a parse failure into a 401 whose code says why
+// the token was rejected.
+func invalidTokenError(err error) error {
+	var tokenErr *auth.TokenError
+	if errors.As(err, &tokenErr) {
+		return apperrors.Unauthorized(tokenErr.Code, tokenErr.Message)
+	}
+
+	return apperrors.Unauthorized("invalid_token", "invalid or expired token")
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type revocationCheckerFunc func(ctx context.Context, claims *auth.Claims) (bool, error)
+
+func (f revocationCheckerFunc) IsTokenRevoked(ctx context.Context, claims *auth.Claims) (bool, error) {
+	return f(ctx, claims)
+}
+
+type apiKeyAuthenticatorFunc func(ctx context.Context, key string) (*models.APIKey, error)
+
+func (f apiKeyAuthenticatorFunc) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	return f(ctx, key)
+}
+
+// noAPIKeys fails the test if a request is treated as using an API key.
+func noAPIKeys(t *testing.T) auth.APIKeyAuthenticator {
+	return apiKeyAuthenticatorFunc(func(context.Context, string) (*models.APIKey, error) {
+		t.Error("unexpected API key lookup")
+		return nil, apperrors.Unauthorized("invalid_api_key", "invalid API key")
+	})
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	token, err := tokenManager.GenerateToken(&models.User{ID: 7, Email: "john@example.com"}, auth.Scopes)
+	assert.NoError(t, err)
+
+	serve := func(checker auth.RevocationChecker, authorization string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(AuthMiddleware(tokenManager, checker, noAPIKeys(t)))
+		router.GET("/test", func(c *gin.Context) {
+			assert.Equal(t, uint(7), c.GetUint("userID"))
+			claims, ok := c.Get("tokenClaims")
+			assert.True(t, ok)
+			assert.NotEmpty(t, claims.(*auth.Claims).ID)
+			assert.Equal(t, auth.Scopes, c.GetStringSlice("scopes"))
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	notRevoked := revocationCheckerFunc(func(context.Context, *auth.Claims) (bool, error) { return false, nil })
+
+	t.Run("accepts a valid token", func(t *testing.T) {
+		rec := serve(notRevoked, "Bearer "+token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("rejects a missing header", func(t *testing.T) {
+		rec := serve(notRevoked, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "missing_authorization_header")
+	})
+
+	t.Run("says why a token was rejected", func(t *testing.T) {
+		expired, err := auth.NewJWTManager("secret", -time.Minute).GenerateToken(&models.User{ID: 7}, auth.Scopes)
+		assert.NoError(t, err)
+
+		rec := serve(notRevoked, "Bearer "+expired)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token_expired")
+
+		rec = serve(notRevoked, "Bearer "+token+"x")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_token")
+	})
+
+	t.Run("rejects a revoked token", func(t *testing.T) {
+		revoked := revocationCheckerFunc(func(context.Context, *auth.Claims) (bool, error) { return true, nil })
+
+		rec := serve(revoked, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token_revoked")
+	})
+
+	t.Run("fails when revocation cannot be checked", func(t *testing.T) {
+		failing := revocationCheckerFunc(func(context.Context, *auth.Claims) (bool, error) { return false, errors.New("db down") })
+
+		rec := serve(failing, "Bearer "+token)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestAuthMiddlewareAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	notRevoked := revocationCheckerFunc(func(context.Context, *auth.Claims) (bool, error) { return false, nil })
+	apiKeys := apiKeyAuthenticatorFunc(func(_ context.Context, key string) (*models.APIKey, error) {
+		if key != "pft_valid" {
+			return nil, apperrors.Unauthorized("invalid_api_key", "invalid API key")
+		}
+		return &models.APIKey{ID: 3, UserID: 7, Scopes: "reports:read"}, nil
+	})
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(AuthMiddleware(tokenManager, notRevoked, apiKeys))
+		router.GET("/test", func(c *gin.Context) {
+			assert.Equal(t, uint(7), c.GetUint("userID"))
+			apiKey, ok := c.Get("apiKey")
+			assert.True(t, ok)
+			assert.Equal(t, uint(3), apiKey.(*models.APIKey).ID)
+			assert.Equal(t, []string{auth.ScopeReportsRead}, c.GetStringSlice("scopes"))
+			_, ok = c.Get("tokenClaims")
+			assert.False(t, ok)
+			c.Status(http.StatusOK)
+		})
+		router.GET("/session", RequireSession(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		path := "/test"
+		if header == "" {
+			header, path = APIKeyHeader, "/session"
+		}
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("accepts a key in its own header", func(t *testing.T) {
+		rec := serve(APIKeyHeader, "pft_valid")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("accepts a key as a Bearer token", func(t *testing.T) {
+		rec := serve("Authorization", "Bearer pft_valid")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("rejects an unknown key", func(t *testing.T) {
+		rec := serve(APIKeyHeader, "pft_other")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_api_key")
+	})
+
+	t.Run("keeps keys out of session-only routes", func(t *testing.T) {
+		rec := serve("", "pft_valid")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "session_required")
+	})
+
+	t.Run("limits keys to their scopes", func(t *testing.T) {
+		router := gin.New()
+		router.Use(AuthMiddleware(tokenManager, notRevoked, apiKeys))
+		router.GET("/reports", RequireScopes(auth.ScopeReportsRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.GET("/transactions", RequireScopes(auth.ScopeTransactionsRead), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		for path, want := range map[string]int{"/reports": http.StatusOK, "/transactions": http.StatusForbidden} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set(APIKeyHeader, "pft_valid")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, want, rec.Code, path)
+		}
+	})
+}
+
+func TestRequireScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(granted []string, required ...string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("scopes", granted)
+			c.Next()
+		})
+		router.GET("/test", RequireScopes(required...), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+		return rec
+	}
+
+	t.Run("allows a request with every required scope", func(t *testing.T) {
+		rec := serve([]string{auth.ScopeBudgetsRead, auth.ScopeTransactionsRead}, auth.ScopeTransactionsRead)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("refuses a request missing a scope", func(t *testing.T) {
+		rec := serve([]string{auth.ScopeTransactionsRead}, auth.ScopeTransactionsRead, auth.ScopeBudgetsRead)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "insufficient_scope")
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="transactions:read budgets:read"`, rec.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("refuses a request without scopes", func(t *testing.T) {
+		rec := serve(nil, auth.ScopeReportsRead)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	AccountTypeChecking   = "checking"
+	AccountTypeSavings    = "savings"
+	AccountTypeCreditCard = "credit_card"
+	AccountTypeCash       = "cash"
+)
+
+type Account struct {
+	ID             uint    `gorm:"primaryKey"`
+	UserID         uint    `gorm:"not null;index"`
+	Name           string  `gorm:"size:100;not null"`
+	Type           string  `gorm:"size:20;not null"` // "checking", "savings", "credit_card", "cash"
+	Currency       string  `gorm:"size:3;not null"`  // ISO 4217 code, e.g. "EUR"
+	OpeningBalance float64 `gorm:"not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// AccountBalance is an account together with its balance computed from
+// the opening balance and every transaction booked up to AsOf.
+type AccountBalance struct {
+	Account Account
+	Balance float64
+	AsOf    time.Time
+}
+
+func IsValidAccountType(accountType string) bool {
+	switch accountType {
+	case AccountTypeChecking, AccountTypeSavings, AccountTypeCreditCard, AccountTypeCash:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey lets scripts and integrations call the API as a user without the
+// user's password. Only a hash of the key is stored; the key itself is
+// shown once, when it is created.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16;not null"` // Start of the key, to tell keys apart
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     string     `gorm:"size:500;not null"` // Comma-separated scopes
+	ExpiresAt  *time.Time // Nil for a key that does not expire
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// ScopeList splits the key's comma-separated scopes.
+func (k APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// Active reports whether the key is neither revoked nor expired at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,15 @@
+package models
+
+// ArchiveData is a user's data as read from an archive, still carrying the
+// IDs it had on the instance that exported it. References between records,
+// such as a transaction's category or tags, use those IDs too; restoring the
+// data assigns new ones. A transaction's Tags only have their ID set.
+type ArchiveData struct {
+	Categories   []Category
+	Accounts     []Account
+	Tags         []Tag
+	Budgets      []Budget
+	Rules        []Rule
+	Transfers    []Transfer
+	Transactions []Transaction
+}
@@ -0,0 +1,33 @@
+package models
+
+// ClassifierCategory holds a user's category-suggestion training totals for
+// one category: how many transactions were learned and how many tokens they had.
+type ClassifierCategory struct {
+	UserID     uint `gorm:"primaryKey"`
+	CategoryID uint `gorm:"primaryKey"`
+	Documents  int  `gorm:"not null;default:0"`
+	Tokens     int  `gorm:"not null;default:0"`
+}
+
+// ClassifierToken counts how many learned transactions of a category contain a token.
+type ClassifierToken struct {
+	UserID      uint   `gorm:"primaryKey"`
+	CategoryID  uint   `gorm:"primaryKey"`
+	Token       string `gorm:"primaryKey;size:50"`
+	Occurrences int    `gorm:"not null;default:0"`
+}
+
+// ClassifierExample is one transaction's contribution to the classifier.
+// Weight is 1 to learn the example and -1 to forget it again.
+type ClassifierExample struct {
+	CategoryID uint
+	Tokens     []string
+	Weight     int
+}
+
+// CategorySuggestion is a category the classifier predicts for a transaction,
+// with its estimated probability between 0 and 1.
+type CategorySuggestion struct {
+	CategoryID uint
+	Confidence float64
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LoginThrottle counts recent failed logins for one account or one client
+// address. Keys are stored hashed so the table does not collect the email
+// addresses people mistype. While LockedUntil is in the future, logins for
+// the key are refused without looking at the password.
+type LoginThrottle struct {
+	ID            uint      `gorm:"primaryKey"`
+	KeyHash       string    `gorm:"size:64;not null;uniqueIndex"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null;index"`
+	LockedUntil   *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// OIDCLoginRequest is a login through the OpenID Connect provider that was
+// started but has not come back yet. It is looked up by the hash of the
+// state sent to the provider and used once.
+type OIDCLoginRequest struct {
+	ID           uint      `gorm:"primaryKey"`
+	StateHash    string    `gorm:"size:64;not null;uniqueIndex"`
+	Nonce        string    `gorm:"size:64;not null"`
+	CodeVerifier string    `gorm:"size:128;not null"` // PKCE verifier for the authorization code
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// TableName keeps GORM from splitting OIDC into single letters.
+func (OIDCLoginRequest) TableName() string {
+	return "oidc_login_requests"
+}
+
+// UserIdentity links a user to their account at an OpenID Connect
+// provider, which names it by its subject.
+type UserIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Issuer    string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject   string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	CreatedAt time.Time
+}
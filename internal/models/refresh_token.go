@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RefreshToken is a long-lived token that can be exchanged once for a new
+// access and refresh token pair. Only a hash of the token is stored. Tokens
+// rotated from the same login share a family, which is revoked as a whole
+// when a token is used twice. Scope holds the space-separated scopes the
+// login asked for, which every access token of the family is limited to.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	FamilyID  string    `gorm:"size:64;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	Scope     string    `gorm:"size:255;not null;default:''"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
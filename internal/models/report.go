@@ -0,0 +1,25 @@
+package models
+
+// CategoryTotal is the summed amount of one transaction type within a category.
+type CategoryTotal struct {
+	CategoryID uint
+	Type       string
+	Total      float64
+}
+
+// TagTotal is the summed amount of one transaction type carrying a tag.
+type TagTotal struct {
+	TagID uint
+	Name  string
+	Type  string
+	Total float64
+}
+
+// TransactionSummary aggregates income and expenses over a set of
+// transactions. Transfers between a user's own accounts are not included.
+type TransactionSummary struct {
+	Income     float64
+	Expense    float64
+	Categories []CategoryTotal
+	Tags       []TagTotal
+}
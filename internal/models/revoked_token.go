@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RevokedToken records an access token that was logged out before it
+// expired. Rows are only needed until ExpiresAt, after which the token is
+// rejected anyway.
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenID   string    `gorm:"size:64;not null;uniqueIndex"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Rule categorises, tags and names the payee of transactions automatically.
+// Every condition that is set must match; actions only fill in what the
+// transaction does not already have, unless rules are re-applied on demand.
+type Rule struct {
+	ID            uint     `gorm:"primaryKey"`
+	UserID        uint     `gorm:"not null;index"`
+	Name          string   `gorm:"size:100;not null"`
+	Priority      int      `gorm:"not null;default:0"` // Lower priorities are evaluated first
+	NoteContains  string   `gorm:"size:255"`           // Case-insensitive substring of the note
+	NotePattern   string   `gorm:"size:255"`           // Regular expression matched against the note
+	PayeeContains string   `gorm:"size:255"`           // Case-insensitive substring of the payee
+	PayeePattern  string   `gorm:"size:255"`           // Regular expression matched against the payee
+	MinAmount     *float64 // Inclusive lower bound, nil for none
+	MaxAmount     *float64 // Inclusive upper bound, nil for none
+	Type          string   `gorm:"size:10"` // "income", "expense" or empty for both
+	CategoryID    *uint    // Category to set, nil to leave it unchanged
+	Tags          string   `gorm:"size:500"` // Comma-separated tag names to add
+	Payee         string   `gorm:"size:255"` // Payee to set, empty to leave it unchanged
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TagNames splits the rule's comma-separated tag list.
+func (r Rule) TagNames() []string {
+	if r.Tags == "" {
+		return nil
+	}
+	return strings.Split(r.Tags, ",")
+}
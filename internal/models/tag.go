@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Tag is a user-defined label that can be attached to any number of
+// transactions, independently of their category.
+type Tag struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name"` // Lowercase, e.g. "vacation-2026"
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -5,17 +5,50 @@ import (
 )
 
 type Transaction struct {
-	ID         uint      `gorm:"primaryKey"`
-	UserID     uint      `gorm:"not null;index"`
-	Type       string    `gorm:"size:10;not null"` // "income" or "expense"
-	Amount     float64   `gorm:"not null"`
-	CategoryID uint      `gorm:"not null;index"`
-	Date       time.Time `gorm:"not null"`
-	Note       string    `gorm:"size:255"`
+	ID         uint               `gorm:"primaryKey"`
+	UserID     uint               `gorm:"not null;index"`
+	Type       string             `gorm:"size:10;not null"` // "income", "expense" or "transfer"
+	Amount     float64            `gorm:"not null"`         // Transfer legs are signed: negative debit, positive credit
+	CategoryID uint               `gorm:"not null;index"`   // First split's category when the transaction is split
+	AccountID  *uint              `gorm:"index"`            // Nullable - transactions recorded before accounts existed
+	TransferID *uint              `gorm:"index"`            // Set on both legs of a transfer
+	Date       time.Time          `gorm:"not null"`
+	Note       string             `gorm:"size:255"`
+	Payee      string             `gorm:"size:255"` // Counterparty, filled from bank statements or by the user
+	ExternalID string             `gorm:"size:255"` // Bank identifier of imported entries, e.g. the OFX FITID
+	Splits     []TransactionSplit `gorm:"foreignKey:TransactionID"`
+	Tags       []Tag              `gorm:"many2many:transaction_tags"`
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
 
+// TransactionSplit assigns part of a transaction's amount to a category.
+// The split amounts of a transaction always add up to its total amount.
+type TransactionSplit struct {
+	ID            uint    `gorm:"primaryKey"`
+	TransactionID uint    `gorm:"not null;index"`
+	CategoryID    uint    `gorm:"not null;index"`
+	Amount        float64 `gorm:"not null"`
+	Note          string  `gorm:"size:255"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Transfer moves money between two of a user's accounts. It is booked as a
+// pair of linked "transfer" transactions that are created and deleted together.
+type Transfer struct {
+	ID            uint          `gorm:"primaryKey"`
+	UserID        uint          `gorm:"not null;index"`
+	FromAccountID uint          `gorm:"not null"`
+	ToAccountID   uint          `gorm:"not null"`
+	Amount        float64       `gorm:"not null"`
+	Date          time.Time     `gorm:"not null"`
+	Note          string        `gorm:"size:255"`
+	Legs          []Transaction `gorm:"foreignKey:TransferID"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
 type RecurringTransaction struct {
 	ID          uint       `gorm:"primaryKey"`
 	UserID      uint       `gorm:"not null;index"`
@@ -27,3 +60,16 @@ type RecurringTransaction struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+// DuplicateMatch lists stored transactions that look like duplicates of
+// Transaction: same type, amount and normalised note, dated close together.
+type DuplicateMatch struct {
+	Transaction Transaction
+	Candidates  []Transaction
+}
+
+// DuplicateGroup is a set of stored transactions that look like copies of
+// each other.
+type DuplicateGroup struct {
+	Transactions []Transaction
+}
@@ -12,4 +12,35 @@ type User struct {
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
 	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions,omitempty"`
+
+	// EmailVerifiedAt is when the user proved they can read mail sent to
+	// Email. It is cleared when the email address changes.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// DeletionScheduledAt is when the account and all its data will be
+	// erased, if the user asked for that.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+
+	// TokensInvalidBefore rejects every access token issued before it, which
+	// logs the user out everywhere.
+	TokensInvalidBefore *time.Time `json:"-"`
+
+	// TOTPSecret is the authenticator app secret. It is set when enrolment
+	// starts and only used once TOTPEnabledAt records that the first code
+	// was confirmed.
+	TOTPSecret    string     `gorm:"size:64;not null;default:''" json:"-"`
+	TOTPEnabledAt *time.Time `json:"-"`
+	// TOTPLastStep is the time step of the last accepted code, so a code
+	// cannot be used twice.
+	TOTPLastStep int64 `gorm:"not null;default:0" json:"-"`
+}
+
+// EmailVerified reports whether the current email address was verified.
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// MFAEnabled reports whether logging in needs a second factor.
+func (u *User) MFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
@@ -0,0 +1,60 @@
+package observability
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Label values the auth metrics are created with, so their series show up
+// before the first failed login.
+var (
+	failedLoginReasons = []string{"invalid_credentials", "invalid_mfa_code", "locked_out"}
+	lockoutScopes      = []string{"account", "address"}
+)
+
+// AuthMetrics counts failed logins and the lockouts they cause.
+type AuthMetrics struct {
+	failedLogins *prometheus.CounterVec
+	lockouts     *prometheus.CounterVec
+}
+
+func NewAuthMetrics(registerer prometheus.Registerer) *AuthMetrics {
+	failedLogins := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "personal_finance_tracker",
+			Subsystem: "auth",
+			Name:      "failed_logins_total",
+			Help:      "Total number of failed login attempts, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	lockouts := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "personal_finance_tracker",
+			Subsystem: "auth",
+			Name:      "login_lockouts_total",
+			Help:      "Total number of times an account or client address was locked out after failed logins.",
+		},
+		[]string{"scope"},
+	)
+
+	for _, reason := range failedLoginReasons {
+		failedLogins.WithLabelValues(reason)
+	}
+	for _, scope := range lockoutScopes {
+		lockouts.WithLabelValues(scope)
+	}
+
+	registerer.MustRegister(failedLogins, lockouts)
+
+	return &AuthMetrics{
+		failedLogins: failedLogins,
+		lockouts:     lockouts,
+	}
+}
+
+func (m *AuthMetrics) FailedLogin(reason string) {
+	m.failedLogins.WithLabelValues(reason).Inc()
+}
+
+func (m *AuthMetrics) LoginLockout(scope string) {
+	m.lockouts.WithLabelValues(scope).Inc()
+}
@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+)
+
+// IDToken holds the claims of an ID token that passed VerifyIDToken.
+type IDToken struct {
+	Issuer          string        `json:"iss"`
+	Subject         string        `json:"sub"`
+	Audience        auth.Audience `json:"aud"`
+	AuthorizedParty string        `json:"azp"`
+	Nonce           string        `json:"nonce"`
+	IssuedAt        int64         `json:"iat"`
+	ExpiresAt       int64         `json:"exp"`
+	Email           string        `json:"email"`
+	EmailVerified   claimBool     `json:"email_verified"`
+	Name            string        `json:"name"`
+}
+
+// claimBool reads a boolean claim that some providers send as the string
+// "true" or "false".
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = claimBool(value)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*b = claimBool(text == "true")
+	return nil
+}
+
+type idTokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// VerifyIDToken checks the ID token's signature against the provider's
+// keys and its claims as OpenID Connect Core section 3.1.3.7 asks: that
+// the provider issued it, for this client, recently, and for the login
+// that nonce belongs to.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*IDToken, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	var header idTokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidIDToken)
+	}
+	// Only asymmetric algorithms: the token must come from the provider,
+	// not from anyone else who knows the client secret.
+	if header.Algorithm != auth.AlgorithmRS256 && header.Algorithm != auth.AlgorithmEdDSA {
+		return nil, fmt.Errorf("%w: algorithm %q is not accepted", ErrInvalidIDToken, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+	if err := p.verifySignature(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var token IDToken
+	if err := decodeSegment(parts[1], &token); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidIDToken)
+	}
+	if err := p.validateClaims(&token, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// verifySignature finds the key the token was signed with, fetching the
+// provider's keys again once if it is not known, since providers rotate
+// their keys.
+func (p *Provider) verifySignature(ctx context.Context, header idTokenHeader, signingInput string, signature []byte) error {
+	for _, refresh := range []bool{false, true} {
+		keys, err := p.signingKeys(ctx, refresh)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, key := range keys {
+			if key.Algorithm != header.Algorithm || (header.KeyID != "" && key.ID != header.KeyID) {
+				continue
+			}
+			found = true
+			if key.Verify(signingInput, signature) {
+				return nil
+			}
+		}
+		if found {
+			return fmt.Errorf("%w: signature is invalid", ErrInvalidIDToken)
+		}
+	}
+
+	return fmt.Errorf("%w: signed with an unknown key", ErrInvalidIDToken)
+}
+
+func (p *Provider) validateClaims(token *IDToken, nonce string, now time.Time) error {
+	switch {
+	case token.Issuer != p.config.IssuerURL:
+		return fmt.Errorf("%w: issued by %q", ErrInvalidIDToken, token.Issuer)
+	case !token.Audience.Contains(p.config.ClientID):
+		return fmt.Errorf("%w: not meant for this client", ErrInvalidIDToken)
+	case token.AuthorizedParty != "" && token.AuthorizedParty != p.config.ClientID:
+		return fmt.Errorf("%w: issued to another client", ErrInvalidIDToken)
+	case token.Subject == "":
+		return fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	case token.ExpiresAt == 0 || !now.Before(time.Unix(token.ExpiresAt, 0).Add(p.clockSkew)):
+		return fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	case token.IssuedAt == 0 || time.Unix(token.IssuedAt, 0).After(now.Add(p.clockSkew)):
+		return fmt.Errorf("%w: issue time is missing or in the future", ErrInvalidIDToken)
+	case nonce == "" || token.Nonce != nonce:
+		return fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, into any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
@@ -0,0 +1,295 @@
+// Package oidc logs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE. It reads the provider's metadata from
+// its discovery document and checks the ID tokens it returns against the
+// provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+)
+
+// keyRefreshInterval is the least time between two fetches of the
+// provider's keys, so tokens naming unknown keys cannot make us hammer it.
+const keyRefreshInterval = time.Minute
+
+// maxResponseSize caps what is read from the provider.
+const maxResponseSize = 1 << 20
+
+var (
+	// ErrInvalidIDToken is wrapped by every reason an ID token is refused.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+	// ErrCodeRejected means the token endpoint refused the authorization
+	// code, which happens when it was used or has expired.
+	ErrCodeRejected = errors.New("authorization code rejected")
+)
+
+// Config names the provider and how this app is registered with it.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Metadata is the part of the provider's discovery document that the
+// login needs.
+type Metadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// Provider talks to one OpenID Connect provider. Its metadata is
+// discovered on first use, so the app starts even when the provider is
+// down, and its keys are fetched again when a token is signed with a key
+// that is not known yet.
+type Provider struct {
+	config    Config
+	client    *http.Client
+	clockSkew time.Duration
+
+	mu          sync.Mutex
+	metadata    *Metadata
+	keys        []auth.SigningKey
+	keysFetched time.Time
+}
+
+// ProviderOption configures optional behaviour of a Provider.
+type ProviderOption func(*Provider)
+
+// WithHTTPClient sets the client used to reach the provider.
+func WithHTTPClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithClockSkew tolerates the provider's clock being off by up to skew
+// when checking when a token was issued and when it expires.
+func WithClockSkew(skew time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.clockSkew = skew
+	}
+}
+
+func NewProvider(config Config, options ...ProviderOption) *Provider {
+	provider := &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, option := range options {
+		option(provider)
+	}
+
+	return provider
+}
+
+// Issuer is the provider's issuer identifier, as in the iss claim of its
+// tokens.
+func (p *Provider) Issuer() string {
+	return p.config.IssuerURL
+}
+
+// AuthCodeURL returns where to send the user to log in. The provider sends
+// state back unchanged, puts nonce in the ID token and only hands out
+// tokens for the code to whoever knows codeVerifier.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse authorization endpoint: %w", err)
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+// Exchange trades an authorization code for the user's ID token, which
+// must carry nonce.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDToken, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.config.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// RFC 6749 section 2.3.1 form-encodes both before joining them.
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	if body.Error == "invalid_grant" {
+		return nil, fmt.Errorf("%w: %s", ErrCodeRejected, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d %s: %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.VerifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// CodeChallenge is the S256 PKCE challenge for a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// discover returns the provider's metadata, fetching it on first use. The
+// lock only guards the cached document, so a slow provider does not hold
+// up logins that already have what they need.
+func (p *Provider) discover(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	metadata := p.metadata
+	p.mu.Unlock()
+	if metadata != nil {
+		return metadata, nil
+	}
+
+	metadata = &Metadata{}
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, metadata); err != nil {
+		return nil, fmt.Errorf("discover provider: %w", err)
+	}
+
+	// OpenID Connect Discovery section 4.3: the document must be about the
+	// issuer it was fetched for.
+	if metadata.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("discover provider: issuer %q does not match %q", metadata.Issuer, p.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("discover provider: authorization, token or JWKS endpoint missing")
+	}
+	if len(metadata.CodeChallengeMethodsSupported) > 0 && !slices.Contains(metadata.CodeChallengeMethodsSupported, "S256") {
+		return nil, errors.New("discover provider: S256 PKCE challenges are not supported")
+	}
+
+	// Concurrent first logins may each fetch the document; the first one
+	// stored is kept.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata == nil {
+		p.metadata = metadata
+	}
+	return p.metadata, nil
+}
+
+// signingKeys returns the provider's keys, fetching them when there are
+// none yet or when refresh is set and they were not fetched just now. Like
+// discover, it does not hold the lock while fetching.
+func (p *Provider) signingKeys(ctx context.Context, refresh bool) ([]auth.SigningKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	keys, fetched := p.keys, p.keysFetched
+	p.mu.Unlock()
+	if keys != nil && (!refresh || time.Since(fetched) < keyRefreshInterval) {
+		return keys, nil
+	}
+
+	var set auth.JSONWebKeySet
+	if err := p.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch provider keys: %w", err)
+	}
+
+	keys = make([]auth.SigningKey, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of types this app cannot verify are skipped; a token signed
+		// with one of them fails as signed with an unknown key.
+		if key, err := jwk.PublicKey(); err == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = keys
+	p.keysFetched = time.Now()
+	return keys, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", target, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(into)
+}
@@ -0,0 +1,277 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testClientID     = "finance-tracker"
+	testClientSecret = "s3cr3t/+"
+	testRedirectURL  = "https://app.example.com/api/v1/oidc/callback"
+)
+
+func newTestProvider(server *oidctest.Server) *Provider {
+	return NewProvider(Config{
+		IssuerURL:    server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+}
+
+func authorize(t *testing.T, server *oidctest.Server, provider *Provider, verifier, nonce string) string {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	assert.Equal(t, "state-1", state)
+	return code
+}
+
+func TestProviderAuthCodeURL(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	provider := newTestProvider(server)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+
+	if assert.NoError(t, err) {
+		parsed, _ := url.Parse(authURL)
+		query := parsed.Query()
+		assert.Equal(t, server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, testClientID, query.Get("client_id"))
+		assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+		assert.Equal(t, "openid email profile", query.Get("scope"))
+		assert.Equal(t, "state-1", query.Get("state"))
+		assert.Equal(t, "nonce-1", query.Get("nonce"))
+		assert.Equal(t, CodeChallenge("verifier-1"), query.Get("code_challenge"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B.
+	assert.Equal(t,
+		"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+		CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func TestProviderExchange(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	server.SetUser(oidctest.User{Subject: "abc", Email: "ann@example.com", EmailVerified: true, Name: "Ann"})
+	provider := newTestProvider(server)
+	code := authorize(t, server, provider, "verifier-1", "nonce-1")
+
+	token, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, server.URL, token.Issuer)
+		assert.Equal(t, "abc", token.Subject)
+		assert.Equal(t, "ann@example.com", token.Email)
+		assert.True(t, bool(token.EmailVerified))
+		assert.Equal(t, "Ann", token.Name)
+	}
+
+	_, err = provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrCodeRejected, "codes are single use")
+}
+
+func TestProviderExchangeRejectsWrongVerifier(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	provider := newTestProvider(server)
+	code := authorize(t, server, provider, "verifier-1", "nonce-1")
+
+	_, err := provider.Exchange(context.Background(), code, "verifier-2", "nonce-1")
+
+	assert.ErrorIs(t, err, ErrCodeRejected)
+}
+
+func TestProviderExchangeRejectsWrongClientSecret(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, "another secret")
+	provider := newTestProvider(server)
+	code := authorize(t, server, provider, "verifier-1", "nonce-1")
+
+	_, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCodeRejected))
+	assert.False(t, errors.Is(err, ErrInvalidIDToken))
+}
+
+func TestProviderExchangeRejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		nonce  string
+		modify func(claims map[string]any)
+	}{
+		{name: "wrong nonce", nonce: "nonce-2"},
+		{name: "other issuer", nonce: "nonce-1", modify: func(claims map[string]any) { claims["iss"] = "https://evil.example.com" }},
+		{name: "other audience", nonce: "nonce-1", modify: func(claims map[string]any) { claims["aud"] = "someone-else" }},
+		{name: "other authorized party", nonce: "nonce-1", modify: func(claims map[string]any) {
+			claims["aud"] = []string{testClientID, "someone-else"}
+			claims["azp"] = "someone-else"
+		}},
+		{name: "expired", nonce: "nonce-1", modify: func(claims map[string]any) { claims["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "issued in the future", nonce: "nonce-1", modify: func(claims map[string]any) { claims["iat"] = time.Now().Add(time.Hour).Unix() }},
+		{name: "no subject", nonce: "nonce-1", modify: func(claims map[string]any) { delete(claims, "sub") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := oidctest.NewServer(t, testClientID, testClientSecret)
+			server.ModifyClaims(tt.modify)
+			provider := newTestProvider(server)
+			code := authorize(t, server, provider, "verifier-1", "nonce-1")
+
+			_, err := provider.Exchange(context.Background(), code, "verifier-1", tt.nonce)
+
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestProviderVerifyIDTokenAcceptsListedAudience(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	provider := newTestProvider(server)
+	raw, _ := server.SignIDToken(map[string]any{
+		"iss": server.URL, "sub": "abc", "aud": []string{"someone-else", testClientID}, "azp": testClientID,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(), "nonce": "nonce-1",
+		"email_verified": "true",
+	})
+
+	token, err := provider.VerifyIDToken(context.Background(), raw, "nonce-1")
+
+	if assert.NoError(t, err) {
+		assert.True(t, bool(token.EmailVerified), "string booleans are read")
+	}
+}
+
+func TestProviderVerifyIDTokenRejectsTampering(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	provider := newTestProvider(server)
+	raw, _ := server.SignIDToken(map[string]any{
+		"iss": server.URL, "sub": "abc", "aud": testClientID,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(), "nonce": "nonce-1",
+	})
+	parts := strings.Split(raw, ".")
+	forgedClaims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`))
+
+	tokens := map[string]string{
+		"changed claims": parts[0] + "." + forgedClaims + "." + parts[2],
+		"unsigned":       base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + forgedClaims + ".",
+		"malformed":      "not-a-token",
+	}
+	for name, token := range tokens {
+		_, err := provider.VerifyIDToken(context.Background(), token, "nonce-1")
+		assert.ErrorIs(t, err, ErrInvalidIDToken, name)
+	}
+}
+
+func TestProviderRefetchesKeysAfterRotation(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	provider := newTestProvider(server)
+
+	code := authorize(t, server, provider, "verifier-1", "nonce-1")
+	_, err := provider.Exchange(context.Background(), code, "verifier-1", "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.KeyRequests())
+
+	server.RotateKey(t)
+	provider.keysFetched = time.Now().Add(-keyRefreshInterval)
+	code = authorize(t, server, provider, "verifier-2", "nonce-2")
+	_, err = provider.Exchange(context.Background(), code, "verifier-2", "nonce-2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.KeyRequests())
+
+	server.RotateKey(t)
+	code = authorize(t, server, provider, "verifier-3", "nonce-3")
+	_, err = provider.Exchange(context.Background(), code, "verifier-3", "nonce-3")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "keys are not fetched again within the refresh interval")
+	assert.Equal(t, 2, server.KeyRequests())
+}
+
+func TestProviderDiscoveryRejectsMismatchedIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issuer":"https://evil.example.com","authorization_endpoint":"https://evil.example.com/authorize","token_endpoint":"https://evil.example.com/token","jwks_uri":"https://evil.example.com/jwks"}`))
+	}))
+	defer server.Close()
+	provider := NewProvider(Config{IssuerURL: server.URL, ClientID: testClientID, RedirectURL: testRedirectURL})
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+	assert.ErrorContains(t, err, "does not match")
+}
+
+func TestProviderDiscoveryRetriesAfterFailure(t *testing.T) {
+	server := oidctest.NewServer(t, testClientID, testClientSecret)
+	issuer := server.URL
+	server.Close()
+	provider := NewProvider(Config{IssuerURL: issuer, ClientID: testClientID, RedirectURL: testRedirectURL})
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+
+	assert.Error(t, err)
+	assert.Nil(t, provider.metadata, "failed discovery is not cached")
+}
+
+func TestProviderDoesNotHoldLockWhileFetching(t *testing.T) {
+	requested, release := make(chan struct{}), make(chan struct{})
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/jwks" {
+			close(requested)
+			<-release
+			_, _ = w.Write([]byte(`{"keys":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"issuer":"` + server.URL + `","authorization_endpoint":"` + server.URL + `/authorize","token_endpoint":"` + server.URL + `/token","jwks_uri":"` + server.URL + `/jwks"}`))
+	}))
+	defer server.Close()
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	defer unblock()
+	provider := NewProvider(Config{IssuerURL: server.URL, ClientID: testClientID, RedirectURL: testRedirectURL})
+
+	fetched := make(chan error, 1)
+	go func() {
+		_, err := provider.signingKeys(context.Background(), false)
+		fetched <- err
+	}()
+	<-requested
+
+	started := make(chan error, 1)
+	go func() {
+		_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+		started <- err
+	}()
+	select {
+	case err := <-started:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a login waited for the key fetch of another one")
+	}
+
+	unblock()
+	assert.NoError(t, <-fetched)
+}
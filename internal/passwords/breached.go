@@ -0,0 +1,123 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prefixLength is how many hex digits of a SHA-1 hash name its range, as
+// in the Pwned Passwords range API.
+const prefixLength = 5
+
+// BreachedList holds the SHA-1 hashes of passwords known from data
+// breaches, grouped by the first five hex digits of the hash the way Pwned
+// Passwords serves them, so a lookup only reads the range of one prefix and
+// never needs the network.
+type BreachedList struct {
+	// dir holds one range file per prefix, read when a password with that
+	// prefix is looked up.
+	dir string
+	// ranges maps prefixes to the hash suffixes in them, for a list loaded
+	// from a single file.
+	ranges map[string]map[string]struct{}
+}
+
+// LoadBreachedList opens a list of breached passwords. The path is either
+// a directory of range files named after their prefix, such as 21BD1.txt,
+// with one 35 digit hash suffix per line, or a single file with one whole
+// 40 digit hash per line, which is read into memory. Lines may end in a
+// colon and the number of times the password was seen, as the Pwned
+// Passwords downloader writes them; lines seen zero times are padding and
+// are skipped.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	if info.IsDir() {
+		return &BreachedList{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	ranges := make(map[string]map[string]struct{})
+	err = readHashes(file, sha1.Size*2, func(hash string) {
+		prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+		if ranges[prefix] == nil {
+			ranges[prefix] = make(map[string]struct{})
+		}
+		ranges[prefix][suffix] = struct{}{}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read breached password list %s: %w", path, err)
+	}
+
+	return &BreachedList{ranges: ranges}, nil
+}
+
+// Contains reports whether the password is on the list.
+func (l *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	if l.ranges != nil {
+		_, ok := l.ranges[prefix][suffix]
+		return ok, nil
+	}
+
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("open breached password range %s: %w", prefix, err)
+	}
+	defer file.Close()
+
+	found := false
+	err = readHashes(file, len(suffix), func(candidate string) {
+		found = found || candidate == suffix
+	})
+	if err != nil {
+		return false, fmt.Errorf("read breached password range %s: %w", prefix, err)
+	}
+
+	return found, nil
+}
+
+// readHashes calls add with every hash of the given length in r, upper
+// cased, and fails on lines that are not one.
+func readHashes(r io.Reader, length int, add func(hash string)) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		hash, count, _ := strings.Cut(text, ":")
+		if count == "0" {
+			continue
+		}
+		if len(hash) != length || strings.Trim(hash, "0123456789abcdefABCDEF") != "" {
+			return fmt.Errorf("line %d: expected a hash of %d hex digits", line, length)
+		}
+
+		add(strings.ToUpper(hash))
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,69 @@
+package passwords
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The SHA-1 hash of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+
+func TestBreachedListFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	writeFile(t, path, "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:10437277\n"+
+		"7C4A8D09CA3762AF61E59520943DC26494F8941B:0\n")
+
+	list, err := LoadBreachedList(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	breached, err := list.Contains("password")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	// Padding lines seen zero times are not breaches.
+	breached, err = list.Contains("123456")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestBreachedListFromRangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "5BAA6.txt"), "003D68EB55068C33ACE09247EE4C639306B:3\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:10437277\n")
+
+	list, err := LoadBreachedList(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	breached, err := list.Contains("password")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	// Prefixes without a range file have no breached passwords.
+	breached, err = list.Contains("c0rrect-h0rse")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestLoadBreachedListRejectsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	writeFile(t, path, "password\n")
+
+	_, err := LoadBreachedList(path)
+	assert.ErrorContains(t, err, "line 1")
+
+	_, err = LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
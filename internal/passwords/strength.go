@@ -0,0 +1,314 @@
+// Package passwords rates how hard passwords are to guess and looks them up
+// in lists of passwords known from data breaches.
+package passwords
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MaxScore is the score of the strongest passwords.
+const MaxScore = 4
+
+// minWordLength is the shortest run of characters matched as a word, a
+// repeat or a sequence; shorter runs are counted character by character.
+const minWordLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords ranks the most used passwords, the most used first.
+var commonPasswords = rankWords(strings.Fields(commonPasswordList))
+
+// keyboardRuns are rows and columns of a QWERTY keyboard. Runs along them,
+// forwards or backwards, are guessed about as early as sequences.
+var keyboardRuns = []string{
+	"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./",
+	"1qaz", "2wsx", "3edc", "4rfv", "5tgb", "6yhn", "7ujm", "8ik,", "9ol.", "0p;/",
+}
+
+// leetSubstitutions undoes the character swaps people make to dress up a
+// word, such as p@ssw0rd for password.
+var leetSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+// Strength rates how hard the password is to guess, from 0, guessed almost
+// straight away, to MaxScore, which would take an offline attack years.
+// Like zxcvbn it estimates how many guesses an attacker needs by finding the
+// cheapest way to build the password from common passwords, repeats,
+// sequences, keyboard runs and single characters. userInputs such as the
+// user's name and email address are treated as the first words an attacker
+// tries.
+func Strength(password string, userInputs ...string) int {
+	return score(estimateGuesses(password, userInputs))
+}
+
+// score buckets an estimate of guesses the way zxcvbn does.
+func score(guesses float64) int {
+	switch {
+	case guesses < 1e3:
+		return 0
+	case guesses < 1e6:
+		return 1
+	case guesses < 1e8:
+		return 2
+	case guesses < 1e10:
+		return 3
+	default:
+		return MaxScore
+	}
+}
+
+// estimateGuesses finds the fewest guesses that build the password from
+// left to right, where best[j] is the cheapest way to build its first j
+// characters.
+func estimateGuesses(password string, userInputs []string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 1
+	}
+
+	words := rankWords(userWords(userInputs))
+	lower := make([]rune, len(runes))
+	unleet := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+		unleet[i] = lower[i]
+		if plain, ok := leetSubstitutions[lower[i]]; ok {
+			unleet[i] = plain
+		}
+	}
+
+	best := make([]float64, len(runes)+1)
+	best[0] = 1
+	for j := 1; j <= len(runes); j++ {
+		best[j] = best[j-1] * cardinality(runes[j-1])
+		for i := 0; i <= j-minWordLength; i++ {
+			if guesses := matchGuesses(runes[i:j], lower[i:j], unleet[i:j], words); guesses > 0 {
+				best[j] = math.Min(best[j], best[i]*guesses)
+			}
+		}
+	}
+
+	return best[len(runes)]
+}
+
+// matchGuesses returns the fewest guesses that find the run as one match,
+// or zero when it matches nothing.
+func matchGuesses(original, lower, unleet []rune, words map[string]int) float64 {
+	guesses := math.Inf(1)
+
+	if rank := wordRank(string(lower), words); rank > 0 {
+		guesses = math.Min(guesses, float64(rank)*caseVariations(original))
+	}
+	if rank := wordRank(reverse(lower), words); rank > 0 {
+		guesses = math.Min(guesses, float64(rank)*caseVariations(original)*2)
+	}
+	if string(unleet) != string(lower) {
+		if rank := wordRank(string(unleet), words); rank > 0 {
+			guesses = math.Min(guesses, float64(rank)*caseVariations(original)*2)
+		}
+	}
+	if repeated := repeatGuesses(original); repeated > 0 {
+		guesses = math.Min(guesses, repeated)
+	}
+	if sequence := sequenceGuesses(original); sequence > 0 {
+		guesses = math.Min(guesses, sequence)
+	}
+	if keyboardRun(string(lower)) {
+		guesses = math.Min(guesses, 40*float64(len(lower)))
+	}
+	if year := yearGuesses(original); year > 0 {
+		guesses = math.Min(guesses, year)
+	}
+
+	if math.IsInf(guesses, 1) {
+		return 0
+	}
+	return guesses
+}
+
+// repeatGuesses covers a run that repeats a shorter one, such as aaaa or
+// abcabc, which is guessed about as early as the shorter run times the
+// number of repeats.
+func repeatGuesses(run []rune) float64 {
+	for size := 1; size <= len(run)/2; size++ {
+		if len(run)%size != 0 {
+			continue
+		}
+
+		base := run[:size]
+		repeats := true
+		for i := size; i < len(run) && repeats; i++ {
+			repeats = run[i] == base[i%size]
+		}
+		if repeats {
+			return estimateGuesses(string(base), nil) * float64(len(run)/size)
+		}
+	}
+	return 0
+}
+
+// sequenceGuesses covers runs such as abcd, 4321 or MNOP that step through
+// the alphabet or the digits one character at a time.
+func sequenceGuesses(run []rune) float64 {
+	step := run[1] - run[0]
+	if step != 1 && step != -1 {
+		return 0
+	}
+	for i := 1; i < len(run); i++ {
+		if run[i]-run[i-1] != step || characterClass(run[i]) != characterClass(run[0]) {
+			return 0
+		}
+	}
+
+	var starts float64
+	switch {
+	case strings.ContainsRune("aAzZ019", run[0]):
+		// The obvious places to start.
+		starts = 4
+	case unicode.IsDigit(run[0]):
+		starts = 10
+	default:
+		starts = 26
+	}
+	if step < 0 {
+		starts *= 2
+	}
+	return starts * float64(len(run))
+}
+
+// yearGuesses covers recent years, which people add to passwords a lot.
+func yearGuesses(run []rune) float64 {
+	if len(run) != 4 || (string(run[:2]) != "19" && string(run[:2]) != "20") {
+		return 0
+	}
+	for _, r := range run[2:] {
+		if !unicode.IsDigit(r) {
+			return 0
+		}
+	}
+	return 200
+}
+
+func keyboardRun(run string) bool {
+	for _, row := range keyboardRuns {
+		if strings.Contains(row, run) || strings.Contains(row, reverse([]rune(run))) {
+			return true
+		}
+	}
+	return false
+}
+
+// caseVariations is how many more guesses a word takes with the capitals it
+// has: none for all lower case, double for a capital first letter or all
+// capitals, and more the more mixed up the capitals are.
+func caseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0 || (upper == 1 && unicode.IsUpper(word[0])):
+		return 2
+	default:
+		return math.Pow(2, float64(min(upper, lower)+1))
+	}
+}
+
+func wordRank(word string, userWords map[string]int) int {
+	if rank, ok := userWords[word]; ok {
+		return rank
+	}
+	return commonPasswords[word]
+}
+
+// cardinality is how many characters an attacker has to try for r.
+func cardinality(r rune) float64 {
+	switch characterClass(r) {
+	case classDigit:
+		return 10
+	case classLower, classUpper:
+		return 26
+	case classSymbol:
+		return 33
+	default:
+		return 100
+	}
+}
+
+const (
+	classDigit = iota
+	classLower
+	classUpper
+	classSymbol
+	classOther
+)
+
+func characterClass(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return classDigit
+	case r >= 'a' && r <= 'z':
+		return classLower
+	case r >= 'A' && r <= 'Z':
+		return classUpper
+	case r < unicode.MaxASCII && unicode.IsPrint(r):
+		return classSymbol
+	default:
+		return classOther
+	}
+}
+
+// userWords splits the user's details into the lower case words worth
+// trying, so alice.smith@example.com gives alice, smith and example too.
+func userWords(inputs []string) []string {
+	var words []string
+	for _, input := range inputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input == "" {
+			continue
+		}
+
+		words = append(words, input)
+		words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return words
+}
+
+// rankWords ranks words by their position, from 1, keeping the better rank
+// of a word listed twice.
+func rankWords(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for i, word := range words {
+		if len([]rune(word)) < minWordLength {
+			continue
+		}
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = i + 1
+		}
+	}
+	return ranks
+}
+
+func reverse(runes []rune) string {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return string(reversed)
+}
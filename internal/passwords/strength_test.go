@@ -0,0 +1,36 @@
+package passwords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 0},
+		{"P@ssw0rd", 0},
+		{"12345678", 0},
+		{"aaaaaaaaaaaa", 0},
+		{"abcabcabcabc", 0},
+		{"qwertyuiop", 0},
+		{"abcd1234", 0},
+		{"newpassword", 1},
+		{"Summer2024!", 2},
+		{"Tr0ub4dor&3", 4},
+		{"correct horse battery staple", 4},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Strength(tt.password), tt.password)
+	}
+}
+
+func TestStrengthUsesUserInputs(t *testing.T) {
+	assert.Equal(t, 4, Strength("AliceSmith1"))
+	assert.Equal(t, 0, Strength("AliceSmith1", "Alice Smith", "alice.smith@example.com"))
+	assert.Less(t, Strength("example2024", "Alice Smith", "alice.smith@example.com"), Strength("example2024"))
+}
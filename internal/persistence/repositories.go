@@ -7,15 +7,37 @@ import (
 )
 
 type Repositories struct {
-	Users        repositorycontracts.UserRepository
-	Transactions repositorycontracts.TransactionRepository
-	Budgets      repositorycontracts.BudgetRepository
+	Users          repositorycontracts.UserRepository
+	RefreshTokens  repositorycontracts.RefreshTokenRepository
+	RevokedTokens  repositorycontracts.RevokedTokenRepository
+	MFA            repositorycontracts.MFARepository
+	LoginThrottles repositorycontracts.LoginThrottleRepository
+	APIKeys        repositorycontracts.APIKeyRepository
+	OIDC           repositorycontracts.OIDCRepository
+	Transactions   repositorycontracts.TransactionRepository
+	Budgets        repositorycontracts.BudgetRepository
+	Accounts       repositorycontracts.AccountRepository
+	Tags           repositorycontracts.TagRepository
+	Rules          repositorycontracts.RuleRepository
+	Classifier     repositorycontracts.ClassifierRepository
+	Archives       repositorycontracts.ArchiveRepository
 }
 
 func NewGormRepositories(db *gorm.DB) Repositories {
 	return Repositories{
-		Users:        gormrepositories.NewUserRepository(db),
-		Transactions: gormrepositories.NewTransactionRepository(db),
-		Budgets:      gormrepositories.NewGormBudgetRepository(db),
+		Users:          gormrepositories.NewUserRepository(db),
+		RefreshTokens:  gormrepositories.NewRefreshTokenRepository(db),
+		RevokedTokens:  gormrepositories.NewRevokedTokenRepository(db),
+		MFA:            gormrepositories.NewMFARepository(db),
+		LoginThrottles: gormrepositories.NewLoginThrottleRepository(db),
+		APIKeys:        gormrepositories.NewAPIKeyRepository(db),
+		OIDC:           gormrepositories.NewOIDCRepository(db),
+		Transactions:   gormrepositories.NewTransactionRepository(db),
+		Budgets:        gormrepositories.NewGormBudgetRepository(db),
+		Accounts:       gormrepositories.NewAccountRepository(db),
+		Tags:           gormrepositories.NewTagRepository(db),
+		Rules:          gormrepositories.NewRuleRepository(db),
+		Classifier:     gormrepositories.NewClassifierRepository(db),
+		Archives:       gormrepositories.NewArchiveRepository(db),
 	}
 }
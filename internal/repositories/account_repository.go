@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// AccountRepository defines the required repository methods
+type AccountRepository interface {
+	CreateAccount(ctx context.Context, account *models.Account) error
+	GetAccountByID(ctx context.Context, id uint) (*models.Account, error)
+	GetAccountsByUserID(ctx context.Context, userID uint) ([]models.Account, error)
+	GetAccountBalancesByUserID(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error)
+	CountAccountTransactions(ctx context.Context, accountID uint) (int64, error)
+	UpdateAccount(ctx context.Context, account *models.Account) error
+	DeleteAccount(ctx context.Context, id uint) error
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// APIKeyRepository defines the required repository methods
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	GetAPIKeysByUserID(ctx context.Context, userID uint) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, at time.Time) error
+	TouchAPIKey(ctx context.Context, id uint, at time.Time) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// ArchiveRepository defines the required repository methods
+type ArchiveRepository interface {
+	GetArchiveData(ctx context.Context, userID uint) (*models.ArchiveData, error)
+	HasUserData(ctx context.Context, userID uint) (bool, error)
+	RestoreArchive(ctx context.Context, userID uint, data *models.ArchiveData) error
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// ClassifierRepository defines the required repository methods
+type ClassifierRepository interface {
+	UpdateClassifier(ctx context.Context, userID uint, examples []models.ClassifierExample) error
+	GetClassifierCategories(ctx context.Context, userID uint) ([]models.ClassifierCategory, error)
+	GetClassifierTokens(ctx context.Context, userID uint, tokens []string) ([]models.ClassifierToken, error)
+	CountClassifierVocabulary(ctx context.Context, userID uint) (int64, error)
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountRepository defines the required repository methods
+type AccountRepository interface {
+	CreateAccount(ctx context.Context, account *models.Account) error
+	GetAccountByID(ctx context.Context, id uint) (*models.Account, error)
+	GetAccountsByUserID(ctx context.Context, userID uint) ([]models.Account, error)
+	GetAccountBalancesByUserID(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error)
+	CountAccountTransactions(ctx context.Context, accountID uint) (int64, error)
+	UpdateAccount(ctx context.Context, account *models.Account) error
+	DeleteAccount(ctx context.Context, id uint) error
+}
+
+// GormAccountRepository handles DB operations for accounts
+type GormAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository initializes a new GormAccountRepository
+func NewAccountRepository(db *gorm.DB) *GormAccountRepository {
+	return &GormAccountRepository{db: db}
+}
+
+// CreateAccount inserts a new account into the database
+func (r *GormAccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// GetAccountByID retrieves an account by its ID
+func (r *GormAccountRepository) GetAccountByID(ctx context.Context, id uint) (*models.Account, error) {
+	var account models.Account
+	err := r.db.WithContext(ctx).First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetAccountsByUserID fetches all accounts for a specific user
+func (r *GormAccountRepository) GetAccountsByUserID(ctx context.Context, userID uint) ([]models.Account, error) {
+	var accounts []models.Account
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name ASC").Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// GetAccountBalancesByUserID computes each account's balance from its opening
+// balance and the transactions booked on or before asOf. Transfer legs are
+// stored with signed amounts, so only expenses need their sign flipped.
+func (r *GormAccountRepository) GetAccountBalancesByUserID(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error) {
+	accounts, err := r.GetAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type accountTotal struct {
+		AccountID uint
+		Total     float64
+	}
+
+	var totals []accountTotal
+	err = r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Select(`account_id, SUM(CASE WHEN "type" = 'expense' THEN -amount ELSE amount END) AS total`).
+		Where("user_id = ? AND account_id IS NOT NULL AND date <= ?", userID, asOf).
+		Group("account_id").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totalsByAccount := make(map[uint]float64, len(totals))
+	for _, total := range totals {
+		totalsByAccount[total.AccountID] = total.Total
+	}
+
+	balances := make([]models.AccountBalance, 0, len(accounts))
+	for _, account := range accounts {
+		balances = append(balances, models.AccountBalance{
+			Account: account,
+			Balance: account.OpeningBalance + totalsByAccount[account.ID],
+			AsOf:    asOf,
+		})
+	}
+
+	return balances, nil
+}
+
+// CountAccountTransactions counts the transactions booked against an account
+func (r *GormAccountRepository) CountAccountTransactions(ctx context.Context, accountID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).Where("account_id = ?", accountID).Count(&count).Error
+	return count, err
+}
+
+// UpdateAccount updates an existing account
+func (r *GormAccountRepository) UpdateAccount(ctx context.Context, account *models.Account) error {
+	return r.db.WithContext(ctx).Save(account).Error
+}
+
+// DeleteAccount removes an account from the database
+func (r *GormAccountRepository) DeleteAccount(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Account{}, id).Error
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupAccountTestDB initializes an in-memory SQLite database for testing.
+func setupAccountTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t)
+	err := database.ApplyMigrations(db)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestAccountRepository(t *testing.T) {
+	db := setupAccountTestDB(t)
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Name: "Test User", Email: "test@example.com", Password: "hashedpassword"}
+	db.Create(user)
+
+	checking := &models.Account{UserID: user.ID, Name: "Checking", Type: models.AccountTypeChecking, Currency: "EUR", OpeningBalance: 1000}
+	savings := &models.Account{UserID: user.ID, Name: "Savings", Type: models.AccountTypeSavings, Currency: "EUR", OpeningBalance: 250}
+
+	t.Run("CreateAccount", func(t *testing.T) {
+		assert.NoError(t, repo.CreateAccount(ctx, checking))
+		assert.NoError(t, repo.CreateAccount(ctx, savings))
+		assert.NotZero(t, checking.ID)
+
+		found, err := repo.GetAccountByID(ctx, checking.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Checking", found.Name)
+	})
+
+	t.Run("GetAccountsByUserID", func(t *testing.T) {
+		accounts, err := repo.GetAccountsByUserID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, accounts, 2)
+		assert.Equal(t, "Checking", accounts[0].Name)
+	})
+
+	t.Run("GetAccountBalancesByUserID", func(t *testing.T) {
+		march := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+		april := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+		db.Create(&models.Transaction{UserID: user.ID, Type: "income", Amount: 500, CategoryID: 1, AccountID: &checking.ID, Date: march})
+		db.Create(&models.Transaction{UserID: user.ID, Type: "expense", Amount: 120, CategoryID: 1, AccountID: &checking.ID, Date: march})
+		db.Create(&models.Transaction{UserID: user.ID, Type: "expense", Amount: 80, CategoryID: 1, AccountID: &checking.ID, Date: april})
+		db.Create(&models.Transaction{UserID: user.ID, Type: "expense", Amount: 999, CategoryID: 1, Date: march})
+		db.Create(&models.Transaction{UserID: user.ID, Type: "transfer", Amount: -100, AccountID: &checking.ID, Date: april})
+		db.Create(&models.Transaction{UserID: user.ID, Type: "transfer", Amount: 100, AccountID: &savings.ID, Date: april})
+
+		balances, err := repo.GetAccountBalancesByUserID(ctx, user.ID, april.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, balances, 2)
+		assert.InDelta(t, 1200, balances[0].Balance, 0.001)
+		assert.InDelta(t, 350, balances[1].Balance, 0.001)
+
+		balances, err = repo.GetAccountBalancesByUserID(ctx, user.ID, march.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.InDelta(t, 1380, balances[0].Balance, 0.001)
+	})
+
+	t.Run("CountAccountTransactions", func(t *testing.T) {
+		count, err := repo.CountAccountTransactions(ctx, checking.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+
+		count, err = repo.CountAccountTransactions(ctx, savings.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		assert.NoError(t, repo.DeleteAccount(ctx, savings.ID))
+
+		_, err := repo.GetAccountByID(ctx, savings.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	older := &models.APIKey{UserID: 1, Name: "Backup", Prefix: "pft_aaaa", KeyHash: "hash-older", Scopes: "reports:read", CreatedAt: now.Add(-time.Hour)}
+	newer := &models.APIKey{UserID: 1, Name: "Sync", Prefix: "pft_bbbb", KeyHash: "hash-newer", Scopes: "transactions:read,transactions:write", CreatedAt: now}
+	otherUser := &models.APIKey{UserID: 2, Name: "Other", Prefix: "pft_cccc", KeyHash: "hash-other", Scopes: "reports:read"}
+	for _, key := range []*models.APIKey{older, newer, otherUser} {
+		assert.NoError(t, repo.CreateAPIKey(ctx, key))
+	}
+
+	t.Run("GetAPIKeyByHash", func(t *testing.T) {
+		key, err := repo.GetAPIKeyByHash(ctx, "hash-newer")
+		if assert.NoError(t, err) {
+			assert.Equal(t, newer.ID, key.ID)
+			assert.Equal(t, []string{"transactions:read", "transactions:write"}, key.ScopeList())
+		}
+
+		_, err = repo.GetAPIKeyByHash(ctx, "missing")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("GetAPIKeysByUserID lists the user's keys newest first", func(t *testing.T) {
+		keys, err := repo.GetAPIKeysByUserID(ctx, 1)
+		if assert.NoError(t, err) && assert.Len(t, keys, 2) {
+			assert.Equal(t, "Sync", keys[0].Name)
+			assert.Equal(t, "Backup", keys[1].Name)
+		}
+	})
+
+	t.Run("TouchAPIKey", func(t *testing.T) {
+		assert.NoError(t, repo.TouchAPIKey(ctx, newer.ID, now))
+
+		key, err := repo.GetAPIKeyByID(ctx, newer.ID)
+		if assert.NoError(t, err) && assert.NotNil(t, key.LastUsedAt) {
+			assert.WithinDuration(t, now, *key.LastUsedAt, time.Second)
+		}
+	})
+
+	t.Run("RevokeAPIKey", func(t *testing.T) {
+		assert.NoError(t, repo.RevokeAPIKey(ctx, older.ID, now))
+		assert.ErrorIs(t, repo.RevokeAPIKey(ctx, older.ID, now), gorm.ErrRecordNotFound)
+
+		keys, err := repo.GetAPIKeysByUserID(ctx, 1)
+		if assert.NoError(t, err) && assert.Len(t, keys, 1) {
+			assert.Equal(t, newer.ID, keys[0].ID)
+		}
+
+		key, err := repo.GetAPIKeyByID(ctx, older.ID)
+		if assert.NoError(t, err) {
+			assert.False(t, key.Active(now))
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// ArchiveRepository defines the required repository methods
+type ArchiveRepository interface {
+	GetArchiveData(ctx context.Context, userID uint) (*models.ArchiveData, error)
+	HasUserData(ctx context.Context, userID uint) (bool, error)
+	RestoreArchive(ctx context.Context, userID uint, data *models.ArchiveData) error
+}
+
+// GormArchiveRepository handles DB operations for whole-account archives
+type GormArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewArchiveRepository initializes a new GormArchiveRepository
+func NewArchiveRepository(db *gorm.DB) *GormArchiveRepository {
+	return &GormArchiveRepository{db: db}
+}
+
+// GetArchiveData fetches everything an archive holds for a user except the
+// transactions, which are streamed separately. Categories are shared
+// between users, so only the ones the user's records refer to are included.
+func (r *GormArchiveRepository) GetArchiveData(ctx context.Context, userID uint) (*models.ArchiveData, error) {
+	db := r.db.WithContext(ctx)
+	data := &models.ArchiveData{}
+
+	err := db.Where(`id IN (SELECT category_id FROM transactions WHERE user_id = ?)
+		OR id IN (SELECT transaction_splits.category_id FROM transaction_splits JOIN transactions ON transactions.id = transaction_splits.transaction_id WHERE transactions.user_id = ?)
+		OR id IN (SELECT category_id FROM budgets WHERE user_id = ?)
+		OR id IN (SELECT category_id FROM rules WHERE user_id = ? AND category_id IS NOT NULL)`,
+		userID, userID, userID, userID,
+	).Order("id ASC").Find(&data.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&data.Accounts).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&data.Tags).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&data.Budgets).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&data.Rules).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&data.Transfers).Error; err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// HasUserData reports whether the user owns any accounts, tags, budgets,
+// rules or transactions.
+func (r *GormArchiveRepository) HasUserData(ctx context.Context, userID uint) (bool, error) {
+	for _, model := range []any{&models.Transaction{}, &models.Account{}, &models.Tag{}, &models.Budget{}, &models.Rule{}} {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(model).Where("user_id = ?", userID).Limit(1).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RestoreArchive creates the archived records for the user in one database
+// transaction, giving every record a new ID and rewriting the references
+// between them. Archived categories are matched to existing ones by name
+// and created when missing.
+func (r *GormArchiveRepository) RestoreArchive(ctx context.Context, userID uint, data *models.ArchiveData) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		categoryIDs := make(map[uint]uint, len(data.Categories))
+		for _, category := range data.Categories {
+			var existing models.Category
+			err := tx.Where("name = ?", category.Name).First(&existing).Error
+			switch {
+			case err == nil:
+				categoryIDs[category.ID] = existing.ID
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				created := models.Category{Name: category.Name, Description: category.Description}
+				if err := tx.Create(&created).Error; err != nil {
+					return err
+				}
+				categoryIDs[category.ID] = created.ID
+			default:
+				return err
+			}
+		}
+
+		accountIDs := make(map[uint]uint, len(data.Accounts))
+		for _, account := range data.Accounts {
+			oldID := account.ID
+			account.ID, account.UserID = 0, userID
+			if err := tx.Create(&account).Error; err != nil {
+				return err
+			}
+			accountIDs[oldID] = account.ID
+		}
+
+		tags := make(map[uint]models.Tag, len(data.Tags))
+		for _, tag := range data.Tags {
+			oldID := tag.ID
+			tag.ID, tag.UserID = 0, userID
+			if err := tx.Create(&tag).Error; err != nil {
+				return err
+			}
+			tags[oldID] = tag
+		}
+
+		for _, budget := range data.Budgets {
+			categoryID, err := remapID(categoryIDs, budget.CategoryID, "category")
+			if err != nil {
+				return err
+			}
+			budget.ID, budget.UserID, budget.CategoryID = 0, userID, categoryID
+			if err := tx.Create(&budget).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, rule := range data.Rules {
+			rule.ID, rule.UserID = 0, userID
+			if rule.CategoryID != nil {
+				categoryID, err := remapID(categoryIDs, *rule.CategoryID, "category")
+				if err != nil {
+					return err
+				}
+				rule.CategoryID = &categoryID
+			}
+			if err := tx.Create(&rule).Error; err != nil {
+				return err
+			}
+		}
+
+		transferIDs := make(map[uint]uint, len(data.Transfers))
+		for _, transfer := range data.Transfers {
+			oldID := transfer.ID
+			from, err := remapID(accountIDs, transfer.FromAccountID, "account")
+			if err != nil {
+				return err
+			}
+			to, err := remapID(accountIDs, transfer.ToAccountID, "account")
+			if err != nil {
+				return err
+			}
+
+			transfer.ID, transfer.UserID, transfer.FromAccountID, transfer.ToAccountID, transfer.Legs = 0, userID, from, to, nil
+			if err := tx.Create(&transfer).Error; err != nil {
+				return err
+			}
+			transferIDs[oldID] = transfer.ID
+		}
+
+		transactions := make([]models.Transaction, 0, len(data.Transactions))
+		for _, transaction := range data.Transactions {
+			transaction.ID, transaction.UserID = 0, userID
+
+			// Transfer legs have no category.
+			if transaction.CategoryID != 0 {
+				categoryID, err := remapID(categoryIDs, transaction.CategoryID, "category")
+				if err != nil {
+					return err
+				}
+				transaction.CategoryID = categoryID
+			}
+
+			if transaction.AccountID != nil {
+				accountID, err := remapID(accountIDs, *transaction.AccountID, "account")
+				if err != nil {
+					return err
+				}
+				transaction.AccountID = &accountID
+			}
+			if transaction.TransferID != nil {
+				transferID, err := remapID(transferIDs, *transaction.TransferID, "transfer")
+				if err != nil {
+					return err
+				}
+				transaction.TransferID = &transferID
+			}
+
+			splits := make([]models.TransactionSplit, 0, len(transaction.Splits))
+			for _, split := range transaction.Splits {
+				categoryID, err := remapID(categoryIDs, split.CategoryID, "category")
+				if err != nil {
+					return err
+				}
+				splits = append(splits, models.TransactionSplit{CategoryID: categoryID, Amount: split.Amount, Note: split.Note})
+			}
+			transaction.Splits = splits
+
+			transactionTags := make([]models.Tag, 0, len(transaction.Tags))
+			for _, tag := range transaction.Tags {
+				restored, ok := tags[tag.ID]
+				if !ok {
+					return fmt.Errorf("archive references unknown tag %d", tag.ID)
+				}
+				transactionTags = append(transactionTags, restored)
+			}
+			transaction.Tags = transactionTags
+
+			transactions = append(transactions, transaction)
+		}
+
+		if len(transactions) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&transactions, streamBatchSize).Error
+	})
+}
+
+func remapID(ids map[uint]uint, id uint, kind string) (uint, error) {
+	newID, ok := ids[id]
+	if !ok {
+		return 0, fmt.Errorf("archive references unknown %s %d", kind, id)
+	}
+	return newID, nil
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupArchiveTestDB initializes an in-memory SQLite database for testing.
+func setupArchiveTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t)
+	err := database.ApplyMigrations(db)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestArchiveRepository(t *testing.T) {
+	db := setupArchiveTestDB(t)
+	repo := NewArchiveRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// The source user's data, next to an unrelated category nobody uses.
+	groceries := models.Category{Name: "Groceries"}
+	unused := models.Category{Name: "Unused"}
+	assert.NoError(t, db.Create(&groceries).Error)
+	assert.NoError(t, db.Create(&unused).Error)
+
+	checking := models.Account{UserID: 1, Name: "Checking", Type: models.AccountTypeChecking, Currency: "EUR"}
+	savings := models.Account{UserID: 1, Name: "Savings", Type: models.AccountTypeSavings, Currency: "EUR"}
+	assert.NoError(t, db.Create(&checking).Error)
+	assert.NoError(t, db.Create(&savings).Error)
+
+	food := models.Tag{UserID: 1, Name: "food"}
+	assert.NoError(t, db.Create(&food).Error)
+	assert.NoError(t, db.Create(&models.Budget{UserID: 1, CategoryID: groceries.ID, Limit: 300, StartDate: date, EndDate: date.AddDate(0, 1, 0)}).Error)
+	assert.NoError(t, db.Create(&models.Rule{UserID: 1, Name: "Rewe", PayeeContains: "rewe", CategoryID: &groceries.ID}).Error)
+
+	assert.NoError(t, transactionRepo.CreateTransaction(ctx, &models.Transaction{
+		UserID: 1, Type: "expense", Amount: 42, CategoryID: groceries.ID, AccountID: &checking.ID, Date: date,
+		Splits: []models.TransactionSplit{{CategoryID: groceries.ID, Amount: 42}},
+		Tags:   []models.Tag{food},
+	}))
+	assert.NoError(t, transactionRepo.CreateTransfer(ctx, &models.Transfer{
+		UserID: 1, FromAccountID: checking.ID, ToAccountID: savings.ID, Amount: 50, Date: date,
+		Legs: []models.Transaction{
+			{UserID: 1, Type: "transfer", Amount: -50, AccountID: &checking.ID, Date: date},
+			{UserID: 1, Type: "transfer", Amount: 50, AccountID: &savings.ID, Date: date},
+		},
+	}))
+
+	t.Run("GetArchiveData", func(t *testing.T) {
+		data, err := repo.GetArchiveData(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, data.Categories, 1)
+		assert.Equal(t, "Groceries", data.Categories[0].Name)
+		assert.Len(t, data.Accounts, 2)
+		assert.Len(t, data.Tags, 1)
+		assert.Len(t, data.Budgets, 1)
+		assert.Len(t, data.Rules, 1)
+		assert.Len(t, data.Transfers, 1)
+		assert.Empty(t, data.Transactions)
+	})
+
+	t.Run("HasUserData", func(t *testing.T) {
+		hasData, err := repo.HasUserData(ctx, 1)
+		assert.NoError(t, err)
+		assert.True(t, hasData)
+
+		hasData, err = repo.HasUserData(ctx, 2)
+		assert.NoError(t, err)
+		assert.False(t, hasData)
+	})
+
+	t.Run("RestoreArchive", func(t *testing.T) {
+		data, err := repo.GetArchiveData(ctx, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, transactionRepo.StreamTransactionsByUserID(ctx, 1, filters.TransactionFilters{}, func(transaction models.Transaction) error {
+			data.Transactions = append(data.Transactions, transaction)
+			return nil
+		}))
+
+		assert.NoError(t, repo.RestoreArchive(ctx, 2, data))
+
+		var categories int64
+		db.Model(&models.Category{}).Count(&categories)
+		assert.Equal(t, int64(2), categories, "the existing category is reused by name")
+
+		var accounts []models.Account
+		assert.NoError(t, db.Where("user_id = ?", 2).Order("id ASC").Find(&accounts).Error)
+		assert.Len(t, accounts, 2)
+		assert.NotEqual(t, checking.ID, accounts[0].ID)
+
+		restored, err := transactionRepo.GetTransactionsByUserID(ctx, 2, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, restored, 3)
+
+		for _, transaction := range restored {
+			switch transaction.Type {
+			case "expense":
+				assert.Equal(t, groceries.ID, transaction.CategoryID)
+				assert.Equal(t, accounts[0].ID, *transaction.AccountID)
+				assert.Len(t, transaction.Splits, 1)
+				if assert.Len(t, transaction.Tags, 1) {
+					assert.Equal(t, uint(2), transaction.Tags[0].UserID)
+					assert.NotEqual(t, food.ID, transaction.Tags[0].ID)
+				}
+			case "transfer":
+				transfer, err := transactionRepo.GetTransferByID(ctx, *transaction.TransferID)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(2), transfer.UserID)
+				assert.Equal(t, accounts[0].ID, transfer.FromAccountID)
+				assert.Equal(t, accounts[1].ID, transfer.ToAccountID)
+			}
+		}
+
+		var rule models.Rule
+		assert.NoError(t, db.Where("user_id = ?", 2).First(&rule).Error)
+		assert.Equal(t, groceries.ID, *rule.CategoryID)
+	})
+}
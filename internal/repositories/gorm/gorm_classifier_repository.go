@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClassifierRepository defines the required repository methods
+type ClassifierRepository interface {
+	UpdateClassifier(ctx context.Context, userID uint, examples []models.ClassifierExample) error
+	GetClassifierCategories(ctx context.Context, userID uint) ([]models.ClassifierCategory, error)
+	GetClassifierTokens(ctx context.Context, userID uint, tokens []string) ([]models.ClassifierToken, error)
+	CountClassifierVocabulary(ctx context.Context, userID uint) (int64, error)
+}
+
+// GormClassifierRepository handles DB operations for the category classifier's training counts
+type GormClassifierRepository struct {
+	db *gorm.DB
+}
+
+// NewClassifierRepository initializes a new GormClassifierRepository
+func NewClassifierRepository(db *gorm.DB) *GormClassifierRepository {
+	return &GormClassifierRepository{db: db}
+}
+
+// UpdateClassifier adds each example's weight to its category and token
+// counts in one database transaction and drops counts that fall to zero
+func (r *GormClassifierRepository) UpdateClassifier(ctx context.Context, userID uint, examples []models.ClassifierExample) error {
+	if len(examples) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, example := range examples {
+			category := models.ClassifierCategory{
+				UserID:     userID,
+				CategoryID: example.CategoryID,
+				Documents:  example.Weight,
+				Tokens:     example.Weight * len(example.Tokens),
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}, {Name: "category_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"documents": gorm.Expr("classifier_categories.documents + ?", category.Documents),
+					"tokens":    gorm.Expr("classifier_categories.tokens + ?", category.Tokens),
+				}),
+			}).Create(&category).Error
+			if err != nil {
+				return err
+			}
+
+			for _, token := range example.Tokens {
+				count := models.ClassifierToken{
+					UserID:      userID,
+					CategoryID:  example.CategoryID,
+					Token:       token,
+					Occurrences: example.Weight,
+				}
+				err := tx.Clauses(clause.OnConflict{
+					Columns: []clause.Column{{Name: "user_id"}, {Name: "category_id"}, {Name: "token"}},
+					DoUpdates: clause.Assignments(map[string]interface{}{
+						"occurrences": gorm.Expr("classifier_tokens.occurrences + ?", count.Occurrences),
+					}),
+				}).Create(&count).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Forgetting an example that was never learned must not leave negative counts behind.
+		if err := tx.Where("user_id = ? AND occurrences <= 0", userID).Delete(&models.ClassifierToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND documents <= 0", userID).Delete(&models.ClassifierCategory{}).Error
+	})
+}
+
+// GetClassifierCategories fetches the user's per-category training totals
+func (r *GormClassifierRepository) GetClassifierCategories(ctx context.Context, userID uint) ([]models.ClassifierCategory, error) {
+	var categories []models.ClassifierCategory
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("category_id ASC").Find(&categories).Error
+	return categories, err
+}
+
+// GetClassifierTokens fetches the user's per-category counts of the given tokens
+func (r *GormClassifierRepository) GetClassifierTokens(ctx context.Context, userID uint, tokens []string) ([]models.ClassifierToken, error) {
+	var counts []models.ClassifierToken
+	if len(tokens) == 0 {
+		return counts, nil
+	}
+
+	err := r.db.WithContext(ctx).Where("user_id = ? AND token IN ?", userID, tokens).Find(&counts).Error
+	return counts, err
+}
+
+// CountClassifierVocabulary counts the distinct tokens the user's classifier has learned
+func (r *GormClassifierRepository) CountClassifierVocabulary(ctx context.Context, userID uint) (int64, error) {
+	var vocabulary int64
+	err := r.db.WithContext(ctx).Model(&models.ClassifierToken{}).Where("user_id = ?", userID).Distinct("token").Count(&vocabulary).Error
+	return vocabulary, err
+}
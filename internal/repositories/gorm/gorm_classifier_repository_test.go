@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupClassifierTestDB initializes an in-memory SQLite database for testing.
+func setupClassifierTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t)
+	err := database.ApplyMigrations(db)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestClassifierRepository(t *testing.T) {
+	db := setupClassifierTestDB(t)
+	repo := NewClassifierRepository(db)
+	ctx := context.Background()
+
+	t.Run("UpdateClassifier", func(t *testing.T) {
+		assert.NoError(t, repo.UpdateClassifier(ctx, 1, []models.ClassifierExample{
+			{CategoryID: 4, Tokens: []string{"rewe", "markt"}, Weight: 1},
+			{CategoryID: 4, Tokens: []string{"rewe"}, Weight: 1},
+			{CategoryID: 7, Tokens: []string{"db", "ticket"}, Weight: 1},
+		}))
+		assert.NoError(t, repo.UpdateClassifier(ctx, 2, []models.ClassifierExample{
+			{CategoryID: 4, Tokens: []string{"aldi"}, Weight: 1},
+		}))
+
+		categories, err := repo.GetClassifierCategories(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ClassifierCategory{
+			{UserID: 1, CategoryID: 4, Documents: 2, Tokens: 3},
+			{UserID: 1, CategoryID: 7, Documents: 1, Tokens: 2},
+		}, categories)
+
+		tokens, err := repo.GetClassifierTokens(ctx, 1, []string{"rewe", "aldi"})
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ClassifierToken{{UserID: 1, CategoryID: 4, Token: "rewe", Occurrences: 2}}, tokens)
+
+		vocabulary, err := repo.CountClassifierVocabulary(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), vocabulary)
+	})
+
+	t.Run("Forget examples", func(t *testing.T) {
+		assert.NoError(t, repo.UpdateClassifier(ctx, 1, []models.ClassifierExample{
+			{CategoryID: 7, Tokens: []string{"db", "ticket"}, Weight: -1},
+			{CategoryID: 9, Tokens: []string{"never", "learned"}, Weight: -1},
+		}))
+
+		categories, err := repo.GetClassifierCategories(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, categories, 1)
+		assert.Equal(t, uint(4), categories[0].CategoryID)
+
+		vocabulary, err := repo.CountClassifierVocabulary(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), vocabulary)
+	})
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginThrottleRepository defines the required repository methods
+type LoginThrottleRepository interface {
+	GetLoginThrottles(ctx context.Context, keyHashes []string) ([]models.LoginThrottle, error)
+	RecordLoginFailure(ctx context.Context, keyHash string, at, windowStart time.Time) (*models.LoginThrottle, error)
+	LockLoginThrottle(ctx context.Context, keyHash string, until time.Time) error
+	DeleteLoginThrottle(ctx context.Context, keyHash string) error
+	DeleteStaleLoginThrottles(ctx context.Context, before time.Time) (int64, error)
+}
+
+// GormLoginThrottleRepository handles DB operations for failed login counters
+type GormLoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginThrottleRepository initializes a new GormLoginThrottleRepository
+func NewLoginThrottleRepository(db *gorm.DB) *GormLoginThrottleRepository {
+	return &GormLoginThrottleRepository{db: db}
+}
+
+// GetLoginThrottles returns the counters that exist for the given keys
+func (r *GormLoginThrottleRepository) GetLoginThrottles(ctx context.Context, keyHashes []string) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := r.db.WithContext(ctx).Where("key_hash IN ?", keyHashes).Find(&throttles).Error
+	return throttles, err
+}
+
+// RecordLoginFailure counts a failed login against the key in a single
+// statement, so concurrent attempts are all counted. A counter whose last
+// failure is older than windowStart starts again from one and loses its
+// lock.
+func (r *GormLoginThrottleRepository) RecordLoginFailure(ctx context.Context, keyHash string, at, windowStart time.Time) (*models.LoginThrottle, error) {
+	throttle := &models.LoginThrottle{KeyHash: keyHash, Failures: 1, LastFailureAt: at}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key_hash"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failures":        gorm.Expr("CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END", windowStart),
+				"locked_until":    gorm.Expr("CASE WHEN last_failure_at < ? THEN NULL ELSE locked_until END", windowStart),
+				"last_failure_at": at,
+			}),
+		}).
+		Create(throttle).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stored models.LoginThrottle
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&stored).Error; err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// LockLoginThrottle refuses logins for the key until the given time
+func (r *GormLoginThrottleRepository) LockLoginThrottle(ctx context.Context, keyHash string, until time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.LoginThrottle{}).
+		Where("key_hash = ?", keyHash).
+		Update("locked_until", until)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteLoginThrottle forgets the failed logins counted against the key
+func (r *GormLoginThrottleRepository) DeleteLoginThrottle(ctx context.Context, keyHash string) error {
+	return r.db.WithContext(ctx).Where("key_hash = ?", keyHash).Delete(&models.LoginThrottle{}).Error
+}
+
+// DeleteStaleLoginThrottles removes counters with no failure since before
+func (r *GormLoginThrottleRepository) DeleteStaleLoginThrottles(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("last_failure_at < ?", before).Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
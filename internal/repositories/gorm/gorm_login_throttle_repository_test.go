@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestLoginThrottleRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewLoginThrottleRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	windowStart := now.Add(-24 * time.Hour)
+
+	t.Run("RecordLoginFailure", func(t *testing.T) {
+		throttle, err := repo.RecordLoginFailure(ctx, "account", now, windowStart)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, throttle.Failures)
+			assert.Nil(t, throttle.LockedUntil)
+		}
+
+		throttle, err = repo.RecordLoginFailure(ctx, "account", now.Add(time.Second), windowStart)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, throttle.Failures)
+		}
+	})
+
+	t.Run("LockLoginThrottle", func(t *testing.T) {
+		assert.NoError(t, repo.LockLoginThrottle(ctx, "account", now.Add(time.Minute)))
+		assert.ErrorIs(t, repo.LockLoginThrottle(ctx, "missing", now.Add(time.Minute)), gorm.ErrRecordNotFound)
+
+		throttles, err := repo.GetLoginThrottles(ctx, []string{"account", "missing"})
+		if assert.NoError(t, err) && assert.Len(t, throttles, 1) {
+			assert.Equal(t, "account", throttles[0].KeyHash)
+			if assert.NotNil(t, throttles[0].LockedUntil) {
+				assert.WithinDuration(t, now.Add(time.Minute), *throttles[0].LockedUntil, time.Second)
+			}
+		}
+	})
+
+	t.Run("RecordLoginFailure starts over after the window", func(t *testing.T) {
+		throttle, err := repo.RecordLoginFailure(ctx, "account", now.Add(25*time.Hour), now.Add(time.Hour))
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, throttle.Failures)
+			assert.Nil(t, throttle.LockedUntil)
+		}
+	})
+
+	t.Run("DeleteLoginThrottle", func(t *testing.T) {
+		assert.NoError(t, repo.DeleteLoginThrottle(ctx, "account"))
+
+		throttles, err := repo.GetLoginThrottles(ctx, []string{"account"})
+		assert.NoError(t, err)
+		assert.Empty(t, throttles)
+	})
+
+	t.Run("DeleteStaleLoginThrottles", func(t *testing.T) {
+		_, err := repo.RecordLoginFailure(ctx, "old", now.Add(-48*time.Hour), windowStart)
+		assert.NoError(t, err)
+		_, err = repo.RecordLoginFailure(ctx, "recent", now, windowStart)
+		assert.NoError(t, err)
+
+		deleted, err := repo.DeleteStaleLoginThrottles(ctx, windowStart)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		throttles, err := repo.GetLoginThrottles(ctx, []string{"old", "recent"})
+		if assert.NoError(t, err) && assert.Len(t, throttles, 1) {
+			assert.Equal(t, "recent", throttles[0].KeyHash)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// MFARepository defines the required repository methods
+type MFARepository interface {
+	SetPendingTOTPSecret(ctx context.Context, userID uint, secret string) error
+	EnableTOTP(ctx context.Context, userID uint, enabledAt time.Time, step int64, codes []models.RecoveryCode) error
+	DisableTOTP(ctx context.Context, userID uint) error
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error)
+}
+
+// GormMFARepository handles DB operations for TOTP secrets and recovery codes
+type GormMFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository initializes a new GormMFARepository
+func NewMFARepository(db *gorm.DB) *GormMFARepository {
+	return &GormMFARepository{db: db}
+}
+
+// SetPendingTOTPSecret stores a secret for a user who has not enabled TOTP
+// yet, replacing any earlier unconfirmed one
+func (r *GormMFARepository) SetPendingTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", userID).
+		Update("totp_secret", secret)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EnableTOTP turns on the pending secret, remembers the step of the code
+// that confirmed it and replaces the user's recovery codes in one database
+// transaction
+func (r *GormMFARepository) EnableTOTP(ctx context.Context, userID uint, enabledAt time.Time, step int64, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_enabled_at IS NULL AND totp_secret <> ''", userID).
+			Updates(map[string]any{"totp_enabled_at": enabledAt, "totp_last_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// DisableTOTP clears the user's secret and deletes their recovery codes
+func (r *GormMFARepository) DisableTOTP(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]any{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseTOTPStep records that a code of the given time step was accepted. It
+// reports false when a code of that step or a later one was accepted
+// before, so every code works once.
+func (r *GormMFARepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used and
+// reports whether there was one
+func (r *GormMFARepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFARepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewMFARepository(db)
+	userRepo := NewUserRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user := &models.User{Name: "John", Email: "john@example.com", Password: "hashed"}
+	assert.NoError(t, userRepo.CreateUser(ctx, user))
+
+	t.Run("EnableTOTP needs a pending secret", func(t *testing.T) {
+		assert.Error(t, repo.EnableTOTP(ctx, user.ID, now, 1, nil))
+	})
+
+	t.Run("SetPendingTOTPSecret and EnableTOTP", func(t *testing.T) {
+		assert.NoError(t, repo.SetPendingTOTPSecret(ctx, user.ID, "SECRET"))
+
+		codes := []models.RecoveryCode{{UserID: user.ID, CodeHash: "hash-1"}, {UserID: user.ID, CodeHash: "hash-2"}}
+		assert.NoError(t, repo.EnableTOTP(ctx, user.ID, now, 100, codes))
+
+		stored, err := userRepo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "SECRET", stored.TOTPSecret)
+		assert.True(t, stored.MFAEnabled())
+		assert.Equal(t, int64(100), stored.TOTPLastStep)
+
+		// An enabled secret is not replaced by a new enrolment.
+		assert.Error(t, repo.SetPendingTOTPSecret(ctx, user.ID, "OTHER"))
+	})
+
+	t.Run("UseTOTPStep accepts each step once", func(t *testing.T) {
+		used, err := repo.UseTOTPStep(ctx, user.ID, 100)
+		assert.NoError(t, err)
+		assert.False(t, used)
+
+		used, err = repo.UseTOTPStep(ctx, user.ID, 101)
+		assert.NoError(t, err)
+		assert.True(t, used)
+
+		used, err = repo.UseTOTPStep(ctx, user.ID, 101)
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("UseRecoveryCode accepts each code once", func(t *testing.T) {
+		used, err := repo.UseRecoveryCode(ctx, user.ID, "hash-1", now)
+		assert.NoError(t, err)
+		assert.True(t, used)
+
+		used, err = repo.UseRecoveryCode(ctx, user.ID, "hash-1", now)
+		assert.NoError(t, err)
+		assert.False(t, used)
+
+		used, err = repo.UseRecoveryCode(ctx, user.ID+1, "hash-2", now)
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("DisableTOTP", func(t *testing.T) {
+		assert.NoError(t, repo.DisableTOTP(ctx, user.ID))
+
+		stored, err := userRepo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.False(t, stored.MFAEnabled())
+		assert.Empty(t, stored.TOTPSecret)
+
+		used, err := repo.UseRecoveryCode(ctx, user.ID, "hash-2", now)
+		assert.NoError(t, err)
+		assert.False(t, used)
+	})
+}
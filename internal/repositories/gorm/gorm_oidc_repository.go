@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// OIDCRepository defines the required repository methods
+type OIDCRepository interface {
+	CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error
+	ConsumeLoginRequest(ctx context.Context, stateHash string) (*models.OIDCLoginRequest, error)
+	DeleteExpiredLoginRequests(ctx context.Context, now time.Time) (int64, error)
+	GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+}
+
+// GormOIDCRepository handles DB operations for OpenID Connect logins
+type GormOIDCRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCRepository initializes a new GormOIDCRepository
+func NewOIDCRepository(db *gorm.DB) *GormOIDCRepository {
+	return &GormOIDCRepository{db: db}
+}
+
+// CreateLoginRequest stores a login waiting for the provider
+func (r *GormOIDCRepository) CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// ConsumeLoginRequest deletes and returns the login with the given state
+// hash. Of two callbacks with the same state only one gets it.
+func (r *GormOIDCRepository) ConsumeLoginRequest(ctx context.Context, stateHash string) (*models.OIDCLoginRequest, error) {
+	var request models.OIDCLoginRequest
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&request).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.OIDCLoginRequest{}, request.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// DeleteExpiredLoginRequests removes logins that were never finished
+func (r *GormOIDCRepository) DeleteExpiredLoginRequests(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.OIDCLoginRequest{})
+	return result.RowsAffected, result.Error
+}
+
+// GetIdentity finds the link to a provider account
+func (r *GormOIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity links a user to a provider account
+func (r *GormOIDCRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
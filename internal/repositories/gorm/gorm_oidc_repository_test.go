@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOIDCRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewOIDCRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("ConsumeLoginRequest hands out a request once", func(t *testing.T) {
+		request := &models.OIDCLoginRequest{StateHash: "state-hash", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: now.Add(10 * time.Minute)}
+		assert.NoError(t, repo.CreateLoginRequest(ctx, request))
+
+		consumed, err := repo.ConsumeLoginRequest(ctx, "state-hash")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "nonce", consumed.Nonce)
+			assert.Equal(t, "verifier", consumed.CodeVerifier)
+		}
+
+		_, err = repo.ConsumeLoginRequest(ctx, "state-hash")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("DeleteExpiredLoginRequests", func(t *testing.T) {
+		assert.NoError(t, repo.CreateLoginRequest(ctx, &models.OIDCLoginRequest{StateHash: "expired", Nonce: "n", CodeVerifier: "v", ExpiresAt: now.Add(-time.Minute)}))
+		assert.NoError(t, repo.CreateLoginRequest(ctx, &models.OIDCLoginRequest{StateHash: "pending", Nonce: "n", CodeVerifier: "v", ExpiresAt: now.Add(time.Minute)}))
+
+		deleted, err := repo.DeleteExpiredLoginRequests(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = repo.ConsumeLoginRequest(ctx, "pending")
+		assert.NoError(t, err)
+	})
+
+	t.Run("identities are unique per issuer and subject", func(t *testing.T) {
+		assert.NoError(t, repo.CreateIdentity(ctx, &models.UserIdentity{UserID: 1, Issuer: "https://id.example.com", Subject: "alice"}))
+		assert.NoError(t, repo.CreateIdentity(ctx, &models.UserIdentity{UserID: 2, Issuer: "https://other.example.com", Subject: "alice"}))
+		assert.Error(t, repo.CreateIdentity(ctx, &models.UserIdentity{UserID: 3, Issuer: "https://id.example.com", Subject: "alice"}))
+
+		identity, err := repo.GetIdentity(ctx, "https://id.example.com", "alice")
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(1), identity.UserID)
+		}
+
+		_, err = repo.GetIdentity(ctx, "https://id.example.com", "bob")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRefreshTokenRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	first := &models.RefreshToken{UserID: 1, FamilyID: "family-a", TokenHash: "hash-1", ExpiresAt: expiresAt}
+
+	t.Run("CreateRefreshToken", func(t *testing.T) {
+		assert.NoError(t, repo.CreateRefreshToken(ctx, first))
+		assert.NotZero(t, first.ID)
+
+		// Hashes are unique.
+		assert.Error(t, repo.CreateRefreshToken(ctx, &models.RefreshToken{UserID: 1, FamilyID: "family-b", TokenHash: "hash-1", ExpiresAt: expiresAt}))
+	})
+
+	t.Run("GetRefreshTokenByHash", func(t *testing.T) {
+		token, err := repo.GetRefreshTokenByHash(ctx, "hash-1")
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, token.ID)
+		assert.Nil(t, token.UsedAt)
+
+		_, err = repo.GetRefreshTokenByHash(ctx, "missing")
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("RotateRefreshToken", func(t *testing.T) {
+		now := time.Now()
+		first.UsedAt = &now
+		second := &models.RefreshToken{UserID: 1, FamilyID: "family-a", TokenHash: "hash-2", ExpiresAt: expiresAt}
+
+		assert.NoError(t, repo.RotateRefreshToken(ctx, first, second))
+		assert.NotZero(t, second.ID)
+
+		stored, err := repo.GetRefreshTokenByHash(ctx, "hash-1")
+		assert.NoError(t, err)
+		assert.NotNil(t, stored.UsedAt)
+
+		// A token can only be rotated once, and a failed rotation stores nothing.
+		third := &models.RefreshToken{UserID: 1, FamilyID: "family-a", TokenHash: "hash-3", ExpiresAt: expiresAt}
+		assert.Equal(t, gorm.ErrRecordNotFound, repo.RotateRefreshToken(ctx, first, third))
+		_, err = repo.GetRefreshTokenByHash(ctx, "hash-3")
+		assert.Error(t, err)
+	})
+
+	t.Run("RevokeRefreshTokenFamily", func(t *testing.T) {
+		other := &models.RefreshToken{UserID: 1, FamilyID: "family-c", TokenHash: "hash-4", ExpiresAt: expiresAt}
+		assert.NoError(t, repo.CreateRefreshToken(ctx, other))
+
+		assert.NoError(t, repo.RevokeRefreshTokenFamily(ctx, "family-a", time.Now()))
+
+		for _, hash := range []string{"hash-1", "hash-2"} {
+			token, err := repo.GetRefreshTokenByHash(ctx, hash)
+			assert.NoError(t, err)
+			assert.NotNil(t, token.RevokedAt)
+		}
+
+		token, err := repo.GetRefreshTokenByHash(ctx, "hash-4")
+		assert.NoError(t, err)
+		assert.Nil(t, token.RevokedAt)
+	})
+	t.Run("RevokeUserRefreshTokens", func(t *testing.T) {
+		otherUser := &models.RefreshToken{UserID: 2, FamilyID: "family-d", TokenHash: "hash-5", ExpiresAt: expiresAt}
+		assert.NoError(t, repo.CreateRefreshToken(ctx, otherUser))
+
+		assert.NoError(t, repo.RevokeUserRefreshTokens(ctx, 1, time.Now()))
+
+		token, err := repo.GetRefreshTokenByHash(ctx, "hash-4")
+		assert.NoError(t, err)
+		assert.NotNil(t, token.RevokedAt)
+
+		token, err = repo.GetRefreshTokenByHash(ctx, "hash-5")
+		assert.NoError(t, err)
+		assert.Nil(t, token.RevokedAt)
+	})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedTokenRepository defines the required repository methods
+type RevokedTokenRepository interface {
+	RevokeToken(ctx context.Context, token *models.RevokedToken) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	ConsumeToken(ctx context.Context, token *models.RevokedToken) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, now time.Time) (int64, error)
+}
+
+// GormRevokedTokenRepository handles DB operations for revoked access tokens
+type GormRevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository initializes a new GormRevokedTokenRepository
+func NewRevokedTokenRepository(db *gorm.DB) *GormRevokedTokenRepository {
+	return &GormRevokedTokenRepository{db: db}
+}
+
+// RevokeToken records a revoked access token. Revoking a token twice is not an error.
+func (r *GormRevokedTokenRepository) RevokeToken(ctx context.Context, token *models.RevokedToken) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "token_id"}}, DoNothing: true}).
+		Create(token).Error
+}
+
+// ConsumeToken records a single-use token as used. It reports false when
+// the token was used before, so of two concurrent uses only one succeeds.
+func (r *GormRevokedTokenRepository) ConsumeToken(ctx context.Context, token *models.RevokedToken) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "token_id"}}, DoNothing: true}).
+		Create(token)
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsTokenRevoked reports whether the access token with the given ID was revoked
+func (r *GormRevokedTokenRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpiredRevokedTokens removes revoked tokens that have expired anyway
+func (r *GormRevokedTokenRepository) DeleteExpiredRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokedTokenRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRevokedTokenRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("RevokeToken", func(t *testing.T) {
+		assert.NoError(t, repo.RevokeToken(ctx, &models.RevokedToken{TokenID: "jti-1", UserID: 1, ExpiresAt: now.Add(time.Hour)}))
+		// Logging out twice with the same token is harmless.
+		assert.NoError(t, repo.RevokeToken(ctx, &models.RevokedToken{TokenID: "jti-1", UserID: 1, ExpiresAt: now.Add(time.Hour)}))
+	})
+
+	t.Run("IsTokenRevoked", func(t *testing.T) {
+		revoked, err := repo.IsTokenRevoked(ctx, "jti-1")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = repo.IsTokenRevoked(ctx, "jti-2")
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("ConsumeToken", func(t *testing.T) {
+		consumed, err := repo.ConsumeToken(ctx, &models.RevokedToken{TokenID: "reset-1", UserID: 1, ExpiresAt: now.Add(time.Hour)})
+		assert.NoError(t, err)
+		assert.True(t, consumed)
+
+		consumed, err = repo.ConsumeToken(ctx, &models.RevokedToken{TokenID: "reset-1", UserID: 1, ExpiresAt: now.Add(time.Hour)})
+		assert.NoError(t, err)
+		assert.False(t, consumed)
+	})
+
+	t.Run("DeleteExpiredRevokedTokens", func(t *testing.T) {
+		assert.NoError(t, repo.RevokeToken(ctx, &models.RevokedToken{TokenID: "jti-3", UserID: 1, ExpiresAt: now.Add(-time.Minute)}))
+
+		deleted, err := repo.DeleteExpiredRevokedTokens(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		revoked, err := repo.IsTokenRevoked(ctx, "jti-1")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// RuleRepository defines the required repository methods
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	GetRuleByID(ctx context.Context, id uint) (*models.Rule, error)
+	GetRulesByUserID(ctx context.Context, userID uint) ([]models.Rule, error)
+	UpdateRule(ctx context.Context, rule *models.Rule) error
+	DeleteRule(ctx context.Context, id uint) error
+}
+
+// GormRuleRepository handles DB operations for categorisation rules
+type GormRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewRuleRepository initializes a new GormRuleRepository
+func NewRuleRepository(db *gorm.DB) *GormRuleRepository {
+	return &GormRuleRepository{db: db}
+}
+
+// CreateRule inserts a new rule into the database
+func (r *GormRuleRepository) CreateRule(ctx context.Context, rule *models.Rule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// GetRuleByID retrieves a rule by its ID
+func (r *GormRuleRepository) GetRuleByID(ctx context.Context, id uint) (*models.Rule, error) {
+	var rule models.Rule
+	err := r.db.WithContext(ctx).First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetRulesByUserID fetches all rules for a specific user in evaluation order
+func (r *GormRuleRepository) GetRulesByUserID(ctx context.Context, userID uint) ([]models.Rule, error) {
+	var rules []models.Rule
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("priority ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// UpdateRule saves changes to an existing rule
+func (r *GormRuleRepository) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+// DeleteRule removes a rule from the database
+func (r *GormRuleRepository) DeleteRule(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Rule{}, id).Error
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupRuleTestDB initializes an in-memory SQLite database for testing.
+func setupRuleTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t)
+	err := database.ApplyMigrations(db)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestRuleRepository(t *testing.T) {
+	db := setupRuleTestDB(t)
+	repo := NewRuleRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Name: "Test User", Email: "test@example.com", Password: "hashedpassword"}
+	db.Create(user)
+
+	categoryID := uint(4)
+	minAmount := 10.0
+	rent := &models.Rule{UserID: user.ID, Name: "Rent", Priority: 20, NoteContains: "rent", CategoryID: &categoryID}
+	groceries := &models.Rule{UserID: user.ID, Name: "Groceries", Priority: 10, PayeePattern: "^REWE", MinAmount: &minAmount, Tags: "food,weekly"}
+
+	t.Run("CreateRule", func(t *testing.T) {
+		assert.NoError(t, repo.CreateRule(ctx, rent))
+		assert.NoError(t, repo.CreateRule(ctx, groceries))
+		assert.NoError(t, repo.CreateRule(ctx, &models.Rule{UserID: user.ID + 1, Name: "Other", NoteContains: "x", Payee: "y"}))
+		assert.NotZero(t, rent.ID)
+	})
+
+	t.Run("GetRulesByUserID", func(t *testing.T) {
+		rules, err := repo.GetRulesByUserID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+		assert.Equal(t, "Groceries", rules[0].Name)
+		assert.Equal(t, 10.0, *rules[0].MinAmount)
+		assert.Nil(t, rules[0].MaxAmount)
+		assert.Equal(t, []string{"food", "weekly"}, rules[0].TagNames())
+		assert.Equal(t, categoryID, *rules[1].CategoryID)
+	})
+
+	t.Run("UpdateRule", func(t *testing.T) {
+		rent.Priority = 1
+		rent.Payee = "Landlord"
+		assert.NoError(t, repo.UpdateRule(ctx, rent))
+
+		stored, err := repo.GetRuleByID(ctx, rent.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Priority)
+		assert.Equal(t, "Landlord", stored.Payee)
+	})
+
+	t.Run("DeleteRule", func(t *testing.T) {
+		assert.NoError(t, repo.DeleteRule(ctx, rent.ID))
+
+		_, err := repo.GetRuleByID(ctx, rent.ID)
+		assert.Error(t, err)
+	})
+}
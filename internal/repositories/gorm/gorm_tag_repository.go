@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"gorm.io/gorm"
+)
+
+// TagRepository defines the required repository methods
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTagByID(ctx context.Context, id uint) (*models.Tag, error)
+	GetTagsByUserID(ctx context.Context, userID uint) ([]models.Tag, error)
+	GetTagsByNames(ctx context.Context, userID uint, names []string) ([]models.Tag, error)
+	DeleteTag(ctx context.Context, id uint) error
+}
+
+// GormTagRepository handles DB operations for tags
+type GormTagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository initializes a new GormTagRepository
+func NewTagRepository(db *gorm.DB) *GormTagRepository {
+	return &GormTagRepository{db: db}
+}
+
+// CreateTag inserts a new tag into the database
+func (r *GormTagRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+// GetTagByID retrieves a tag by its ID
+func (r *GormTagRepository) GetTagByID(ctx context.Context, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.WithContext(ctx).First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetTagsByUserID fetches all tags for a specific user ordered by name
+func (r *GormTagRepository) GetTagsByUserID(ctx context.Context, userID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// GetTagsByNames fetches the user's tags matching the given names
+func (r *GormTagRepository) GetTagsByNames(ctx context.Context, userID uint, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	err := r.db.WithContext(ctx).Where("user_id = ? AND name IN ?", userID, names).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+// DeleteTag removes a tag and detaches it from all transactions in one database transaction
+func (r *GormTagRepository) DeleteTag(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupTagTestDB initializes an in-memory SQLite database for testing.
+func setupTagTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t)
+	err := database.ApplyMigrations(db)
+	assert.NoError(t, err)
+	return db
+}
+
+func TestTagRepository(t *testing.T) {
+	db := setupTagTestDB(t)
+	repo := NewTagRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Name: "Test User", Email: "test@example.com", Password: "hashedpassword"}
+	db.Create(user)
+
+	vacation := &models.Tag{UserID: user.ID, Name: "vacation-2026"}
+	reimbursable := &models.Tag{UserID: user.ID, Name: "reimbursable"}
+
+	t.Run("CreateTag", func(t *testing.T) {
+		assert.NoError(t, repo.CreateTag(ctx, vacation))
+		assert.NoError(t, repo.CreateTag(ctx, reimbursable))
+		assert.NotZero(t, vacation.ID)
+
+		// Names are unique per user.
+		assert.Error(t, repo.CreateTag(ctx, &models.Tag{UserID: user.ID, Name: "vacation-2026"}))
+		assert.NoError(t, repo.CreateTag(ctx, &models.Tag{UserID: user.ID + 1, Name: "vacation-2026"}))
+	})
+
+	t.Run("GetTagsByUserID", func(t *testing.T) {
+		tags, err := repo.GetTagsByUserID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+		assert.Equal(t, "reimbursable", tags[0].Name)
+	})
+
+	t.Run("GetTagsByNames", func(t *testing.T) {
+		tags, err := repo.GetTagsByNames(ctx, user.ID, []string{"vacation-2026", "unknown"})
+		assert.NoError(t, err)
+		assert.Len(t, tags, 1)
+		assert.Equal(t, vacation.ID, tags[0].ID)
+	})
+
+	t.Run("DeleteTag", func(t *testing.T) {
+		transaction := &models.Transaction{UserID: user.ID, Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{*vacation}}
+		assert.NoError(t, db.Create(transaction).Error)
+
+		assert.NoError(t, repo.DeleteTag(ctx, vacation.ID))
+
+		_, err := repo.GetTagByID(ctx, vacation.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+		var assignments int64
+		db.Table("transaction_tags").Where("tag_id = ?", vacation.ID).Count(&assignments)
+		assert.Equal(t, int64(0), assignments)
+	})
+}
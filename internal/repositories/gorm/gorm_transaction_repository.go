@@ -12,13 +12,26 @@ import (
 // TransactionRepository defines the required repository methods
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, transaction *models.Transaction) error
+	CreateTransactions(ctx context.Context, transactions []models.Transaction) error
 	GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error)
+	GetExistingExternalIDs(ctx context.Context, userID uint, externalIDs []string) ([]string, error)
 	GetTransactionsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.Transaction, error)
 	GetTransactionsPageByUserID(ctx context.Context, userID uint, params pagination.Params, filters filters.TransactionFilters) ([]models.Transaction, int64, error)
+	StreamTransactionsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters, fn func(models.Transaction) error) error
 	UpdateTransaction(ctx context.Context, transaction *models.Transaction) error
+	UpdateTransactions(ctx context.Context, transactions []models.Transaction) error
 	DeleteTransaction(ctx context.Context, id uint) error
+	MergeTransactions(ctx context.Context, kept *models.Transaction, duplicateIDs []uint) error
+	CreateTransfer(ctx context.Context, transfer *models.Transfer) error
+	GetTransferByID(ctx context.Context, id uint) (*models.Transfer, error)
+	DeleteTransfer(ctx context.Context, id uint) error
+	GetCategoryTotalsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.CategoryTotal, error)
+	GetTagTotalsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.TagTotal, error)
 }
 
+// streamBatchSize is how many transactions StreamTransactionsByUserID reads at a time.
+const streamBatchSize = 500
+
 // TransactionRepository handles DB operations for transactions
 type GormTransactionRepository struct {
 	db *gorm.DB
@@ -29,15 +42,90 @@ func NewTransactionRepository(db *gorm.DB) *GormTransactionRepository {
 	return &GormTransactionRepository{db: db}
 }
 
-// CreateTransaction inserts a new transaction into the database
+// CreateTransaction inserts a new transaction into the database, together
+// with the tags it names that are not stored yet
 func (r *GormTransactionRepository) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
-	return r.db.WithContext(ctx).Create(transaction).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createMissingTags(tx, transaction.Tags, map[tagKey]uint{}); err != nil {
+			return err
+		}
+		return tx.Create(transaction).Error
+	})
+}
+
+// CreateTransactions inserts several transactions in one database
+// transaction, together with the tags they name that are not stored yet
+func (r *GormTransactionRepository) CreateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created := map[tagKey]uint{}
+		for i := range transactions {
+			if err := createMissingTags(tx, transactions[i].Tags, created); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&transactions).Error
+	})
+}
+
+type tagKey struct {
+	userID uint
+	name   string
+}
+
+// createMissingTags stores the tags that have no ID yet inside tx, so they
+// are rolled back with the transactions that name them. created remembers
+// the tags made earlier in the same database transaction, so a new tag named
+// by several transactions is only created once.
+func createMissingTags(tx *gorm.DB, tags []models.Tag, created map[tagKey]uint) error {
+	for i := range tags {
+		tag := &tags[i]
+		if tag.ID != 0 {
+			continue
+		}
+
+		key := tagKey{userID: tag.UserID, name: tag.Name}
+		if id, ok := created[key]; ok {
+			tag.ID = id
+			continue
+		}
+
+		if err := tx.Where(models.Tag{UserID: tag.UserID, Name: tag.Name}).FirstOrCreate(tag).Error; err != nil {
+			return err
+		}
+		created[key] = tag.ID
+	}
+
+	return nil
+}
+
+// GetExistingExternalIDs returns which of the given external IDs the user
+// has already imported
+func (r *GormTransactionRepository) GetExistingExternalIDs(ctx context.Context, userID uint, externalIDs []string) ([]string, error) {
+	existing := []string{}
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("user_id = ? AND external_id IN ?", userID, externalIDs).
+		Distinct().
+		Pluck("external_id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
 }
 
 // GetTransactionByID retrieves a transaction by its ID
 func (r *GormTransactionRepository) GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.WithContext(ctx).First(&transaction, id).Error
+	err := r.db.WithContext(ctx).Preload("Splits").Preload("Tags").First(&transaction, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +136,7 @@ func (r *GormTransactionRepository) GetTransactionByID(ctx context.Context, id u
 func (r *GormTransactionRepository) GetTransactionsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.Transaction, error) {
 	var transactions []models.Transaction
 	err := r.transactionQuery(ctx, userID, transactionFilters).
+		Preload("Splits").Preload("Tags").
 		Order("date DESC").
 		Order("id DESC").
 		Find(&transactions).Error
@@ -67,6 +156,7 @@ func (r *GormTransactionRepository) GetTransactionsPageByUserID(ctx context.Cont
 	}
 
 	err := r.transactionQuery(ctx, userID, transactionFilters).
+		Preload("Splits").Preload("Tags").
 		Order("date DESC").
 		Order("id DESC").
 		Offset(params.Offset()).
@@ -79,34 +169,263 @@ func (r *GormTransactionRepository) GetTransactionsPageByUserID(ctx context.Cont
 	return transactions, total, nil
 }
 
-// UpdateTransaction updates an existing transaction
+// StreamTransactionsByUserID calls fn for each of a user's transactions
+// matching the filters, oldest first, and stops at the first error fn
+// returns. Transactions are read in keyset-paginated batches with their
+// split lines and tags, so memory use does not grow with the user's history
+// and no connection is held between batches.
+func (r *GormTransactionRepository) StreamTransactionsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters, fn func(models.Transaction) error) error {
+	var last *models.Transaction
+
+	for {
+		query := r.transactionQuery(ctx, userID, transactionFilters)
+		if last != nil {
+			query = query.Where("(transactions.date > ? OR (transactions.date = ? AND transactions.id > ?))", last.Date, last.Date, last.ID)
+		}
+
+		var batch []models.Transaction
+		err := query.
+			Preload("Splits").Preload("Tags").
+			Order("date ASC").
+			Order("id ASC").
+			Limit(streamBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+
+		for _, transaction := range batch {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// UpdateTransaction updates an existing transaction, replacing its split lines and tags
 func (r *GormTransactionRepository) UpdateTransaction(ctx context.Context, transaction *models.Transaction) error {
-	return r.db.WithContext(ctx).Save(transaction).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveTransaction(tx, transaction, map[tagKey]uint{})
+	})
+}
+
+// UpdateTransactions updates several transactions in one database transaction,
+// replacing their split lines and tags
+func (r *GormTransactionRepository) UpdateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created := map[tagKey]uint{}
+		for i := range transactions {
+			if err := saveTransaction(tx, &transactions[i], created); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// DeleteTransaction removes a transaction from the database
+func saveTransaction(tx *gorm.DB, transaction *models.Transaction, createdTags map[tagKey]uint) error {
+	if err := createMissingTags(tx, transaction.Tags, createdTags); err != nil {
+		return err
+	}
+
+	if err := tx.Omit("Splits", "Tags").Save(transaction).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+
+	for i := range transaction.Splits {
+		transaction.Splits[i].ID = 0
+		transaction.Splits[i].TransactionID = transaction.ID
+	}
+
+	if len(transaction.Splits) > 0 {
+		if err := tx.Create(&transaction.Splits).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(transaction).Association("Tags").Replace(transaction.Tags)
+}
+
+// DeleteTransaction removes a transaction with its split lines and tag assignments from the database
 func (r *GormTransactionRepository) DeleteTransaction(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.Transaction{}, id).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", id).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Transaction{}, id).Error
+	})
+}
+
+// MergeTransactions saves the kept transaction and removes the duplicates,
+// with their split lines and tag assignments, in one database transaction
+func (r *GormTransactionRepository) MergeTransactions(ctx context.Context, kept *models.Transaction, duplicateIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Splits", "Tags").Save(kept).Error; err != nil {
+			return err
+		}
+
+		if err := createMissingTags(tx, kept.Tags, map[tagKey]uint{}); err != nil {
+			return err
+		}
+
+		if err := tx.Model(kept).Association("Tags").Replace(kept.Tags); err != nil {
+			return err
+		}
+
+		if len(duplicateIDs) == 0 {
+			return nil
+		}
+
+		if err := tx.Where("transaction_id IN ?", duplicateIDs).Delete(&models.TransactionSplit{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN ?", duplicateIDs).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Transaction{}, duplicateIDs).Error
+	})
+}
+
+// CreateTransfer inserts a transfer together with its debit and credit legs
+func (r *GormTransactionRepository) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+// GetTransferByID retrieves a transfer and its legs by the transfer ID
+func (r *GormTransactionRepository) GetTransferByID(ctx context.Context, id uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.WithContext(ctx).Preload("Legs").First(&transfer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// DeleteTransfer removes a transfer and both of its legs in one database transaction
+func (r *GormTransactionRepository) DeleteTransfer(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transfer_id = ?", id).Delete(&models.Transaction{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Transfer{}, id).Error
+	})
+}
+
+// GetCategoryTotalsByUserID sums income and expense amounts per category. Split
+// transactions contribute each split line to its own category and transfer legs are excluded.
+func (r *GormTransactionRepository) GetCategoryTotalsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.CategoryTotal, error) {
+	lineCategory := "COALESCE(transaction_splits.category_id, transactions.category_id)"
+
+	categoryFilter := transactionFilters
+	categoryFilter.CategoryID = nil
+
+	query := r.transactionQuery(ctx, userID, categoryFilter).
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Select(lineCategory+` AS category_id, transactions."type" AS "type", SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS total`).
+		Where(`transactions."type" IN ?`, []string{"income", "expense"})
+
+	if transactionFilters.CategoryID != nil {
+		query = query.Where(lineCategory+" = ?", *transactionFilters.CategoryID)
+	}
+
+	var totals []models.CategoryTotal
+	err := query.
+		Group(lineCategory + `, transactions."type"`).
+		Order("category_id ASC").
+		Order(`"type" ASC`).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetTagTotalsByUserID sums income and expense amounts per tag. A transaction
+// with several tags counts in full towards each of them.
+func (r *GormTransactionRepository) GetTagTotalsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.TagTotal, error) {
+	var totals []models.TagTotal
+	err := r.transactionQuery(ctx, userID, transactionFilters).
+		Joins("JOIN transaction_tags ON transaction_tags.transaction_id = transactions.id").
+		Joins("JOIN tags ON tags.id = transaction_tags.tag_id").
+		Select(`tags.id AS tag_id, tags.name AS name, transactions."type" AS "type", SUM(transactions.amount) AS total`).
+		Where(`transactions."type" IN ?`, []string{"income", "expense"}).
+		Group(`tags.id, tags.name, transactions."type"`).
+		Order("name ASC").
+		Order(`"type" ASC`).
+		Scan(&totals).Error
+	return totals, err
 }
 
 func (r *GormTransactionRepository) transactionQuery(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) *gorm.DB {
-	query := r.db.WithContext(ctx).Model(&models.Transaction{}).Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Model(&models.Transaction{}).Where("transactions.user_id = ?", userID)
 
 	if transactionFilters.Type != "" {
-		query = query.Where("\"type\" = ?", transactionFilters.Type)
+		query = query.Where("transactions.\"type\" = ?", transactionFilters.Type)
 	}
 
+	// A split transaction matches a category when any of its split lines does.
 	if transactionFilters.CategoryID != nil {
-		query = query.Where("category_id = ?", *transactionFilters.CategoryID)
+		query = query.Where(
+			`(EXISTS (SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id AND transaction_splits.category_id = ?)
+			OR (transactions.category_id = ? AND NOT EXISTS (SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id)))`,
+			*transactionFilters.CategoryID, *transactionFilters.CategoryID,
+		)
+	}
+
+	if transactionFilters.AccountID != nil {
+		query = query.Where("transactions.account_id = ?", *transactionFilters.AccountID)
+	}
+
+	if len(transactionFilters.Tags) > 0 {
+		taggedWith := `SELECT COUNT(DISTINCT tags.name) FROM transaction_tags
+			JOIN tags ON tags.id = transaction_tags.tag_id
+			WHERE transaction_tags.transaction_id = transactions.id AND tags.name IN ?`
+
+		if transactionFilters.TagMatch == filters.TagMatchAll {
+			query = query.Where("("+taggedWith+") = ?", transactionFilters.Tags, len(uniqueStrings(transactionFilters.Tags)))
+		} else {
+			query = query.Where("("+taggedWith+") > 0", transactionFilters.Tags)
+		}
 	}
 
 	if transactionFilters.From != nil {
-		query = query.Where("date >= ?", *transactionFilters.From)
+		query = query.Where("transactions.date >= ?", *transactionFilters.From)
 	}
 
 	if transactionFilters.To != nil {
-		query = query.Where("date <= ?", *transactionFilters.To)
+		query = query.Where("transactions.date <= ?", *transactionFilters.To)
 	}
 
 	return query
 }
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -181,6 +182,293 @@ func TestTransactionRepository(t *testing.T) {
 		assert.Error(t, err) // Should return an error because it's deleted
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
+
+	t.Run("CreateTransfer", func(t *testing.T) {
+		fromAccountID, toAccountID := uint(1), uint(2)
+		transfer := &models.Transfer{
+			UserID:        user.ID,
+			FromAccountID: fromAccountID,
+			ToAccountID:   toAccountID,
+			Amount:        75,
+			Date:          time.Now(),
+			Legs: []models.Transaction{
+				{UserID: user.ID, Type: "transfer", Amount: -75, AccountID: &fromAccountID, Date: time.Now()},
+				{UserID: user.ID, Type: "transfer", Amount: 75, AccountID: &toAccountID, Date: time.Now()},
+			},
+		}
+
+		err := repo.CreateTransfer(ctx, transfer)
+		assert.NoError(t, err)
+
+		found, err := repo.GetTransferByID(ctx, transfer.ID)
+		assert.NoError(t, err)
+		assert.Len(t, found.Legs, 2)
+		assert.Equal(t, transfer.ID, *found.Legs[0].TransferID)
+
+		err = repo.DeleteTransfer(ctx, transfer.ID)
+		assert.NoError(t, err)
+
+		var legCount int64
+		db.Model(&models.Transaction{}).Where("transfer_id = ?", transfer.ID).Count(&legCount)
+		assert.Equal(t, int64(0), legCount)
+
+		_, err = repo.GetTransferByID(ctx, transfer.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("GetCategoryTotalsByUserID", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "expense", Amount: 40, CategoryID: 1, Date: time.Now()}))
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now()}))
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "income", Amount: 300, CategoryID: 2, Date: time.Now()}))
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "transfer", Amount: 20, Date: time.Now()}))
+
+		totals, err := repo.GetCategoryTotalsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, totals, 2)
+		assert.InDelta(t, 50, totals[0].Total, 0.001)
+		assert.Equal(t, "expense", totals[0].Type)
+		assert.InDelta(t, 300, totals[1].Total, 0.001)
+	})
+
+	t.Run("SplitTransactions", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		receipt := &models.Transaction{
+			UserID:     user.ID,
+			Type:       "expense",
+			Amount:     80,
+			CategoryID: 5,
+			Date:       time.Now(),
+			Splits: []models.TransactionSplit{
+				{CategoryID: 5, Amount: 50},
+				{CategoryID: 6, Amount: 30},
+			},
+		}
+		assert.NoError(t, repo.CreateTransaction(ctx, receipt))
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "expense", Amount: 15, CategoryID: 6, Date: time.Now()}))
+
+		found, err := repo.GetTransactionByID(ctx, receipt.ID)
+		assert.NoError(t, err)
+		assert.Len(t, found.Splits, 2)
+
+		// The receipt matches the category of its second split line too.
+		categoryID := uint(6)
+		transactions, err := repo.GetTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{CategoryID: &categoryID})
+		assert.NoError(t, err)
+		assert.Len(t, transactions, 2)
+
+		totals, err := repo.GetCategoryTotalsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, totals, 2)
+		assert.InDelta(t, 50, totals[0].Total, 0.001)
+		assert.InDelta(t, 45, totals[1].Total, 0.001)
+
+		totals, err = repo.GetCategoryTotalsByUserID(ctx, user.ID, filters.TransactionFilters{CategoryID: &categoryID})
+		assert.NoError(t, err)
+		assert.Len(t, totals, 1)
+		assert.InDelta(t, 45, totals[0].Total, 0.001)
+
+		assert.NoError(t, repo.DeleteTransaction(ctx, receipt.ID))
+
+		var splitCount int64
+		db.Model(&models.TransactionSplit{}).Where("transaction_id = ?", receipt.ID).Count(&splitCount)
+		assert.Equal(t, int64(0), splitCount)
+	})
+
+	t.Run("TaggedTransactions", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		vacation := models.Tag{UserID: user.ID, Name: "vacation-2026"}
+		reimbursable := models.Tag{UserID: user.ID, Name: "reimbursable"}
+		assert.NoError(t, db.Create(&vacation).Error)
+		assert.NoError(t, db.Create(&reimbursable).Error)
+
+		hotel := &models.Transaction{UserID: user.ID, Type: "expense", Amount: 300, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{vacation, reimbursable}}
+		dinner := &models.Transaction{UserID: user.ID, Type: "expense", Amount: 40, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{vacation}}
+		assert.NoError(t, repo.CreateTransaction(ctx, hotel))
+		assert.NoError(t, repo.CreateTransaction(ctx, dinner))
+		assert.NoError(t, repo.CreateTransaction(ctx, &models.Transaction{UserID: user.ID, Type: "expense", Amount: 5, CategoryID: 1, Date: time.Now()}))
+
+		anyTag := filters.TransactionFilters{Tags: []string{"vacation-2026", "reimbursable"}}
+		transactions, err := repo.GetTransactionsByUserID(ctx, user.ID, anyTag)
+		assert.NoError(t, err)
+		assert.Len(t, transactions, 2)
+
+		allTags := filters.TransactionFilters{Tags: []string{"vacation-2026", "reimbursable"}, TagMatch: filters.TagMatchAll}
+		transactions, err = repo.GetTransactionsByUserID(ctx, user.ID, allTags)
+		assert.NoError(t, err)
+		assert.Len(t, transactions, 1)
+		assert.Equal(t, hotel.ID, transactions[0].ID)
+		assert.Len(t, transactions[0].Tags, 2)
+
+		totals, err := repo.GetTagTotalsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, totals, 2)
+		assert.Equal(t, "reimbursable", totals[0].Name)
+		assert.InDelta(t, 300, totals[0].Total, 0.001)
+		assert.InDelta(t, 340, totals[1].Total, 0.001)
+
+		// Updating replaces the tag set.
+		dinner.Tags = []models.Tag{reimbursable}
+		assert.NoError(t, repo.UpdateTransaction(ctx, dinner))
+
+		found, err := repo.GetTransactionByID(ctx, dinner.ID)
+		assert.NoError(t, err)
+		assert.Len(t, found.Tags, 1)
+		assert.Equal(t, "reimbursable", found.Tags[0].Name)
+	})
+
+	t.Run("CreateTransactions", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		transactions := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 12, CategoryID: 1, Date: time.Now(), Note: "Coffee"},
+			{UserID: user.ID, Type: "income", Amount: 900, CategoryID: 2, Date: time.Now(), Note: "Refund"},
+		}
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+		assert.NotZero(t, transactions[1].ID)
+
+		stored, err := repo.GetTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, stored, 2)
+	})
+
+	t.Run("CreateTransactionsWithNewTags", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		transactions := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 30, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{{UserID: user.ID, Name: "groceries"}}},
+			{UserID: user.ID, Type: "expense", Amount: 20, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{{UserID: user.ID, Name: "groceries"}}},
+		}
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+
+		var count int64
+		db.Model(&models.Tag{}).Where("user_id = ? AND name = ?", user.ID, "groceries").Count(&count)
+		assert.Equal(t, int64(1), count, "a new tag named twice is created once")
+		assert.Equal(t, transactions[0].Tags[0].ID, transactions[1].Tags[0].ID)
+
+		// A failed insert rolls the new tags back with it.
+		failing := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 5, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{{UserID: user.ID, Name: "rolled-back"}}},
+			{ID: transactions[0].ID, UserID: user.ID, Type: "expense", Amount: 5, CategoryID: 1, Date: time.Now()},
+		}
+		assert.Error(t, repo.CreateTransactions(ctx, failing))
+		db.Model(&models.Tag{}).Where("user_id = ? AND name = ?", user.ID, "rolled-back").Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("GetExistingExternalIDs", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		transactions := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 12, CategoryID: 1, Date: time.Now(), ExternalID: "FIT-1"},
+			{UserID: user.ID + 1, Type: "expense", Amount: 12, CategoryID: 1, Date: time.Now(), ExternalID: "FIT-2"},
+		}
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+
+		existing, err := repo.GetExistingExternalIDs(ctx, user.ID, []string{"FIT-1", "FIT-2", "FIT-3"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"FIT-1"}, existing)
+	})
+
+	t.Run("MergeTransactions", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		tag := models.Tag{UserID: user.ID, Name: "merged"}
+		assert.NoError(t, db.Create(&tag).Error)
+
+		transactions := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 12, CategoryID: 1, Date: time.Now(), Note: "Lunch"},
+			{UserID: user.ID, Type: "expense", Amount: 12, CategoryID: 1, Date: time.Now(), Note: "LUNCH", ExternalID: "FIT-9", Tags: []models.Tag{tag}},
+		}
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+
+		kept := transactions[0]
+		kept.ExternalID = "FIT-9"
+		kept.Tags = []models.Tag{tag}
+		assert.NoError(t, repo.MergeTransactions(ctx, &kept, []uint{transactions[1].ID}))
+
+		stored, err := repo.GetTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, stored, 1)
+		assert.Equal(t, "FIT-9", stored[0].ExternalID)
+		assert.Len(t, stored[0].Tags, 1)
+
+		var tagRows int64
+		db.Table("transaction_tags").Where("transaction_id = ?", transactions[1].ID).Count(&tagRows)
+		assert.Zero(t, tagRows)
+	})
+
+	t.Run("UpdateTransactions", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		tag := models.Tag{UserID: user.ID, Name: "utilities"}
+		assert.NoError(t, db.Create(&tag).Error)
+
+		transactions := []models.Transaction{
+			{UserID: user.ID, Type: "expense", Amount: 80, CategoryID: 1, Date: time.Now(), Payee: "STADTWERKE"},
+			{UserID: user.ID, Type: "expense", Amount: 60, CategoryID: 1, Date: time.Now(), Payee: "STADTWERKE"},
+		}
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+
+		for i := range transactions {
+			transactions[i].CategoryID = 8
+			transactions[i].Payee = "Stadtwerke"
+			transactions[i].Tags = []models.Tag{tag}
+		}
+		assert.NoError(t, repo.UpdateTransactions(ctx, transactions))
+
+		stored, err := repo.GetTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, stored, 2)
+		for _, transaction := range stored {
+			assert.Equal(t, uint(8), transaction.CategoryID)
+			assert.Equal(t, "Stadtwerke", transaction.Payee)
+			assert.Len(t, transaction.Tags, 1)
+		}
+
+		assert.NoError(t, repo.UpdateTransactions(ctx, nil))
+	})
+
+	t.Run("StreamTransactionsByUserID", func(t *testing.T) {
+		db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Transaction{})
+
+		// More than one batch, with several transactions sharing a date so
+		// the keyset has to fall back to the ID.
+		start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		transactions := make([]models.Transaction, 0, streamBatchSize+20)
+		for i := 0; i < streamBatchSize+20; i++ {
+			transactions = append(transactions, models.Transaction{
+				UserID: user.ID, Type: "expense", Amount: float64(i + 1), CategoryID: 1,
+				Date: start.Add(time.Duration(i/3) * time.Hour),
+			})
+		}
+		transactions = append(transactions, models.Transaction{UserID: user.ID, Type: "income", Amount: 5, CategoryID: 2, Date: start})
+		assert.NoError(t, repo.CreateTransactions(ctx, transactions))
+
+		var streamed []models.Transaction
+		err := repo.StreamTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{Type: "expense"}, func(transaction models.Transaction) error {
+			streamed = append(streamed, transaction)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, streamed, streamBatchSize+20)
+		for i := 1; i < len(streamed); i++ {
+			previous, current := streamed[i-1], streamed[i]
+			assert.True(t, previous.Date.Before(current.Date) || (previous.Date.Equal(current.Date) && previous.ID < current.ID))
+		}
+
+		stop := errors.New("stop")
+		calls := 0
+		err = repo.StreamTransactionsByUserID(ctx, user.ID, filters.TransactionFilters{}, func(models.Transaction) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
 }
 
 func ptrTime(value time.Time) *time.Time {
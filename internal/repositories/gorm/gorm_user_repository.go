@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"time"
 
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 	"gorm.io/gorm"
 )
@@ -10,7 +12,14 @@ import (
 // UserRepository defines the required repository methods
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	ScheduleUserDeletion(ctx context.Context, id uint, at *time.Time) error
+	GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error)
+	EraseUser(ctx context.Context, id uint) error
+	InvalidateUserTokens(ctx context.Context, id uint, before time.Time) error
+	MarkEmailVerified(ctx context.Context, id uint, email string, at time.Time) (bool, error)
 }
 
 // GormUserRepository handles DB operations for users
@@ -28,6 +37,16 @@ func (r *GormUserRepository) CreateUser(ctx context.Context, user *models.User)
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+// GetUserByID retrieves a user by ID
+func (r *GormUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // GetUserByEmail retrieves a user by email
 func (r *GormUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
@@ -38,7 +57,95 @@ func (r *GormUserRepository) GetUserByEmail(ctx context.Context, email string) (
 	return &user, nil
 }
 
+// UpdateUser saves a user's name, email and password
+func (r *GormUserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Model(user).Select("name", "email", "password", "email_verified_at").Updates(user).Error
+}
+
 // DeleteUser removes a user from the database
 func (r *GormUserRepository) DeleteUser(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
+
+// ScheduleUserDeletion sets when a user's account will be erased, or clears
+// the schedule when at is nil
+func (r *GormUserRepository) ScheduleUserDeletion(ctx context.Context, id uint, at *time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("deletion_scheduled_at", at).Error
+}
+
+// GetUsersDueForDeletion retrieves the users whose scheduled deletion is due
+func (r *GormUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// EraseUser removes a user, everything they own, their refresh and revoked
+// tokens, their recovery codes and the failed logins counted against their
+// email address in one database transaction. Categories are shared between
+// users and are left alone. Pending single sign-on logins are not tied to
+// an account until they come back, so there are none of the user's to
+// remove; they expire on their own.
+func (r *GormUserRepository) EraseUser(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id", "email").First(&user, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("key_hash = ?", auth.AccountThrottleKey(user.Email)).Delete(&models.LoginThrottle{}).Error; err != nil {
+			return err
+		}
+
+		statements := []string{
+			"DELETE FROM transaction_tags WHERE transaction_id IN (SELECT id FROM transactions WHERE user_id = ?)",
+			"DELETE FROM transaction_tags WHERE tag_id IN (SELECT id FROM tags WHERE user_id = ?)",
+			"DELETE FROM transaction_splits WHERE transaction_id IN (SELECT id FROM transactions WHERE user_id = ?)",
+			"DELETE FROM transactions WHERE user_id = ?",
+			"DELETE FROM transfers WHERE user_id = ?",
+			"DELETE FROM budgets WHERE user_id = ?",
+			"DELETE FROM accounts WHERE user_id = ?",
+			"DELETE FROM tags WHERE user_id = ?",
+			"DELETE FROM rules WHERE user_id = ?",
+			"DELETE FROM classifier_tokens WHERE user_id = ?",
+			"DELETE FROM classifier_categories WHERE user_id = ?",
+			"DELETE FROM refresh_tokens WHERE user_id = ?",
+			"DELETE FROM revoked_tokens WHERE user_id = ?",
+			"DELETE FROM recovery_codes WHERE user_id = ?",
+			"DELETE FROM api_keys WHERE user_id = ?",
+			"DELETE FROM user_identities WHERE user_id = ?",
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement, id).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Delete(&models.User{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// InvalidateUserTokens sets the time before which the user's access tokens
+// are no longer accepted
+func (r *GormUserRepository) InvalidateUserTokens(ctx context.Context, id uint, before time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("tokens_invalid_before", before).Error
+}
+
+// MarkEmailVerified records that the user verified the given email address.
+// It reports false, and changes nothing, when the user's address is no
+// longer the one that was verified.
+func (r *GormUserRepository) MarkEmailVerified(ctx context.Context, id uint, email string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email = ?", id, email).
+		Update("email_verified_at", at)
+	return result.RowsAffected > 0, result.Error
+}
@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/database"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -70,4 +72,150 @@ func TestUserRepository(t *testing.T) {
 		assert.Equal(t, gorm.ErrRecordNotFound, err)
 	})
 
+	t.Run("should retrieve a user by ID", func(t *testing.T) {
+		user := &models.User{Name: "Ann Lee", Email: "ann@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+
+		foundUser, err := repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "ann@example.com", foundUser.Email)
+
+		_, err = repo.GetUserByID(ctx, 9999)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("should update a user", func(t *testing.T) {
+		user := &models.User{Name: "Cara Diaz", Email: "cara@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+
+		user.Name = "Cara Díaz"
+		user.Email = "cara.diaz@example.com"
+		user.Password = "newhash"
+		assert.NoError(t, repo.UpdateUser(ctx, user))
+
+		foundUser, err := repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Cara Díaz", foundUser.Name)
+		assert.Equal(t, "cara.diaz@example.com", foundUser.Email)
+		assert.Equal(t, "newhash", foundUser.Password)
+
+		user.Email = "ann@example.com"
+		assert.Error(t, repo.UpdateUser(ctx, user))
+	})
+
+	t.Run("should schedule and cancel a deletion", func(t *testing.T) {
+		user := &models.User{Name: "Bo Chen", Email: "bo@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+
+		now := time.Now().UTC()
+		due := now.Add(-time.Minute)
+		assert.NoError(t, repo.ScheduleUserDeletion(ctx, user.ID, &due))
+
+		users, err := repo.GetUsersDueForDeletion(ctx, now)
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, user.ID, users[0].ID)
+
+		users, err = repo.GetUsersDueForDeletion(ctx, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+
+		assert.NoError(t, repo.ScheduleUserDeletion(ctx, user.ID, nil))
+		users, err = repo.GetUsersDueForDeletion(ctx, now)
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("should invalidate a user's tokens", func(t *testing.T) {
+		user := &models.User{Name: "Di Park", Email: "di@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+
+		before := time.Now().UTC().Truncate(time.Second)
+		assert.NoError(t, repo.InvalidateUserTokens(ctx, user.ID, before))
+
+		foundUser, err := repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, foundUser.TokensInvalidBefore) {
+			assert.True(t, before.Equal(*foundUser.TokensInvalidBefore))
+		}
+	})
+
+	t.Run("should mark the current email verified", func(t *testing.T) {
+		user := &models.User{Name: "Vi Tran", Email: "vi@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+		now := time.Now().UTC()
+
+		marked, err := repo.MarkEmailVerified(ctx, user.ID, "old@example.com", now)
+		assert.NoError(t, err)
+		assert.False(t, marked)
+
+		marked, err = repo.MarkEmailVerified(ctx, user.ID, "vi@example.com", now)
+		assert.NoError(t, err)
+		assert.True(t, marked)
+
+		foundUser, err := repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.True(t, foundUser.EmailVerified())
+
+		// Saving the user with the verification cleared, as when the email
+		// changes, clears it in the database too.
+		foundUser.Email = "vi.tran@example.com"
+		foundUser.EmailVerifiedAt = nil
+		assert.NoError(t, repo.UpdateUser(ctx, foundUser))
+
+		foundUser, err = repo.GetUserByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.False(t, foundUser.EmailVerified())
+	})
+
+	t.Run("should erase a user and everything they own", func(t *testing.T) {
+		user := &models.User{Name: "Erased", Email: "erased@example.com", Password: "hashedpassword"}
+		other := &models.User{Name: "Kept", Email: "kept@example.com", Password: "hashedpassword"}
+		assert.NoError(t, repo.CreateUser(ctx, user))
+		assert.NoError(t, repo.CreateUser(ctx, other))
+
+		for _, owner := range []*models.User{user, other} {
+			account := &models.Account{UserID: owner.ID, Name: "Checking", Type: "checking", Currency: "EUR"}
+			assert.NoError(t, db.Create(account).Error)
+			assert.NoError(t, db.Create(&models.Budget{UserID: owner.ID, CategoryID: 1, Limit: 100, StartDate: time.Now(), EndDate: time.Now().AddDate(0, 1, 0)}).Error)
+			assert.NoError(t, db.Create(&models.Rule{UserID: owner.ID, Name: "Rent", NoteContains: "rent", Payee: "Landlord"}).Error)
+			assert.NoError(t, db.Create(&models.APIKey{UserID: owner.ID, Name: "Sync", Prefix: "pft_", KeyHash: owner.Email, Scopes: "transactions:read"}).Error)
+			assert.NoError(t, db.Create(&models.UserIdentity{UserID: owner.ID, Issuer: "https://id.example.com", Subject: owner.Email}).Error)
+			assert.NoError(t, db.Create(&models.LoginThrottle{KeyHash: auth.AccountThrottleKey(owner.Email), Failures: 2, LastFailureAt: time.Now()}).Error)
+			assert.NoError(t, db.Create(&models.Transaction{
+				UserID:     owner.ID,
+				Type:       "expense",
+				Amount:     20,
+				CategoryID: 1,
+				AccountID:  &account.ID,
+				Date:       time.Now(),
+				Splits:     []models.TransactionSplit{{CategoryID: 1, Amount: 20}},
+				Tags:       []models.Tag{{UserID: owner.ID, Name: "food"}},
+			}).Error)
+		}
+
+		assert.NoError(t, repo.EraseUser(ctx, user.ID))
+
+		for _, model := range []any{&models.Transaction{}, &models.Account{}, &models.Tag{}, &models.Budget{}, &models.Rule{}, &models.APIKey{}, &models.UserIdentity{}} {
+			var count int64
+			assert.NoError(t, db.Model(model).Where("user_id = ?", user.ID).Count(&count).Error)
+			assert.Zero(t, count)
+			assert.NoError(t, db.Model(model).Where("user_id = ?", other.ID).Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+		}
+
+		var count int64
+		assert.NoError(t, db.Model(&models.LoginThrottle{}).Where("key_hash = ?", auth.AccountThrottleKey(user.Email)).Count(&count).Error)
+		assert.Zero(t, count)
+		assert.NoError(t, db.Model(&models.LoginThrottle{}).Where("key_hash = ?", auth.AccountThrottleKey(other.Email)).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+		assert.NoError(t, db.Model(&models.TransactionSplit{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+		assert.NoError(t, db.Table("transaction_tags").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+
+		_, err := repo.GetUserByID(ctx, user.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.Equal(t, gorm.ErrRecordNotFound, repo.EraseUser(ctx, user.ID))
+	})
 }
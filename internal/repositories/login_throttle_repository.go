@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// LoginThrottleRepository defines the required repository methods
+type LoginThrottleRepository interface {
+	GetLoginThrottles(ctx context.Context, keyHashes []string) ([]models.LoginThrottle, error)
+	RecordLoginFailure(ctx context.Context, keyHash string, at, windowStart time.Time) (*models.LoginThrottle, error)
+	LockLoginThrottle(ctx context.Context, keyHash string, until time.Time) error
+	DeleteLoginThrottle(ctx context.Context, keyHash string) error
+	DeleteStaleLoginThrottles(ctx context.Context, before time.Time) (int64, error)
+}
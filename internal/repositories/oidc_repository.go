@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// OIDCRepository defines the required repository methods
+type OIDCRepository interface {
+	CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error
+	ConsumeLoginRequest(ctx context.Context, stateHash string) (*models.OIDCLoginRequest, error)
+	DeleteExpiredLoginRequests(ctx context.Context, now time.Time) (int64, error)
+	GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+}
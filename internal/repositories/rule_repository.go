@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// RuleRepository defines the required repository methods
+type RuleRepository interface {
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	GetRuleByID(ctx context.Context, id uint) (*models.Rule, error)
+	GetRulesByUserID(ctx context.Context, userID uint) ([]models.Rule, error)
+	UpdateRule(ctx context.Context, rule *models.Rule) error
+	DeleteRule(ctx context.Context, id uint) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// TagRepository defines the required repository methods
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTagByID(ctx context.Context, id uint) (*models.Tag, error)
+	GetTagsByUserID(ctx context.Context, userID uint) ([]models.Tag, error)
+	GetTagsByNames(ctx context.Context, userID uint, names []string) ([]models.Tag, error)
+	DeleteTag(ctx context.Context, id uint) error
+}
@@ -11,9 +11,19 @@ import (
 // TransactionRepository defines the required repository methods
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, transaction *models.Transaction) error
+	CreateTransactions(ctx context.Context, transactions []models.Transaction) error
 	GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error)
+	GetExistingExternalIDs(ctx context.Context, userID uint, externalIDs []string) ([]string, error)
 	GetTransactionsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.Transaction, error)
 	GetTransactionsPageByUserID(ctx context.Context, userID uint, params pagination.Params, filters filters.TransactionFilters) ([]models.Transaction, int64, error)
+	StreamTransactionsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters, fn func(models.Transaction) error) error
 	UpdateTransaction(ctx context.Context, transaction *models.Transaction) error
+	UpdateTransactions(ctx context.Context, transactions []models.Transaction) error
 	DeleteTransaction(ctx context.Context, id uint) error
+	MergeTransactions(ctx context.Context, kept *models.Transaction, duplicateIDs []uint) error
+	CreateTransfer(ctx context.Context, transfer *models.Transfer) error
+	GetTransferByID(ctx context.Context, id uint) (*models.Transfer, error)
+	DeleteTransfer(ctx context.Context, id uint) error
+	GetCategoryTotalsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.CategoryTotal, error)
+	GetTagTotalsByUserID(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.TagTotal, error)
 }
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 )
@@ -9,5 +10,12 @@ import (
 // UserRepository defines the required repository methods
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	ScheduleUserDeletion(ctx context.Context, id uint, at *time.Time) error
+	GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error)
+	EraseUser(ctx context.Context, id uint) error
+	InvalidateUserTokens(ctx context.Context, id uint, before time.Time) error
+	MarkEmailVerified(ctx context.Context, id uint, email string, at time.Time) (bool, error)
 }
@@ -1,42 +1,140 @@
 package routes
 
 import (
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/controllers"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc, userController *controllers.UserController, transactionController *controllers.TransactionController, budgetController *controllers.BudgetController) {
-	registerPublicRoutes(router, userController)
+// Controllers groups the HTTP controllers that expose the API routes.
+type Controllers struct {
+	User        *controllers.UserController
+	Transaction *controllers.TransactionController
+	Budget      *controllers.BudgetController
+	Account     *controllers.AccountController
+	Report      *controllers.ReportController
+	Tag         *controllers.TagController
+	Import      *controllers.ImportController
+	Rule        *controllers.RuleController
+	Export      *controllers.ExportController
+	APIKey      *controllers.APIKeyController
+	// OIDC is nil unless single sign-on is configured.
+	OIDC *controllers.OIDCController
+}
+
+// Scope checks for the protected routes. Tags and rules only exist to
+// organise transactions, so they share the transaction scopes.
+var (
+	readTransactions  = middleware.RequireScopes(auth.ScopeTransactionsRead)
+	writeTransactions = middleware.RequireScopes(auth.ScopeTransactionsWrite)
+	readBudgets       = middleware.RequireScopes(auth.ScopeBudgetsRead)
+	writeBudgets      = middleware.RequireScopes(auth.ScopeBudgetsWrite)
+	readAccounts      = middleware.RequireScopes(auth.ScopeAccountsRead)
+	writeAccounts     = middleware.RequireScopes(auth.ScopeAccountsWrite)
+	readReports       = middleware.RequireScopes(auth.ScopeReportsRead)
+	// An archive holds, and restores, all of these at once.
+	readArchive  = middleware.RequireScopes(auth.ScopeAccountsRead, auth.ScopeBudgetsRead, auth.ScopeTransactionsRead)
+	writeArchive = middleware.RequireScopes(auth.ScopeAccountsWrite, auth.ScopeBudgetsWrite, auth.ScopeTransactionsWrite)
+	// Managing the account, including its API keys, needs a session that
+	// was not narrowed at login.
+	manageAccount = middleware.RequireScopes(auth.Scopes...)
+)
+
+func SetupRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc, handlers Controllers) {
+	registerPublicRoutes(router, handlers)
 	legacyProtected := router.Group("/")
 	legacyProtected.Use(authMiddleware)
-	registerLegacyProtectedRoutes(legacyProtected, transactionController, budgetController)
+	registerLegacyProtectedRoutes(legacyProtected, handlers)
 
 	v1 := router.Group("/api/v1")
-	registerPublicRoutes(v1, userController)
+	registerPublicRoutes(v1, handlers)
+	registerVersionedPublicRoutes(v1, handlers)
 	v1Protected := v1.Group("/")
 	v1Protected.Use(authMiddleware)
-	registerVersionedProtectedRoutes(v1Protected, transactionController, budgetController)
+	registerVersionedProtectedRoutes(v1Protected, handlers)
+	v1Session := v1Protected.Group("/")
+	v1Session.Use(middleware.RequireSession())
+	registerSessionRoutes(v1Session, handlers)
+}
+
+func registerPublicRoutes(router gin.IRoutes, handlers Controllers) {
+	router.POST("/register", handlers.User.Register)
+	router.POST("/login", handlers.User.Login)
+	router.POST("/login/mfa", handlers.User.VerifyMFALogin)
+}
+
+func registerVersionedPublicRoutes(router gin.IRoutes, handlers Controllers) {
+	router.POST("/token/refresh", handlers.User.RefreshToken)
+	router.POST("/email/verification", handlers.User.RequestEmailVerification)
+	router.POST("/email/verify", handlers.User.VerifyEmail)
+	router.POST("/password/forgot", handlers.User.ForgotPassword)
+	router.POST("/password/reset", handlers.User.ResetPassword)
+	if handlers.OIDC != nil {
+		router.GET("/oidc/login", handlers.OIDC.Login)
+		router.GET("/oidc/callback", handlers.OIDC.Callback)
+	}
 }
 
-func registerPublicRoutes(router gin.IRoutes, userController *controllers.UserController) {
-	router.POST("/register", userController.Register)
-	router.POST("/login", userController.Login)
+func registerLegacyProtectedRoutes(router gin.IRoutes, handlers Controllers) {
+	router.GET("/transactions", readTransactions, handlers.Transaction.GetTransactions)
+	router.POST("/transactions", writeTransactions, handlers.Transaction.CreateTransaction)
+	router.DELETE("/transactions/:id", writeTransactions, handlers.Transaction.DeleteTransaction)
+	router.GET("/budgets", readBudgets, handlers.Budget.GetBudgets)
+	router.POST("/budgets", writeBudgets, handlers.Budget.CreateBudget)
+	router.DELETE("/budgets/:id", writeBudgets, handlers.Budget.DeleteBudget)
 }
 
-func registerLegacyProtectedRoutes(router gin.IRoutes, transactionController *controllers.TransactionController, budgetController *controllers.BudgetController) {
-	router.GET("/transactions", transactionController.GetTransactions)
-	router.POST("/transactions", transactionController.CreateTransaction)
-	router.DELETE("/transactions/:id", transactionController.DeleteTransaction)
-	router.GET("/budgets", budgetController.GetBudgets)
-	router.POST("/budgets", budgetController.CreateBudget)
-	router.DELETE("/budgets/:id", budgetController.DeleteBudget)
+// registerSessionRoutes adds the routes that manage the account itself,
+// which API keys cannot reach. Any session may log out.
+func registerSessionRoutes(router gin.IRoutes, handlers Controllers) {
+	router.POST("/logout", handlers.User.Logout)
+	router.POST("/logout/all", handlers.User.LogoutAll)
+	router.GET("/me", manageAccount, handlers.User.GetCurrentUser)
+	router.PATCH("/me", manageAccount, handlers.User.UpdateCurrentUser)
+	router.DELETE("/me", manageAccount, handlers.User.DeleteCurrentUser)
+	router.POST("/me/password", manageAccount, handlers.User.ChangePassword)
+	router.DELETE("/me/deletion", manageAccount, handlers.User.CancelCurrentUserDeletion)
+	router.POST("/me/mfa/totp", manageAccount, handlers.User.BeginTOTPEnrolment)
+	router.POST("/me/mfa/totp/confirm", manageAccount, handlers.User.ConfirmTOTPEnrolment)
+	router.DELETE("/me/mfa/totp", manageAccount, handlers.User.DisableTOTP)
+	router.GET("/api-keys", manageAccount, handlers.APIKey.GetAPIKeys)
+	router.POST("/api-keys", manageAccount, handlers.APIKey.CreateAPIKey)
+	router.DELETE("/api-keys/:id", manageAccount, handlers.APIKey.RevokeAPIKey)
 }
 
-func registerVersionedProtectedRoutes(router gin.IRoutes, transactionController *controllers.TransactionController, budgetController *controllers.BudgetController) {
-	router.GET("/transactions", transactionController.GetTransactionsPage)
-	router.POST("/transactions", transactionController.CreateTransaction)
-	router.DELETE("/transactions/:id", transactionController.DeleteTransaction)
-	router.GET("/budgets", budgetController.GetBudgetsPage)
-	router.POST("/budgets", budgetController.CreateBudget)
-	router.DELETE("/budgets/:id", budgetController.DeleteBudget)
+func registerVersionedProtectedRoutes(router gin.IRoutes, handlers Controllers) {
+	router.GET("/transactions", readTransactions, handlers.Transaction.GetTransactionsPage)
+	router.POST("/transactions", writeTransactions, handlers.Transaction.CreateTransaction)
+	router.GET("/transactions/duplicates", readTransactions, handlers.Transaction.GetDuplicates)
+	router.POST("/transactions/duplicates/merge", writeTransactions, handlers.Transaction.MergeDuplicates)
+	router.GET("/transactions/suggest-category", readTransactions, handlers.Transaction.SuggestCategories)
+	router.PUT("/transactions/:id", writeTransactions, handlers.Transaction.UpdateTransaction)
+	router.DELETE("/transactions/:id", writeTransactions, handlers.Transaction.DeleteTransaction)
+	router.GET("/budgets", readBudgets, handlers.Budget.GetBudgetsPage)
+	router.POST("/budgets", writeBudgets, handlers.Budget.CreateBudget)
+	router.DELETE("/budgets/:id", writeBudgets, handlers.Budget.DeleteBudget)
+	router.GET("/accounts", readAccounts, handlers.Account.GetAccounts)
+	router.POST("/accounts", writeAccounts, handlers.Account.CreateAccount)
+	router.GET("/accounts/balances", readAccounts, handlers.Account.GetAccountBalances)
+	router.DELETE("/accounts/:id", writeAccounts, handlers.Account.DeleteAccount)
+	router.POST("/transfers", writeTransactions, handlers.Transaction.CreateTransfer)
+	router.DELETE("/transfers/:id", writeTransactions, handlers.Transaction.DeleteTransfer)
+	router.GET("/reports/summary", readReports, handlers.Report.GetSummary)
+	router.GET("/tags", readTransactions, handlers.Tag.GetTags)
+	router.POST("/tags", writeTransactions, handlers.Tag.CreateTag)
+	router.DELETE("/tags/:id", writeTransactions, handlers.Tag.DeleteTag)
+	router.POST("/imports/csv", writeTransactions, handlers.Import.PreviewCSVImport)
+	router.POST("/imports/ofx", writeTransactions, handlers.Import.PreviewOFXImport)
+	router.POST("/imports/camt053", writeTransactions, handlers.Import.PreviewCAMT053Import)
+	router.POST("/imports/mt940", writeTransactions, handlers.Import.PreviewMT940Import)
+	router.POST("/imports/confirm", writeTransactions, handlers.Import.ConfirmImport)
+	router.POST("/imports/archive", writeArchive, handlers.Import.ImportArchive)
+	router.GET("/exports/transactions", readTransactions, handlers.Export.ExportTransactions)
+	router.GET("/exports/archive", readArchive, handlers.Export.ExportArchive)
+	router.GET("/rules", readTransactions, handlers.Rule.GetRules)
+	router.POST("/rules", writeTransactions, handlers.Rule.CreateRule)
+	router.POST("/rules/apply", writeTransactions, handlers.Rule.ApplyRules)
+	router.PUT("/rules/:id", writeTransactions, handlers.Rule.UpdateRule)
+	router.DELETE("/rules/:id", writeTransactions, handlers.Rule.DeleteRule)
 }
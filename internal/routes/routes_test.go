@@ -2,12 +2,20 @@ package routes
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/controllers"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/pagination"
 	"github.com/gin-gonic/gin"
@@ -19,10 +27,30 @@ func (stubUserService) RegisterUser(context.Context, string, string, string) (*m
 	return nil, nil
 }
 
-func (stubUserService) AuthenticateUser(context.Context, string, string) (*models.User, error) {
+func (stubUserService) AuthenticateUser(context.Context, string, string, string) (*models.User, error) {
 	return nil, nil
 }
 
+func (stubUserService) GetUser(context.Context, uint) (*models.User, error) {
+	return nil, nil
+}
+
+func (stubUserService) UpdateUser(context.Context, uint, *string, *string, string) (*models.User, error) {
+	return nil, nil
+}
+
+func (stubUserService) ChangePassword(context.Context, uint, string, string) error {
+	return nil
+}
+
+func (stubUserService) DeleteUser(context.Context, uint, string) (*time.Time, error) {
+	return nil, nil
+}
+
+func (stubUserService) CancelUserDeletion(context.Context, uint) error {
+	return nil
+}
+
 type stubTransactionService struct{}
 
 func (stubTransactionService) AddTransaction(context.Context, *models.Transaction) error {
@@ -37,10 +65,116 @@ func (stubTransactionService) GetTransactionsPageByUser(context.Context, uint, p
 	return nil, 0, nil
 }
 
+func (stubTransactionService) AddTransactions(context.Context, []models.Transaction) error {
+	return nil
+}
+
+func (stubTransactionService) UpdateTransactionForUser(context.Context, uint, *models.Transaction) error {
+	return nil
+}
+
 func (stubTransactionService) DeleteTransactionForUser(context.Context, uint, uint) error {
 	return nil
 }
 
+func (stubTransactionService) CreateTransfer(context.Context, *models.Transfer) error {
+	return nil
+}
+
+func (stubTransactionService) DeleteTransferForUser(context.Context, uint, uint) error {
+	return nil
+}
+
+func (stubTransactionService) FindDuplicates(context.Context, uint, []models.Transaction) ([]models.DuplicateMatch, error) {
+	return nil, nil
+}
+
+func (stubTransactionService) GetDuplicateGroupsByUser(context.Context, uint) ([]models.DuplicateGroup, error) {
+	return []models.DuplicateGroup{}, nil
+}
+
+func (stubTransactionService) MergeDuplicatesForUser(context.Context, uint, uint, []uint) (*models.Transaction, error) {
+	return &models.Transaction{}, nil
+}
+
+func (stubTransactionService) ApplyRules(context.Context, uint, []models.Transaction) error {
+	return nil
+}
+
+func (stubTransactionService) ReapplyRulesForUser(context.Context, uint, filters.TransactionFilters) (int, error) {
+	return 0, nil
+}
+
+func (stubTransactionService) SuggestCategories(context.Context, uint, string, string) ([]models.CategorySuggestion, error) {
+	return nil, nil
+}
+
+type stubTagService struct{}
+
+func (stubTagService) CreateTag(context.Context, *models.Tag) error {
+	return nil
+}
+
+func (stubTagService) GetTagsByUser(context.Context, uint) ([]models.Tag, error) {
+	return nil, nil
+}
+
+func (stubTagService) DeleteTagForUser(context.Context, uint, uint) error {
+	return nil
+}
+
+type stubRuleService struct{}
+
+func (stubRuleService) CreateRule(context.Context, *models.Rule) error {
+	return nil
+}
+
+func (stubRuleService) GetRulesByUser(context.Context, uint) ([]models.Rule, error) {
+	return nil, nil
+}
+
+func (stubRuleService) UpdateRuleForUser(context.Context, uint, *models.Rule) error {
+	return nil
+}
+
+func (stubRuleService) DeleteRuleForUser(context.Context, uint, uint) error {
+	return nil
+}
+
+type stubImportService struct{}
+
+func (stubImportService) PreviewCSV(context.Context, uint, io.Reader, imports.CSVMapping) (*imports.Preview, error) {
+	return &imports.Preview{}, nil
+}
+
+func (stubImportService) PreviewStatement(context.Context, uint, imports.Format, io.Reader) (*imports.Preview, error) {
+	return &imports.Preview{}, nil
+}
+
+func (stubImportService) ConfirmImport(context.Context, uint, uint, []models.Transaction) (*imports.Result, error) {
+	return &imports.Result{}, nil
+}
+
+func (stubImportService) ImportArchive(context.Context, uint, io.ReaderAt, int64) (*archive.Summary, error) {
+	return &archive.Summary{}, nil
+}
+
+type stubExportService struct{}
+
+func (stubExportService) ExportTransactions(context.Context, uint, exports.Format, filters.TransactionFilters, io.Writer) error {
+	return nil
+}
+
+func (stubExportService) ExportArchive(context.Context, uint, io.Writer) error {
+	return nil
+}
+
+type stubReportService struct{}
+
+func (stubReportService) GetSummaryByUser(context.Context, uint, filters.TransactionFilters) (*models.TransactionSummary, error) {
+	return &models.TransactionSummary{}, nil
+}
+
 type stubBudgetService struct{}
 
 func (stubBudgetService) CreateBudget(context.Context, *models.Budget) error {
@@ -63,25 +197,132 @@ func (stubBudgetService) DeleteBudgetForUser(context.Context, uint, uint) error
 	return nil
 }
 
-type stubTokenManager struct{}
+type stubAccountService struct{}
 
-func (stubTokenManager) GenerateToken(*models.User) (string, error) {
-	return "", nil
+func (stubAccountService) CreateAccount(context.Context, *models.Account) error {
+	return nil
+}
+
+func (stubAccountService) GetAccountsByUser(context.Context, uint) ([]models.Account, error) {
+	return nil, nil
 }
 
-func (stubTokenManager) ParseToken(string) (*auth.Claims, error) {
-	return &auth.Claims{UserID: 1, Email: "test@example.com"}, nil
+func (stubAccountService) GetAccountBalancesByUser(context.Context, uint, time.Time) ([]models.AccountBalance, error) {
+	return nil, nil
+}
+
+func (stubAccountService) DeleteAccountForUser(context.Context, uint, uint) error {
+	return nil
+}
+
+type stubTokenService struct{}
+
+func (stubTokenService) IssueTokens(context.Context, *models.User, string) (*auth.TokenPair, error) {
+	return &auth.TokenPair{}, nil
+}
+
+func (stubTokenService) RefreshTokens(context.Context, string) (*auth.TokenPair, error) {
+	return &auth.TokenPair{}, nil
+}
+
+func (stubTokenService) Logout(context.Context, *auth.Claims, string) error {
+	return nil
+}
+
+func (stubTokenService) LogoutAll(context.Context, uint) error {
+	return nil
+}
+
+type stubMFAService struct{}
+
+func (stubMFAService) BeginTOTPEnrolment(context.Context, uint, string) (*auth.TOTPEnrolment, error) {
+	return &auth.TOTPEnrolment{}, nil
+}
+
+func (stubMFAService) ConfirmTOTPEnrolment(context.Context, uint, string) ([]string, error) {
+	return nil, nil
+}
+
+func (stubMFAService) DisableTOTP(context.Context, uint, string, string) error {
+	return nil
+}
+
+func (stubMFAService) StartMFAChallenge(context.Context, *models.User) (*auth.MFAChallenge, error) {
+	return &auth.MFAChallenge{}, nil
+}
+
+func (stubMFAService) VerifyMFAChallenge(context.Context, string, string) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+type stubAccountEmailService struct{}
+
+func (stubAccountEmailService) RequestEmailVerification(context.Context, string) error {
+	return nil
+}
+
+func (stubAccountEmailService) VerifyEmail(context.Context, string) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+func (stubAccountEmailService) RequestPasswordReset(context.Context, string) error {
+	return nil
+}
+
+func (stubAccountEmailService) ResetPassword(context.Context, string, string) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+type stubAPIKeyService struct{}
+
+func (stubAPIKeyService) CreateAPIKey(context.Context, uint, []string, string, []string, *time.Time) (*models.APIKey, string, error) {
+	return &models.APIKey{}, "", nil
+}
+
+func (stubAPIKeyService) ListAPIKeys(context.Context, uint) ([]models.APIKey, error) {
+	return nil, nil
+}
+
+func (stubAPIKeyService) RevokeAPIKey(context.Context, uint, uint) error {
+	return nil
+}
+
+func (stubAPIKeyService) AuthenticateAPIKey(context.Context, string) (*models.APIKey, error) {
+	return &models.APIKey{}, nil
+}
+
+type stubOIDCService struct{}
+
+func (stubOIDCService) StartLogin(context.Context) (string, string, error) {
+	return "https://idp.example.com/authorize", "state", nil
+}
+
+func (stubOIDCService) CompleteLogin(context.Context, string, string) (*models.User, error) {
+	return &models.User{}, nil
+}
+
+func stubControllers() Controllers {
+	return Controllers{
+		User:        controllers.NewUserController(stubUserService{}, stubTokenService{}, stubMFAService{}, stubAccountEmailService{}),
+		Transaction: controllers.NewTransactionController(stubTransactionService{}),
+		Budget:      controllers.NewBudgetController(stubBudgetService{}),
+		Account:     controllers.NewAccountController(stubAccountService{}),
+		Report:      controllers.NewReportController(stubReportService{}),
+		Tag:         controllers.NewTagController(stubTagService{}),
+		Import:      controllers.NewImportController(stubImportService{}),
+		Rule:        controllers.NewRuleController(stubRuleService{}, stubTransactionService{}),
+		Export:      controllers.NewExportController(stubExportService{}),
+		APIKey:      controllers.NewAPIKeyController(stubAPIKeyService{}),
+	}
 }
 
 func TestSetupRoutesRegistersLegacyAndVersionedAPIPaths(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	router := gin.New()
-	userController := controllers.NewUserController(stubUserService{}, stubTokenManager{})
-	transactionController := controllers.NewTransactionController(stubTransactionService{})
-	budgetController := controllers.NewBudgetController(stubBudgetService{})
+	handlers := stubControllers()
 
-	SetupRoutes(router, func(c *gin.Context) { c.Next() }, userController, transactionController, budgetController)
+	SetupRoutes(router, func(c *gin.Context) { c.Next() }, handlers)
 
 	got := make([]string, 0, len(router.Routes()))
 	for _, route := range router.Routes() {
@@ -90,22 +331,69 @@ func TestSetupRoutesRegistersLegacyAndVersionedAPIPaths(t *testing.T) {
 	sort.Strings(got)
 
 	want := []string{
+		"DELETE /api/v1/accounts/:id",
+		"DELETE /api/v1/api-keys/:id",
 		"DELETE /api/v1/budgets/:id",
+		"DELETE /api/v1/me",
+		"DELETE /api/v1/me/deletion",
+		"DELETE /api/v1/me/mfa/totp",
+		"DELETE /api/v1/rules/:id",
+		"DELETE /api/v1/tags/:id",
 		"DELETE /api/v1/transactions/:id",
+		"DELETE /api/v1/transfers/:id",
 		"DELETE /budgets/:id",
 		"DELETE /transactions/:id",
+		"GET /api/v1/accounts",
+		"GET /api/v1/api-keys",
+		"GET /api/v1/accounts/balances",
 		"GET /api/v1/budgets",
+		"GET /api/v1/me",
+		"GET /api/v1/exports/archive",
+		"GET /api/v1/exports/transactions",
+		"GET /api/v1/reports/summary",
+		"GET /api/v1/rules",
+		"GET /api/v1/tags",
 		"GET /api/v1/transactions",
+		"GET /api/v1/transactions/duplicates",
+		"GET /api/v1/transactions/suggest-category",
 		"GET /budgets",
 		"GET /transactions",
+		"POST /api/v1/accounts",
+		"POST /api/v1/api-keys",
 		"POST /api/v1/budgets",
+		"POST /api/v1/email/verification",
+		"POST /api/v1/email/verify",
+		"POST /api/v1/imports/archive",
+		"POST /api/v1/imports/camt053",
+		"POST /api/v1/imports/confirm",
+		"POST /api/v1/imports/csv",
+		"POST /api/v1/imports/mt940",
+		"POST /api/v1/imports/ofx",
 		"POST /api/v1/login",
+		"POST /api/v1/login/mfa",
+		"POST /api/v1/logout",
+		"POST /api/v1/logout/all",
+		"POST /api/v1/me/mfa/totp",
+		"POST /api/v1/me/mfa/totp/confirm",
+		"POST /api/v1/me/password",
+		"POST /api/v1/password/forgot",
+		"POST /api/v1/password/reset",
 		"POST /api/v1/register",
+		"POST /api/v1/rules",
+		"POST /api/v1/rules/apply",
+		"POST /api/v1/tags",
+		"POST /api/v1/token/refresh",
 		"POST /api/v1/transactions",
+		"POST /api/v1/transactions/duplicates/merge",
+		"POST /api/v1/transfers",
 		"POST /budgets",
 		"POST /login",
+		"POST /login/mfa",
 		"POST /register",
 		"POST /transactions",
+		"PATCH /api/v1/me",
+		"PUT /api/v1/rules/:id",
+		"PUT /api/v1/transactions/:id",
 	}
 	sort.Strings(want)
 
@@ -119,3 +407,116 @@ func TestSetupRoutesRegistersLegacyAndVersionedAPIPaths(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupRoutesRegistersOIDCRoutesOnlyWhenConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	oidcRoutes := func(handlers Controllers) []string {
+		router := gin.New()
+		SetupRoutes(router, func(c *gin.Context) { c.Next() }, handlers)
+
+		var got []string
+		for _, route := range router.Routes() {
+			if strings.HasPrefix(route.Path, "/api/v1/oidc/") {
+				got = append(got, route.Method+" "+route.Path)
+			}
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	if got := oidcRoutes(stubControllers()); len(got) != 0 {
+		t.Fatalf("expected no OIDC routes without a provider, got %v", got)
+	}
+
+	handlers := stubControllers()
+	handlers.OIDC = controllers.NewOIDCController(stubOIDCService{}, stubTokenService{}, stubMFAService{})
+	got := oidcRoutes(handlers)
+	want := []string{"GET /api/v1/oidc/callback", "GET /api/v1/oidc/login"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected OIDC routes %v, got %v", want, got)
+	}
+}
+
+func TestSetupRoutesKeepsAPIKeysOutOfAccountRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	SetupRoutes(router, func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Set("apiKey", &models.APIKey{ID: 1, UserID: 1})
+		c.Set("scopes", []string{auth.ScopeTransactionsRead})
+		c.Next()
+	}, stubControllers())
+
+	for _, path := range []string{"/api/v1/me", "/api/v1/api-keys"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected %s to be forbidden with an API key, got %d", path, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tags", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected tags to be reachable with an API key, got %d", rec.Code)
+	}
+}
+
+func TestSetupRoutesRequiresScopesOnDataRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	SetupRoutes(router, func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: auth.ScopeReportsRead})
+		c.Set("scopes", []string{auth.ScopeReportsRead})
+		c.Next()
+	}, stubControllers())
+
+	unscoped := map[string]bool{
+		"/register": true, "/login": true, "/login/mfa": true,
+		"/api/v1/register": true, "/api/v1/login": true, "/api/v1/login/mfa": true,
+		"/api/v1/token/refresh": true, "/api/v1/email/verification": true, "/api/v1/email/verify": true,
+		"/api/v1/password/forgot": true, "/api/v1/password/reset": true,
+		"/api/v1/logout": true, "/api/v1/logout/all": true,
+		"/api/v1/reports/summary": true,
+	}
+
+	for _, route := range router.Routes() {
+		if unscoped[route.Path] {
+			continue
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(route.Method, strings.ReplaceAll(route.Path, ":id", "1"), nil))
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "insufficient_scope") {
+			t.Errorf("expected %s %s to need a scope, got %d: %s", route.Method, route.Path, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports/summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the reports:read scope to reach the summary, got %d", rec.Code)
+	}
+}
+
+func TestSetupRoutesRequiresFullSessionForAccountRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	SetupRoutes(router, func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		c.Set("tokenClaims", &auth.Claims{UserID: 1, Scope: strings.Join(auth.Scopes, " ")})
+		c.Set("scopes", auth.Scopes)
+		c.Next()
+	}, stubControllers())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected API keys to be reachable with a full session, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// Engine evaluates a user's rules against transactions. Patterns are
+// compiled once, so an engine can be reused for a whole import.
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	models.Rule
+	notePattern  *regexp.Regexp
+	payeePattern *regexp.Regexp
+}
+
+// New compiles the given rules and orders them by priority, then by ID so
+// rules sharing a priority run in the order they were created.
+func New(rules []models.Rule) (*Engine, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, c)
+	}
+
+	sort.SliceStable(compiled, func(i, j int) bool {
+		if compiled[i].Priority != compiled[j].Priority {
+			return compiled[i].Priority < compiled[j].Priority
+		}
+		return compiled[i].ID < compiled[j].ID
+	})
+
+	return &Engine{rules: compiled}, nil
+}
+
+// Validate reports whether the rule's patterns compile.
+func Validate(rule models.Rule) error {
+	_, err := compile(rule)
+	return err
+}
+
+func compile(rule models.Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+
+	var err error
+	if rule.NotePattern != "" {
+		if c.notePattern, err = regexp.Compile(rule.NotePattern); err != nil {
+			return compiledRule{}, fmt.Errorf("invalid note pattern: %w", err)
+		}
+	}
+	if rule.PayeePattern != "" {
+		if c.payeePattern, err = regexp.Compile(rule.PayeePattern); err != nil {
+			return compiledRule{}, fmt.Errorf("invalid payee pattern: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// Apply runs the rules against a transaction in priority order and reports
+// whether anything changed. The first matching rule that sets a category or
+// payee wins; tags from every matching rule are added. Unless overwrite is
+// set, a category or payee the transaction already has is kept. Transfers are
+// never touched, and split transactions keep their split categories.
+func (e *Engine) Apply(transaction *models.Transaction, overwrite bool) bool {
+	if e == nil || transaction.Type == "transfer" {
+		return false
+	}
+
+	categorySet := len(transaction.Splits) > 0 || (!overwrite && transaction.CategoryID != 0)
+	payeeSet := !overwrite && transaction.Payee != ""
+	changed := false
+
+	for _, rule := range e.rules {
+		if !rule.matches(*transaction) {
+			continue
+		}
+
+		if rule.CategoryID != nil && !categorySet {
+			categorySet = true
+			if transaction.CategoryID != *rule.CategoryID {
+				transaction.CategoryID = *rule.CategoryID
+				changed = true
+			}
+		}
+
+		if rule.Payee != "" && !payeeSet {
+			payeeSet = true
+			if transaction.Payee != rule.Payee {
+				transaction.Payee = rule.Payee
+				changed = true
+			}
+		}
+
+		for _, name := range rule.TagNames() {
+			if !hasTag(transaction.Tags, name) {
+				transaction.Tags = append(transaction.Tags, models.Tag{UserID: transaction.UserID, Name: name})
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// matches reports whether every condition the rule sets holds for the transaction.
+func (r compiledRule) matches(transaction models.Transaction) bool {
+	if r.Type != "" && r.Type != transaction.Type {
+		return false
+	}
+	if r.MinAmount != nil && transaction.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && transaction.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.NoteContains != "" && !containsFold(transaction.Note, r.NoteContains) {
+		return false
+	}
+	if r.notePattern != nil && !r.notePattern.MatchString(transaction.Note) {
+		return false
+	}
+	if r.PayeeContains != "" && !containsFold(transaction.Payee, r.PayeeContains) {
+		return false
+	}
+	if r.payeePattern != nil && !r.payeePattern.MatchString(transaction.Payee) {
+		return false
+	}
+	return true
+}
+
+func containsFold(value, substring string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substring))
+}
+
+func hasTag(tags []models.Tag, name string) bool {
+	for _, tag := range tags {
+		if strings.EqualFold(strings.TrimSpace(tag.Name), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptrUint(value uint) *uint {
+	return &value
+}
+
+func ptrFloat(value float64) *float64 {
+	return &value
+}
+
+func TestEngineApply(t *testing.T) {
+	t.Run("Conditions must all match", func(t *testing.T) {
+		engine, err := New([]models.Rule{{
+			ID:           1,
+			NoteContains: "coffee",
+			Type:         "expense",
+			MinAmount:    ptrFloat(2),
+			MaxAmount:    ptrFloat(10),
+			CategoryID:   ptrUint(5),
+		}})
+		assert.NoError(t, err)
+
+		matching := models.Transaction{Type: "expense", Amount: 4.5, Note: "Morning COFFEE"}
+		assert.True(t, engine.Apply(&matching, false))
+		assert.Equal(t, uint(5), matching.CategoryID)
+
+		tooExpensive := models.Transaction{Type: "expense", Amount: 25, Note: "coffee beans"}
+		assert.False(t, engine.Apply(&tooExpensive, false))
+		assert.Zero(t, tooExpensive.CategoryID)
+
+		income := models.Transaction{Type: "income", Amount: 5, Note: "coffee refund"}
+		assert.False(t, engine.Apply(&income, false))
+	})
+
+	t.Run("Patterns match note and payee", func(t *testing.T) {
+		engine, err := New([]models.Rule{{
+			ID:           1,
+			NotePattern:  `(?i)^card payment`,
+			PayeePattern: `^ACME (GmbH|Ltd)$`,
+			Payee:        "Acme",
+			Tags:         "work,reimbursable",
+		}})
+		assert.NoError(t, err)
+
+		transaction := models.Transaction{UserID: 3, Type: "expense", Amount: 30, Note: "Card payment 1234", Payee: "ACME Ltd", CategoryID: 2}
+		assert.True(t, engine.Apply(&transaction, true))
+		assert.Equal(t, "Acme", transaction.Payee)
+		assert.Equal(t, uint(2), transaction.CategoryID)
+		assert.Equal(t, []models.Tag{{UserID: 3, Name: "work"}, {UserID: 3, Name: "reimbursable"}}, transaction.Tags)
+
+		// Tags are only added once.
+		assert.False(t, engine.Apply(&transaction, true))
+		assert.Len(t, transaction.Tags, 2)
+	})
+
+	t.Run("First matching rule wins and tags accumulate", func(t *testing.T) {
+		engine, err := New([]models.Rule{
+			{ID: 1, Priority: 20, NoteContains: "rent", CategoryID: ptrUint(9), Tags: "home"},
+			{ID: 2, Priority: 10, NoteContains: "rent", CategoryID: ptrUint(7), Payee: "Landlord", Tags: "monthly"},
+		})
+		assert.NoError(t, err)
+
+		transaction := models.Transaction{Type: "expense", Amount: 900, Note: "Rent March"}
+		assert.True(t, engine.Apply(&transaction, false))
+		assert.Equal(t, uint(7), transaction.CategoryID)
+		assert.Equal(t, "Landlord", transaction.Payee)
+		assert.Equal(t, []models.Tag{{Name: "monthly"}, {Name: "home"}}, transaction.Tags)
+	})
+
+	t.Run("Existing values are kept unless overwriting", func(t *testing.T) {
+		engine, err := New([]models.Rule{{ID: 1, NoteContains: "gym", CategoryID: ptrUint(4), Payee: "FitCo"}})
+		assert.NoError(t, err)
+
+		kept := models.Transaction{Type: "expense", Amount: 40, Note: "Gym", CategoryID: 1, Payee: "Gym Ltd"}
+		assert.False(t, engine.Apply(&kept, false))
+		assert.Equal(t, uint(1), kept.CategoryID)
+		assert.Equal(t, "Gym Ltd", kept.Payee)
+
+		overwritten := kept
+		assert.True(t, engine.Apply(&overwritten, true))
+		assert.Equal(t, uint(4), overwritten.CategoryID)
+		assert.Equal(t, "FitCo", overwritten.Payee)
+	})
+
+	t.Run("Transfers and split categories are left alone", func(t *testing.T) {
+		engine, err := New([]models.Rule{{ID: 1, NoteContains: "shop", CategoryID: ptrUint(4)}})
+		assert.NoError(t, err)
+
+		transfer := models.Transaction{Type: "transfer", Amount: -50, Note: "shop savings"}
+		assert.False(t, engine.Apply(&transfer, true))
+
+		split := models.Transaction{Type: "expense", Amount: 50, Note: "shop", CategoryID: 1, Splits: []models.TransactionSplit{{CategoryID: 1, Amount: 50}}}
+		assert.False(t, engine.Apply(&split, true))
+		assert.Equal(t, uint(1), split.CategoryID)
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := New([]models.Rule{{ID: 1, NotePattern: "("}})
+		assert.ErrorContains(t, err, "invalid note pattern")
+
+		assert.ErrorContains(t, Validate(models.Rule{PayeePattern: "[a-"}), "invalid payee pattern")
+		assert.NoError(t, Validate(models.Rule{NotePattern: "^ok$"}))
+	})
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// AccountEmailService defines the interface for the flows that work
+// through the user's email address
+type AccountEmailService interface {
+	RequestEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error)
+}
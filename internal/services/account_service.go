@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// AccountService defines the interface for account operations
+type AccountService interface {
+	CreateAccount(ctx context.Context, account *models.Account) error
+	GetAccountsByUser(ctx context.Context, userID uint) ([]models.Account, error)
+	GetAccountBalancesByUser(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error)
+	DeleteAccountForUser(ctx context.Context, userID, accountID uint) error
+}
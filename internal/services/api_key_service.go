@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// APIKeyService defines the interface for managing personal API keys
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID uint, granted []string, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID uint) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error)
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/mail"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultLinkBaseURL is where links in emails point unless
+// WithLinkBaseURL says otherwise.
+const defaultLinkBaseURL = "http://localhost:8080"
+
+// DefaultAccountEmailService verifies email addresses and resets
+// forgotten passwords with signed tokens sent by email. Every token works
+// once: its ID is recorded as revoked when it is used.
+type DefaultAccountEmailService struct {
+	userRepo         repositories.UserRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenManager     auth.EmailTokenManager
+	mailer           mail.Mailer
+	linkBaseURL      string
+	passwordPolicy   PasswordPolicy
+}
+
+// AccountEmailServiceOption configures optional behaviour of the account
+// email service.
+type AccountEmailServiceOption func(*DefaultAccountEmailService)
+
+// WithLinkBaseURL sets the address of the app that links in emails open.
+func WithLinkBaseURL(baseURL string) AccountEmailServiceOption {
+	return func(s *DefaultAccountEmailService) {
+		if baseURL != "" {
+			s.linkBaseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithResetPasswordPolicy sets which passwords a reset may set, which
+// should be the policy the user service applies.
+func WithResetPasswordPolicy(policy PasswordPolicy) AccountEmailServiceOption {
+	return func(s *DefaultAccountEmailService) {
+		s.passwordPolicy = policy
+	}
+}
+
+func NewAccountEmailService(userRepo repositories.UserRepository, revokedTokenRepo repositories.RevokedTokenRepository, tokenManager auth.EmailTokenManager, mailer mail.Mailer, options ...AccountEmailServiceOption) *DefaultAccountEmailService {
+	service := &DefaultAccountEmailService{
+		userRepo:         userRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenManager:     tokenManager,
+		mailer:           mailer,
+		linkBaseURL:      defaultLinkBaseURL,
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+// RequestEmailVerification sends a verification link to the address if it
+// belongs to a user who has not verified it yet. Unknown and already
+// verified addresses are ignored without saying so, so the answer does not
+// reveal who is registered.
+func (s *DefaultAccountEmailService) RequestEmailVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil || user.EmailVerified() {
+		return nil
+	}
+
+	token, err := s.tokenManager.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return apperrors.Internal("email_verification_failed", "failed to send verification email", err)
+	}
+
+	message := mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm that this is your email address by opening the link below:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.Name, s.link("/verify-email", token), expiryText(auth.EmailVerificationTokenTTL)),
+	}
+	if err := s.mailer.Send(ctx, message); err != nil {
+		return apperrors.Internal("email_verification_failed", "failed to send verification email", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the user's email address as verified. The token only
+// works for the address it was sent to, so changing the email address
+// invalidates links sent before.
+func (s *DefaultAccountEmailService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	claims, err := s.tokenManager.ParseEmailVerificationToken(token)
+	if err != nil {
+		return nil, invalidVerificationToken()
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, invalidVerificationToken()
+	}
+
+	if err := s.consume(ctx, claims, invalidVerificationToken); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	verified, err := s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email, now)
+	if err != nil {
+		return nil, apperrors.Internal("email_verification_failed", "failed to verify email", err)
+	}
+	if !verified {
+		return nil, invalidVerificationToken()
+	}
+
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// RequestPasswordReset sends a password reset link to the address if it
+// belongs to a user. Unknown addresses are ignored without saying so.
+func (s *DefaultAccountEmailService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return nil
+	}
+
+	token, err := s.tokenManager.GeneratePasswordResetToken(user)
+	if err != nil {
+		return apperrors.Internal("password_reset_failed", "failed to send password reset email", err)
+	}
+
+	message := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. To choose a new password, open the link below:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s and works once. If you did not ask for this, you can ignore this email; your password has not changed.\n",
+			user.Name, s.link("/reset-password", token), expiryText(auth.PasswordResetTokenTTL)),
+	}
+	if err := s.mailer.Send(ctx, message); err != nil {
+		return apperrors.Internal("password_reset_failed", "failed to send password reset email", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password for the user the token was sent to.
+// Tokens issued before the user last logged out everywhere, which changing
+// the password does, no longer work, and neither do tokens sent to an
+// address the user has since changed.
+func (s *DefaultAccountEmailService) ResetPassword(ctx context.Context, token, newPassword string) (*models.User, error) {
+	claims, err := s.tokenManager.ParsePasswordResetToken(token)
+	if err != nil {
+		return nil, invalidPasswordResetToken()
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil || !strings.EqualFold(user.Email, claims.Email) {
+		return nil, invalidPasswordResetToken()
+	}
+	if user.TokensInvalidBefore != nil && claims.IssuedAt < user.TokensInvalidBefore.Unix() {
+		return nil, invalidPasswordResetToken()
+	}
+
+	// Checked before the token is used up, so the user can try again with
+	// a better password.
+	if err := s.passwordPolicy.check(newPassword, user.Name, user.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.Internal("password_reset_failed", "failed to reset password", err)
+	}
+
+	if err := s.consume(ctx, claims, invalidPasswordResetToken); err != nil {
+		return nil, err
+	}
+
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		return nil, apperrors.Internal("password_reset_failed", "failed to reset password", err)
+	}
+
+	return user, nil
+}
+
+// consume uses up a token, failing with invalid when it was used before.
+func (s *DefaultAccountEmailService) consume(ctx context.Context, claims *auth.Claims, invalid func() error) error {
+	used := &models.RevokedToken{
+		TokenID:   claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}
+
+	consumed, err := s.revokedTokenRepo.ConsumeToken(ctx, used)
+	if err != nil {
+		return apperrors.Internal("token_check_failed", "failed to check token", err)
+	}
+	if !consumed {
+		return invalid()
+	}
+
+	return nil
+}
+
+func (s *DefaultAccountEmailService) link(path, token string) string {
+	return s.linkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// expiryText spells out a token lifetime of whole hours for an email.
+func expiryText(ttl time.Duration) string {
+	hours := int(ttl / time.Hour)
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
+
+func invalidVerificationToken() error {
+	return apperrors.Unauthorized("invalid_verification_token", "invalid, expired or already used verification link")
+}
+
+func invalidPasswordResetToken() error {
+	return apperrors.Unauthorized("invalid_password_reset_token", "invalid, expired or already used password reset link")
+}
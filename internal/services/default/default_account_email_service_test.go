@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/mail"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recordingMailer keeps the messages it is asked to send.
+type recordingMailer struct {
+	messages []mail.Message
+	err      error
+}
+
+func (m *recordingMailer) Send(_ context.Context, message mail.Message) error {
+	m.messages = append(m.messages, message)
+	return m.err
+}
+
+// tokenFromLink pulls the token out of the link in a sent message.
+func tokenFromLink(t *testing.T, message mail.Message) string {
+	t.Helper()
+
+	for _, field := range strings.Fields(message.Body) {
+		if !strings.HasPrefix(field, "http") {
+			continue
+		}
+		link, err := url.Parse(field)
+		if err != nil {
+			t.Fatalf("parse link: %v", err)
+		}
+		return link.Query().Get("token")
+	}
+
+	t.Fatalf("no link in message %q", message.Body)
+	return ""
+}
+
+func TestRequestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+
+	t.Run("should send a verification link", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mailer := &recordingMailer{}
+		service := NewAccountEmailService(mockUserRepo, new(MockRevokedTokenRepository), tokenManager, mailer, WithLinkBaseURL("https://finance.example.com/"))
+
+		mockUserRepo.On("GetUserByEmail", ctx, "john@example.com").Return(&models.User{ID: 1, Name: "John", Email: "john@example.com"}, nil)
+
+		assert.NoError(t, service.RequestEmailVerification(ctx, " john@example.com "))
+		if assert.Len(t, mailer.messages, 1) {
+			message := mailer.messages[0]
+			assert.Equal(t, "john@example.com", message.To)
+			assert.Contains(t, message.Body, "https://finance.example.com/verify-email?token=")
+
+			claims, err := tokenManager.ParseEmailVerificationToken(tokenFromLink(t, message))
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint(1), claims.UserID)
+			}
+		}
+	})
+
+	t.Run("should quietly skip unknown and verified addresses", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mailer := &recordingMailer{}
+		service := NewAccountEmailService(mockUserRepo, new(MockRevokedTokenRepository), tokenManager, mailer)
+		verifiedAt := time.Now()
+
+		mockUserRepo.On("GetUserByEmail", ctx, "nobody@example.com").Return(nil, errors.New("not found"))
+		mockUserRepo.On("GetUserByEmail", ctx, "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+		assert.NoError(t, service.RequestEmailVerification(ctx, "nobody@example.com"))
+		assert.NoError(t, service.RequestEmailVerification(ctx, "john@example.com"))
+		assert.Empty(t, mailer.messages)
+	})
+
+	t.Run("should report a failed delivery", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewAccountEmailService(mockUserRepo, new(MockRevokedTokenRepository), tokenManager, &recordingMailer{err: errors.New("connection refused")})
+
+		mockUserRepo.On("GetUserByEmail", ctx, "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+
+		err := service.RequestEmailVerification(ctx, "john@example.com")
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	token, err := tokenManager.GenerateEmailVerificationToken(user)
+	assert.NoError(t, err)
+
+	t.Run("should mark the email verified once", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{})
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.AnythingOfType("*models.RevokedToken")).Return(true, nil).Once()
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.AnythingOfType("*models.RevokedToken")).Return(false, nil)
+		mockUserRepo.On("MarkEmailVerified", ctx, uint(1), "john@example.com", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		verified, err := service.VerifyEmail(ctx, token)
+		if assert.NoError(t, err) {
+			assert.True(t, verified.EmailVerified())
+		}
+
+		_, err = service.VerifyEmail(ctx, token)
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+		mockUserRepo.AssertNumberOfCalls(t, "MarkEmailVerified", 1)
+	})
+
+	t.Run("should refuse a token sent to a previous address", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{})
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "jane@example.com"}, nil)
+
+		_, err := service.VerifyEmail(ctx, token)
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+		mockRevokedRepo.AssertNotCalled(t, "ConsumeToken")
+	})
+
+	t.Run("should refuse a password reset token", func(t *testing.T) {
+		service := NewAccountEmailService(new(MockUserRepository), new(MockRevokedTokenRepository), tokenManager, &recordingMailer{})
+		resetToken, err := tokenManager.GeneratePasswordResetToken(user)
+		assert.NoError(t, err)
+
+		_, err = service.VerifyEmail(ctx, resetToken)
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+
+	t.Run("should send a reset link", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mailer := &recordingMailer{}
+		service := NewAccountEmailService(mockUserRepo, new(MockRevokedTokenRepository), tokenManager, mailer)
+
+		mockUserRepo.On("GetUserByEmail", ctx, "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+
+		assert.NoError(t, service.RequestPasswordReset(ctx, "john@example.com"))
+		if assert.Len(t, mailer.messages, 1) {
+			assert.Contains(t, mailer.messages[0].Body, "http://localhost:8080/reset-password?token=")
+			_, err := tokenManager.ParsePasswordResetToken(tokenFromLink(t, mailer.messages[0]))
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("should quietly skip an unknown address", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mailer := &recordingMailer{}
+		service := NewAccountEmailService(mockUserRepo, new(MockRevokedTokenRepository), tokenManager, mailer)
+
+		mockUserRepo.On("GetUserByEmail", ctx, "nobody@example.com").Return(nil, errors.New("not found"))
+
+		assert.NoError(t, service.RequestPasswordReset(ctx, "nobody@example.com"))
+		assert.Empty(t, mailer.messages)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	token, err := tokenManager.GeneratePasswordResetToken(&models.User{ID: 1, Email: "john@example.com"})
+	assert.NoError(t, err)
+
+	t.Run("should set the new password", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{})
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.AnythingOfType("*models.RevokedToken")).Return(true, nil)
+		mockUserRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("newpassword")) == nil
+		})).Return(nil)
+
+		_, err := service.ResetPassword(ctx, token, "newpassword")
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("should refuse a used token", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{})
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.AnythingOfType("*models.RevokedToken")).Return(false, nil)
+
+		_, err := service.ResetPassword(ctx, token, "newpassword")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+		mockUserRepo.AssertNotCalled(t, "UpdateUser")
+	})
+
+	t.Run("should refuse a weak password without using up the token", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{}, WithResetPasswordPolicy(PasswordPolicy{MinScore: 3}))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+
+		_, err := service.ResetPassword(ctx, token, "password1")
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRevokedRepo.AssertNotCalled(t, "ConsumeToken")
+	})
+
+	t.Run("should refuse a token issued before the tokens were invalidated", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewAccountEmailService(mockUserRepo, mockRevokedRepo, tokenManager, &recordingMailer{})
+		invalidBefore := time.Now().Add(time.Hour)
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", TokensInvalidBefore: &invalidBefore}, nil)
+
+		_, err := service.ResetPassword(ctx, token, "newpassword")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+		mockRevokedRepo.AssertNotCalled(t, "ConsumeToken")
+	})
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+type DefaultAccountService struct {
+	accountRepo repositories.AccountRepository
+}
+
+func NewAccountService(accountRepo repositories.AccountRepository) *DefaultAccountService {
+	return &DefaultAccountService{accountRepo: accountRepo}
+}
+
+// CreateAccount validates and adds an account
+func (s *DefaultAccountService) CreateAccount(ctx context.Context, account *models.Account) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return apperrors.Validation("invalid_account_name", "account name is required")
+	}
+
+	if !models.IsValidAccountType(account.Type) {
+		return apperrors.Validation("invalid_account_type", "type must be one of checking, savings, credit_card or cash")
+	}
+
+	account.Currency = strings.ToUpper(strings.TrimSpace(account.Currency))
+	if !isCurrencyCode(account.Currency) {
+		return apperrors.Validation("invalid_account_currency", "currency must be a three-letter ISO 4217 code")
+	}
+
+	if err := s.accountRepo.CreateAccount(ctx, account); err != nil {
+		return apperrors.Internal("account_create_failed", "failed to create account", err)
+	}
+
+	return nil
+}
+
+// GetAccountsByUser retrieves accounts for a user
+func (s *DefaultAccountService) GetAccountsByUser(ctx context.Context, userID uint) ([]models.Account, error) {
+	accounts, err := s.accountRepo.GetAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("accounts_fetch_failed", "failed to retrieve accounts", err)
+	}
+
+	return accounts, nil
+}
+
+// GetAccountBalancesByUser computes the balance of every account a user owns as of the given time.
+func (s *DefaultAccountService) GetAccountBalancesByUser(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error) {
+	balances, err := s.accountRepo.GetAccountBalancesByUserID(ctx, userID, asOf)
+	if err != nil {
+		return nil, apperrors.Internal("account_balances_fetch_failed", "failed to compute account balances", err)
+	}
+
+	return balances, nil
+}
+
+// DeleteAccountForUser removes an account that belongs to the authenticated user.
+func (s *DefaultAccountService) DeleteAccountForUser(ctx context.Context, userID, accountID uint) error {
+	account, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return apperrors.NotFound("account_not_found", "account not found")
+	}
+
+	if account.UserID != userID {
+		return apperrors.NotFound("account_not_found", "account not found")
+	}
+
+	transactionCount, err := s.accountRepo.CountAccountTransactions(ctx, accountID)
+	if err != nil {
+		return apperrors.Internal("account_delete_failed", "failed to delete account", err)
+	}
+
+	if transactionCount > 0 {
+		return apperrors.Conflict("account_has_transactions", "account still has transactions")
+	}
+
+	if err := s.accountRepo.DeleteAccount(ctx, accountID); err != nil {
+		return apperrors.Internal("account_delete_failed", "failed to delete account", err)
+	}
+
+	return nil
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountRepository implements the AccountRepository interface
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) CreateAccount(ctx context.Context, account *models.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetAccountByID(ctx context.Context, id uint) (*models.Account, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Account), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAccountRepository) GetAccountsByUserID(ctx context.Context, userID uint) ([]models.Account, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetAccountBalancesByUserID(ctx context.Context, userID uint, asOf time.Time) ([]models.AccountBalance, error) {
+	args := m.Called(ctx, userID, asOf)
+	return args.Get(0).([]models.AccountBalance), args.Error(1)
+}
+
+func (m *MockAccountRepository) CountAccountTransactions(ctx context.Context, accountID uint) (int64, error) {
+	args := m.Called(ctx, accountID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAccountRepository) UpdateAccount(ctx context.Context, account *models.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) DeleteAccount(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateAccount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create valid account", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+		account := &models.Account{UserID: 1, Name: " Checking ", Type: models.AccountTypeChecking, Currency: "eur", OpeningBalance: 100}
+
+		mockRepo.On("CreateAccount", ctx, account).Return(nil)
+
+		err := service.CreateAccount(ctx, account)
+		assert.NoError(t, err)
+		assert.Equal(t, "Checking", account.Name)
+		assert.Equal(t, "EUR", account.Currency)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail with unknown account type", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+		account := &models.Account{UserID: 1, Name: "Broker", Type: "brokerage", Currency: "EUR"}
+
+		err := service.CreateAccount(ctx, account)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "CreateAccount")
+	})
+
+	t.Run("Fail with invalid currency", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+		account := &models.Account{UserID: 1, Name: "Wallet", Type: models.AccountTypeCash, Currency: "EURO"}
+
+		err := service.CreateAccount(ctx, account)
+		assert.Error(t, err)
+		assert.Equal(t, "currency must be a three-letter ISO 4217 code", err.Error())
+		mockRepo.AssertNotCalled(t, "CreateAccount")
+	})
+}
+
+func TestGetAccountBalancesByUser(t *testing.T) {
+	ctx := context.Background()
+	asOf := time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("Retrieve balances", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+		balances := []models.AccountBalance{{Account: models.Account{ID: 1, UserID: 1}, Balance: 42, AsOf: asOf}}
+
+		mockRepo.On("GetAccountBalancesByUserID", ctx, uint(1), asOf).Return(balances, nil)
+
+		result, err := service.GetAccountBalancesByUser(ctx, 1, asOf)
+		assert.NoError(t, err)
+		assert.Equal(t, balances, result)
+	})
+
+	t.Run("Fail when repository errors", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+
+		mockRepo.On("GetAccountBalancesByUserID", ctx, uint(1), asOf).Return([]models.AccountBalance{}, errors.New("db error"))
+
+		_, err := service.GetAccountBalancesByUser(ctx, 1, asOf)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestDeleteAccountForUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Delete empty account", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+
+		mockRepo.On("GetAccountByID", ctx, uint(1)).Return(&models.Account{ID: 1, UserID: 1}, nil)
+		mockRepo.On("CountAccountTransactions", ctx, uint(1)).Return(int64(0), nil)
+		mockRepo.On("DeleteAccount", ctx, uint(1)).Return(nil)
+
+		err := service.DeleteAccountForUser(ctx, 1, 1)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when account has transactions", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+
+		mockRepo.On("GetAccountByID", ctx, uint(1)).Return(&models.Account{ID: 1, UserID: 1}, nil)
+		mockRepo.On("CountAccountTransactions", ctx, uint(1)).Return(int64(3), nil)
+
+		err := service.DeleteAccountForUser(ctx, 1, 1)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+		mockRepo.AssertNotCalled(t, "DeleteAccount", ctx, uint(1))
+	})
+
+	t.Run("Fail to delete another user's account", func(t *testing.T) {
+		mockRepo := new(MockAccountRepository)
+		service := NewAccountService(mockRepo)
+
+		mockRepo.On("GetAccountByID", ctx, uint(2)).Return(&models.Account{ID: 2, UserID: 99}, nil)
+
+		err := service.DeleteAccountForUser(ctx, 1, 2)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+	})
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+const (
+	maxAPIKeyNameLength = 100
+	// apiKeyDisplayLength is how much of a key is kept in the clear for
+	// the user to recognise it by.
+	apiKeyDisplayLength = len(auth.APIKeyPrefix) + 8
+	// apiKeyTouchInterval keeps a busy key from writing its last used time
+	// on every request.
+	apiKeyTouchInterval = time.Minute
+)
+
+type DefaultAPIKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository) *DefaultAPIKeyService {
+	return &DefaultAPIKeyService{apiKeyRepo: apiKeyRepo, userRepo: userRepo}
+}
+
+// CreateAPIKey issues a new key for the user. The key cannot have scopes
+// beyond the granted ones of the session creating it. It is returned only
+// here; the stored record keeps its hash.
+func (s *DefaultAPIKeyService) CreateAPIKey(ctx context.Context, userID uint, granted []string, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxAPIKeyNameLength {
+		return nil, "", apperrors.Validation("invalid_api_key_name", "API key names must be between 1 and 100 characters")
+	}
+
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return nil, "", apperrors.Forbidden("insufficient_scope", "API keys cannot have the "+scope+" scope, which this session was not granted")
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", apperrors.Validation("invalid_api_key_expiry", "API key expiry must be in the future")
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, "", apperrors.Internal("api_key_create_failed", "failed to create API key", err)
+	}
+	key := auth.APIKeyPrefix + token
+
+	apiKey := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   auth.HashOpaqueToken(key),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, "", apperrors.Internal("api_key_create_failed", "failed to create API key", err)
+	}
+
+	return apiKey, key, nil
+}
+
+// ListAPIKeys returns the user's keys that have not been revoked.
+func (s *DefaultAPIKeyService) ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetAPIKeysByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("api_keys_fetch_failed", "failed to retrieve API keys", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey stops one of the user's keys from working.
+func (s *DefaultAPIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uint) error {
+	key, err := s.apiKeyRepo.GetAPIKeyByID(ctx, keyID)
+	if err != nil || key.UserID != userID || key.RevokedAt != nil {
+		return apperrors.NotFound("api_key_not_found", "API key not found")
+	}
+
+	if err := s.apiKeyRepo.RevokeAPIKey(ctx, keyID, time.Now().UTC()); err != nil {
+		return apperrors.Internal("api_key_revoke_failed", "failed to revoke API key", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the active key matching key and notes that it
+// was used. Keys stop working as soon as their owner's account is
+// scheduled for deletion; only a logged-in user can cancel that.
+func (s *DefaultAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, auth.APIKeyPrefix) {
+		return nil, apperrors.Unauthorized("invalid_api_key", "invalid API key")
+	}
+
+	apiKey, err := s.apiKeyRepo.GetAPIKeyByHash(ctx, auth.HashOpaqueToken(key))
+	if err != nil {
+		return nil, apperrors.Unauthorized("invalid_api_key", "invalid API key")
+	}
+
+	now := time.Now().UTC()
+	if apiKey.RevokedAt != nil {
+		return nil, apperrors.Unauthorized("api_key_revoked", "API key has been revoked")
+	}
+	if !apiKey.Active(now) {
+		return nil, apperrors.Unauthorized("api_key_expired", "API key has expired")
+	}
+
+	owner, err := s.userRepo.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, apperrors.Unauthorized("invalid_api_key", "invalid API key")
+	}
+	if owner.DeletionScheduledAt != nil {
+		return nil, apperrors.Unauthorized("account_deletion_scheduled", "API keys do not work while the account is scheduled for deletion")
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		// A failed write only loses the last used time, which is not worth
+		// failing the request over.
+		if err := s.apiKeyRepo.TouchAPIKey(ctx, apiKey.ID, now); err == nil {
+			apiKey.LastUsedAt = &now
+		}
+	}
+
+	return apiKey, nil
+}
+
+// normalizeScopes checks the requested scopes and puts them in the order
+// auth.Scopes lists them, without repeats.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, apperrors.Validation("invalid_api_key_scopes", "API keys need at least one scope")
+	}
+
+	normalized := make([]string, 0, len(scopes))
+	for _, known := range auth.Scopes {
+		if slices.Contains(scopes, known) {
+			normalized = append(normalized, known)
+		}
+	}
+	for _, scope := range scopes {
+		if !auth.KnownScope(scope) {
+			return nil, apperrors.Validation("invalid_api_key_scopes", "unknown scope "+scope)
+		}
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository implements the APIKeyRepository interface
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.APIKey), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Store only the hash and return the key once", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := NewAPIKeyService(mockRepo, new(MockUserRepository))
+		expiresAt := time.Now().Add(24 * time.Hour)
+
+		mockRepo.On("CreateAPIKey", ctx, mock.AnythingOfType("*models.APIKey")).Return(nil)
+
+		apiKey, key, err := service.CreateAPIKey(ctx, 1, auth.Scopes, " Sync script ", []string{auth.ScopeTransactionsWrite, auth.ScopeTransactionsRead, auth.ScopeTransactionsRead}, &expiresAt)
+		if assert.NoError(t, err) {
+			assert.True(t, strings.HasPrefix(key, auth.APIKeyPrefix))
+			assert.Equal(t, "Sync script", apiKey.Name)
+			assert.Equal(t, key[:apiKeyDisplayLength], apiKey.Prefix)
+			assert.Equal(t, auth.HashOpaqueToken(key), apiKey.KeyHash)
+			assert.Equal(t, "transactions:read,transactions:write", apiKey.Scopes)
+			assert.Equal(t, &expiresAt, apiKey.ExpiresAt)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject bad input", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := NewAPIKeyService(mockRepo, new(MockUserRepository))
+		past := time.Now().Add(-time.Minute)
+
+		_, _, err := service.CreateAPIKey(ctx, 1, auth.Scopes, " ", []string{auth.ScopeReportsRead}, nil)
+		assertAppErrorCode(t, err, "invalid_api_key_name")
+
+		_, _, err = service.CreateAPIKey(ctx, 1, auth.Scopes, "Sync", nil, nil)
+		assertAppErrorCode(t, err, "invalid_api_key_scopes")
+
+		_, _, err = service.CreateAPIKey(ctx, 1, auth.Scopes, "Sync", []string{"everything"}, nil)
+		assertAppErrorCode(t, err, "invalid_api_key_scopes")
+
+		_, _, err = service.CreateAPIKey(ctx, 1, auth.Scopes, "Sync", []string{auth.ScopeReportsRead}, &past)
+		assertAppErrorCode(t, err, "invalid_api_key_expiry")
+
+		mockRepo.AssertNotCalled(t, "CreateAPIKey")
+	})
+
+	t.Run("Reject scopes the session was not granted", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := NewAPIKeyService(mockRepo, new(MockUserRepository))
+
+		_, _, err := service.CreateAPIKey(ctx, 1, []string{auth.ScopeReportsRead}, "Sync", []string{auth.ScopeReportsRead, auth.ScopeTransactionsWrite}, nil)
+		assertAppErrorCode(t, err, "insufficient_scope")
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+
+		_, _, err = service.CreateAPIKey(ctx, 1, nil, "Sync", []string{auth.ScopeReportsRead}, nil)
+		assertAppErrorCode(t, err, "insufficient_scope")
+
+		mockRepo.AssertNotCalled(t, "CreateAPIKey")
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revoke own key", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := NewAPIKeyService(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetAPIKeyByID", ctx, uint(5)).Return(&models.APIKey{ID: 5, UserID: 1}, nil)
+		mockRepo.On("RevokeAPIKey", ctx, uint(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+		assert.NoError(t, service.RevokeAPIKey(ctx, 1, 5))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Hide keys of other users", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		service := NewAPIKeyService(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetAPIKeyByID", ctx, uint(5)).Return(&models.APIKey{ID: 5, UserID: 2}, nil)
+
+		err := service.RevokeAPIKey(ctx, 1, 5)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockRepo.AssertNotCalled(t, "RevokeAPIKey")
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	key := auth.APIKeyPrefix + "secret"
+	hash := auth.HashOpaqueToken(key)
+
+	t.Run("Accept active key and record its use", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		mockUserRepo := activeOwner(ctx)
+		service := NewAPIKeyService(mockRepo, mockUserRepo)
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, UserID: 1}, nil)
+		mockRepo.On("TouchAPIKey", ctx, uint(5), mock.AnythingOfType("time.Time")).Return(nil)
+
+		apiKey, err := service.AuthenticateAPIKey(ctx, key)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(1), apiKey.UserID)
+			assert.NotNil(t, apiKey.LastUsedAt)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skip recording use within a minute", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		mockUserRepo := activeOwner(ctx)
+		service := NewAPIKeyService(mockRepo, mockUserRepo)
+		lastUsed := time.Now().Add(-10 * time.Second)
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, UserID: 1, LastUsedAt: &lastUsed}, nil)
+
+		_, err := service.AuthenticateAPIKey(ctx, key)
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "TouchAPIKey")
+	})
+
+	t.Run("Still accept the key when recording use fails", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		mockUserRepo := activeOwner(ctx)
+		service := NewAPIKeyService(mockRepo, mockUserRepo)
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, UserID: 1}, nil)
+		mockRepo.On("TouchAPIKey", ctx, uint(5), mock.AnythingOfType("time.Time")).Return(errors.New("db down"))
+
+		_, err := service.AuthenticateAPIKey(ctx, key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reject unusable keys", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		mockUserRepo := activeOwner(ctx)
+		service := NewAPIKeyService(mockRepo, mockUserRepo)
+		past := time.Now().Add(-time.Hour)
+
+		_, err := service.AuthenticateAPIKey(ctx, "secret")
+		assertAppErrorCode(t, err, "invalid_api_key")
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(nil, errors.New("record not found")).Once()
+		_, err = service.AuthenticateAPIKey(ctx, key)
+		assertAppErrorCode(t, err, "invalid_api_key")
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, RevokedAt: &past}, nil).Once()
+		_, err = service.AuthenticateAPIKey(ctx, key)
+		assertAppErrorCode(t, err, "api_key_revoked")
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, ExpiresAt: &past}, nil).Once()
+		_, err = service.AuthenticateAPIKey(ctx, key)
+		assertAppErrorCode(t, err, "api_key_expired")
+
+		mockRepo.AssertNotCalled(t, "TouchAPIKey")
+	})
+
+	t.Run("Reject keys of an account scheduled for deletion", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewAPIKeyService(mockRepo, mockUserRepo)
+		scheduledAt := time.Now().Add(24 * time.Hour)
+
+		mockRepo.On("GetAPIKeyByHash", ctx, hash).Return(&models.APIKey{ID: 5, UserID: 1}, nil)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, DeletionScheduledAt: &scheduledAt}, nil).Once()
+
+		_, err := service.AuthenticateAPIKey(ctx, key)
+		assertAppErrorCode(t, err, "account_deletion_scheduled")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(nil, errors.New("record not found")).Once()
+		_, err = service.AuthenticateAPIKey(ctx, key)
+		assertAppErrorCode(t, err, "invalid_api_key")
+
+		mockRepo.AssertNotCalled(t, "TouchAPIKey")
+	})
+}
+
+// activeOwner returns a user repository holding the owner of the keys in
+// TestAuthenticateAPIKey, with no deletion scheduled.
+func activeOwner(ctx context.Context) *MockUserRepository {
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil)
+	return mockUserRepo
+}
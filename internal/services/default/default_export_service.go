@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+type DefaultExportService struct {
+	transactionRepo repositories.TransactionRepository
+	accountRepo     repositories.AccountRepository
+	archiveRepo     repositories.ArchiveRepository
+}
+
+func NewExportService(transactionRepo repositories.TransactionRepository, accountRepo repositories.AccountRepository, archiveRepo repositories.ArchiveRepository) *DefaultExportService {
+	return &DefaultExportService{
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		archiveRepo:     archiveRepo,
+	}
+}
+
+// ExportTransactions writes the user's transactions matching the filters to
+// w in the given format, oldest first, one transaction at a time as they
+// are read from the database.
+func (s *DefaultExportService) ExportTransactions(ctx context.Context, userID uint, format exports.Format, transactionFilters filters.TransactionFilters, w io.Writer) error {
+	writer, err := exports.NewWriter(format, w, s.statement(ctx, userID, transactionFilters))
+	if err != nil {
+		return apperrors.Validation("invalid_export_format", err.Error())
+	}
+
+	err = s.transactionRepo.StreamTransactionsByUserID(ctx, userID, transactionFilters, func(transaction models.Transaction) error {
+		return writer.Write(transaction)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return apperrors.Internal("transactions_export_failed", "failed to export transactions", err)
+	}
+
+	return nil
+}
+
+// ExportArchive writes a versioned zip archive of all the user's data to w.
+// Transactions are streamed into it as they are read from the database.
+func (s *DefaultExportService) ExportArchive(ctx context.Context, userID uint, w io.Writer) error {
+	data, err := s.archiveRepo.GetArchiveData(ctx, userID)
+	if err != nil {
+		return apperrors.Internal("archive_export_failed", "failed to export archive", err)
+	}
+
+	writer := archive.NewWriter(w)
+	if err := writer.WriteData(data); err != nil {
+		return apperrors.Internal("archive_export_failed", "failed to export archive", err)
+	}
+
+	err = writer.WriteTransactions(func(fn func(models.Transaction) error) error {
+		return s.transactionRepo.StreamTransactionsByUserID(ctx, userID, filters.TransactionFilters{}, fn)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return apperrors.Internal("archive_export_failed", "failed to export archive", err)
+	}
+
+	return nil
+}
+
+// statement describes the export for formats that declare it up front. The
+// account's number and currency are only known when the export is limited
+// to one of the user's accounts.
+func (s *DefaultExportService) statement(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) exports.Statement {
+	var statement exports.Statement
+	if transactionFilters.From != nil {
+		statement.From = *transactionFilters.From
+	}
+	if transactionFilters.To != nil {
+		statement.To = *transactionFilters.To
+	}
+
+	if transactionFilters.AccountID != nil {
+		account, err := s.accountRepo.GetAccountByID(ctx, *transactionFilters.AccountID)
+		if err == nil && account.UserID == userID {
+			statement.AccountID = strconv.FormatUint(uint64(account.ID), 10)
+			statement.Currency = account.Currency
+		}
+	}
+
+	return statement
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockArchiveRepository implements the ArchiveRepository interface
+type MockArchiveRepository struct {
+	mock.Mock
+}
+
+func (m *MockArchiveRepository) GetArchiveData(ctx context.Context, userID uint) (*models.ArchiveData, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.ArchiveData), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockArchiveRepository) HasUserData(ctx context.Context, userID uint) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockArchiveRepository) RestoreArchive(ctx context.Context, userID uint, data *models.ArchiveData) error {
+	args := m.Called(ctx, userID, data)
+	return args.Error(0)
+}
+
+func TestExportTransactions(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Export as JSON Lines", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewExportService(mockTransactionRepo, new(MockAccountRepository), new(MockArchiveRepository))
+
+		transactionFilters := filters.TransactionFilters{Type: "expense"}
+		mockTransactionRepo.On("StreamTransactionsByUserID", ctx, uint(1), transactionFilters, mock.Anything).Return([]models.Transaction{
+			{ID: 1, UserID: 1, Type: "expense", Amount: 12, CategoryID: 2, Date: date},
+			{ID: 2, UserID: 1, Type: "expense", Amount: 8, CategoryID: 2, Date: date},
+		}, nil)
+
+		var out bytes.Buffer
+		err := service.ExportTransactions(ctx, 1, exports.FormatJSONL, transactionFilters, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, bytes.Count(out.Bytes(), []byte("\n")))
+	})
+
+	t.Run("Declare the filtered account in OFX", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockAccountRepo := new(MockAccountRepository)
+		service := NewExportService(mockTransactionRepo, mockAccountRepo, new(MockArchiveRepository))
+
+		accountID := uint(3)
+		transactionFilters := filters.TransactionFilters{AccountID: &accountID, From: &date}
+		mockAccountRepo.On("GetAccountByID", ctx, uint(3)).Return(&models.Account{ID: 3, UserID: 1, Currency: "USD"}, nil)
+		mockTransactionRepo.On("StreamTransactionsByUserID", ctx, uint(1), transactionFilters, mock.Anything).Return([]models.Transaction{}, nil)
+
+		var out bytes.Buffer
+		err := service.ExportTransactions(ctx, 1, exports.FormatOFX, transactionFilters, &out)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "<CURDEF>USD</CURDEF>")
+		assert.Contains(t, out.String(), "<ACCTID>3</ACCTID>")
+		assert.Contains(t, out.String(), "<DTSTART>20260301000000</DTSTART>")
+	})
+
+	t.Run("Fail with an unsupported format", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewExportService(mockTransactionRepo, new(MockAccountRepository), new(MockArchiveRepository))
+
+		err := service.ExportTransactions(ctx, 1, exports.Format("xlsx"), filters.TransactionFilters{}, &bytes.Buffer{})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "StreamTransactionsByUserID")
+	})
+
+	t.Run("Fail when reading stops", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewExportService(mockTransactionRepo, new(MockAccountRepository), new(MockArchiveRepository))
+
+		mockTransactionRepo.On("StreamTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{}, mock.Anything).
+			Return([]models.Transaction{{ID: 1, UserID: 1, Type: "income", Amount: 5, Date: date}}, errors.New("connection reset"))
+
+		err := service.ExportTransactions(ctx, 1, exports.FormatCSV, filters.TransactionFilters{}, &bytes.Buffer{})
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestExportArchive(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Export data and streamed transactions", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewExportService(mockTransactionRepo, new(MockAccountRepository), mockArchiveRepo)
+
+		accountID := uint(4)
+		mockArchiveRepo.On("GetArchiveData", ctx, uint(1)).Return(&models.ArchiveData{
+			Categories: []models.Category{{ID: 2, Name: "Groceries"}},
+			Accounts:   []models.Account{{ID: 4, UserID: 1, Name: "Checking", Type: "checking", Currency: "EUR"}},
+		}, nil)
+		mockTransactionRepo.On("StreamTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{}, mock.Anything).Return([]models.Transaction{
+			{ID: 1, UserID: 1, Type: "expense", Amount: 12, CategoryID: 2, AccountID: &accountID, Date: date},
+		}, nil)
+
+		var out bytes.Buffer
+		err := service.ExportArchive(ctx, 1, &out)
+		assert.NoError(t, err)
+
+		data, err := archive.Read(bytes.NewReader(out.Bytes()), int64(out.Len()))
+		assert.NoError(t, err)
+		assert.Len(t, data.Accounts, 1)
+		assert.Len(t, data.Transactions, 1)
+	})
+
+	t.Run("Fail when the data cannot be read", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewExportService(mockTransactionRepo, new(MockAccountRepository), mockArchiveRepo)
+
+		mockArchiveRepo.On("GetArchiveData", ctx, uint(1)).Return(nil, errors.New("db error"))
+
+		err := service.ExportArchive(ctx, 1, &bytes.Buffer{})
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+		mockTransactionRepo.AssertNotCalled(t, "StreamTransactionsByUserID")
+	})
+}
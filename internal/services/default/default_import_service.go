@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	servicecontracts "github.com/TsonasIoannis/go-personal-finance-tracker/internal/services"
+)
+
+// maxNoteLength mirrors the size of the transactions.note and payee columns.
+const maxNoteLength = 255
+
+type DefaultImportService struct {
+	transactionService servicecontracts.TransactionService
+	transactionRepo    repositories.TransactionRepository
+	archiveRepo        repositories.ArchiveRepository
+}
+
+func NewImportService(transactionService servicecontracts.TransactionService, transactionRepo repositories.TransactionRepository, archiveRepo repositories.ArchiveRepository) *DefaultImportService {
+	return &DefaultImportService{
+		transactionService: transactionService,
+		transactionRepo:    transactionRepo,
+		archiveRepo:        archiveRepo,
+	}
+}
+
+// PreviewCSV parses a CSV statement without storing anything.
+func (s *DefaultImportService) PreviewCSV(ctx context.Context, userID uint, file io.Reader, mapping imports.CSVMapping) (*imports.Preview, error) {
+	preview, err := imports.ParseCSV(file, mapping)
+	if err != nil {
+		return nil, apperrors.Validation("invalid_csv", err.Error())
+	}
+
+	return preview, nil
+}
+
+// PreviewStatement parses an OFX, camt.053 or MT940 statement without
+// storing anything. Rows whose bank reference the user has already imported
+// are moved to Skipped.
+func (s *DefaultImportService) PreviewStatement(ctx context.Context, userID uint, format imports.Format, file io.Reader) (*imports.Preview, error) {
+	preview, err := imports.Parse(format, file)
+	if err != nil {
+		return nil, apperrors.Validation("invalid_"+string(format), err.Error())
+	}
+
+	externalIDs := make([]string, 0, len(preview.Rows))
+	for _, row := range preview.Rows {
+		if row.ExternalID != "" {
+			externalIDs = append(externalIDs, row.ExternalID)
+		}
+	}
+
+	existing, err := s.existingExternalIDs(ctx, userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]imports.ParsedRow, 0, len(preview.Rows))
+	for _, row := range preview.Rows {
+		if row.ExternalID != "" && existing[row.ExternalID] {
+			preview.Skipped = append(preview.Skipped, row)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	preview.Rows = rows
+
+	return preview, nil
+}
+
+// ConfirmImport stores previewed rows through the regular transaction
+// validations. Either every new row is imported or none is; rows whose
+// external ID was imported before are skipped. The user's rules run first,
+// and rows they leave uncategorised get fallbackCategoryID.
+func (s *DefaultImportService) ConfirmImport(ctx context.Context, userID, fallbackCategoryID uint, transactions []models.Transaction) (*imports.Result, error) {
+	if len(transactions) == 0 {
+		return nil, apperrors.Validation("empty_import", "there are no transactions to import")
+	}
+
+	externalIDs := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.ExternalID != "" {
+			externalIDs = append(externalIDs, transaction.ExternalID)
+		}
+	}
+
+	existing, err := s.existingExternalIDs(ctx, userID, externalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &imports.Result{}
+	pending := make([]models.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.ExternalID != "" {
+			if existing[transaction.ExternalID] {
+				result.Skipped++
+				continue
+			}
+			// Also drops repeats within the same request.
+			existing[transaction.ExternalID] = true
+		}
+
+		transaction.UserID = userID
+		transaction.Note = truncate(transaction.Note, maxNoteLength)
+		transaction.Payee = truncate(transaction.Payee, maxNoteLength)
+		pending = append(pending, transaction)
+	}
+
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	if err := s.transactionService.ApplyRules(ctx, userID, pending); err != nil {
+		return nil, err
+	}
+	for i := range pending {
+		if pending[i].CategoryID == 0 {
+			pending[i].CategoryID = fallbackCategoryID
+		}
+	}
+
+	if err := s.transactionService.AddTransactions(ctx, pending); err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(pending)
+
+	// Duplicate detection is advisory: the rows are already stored, so a
+	// failed lookup only means no candidates are reported.
+	if matches, err := s.transactionService.FindDuplicates(ctx, userID, pending); err == nil {
+		result.Duplicates = matches
+	}
+
+	return result, nil
+}
+
+func (s *DefaultImportService) existingExternalIDs(ctx context.Context, userID uint, externalIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(externalIDs))
+	if len(externalIDs) == 0 {
+		return existing, nil
+	}
+
+	found, err := s.transactionRepo.GetExistingExternalIDs(ctx, userID, externalIDs)
+	if err != nil {
+		return nil, apperrors.Internal("import_lookup_failed", "failed to check previously imported transactions", err)
+	}
+
+	for _, externalID := range found {
+		existing[externalID] = true
+	}
+	return existing, nil
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
+
+// ImportArchive restores an archive written by ExportArchive into the
+// user's account, which must not hold any data yet. Every record gets a new
+// ID and everything is stored in a single database transaction. Records
+// are restored as they were exported, so rules and budget limits are not
+// applied to them, but split lines must pass the same checks as when a
+// transaction is created.
+func (s *DefaultImportService) ImportArchive(ctx context.Context, userID uint, file io.ReaderAt, size int64) (*archive.Summary, error) {
+	data, err := archive.Read(file, size)
+	if errors.Is(err, archive.ErrUnsupportedVersion) {
+		return nil, apperrors.Validation("unsupported_archive_version", err.Error())
+	}
+	if err != nil {
+		return nil, apperrors.Validation("invalid_archive", err.Error())
+	}
+	for i := range data.Transactions {
+		if err := validateSplits(&data.Transactions[i]); err != nil {
+			return nil, apperrors.Validation("invalid_archive", fmt.Sprintf("transaction %d: %s", data.Transactions[i].ID, err.Error()))
+		}
+	}
+
+	hasData, err := s.archiveRepo.HasUserData(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("archive_import_failed", "failed to import archive", err)
+	}
+	if hasData {
+		return nil, apperrors.Conflict("account_not_empty", "archives can only be imported into an account without data")
+	}
+
+	if err := s.archiveRepo.RestoreArchive(ctx, userID, data); err != nil {
+		return nil, apperrors.Internal("archive_import_failed", "failed to import archive", err)
+	}
+
+	summary := archive.Summarize(data)
+	return &summary, nil
+}
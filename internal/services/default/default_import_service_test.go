@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactionService implements the TransactionService interface
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) AddTransaction(ctx context.Context, transaction *models.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) AddTransactions(ctx context.Context, transactions []models.Transaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) GetTransactionsByUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.Transaction, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	return args.Get(0).([]models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionService) GetTransactionsPageByUser(ctx context.Context, userID uint, params pagination.Params, transactionFilters filters.TransactionFilters) ([]models.Transaction, int64, error) {
+	args := m.Called(ctx, userID, params, transactionFilters)
+	return args.Get(0).([]models.Transaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransactionService) UpdateTransactionForUser(ctx context.Context, userID uint, transaction *models.Transaction) error {
+	args := m.Called(ctx, userID, transaction)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) DeleteTransactionForUser(ctx context.Context, userID, transactionID uint) error {
+	args := m.Called(ctx, userID, transactionID)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) DeleteTransferForUser(ctx context.Context, userID, transferID uint) error {
+	args := m.Called(ctx, userID, transferID)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) FindDuplicates(ctx context.Context, userID uint, transactions []models.Transaction) ([]models.DuplicateMatch, error) {
+	args := m.Called(ctx, userID, transactions)
+	if args.Get(0) != nil {
+		return args.Get(0).([]models.DuplicateMatch), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) GetDuplicateGroupsByUser(ctx context.Context, userID uint) ([]models.DuplicateGroup, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.DuplicateGroup), args.Error(1)
+}
+
+func (m *MockTransactionService) MergeDuplicatesForUser(ctx context.Context, userID, keepID uint, duplicateIDs []uint) (*models.Transaction, error) {
+	args := m.Called(ctx, userID, keepID, duplicateIDs)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Transaction), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) ApplyRules(ctx context.Context, userID uint, transactions []models.Transaction) error {
+	args := m.Called(ctx, userID, transactions)
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) ReapplyRulesForUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) (int, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionService) SuggestCategories(ctx context.Context, userID uint, note, payee string) ([]models.CategorySuggestion, error) {
+	args := m.Called(ctx, userID, note, payee)
+	return args.Get(0).([]models.CategorySuggestion), args.Error(1)
+}
+
+func TestPreviewCSV(t *testing.T) {
+	ctx := context.Background()
+	service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), new(MockArchiveRepository))
+
+	t.Run("Parse statement", func(t *testing.T) {
+		preview, err := service.PreviewCSV(ctx, 1, strings.NewReader("Date,Amount\n2026-03-01,-10\n"), imports.CSVMapping{DateColumn: "Date", AmountColumn: "Amount"})
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 1)
+	})
+
+	t.Run("Fail with unusable mapping", func(t *testing.T) {
+		_, err := service.PreviewCSV(ctx, 1, strings.NewReader("Date,Amount\n"), imports.CSVMapping{DateColumn: "Date"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+	})
+}
+
+func TestPreviewStatement(t *testing.T) {
+	ctx := context.Background()
+
+	statement := `<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260301</DTPOSTED><TRNAMT>-42.50</TRNAMT><FITID>FIT-1</FITID><NAME>Supermarket</NAME></STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20260302</DTPOSTED><TRNAMT>1000.00</TRNAMT><FITID>FIT-2</FITID><NAME>Salary</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+	t.Run("Skip previously imported rows", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewImportService(new(MockTransactionService), mockTransactionRepo, new(MockArchiveRepository))
+
+		mockTransactionRepo.On("GetExistingExternalIDs", ctx, uint(1), []string{"FIT-1", "FIT-2"}).Return([]string{"FIT-1"}, nil).Once()
+
+		preview, err := service.PreviewStatement(ctx, 1, imports.FormatOFX, strings.NewReader(statement))
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 1)
+		assert.Equal(t, "FIT-2", preview.Rows[0].ExternalID)
+		assert.Len(t, preview.Skipped, 1)
+		assert.Equal(t, "FIT-1", preview.Skipped[0].ExternalID)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Preview entries without a bank reference", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewImportService(new(MockTransactionService), mockTransactionRepo, new(MockArchiveRepository))
+
+		preview, err := service.PreviewStatement(ctx, 1, imports.FormatMT940, strings.NewReader(":20:STARTUMS\n:61:260103D5,00NMSCNONREF\n:86:Card fee\n"))
+		assert.NoError(t, err)
+		assert.Len(t, preview.Rows, 1)
+		assert.Empty(t, preview.Skipped)
+		mockTransactionRepo.AssertNotCalled(t, "GetExistingExternalIDs")
+	})
+
+	t.Run("Fail with a file that is not OFX", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewImportService(new(MockTransactionService), mockTransactionRepo, new(MockArchiveRepository))
+
+		_, err := service.PreviewStatement(ctx, 1, imports.FormatOFX, strings.NewReader("Date,Amount\n"))
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "GetExistingExternalIDs")
+	})
+}
+
+func TestConfirmImport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Import rows for the user", func(t *testing.T) {
+		mockTransactionService := new(MockTransactionService)
+		service := NewImportService(mockTransactionService, new(MockTransactionRepository), new(MockArchiveRepository))
+
+		transactions := []models.Transaction{
+			{Type: "expense", Amount: 10, Date: time.Now(), Note: strings.Repeat("x", 300)},
+			{Type: "income", Amount: 20, Date: time.Now()},
+			{Type: "income", Amount: 30, CategoryID: 5, Date: time.Now()},
+		}
+
+		mockTransactionService.On("ApplyRules", ctx, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(2).([]models.Transaction)[0].CategoryID = 7
+		}).Return(nil).Once()
+		mockTransactionService.On("AddTransactions", ctx, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 3 &&
+				transactions[0].UserID == 1 &&
+				transactions[1].UserID == 1 &&
+				len(transactions[0].Note) == 255 &&
+				transactions[0].CategoryID == 7 &&
+				transactions[1].CategoryID == 3 &&
+				transactions[2].CategoryID == 5
+		})).Return(nil).Once()
+		mockTransactionService.On("FindDuplicates", ctx, uint(1), mock.Anything).Return([]models.DuplicateMatch{{
+			Transaction: models.Transaction{ID: 11},
+			Candidates:  []models.Transaction{{ID: 2}},
+		}}, nil).Once()
+
+		result, err := service.ConfirmImport(ctx, 1, 3, transactions)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.Imported)
+		assert.Len(t, result.Duplicates, 1)
+		mockTransactionService.AssertExpectations(t)
+	})
+
+	t.Run("Skip rows imported before", func(t *testing.T) {
+		mockTransactionService := new(MockTransactionService)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewImportService(mockTransactionService, mockTransactionRepo, new(MockArchiveRepository))
+
+		transactions := []models.Transaction{
+			{Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now(), ExternalID: "FIT-1"},
+			{Type: "income", Amount: 20, CategoryID: 1, Date: time.Now(), ExternalID: "FIT-2"},
+			{Type: "income", Amount: 20, CategoryID: 1, Date: time.Now(), ExternalID: "FIT-2"},
+		}
+
+		mockTransactionRepo.On("GetExistingExternalIDs", ctx, uint(1), []string{"FIT-1", "FIT-2", "FIT-2"}).Return([]string{"FIT-1"}, nil).Once()
+		mockTransactionService.On("ApplyRules", ctx, uint(1), mock.Anything).Return(nil).Once()
+		mockTransactionService.On("AddTransactions", ctx, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 1 && transactions[0].ExternalID == "FIT-2"
+		})).Return(nil).Once()
+		mockTransactionService.On("FindDuplicates", ctx, uint(1), mock.Anything).Return(nil, assert.AnError).Once()
+
+		result, err := service.ConfirmImport(ctx, 1, 1, transactions)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 2, result.Skipped)
+		assert.Empty(t, result.Duplicates)
+		mockTransactionService.AssertExpectations(t)
+	})
+
+	t.Run("Fail with nothing to import", func(t *testing.T) {
+		mockTransactionService := new(MockTransactionService)
+		service := NewImportService(mockTransactionService, new(MockTransactionRepository), new(MockArchiveRepository))
+
+		_, err := service.ConfirmImport(ctx, 1, 1, nil)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionService.AssertNotCalled(t, "AddTransactions")
+	})
+}
+
+func TestImportArchive(t *testing.T) {
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	writer := archive.NewWriter(&buf)
+	assert.NoError(t, writer.WriteData(&models.ArchiveData{Categories: []models.Category{{ID: 1, Name: "Groceries"}}, Tags: []models.Tag{{ID: 2, Name: "food"}}}))
+	assert.NoError(t, writer.WriteTransactions(func(fn func(models.Transaction) error) error {
+		return fn(models.Transaction{ID: 1, Type: "expense", Amount: 4, CategoryID: 1, Date: time.Now(), Tags: []models.Tag{{ID: 2}}})
+	}))
+	assert.NoError(t, writer.Close())
+	file := bytes.NewReader(buf.Bytes())
+
+	t.Run("Restore into an empty account", func(t *testing.T) {
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), mockArchiveRepo)
+
+		mockArchiveRepo.On("HasUserData", ctx, uint(1)).Return(false, nil)
+		mockArchiveRepo.On("RestoreArchive", ctx, uint(1), mock.MatchedBy(func(data *models.ArchiveData) bool {
+			return len(data.Tags) == 1 && len(data.Transactions) == 1
+		})).Return(nil)
+
+		summary, err := service.ImportArchive(ctx, 1, file, file.Size())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, summary.Tags)
+		assert.Equal(t, 1, summary.Transactions)
+		mockArchiveRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when the account has data", func(t *testing.T) {
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), mockArchiveRepo)
+
+		mockArchiveRepo.On("HasUserData", ctx, uint(1)).Return(true, nil)
+
+		_, err := service.ImportArchive(ctx, 1, file, file.Size())
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+		mockArchiveRepo.AssertNotCalled(t, "RestoreArchive")
+	})
+
+	t.Run("Fail with an invalid archive", func(t *testing.T) {
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), mockArchiveRepo)
+
+		_, err := service.ImportArchive(ctx, 1, strings.NewReader("not a zip"), 9)
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_archive", appErr.Code)
+		mockArchiveRepo.AssertNotCalled(t, "HasUserData")
+	})
+
+	t.Run("Fail with split lines that do not add up", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := archive.NewWriter(&buf)
+		assert.NoError(t, writer.WriteData(&models.ArchiveData{Categories: []models.Category{{ID: 1, Name: "Groceries"}, {ID: 2, Name: "Household"}}}))
+		assert.NoError(t, writer.WriteTransactions(func(fn func(models.Transaction) error) error {
+			return fn(models.Transaction{ID: 1, Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now(), Splits: []models.TransactionSplit{
+				{CategoryID: 1, Amount: 4},
+				{CategoryID: 2, Amount: 4},
+			}})
+		}))
+		assert.NoError(t, writer.Close())
+		file := bytes.NewReader(buf.Bytes())
+
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), mockArchiveRepo)
+
+		_, err := service.ImportArchive(ctx, 1, file, file.Size())
+		assertAppErrorCode(t, err, "invalid_archive")
+		assert.ErrorContains(t, err, "split amounts must add up")
+		mockArchiveRepo.AssertNotCalled(t, "HasUserData")
+	})
+
+	t.Run("Fail when restoring fails", func(t *testing.T) {
+		mockArchiveRepo := new(MockArchiveRepository)
+		service := NewImportService(new(MockTransactionService), new(MockTransactionRepository), mockArchiveRepo)
+
+		mockArchiveRepo.On("HasUserData", ctx, uint(1)).Return(false, nil)
+		mockArchiveRepo.On("RestoreArchive", ctx, uint(1), mock.Anything).Return(errors.New("db error"))
+
+		_, err := service.ImportArchive(ctx, 1, file, file.Size())
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
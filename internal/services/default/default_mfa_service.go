@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// defaultTOTPIssuer is the name authenticator apps list the account under
+// unless WithTOTPIssuer says otherwise.
+const defaultTOTPIssuer = "Personal Finance Tracker"
+
+type DefaultMFAService struct {
+	userRepo         repositories.UserRepository
+	mfaRepo          repositories.MFARepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	tokenManager     auth.MFATokenManager
+	issuer           string
+	throttle         *LoginThrottle
+}
+
+// MFAServiceOption configures optional behaviour of the MFA service.
+type MFAServiceOption func(*DefaultMFAService)
+
+// WithTOTPIssuer sets the name authenticator apps show next to the code.
+func WithTOTPIssuer(issuer string) MFAServiceOption {
+	return func(s *DefaultMFAService) {
+		if issuer != "" {
+			s.issuer = issuer
+		}
+	}
+}
+
+// WithMFALoginThrottle counts wrong codes against the account, so the
+// second factor cannot be guessed either.
+func WithMFALoginThrottle(throttle *LoginThrottle) MFAServiceOption {
+	return func(s *DefaultMFAService) {
+		s.throttle = throttle
+	}
+}
+
+func NewMFAService(userRepo repositories.UserRepository, mfaRepo repositories.MFARepository, revokedTokenRepo repositories.RevokedTokenRepository, tokenManager auth.MFATokenManager, options ...MFAServiceOption) *DefaultMFAService {
+	service := &DefaultMFAService{
+		userRepo:         userRepo,
+		mfaRepo:          mfaRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		tokenManager:     tokenManager,
+		issuer:           defaultTOTPIssuer,
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+// BeginTOTPEnrolment creates a new secret for the user's authenticator
+// app once the password is confirmed. It only takes effect once
+// ConfirmTOTPEnrolment sees a code from it; starting again replaces a
+// secret that was never confirmed.
+func (s *DefaultMFAService) BeginTOTPEnrolment(ctx context.Context, userID uint, password string) (*auth.TOTPEnrolment, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NotFound("user_not_found", "user not found")
+	}
+	if user.MFAEnabled() {
+		return nil, apperrors.Conflict("mfa_already_enabled", "two-factor authentication is already enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, apperrors.Forbidden("invalid_password", "password is incorrect")
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, apperrors.Internal("mfa_enrolment_failed", "failed to start two-factor enrolment", err)
+	}
+
+	if err := s.mfaRepo.SetPendingTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, apperrors.Internal("mfa_enrolment_failed", "failed to start two-factor enrolment", err)
+	}
+
+	return &auth.TOTPEnrolment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrolment enables TOTP once the user proves their app
+// produces the right codes, and returns recovery codes that are shown this
+// one time only.
+func (s *DefaultMFAService) ConfirmTOTPEnrolment(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NotFound("user_not_found", "user not found")
+	}
+	if user.MFAEnabled() {
+		return nil, apperrors.Conflict("mfa_already_enabled", "two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperrors.Conflict("mfa_enrolment_not_started", "two-factor enrolment has not been started")
+	}
+
+	now := time.Now().UTC()
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, normalizeMFACode(code), now)
+	if !ok {
+		return nil, apperrors.Validation("invalid_mfa_code", "invalid authentication code")
+	}
+
+	codes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, apperrors.Internal("mfa_enrolment_failed", "failed to enable two-factor authentication", err)
+	}
+
+	stored := make([]models.RecoveryCode, len(codes))
+	for i, recoveryCode := range codes {
+		stored[i] = models.RecoveryCode{UserID: userID, CodeHash: auth.HashRecoveryCode(recoveryCode)}
+	}
+
+	if err := s.mfaRepo.EnableTOTP(ctx, userID, now, step, stored); err != nil {
+		return nil, apperrors.Internal("mfa_enrolment_failed", "failed to enable two-factor authentication", err)
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off after the password and a
+// current code or recovery code are confirmed.
+func (s *DefaultMFAService) DisableTOTP(ctx context.Context, userID uint, password, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return apperrors.NotFound("user_not_found", "user not found")
+	}
+	if !user.MFAEnabled() {
+		return apperrors.Conflict("mfa_not_enabled", "two-factor authentication is not enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return apperrors.Forbidden("invalid_password", "password is incorrect")
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, code)
+	if err != nil {
+		return apperrors.Internal("mfa_disable_failed", "failed to disable two-factor authentication", err)
+	}
+	if !ok {
+		return apperrors.Forbidden("invalid_mfa_code", "invalid authentication code")
+	}
+
+	if err := s.mfaRepo.DisableTOTP(ctx, userID); err != nil {
+		return apperrors.Internal("mfa_disable_failed", "failed to disable two-factor authentication", err)
+	}
+
+	return nil
+}
+
+// StartMFAChallenge returns the short-lived token a user whose password
+// was accepted exchanges, together with a code, for their access token.
+func (s *DefaultMFAService) StartMFAChallenge(_ context.Context, user *models.User) (*auth.MFAChallenge, error) {
+	token, err := s.tokenManager.GenerateMFAToken(user)
+	if err != nil {
+		return nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	claims, err := s.tokenManager.ParseMFAToken(token)
+	if err != nil {
+		return nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	return &auth.MFAChallenge{Token: token, ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC()}, nil
+}
+
+// VerifyMFAChallenge checks the second factor of a login and returns the
+// user it belongs to. The code is either from the authenticator app or one
+// of the recovery codes, and either kind works only once, as does the
+// challenge token.
+func (s *DefaultMFAService) VerifyMFAChallenge(ctx context.Context, mfaToken, code string) (*models.User, error) {
+	claims, err := s.tokenManager.ParseMFAToken(mfaToken)
+	if err != nil {
+		return nil, invalidMFAToken()
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil || !user.MFAEnabled() {
+		return nil, invalidMFAToken()
+	}
+
+	now := time.Now().UTC()
+	keys := s.throttle.keys(user.Email, "")
+	if err := s.throttle.check(ctx, keys, now); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, code)
+	if err != nil {
+		return nil, apperrors.Internal("mfa_verification_failed", "failed to verify authentication code", err)
+	}
+	if !ok {
+		if err := s.throttle.recordFailure(ctx, keys, loginFailureInvalidMFACode, now); err != nil {
+			return nil, err
+		}
+		return nil, apperrors.Unauthorized("invalid_mfa_code", "invalid authentication code")
+	}
+
+	used := &models.RevokedToken{TokenID: claims.ID, UserID: claims.UserID, ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC()}
+	consumed, err := s.revokedTokenRepo.ConsumeToken(ctx, used)
+	if err != nil {
+		return nil, apperrors.Internal("token_check_failed", "failed to check token", err)
+	}
+	if !consumed {
+		return nil, invalidMFAToken()
+	}
+
+	if err := s.throttle.reset(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkSecondFactor accepts a six digit code from the user's app or one of
+// their recovery codes, and uses it up.
+func (s *DefaultMFAService) checkSecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	code = normalizeMFACode(code)
+	now := time.Now().UTC()
+
+	if step, ok := auth.ValidateTOTP(user.TOTPSecret, code, now); ok {
+		return s.mfaRepo.UseTOTPStep(ctx, user.ID, step)
+	}
+	if code == "" {
+		return false, nil
+	}
+
+	return s.mfaRepo.UseRecoveryCode(ctx, user.ID, auth.HashRecoveryCode(code), now)
+}
+
+// normalizeMFACode drops the spaces apps put in the middle of a code.
+func normalizeMFACode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+func invalidMFAToken() error {
+	return apperrors.Unauthorized("invalid_mfa_token", "invalid or expired MFA token")
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockMFARepository implements the MFARepository interface
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) SetPendingTOTPSecret(ctx context.Context, userID uint, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) EnableTOTP(ctx context.Context, userID uint, enabledAt time.Time, step int64, codes []models.RecoveryCode) error {
+	args := m.Called(ctx, userID, enabledAt, step, codes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) DisableTOTP(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	args := m.Called(ctx, userID, codeHash, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestBeginTOTPEnrolment(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	t.Run("should store a pending secret and return its provisioning URI", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute), WithTOTPIssuer("Finance"))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+		mockMFARepo.On("SetPendingTOTPSecret", ctx, uint(1), mock.AnythingOfType("string")).Return(nil)
+
+		enrolment, err := service.BeginTOTPEnrolment(ctx, 1, "password123")
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, enrolment.Secret)
+		assert.Contains(t, enrolment.ProvisioningURI, "otpauth://totp/Finance:john@example.com?")
+		assert.Contains(t, enrolment.ProvisioningURI, "secret="+enrolment.Secret)
+		mockMFARepo.AssertCalled(t, "SetPendingTOTPSecret", ctx, uint(1), enrolment.Secret)
+	})
+
+	t.Run("should refuse when TOTP is already enabled", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewMFAService(mockUserRepo, new(MockMFARepository), new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+		enabledAt := time.Now()
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, TOTPEnabledAt: &enabledAt}, nil)
+
+		_, err := service.BeginTOTPEnrolment(ctx, 1, "password123")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+	})
+
+	t.Run("should refuse a wrong password", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+
+		_, err := service.BeginTOTPEnrolment(ctx, 1, "wrong")
+
+		assertAppErrorCode(t, err, "invalid_password")
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockMFARepo.AssertNotCalled(t, "SetPendingTOTPSecret", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfirmTOTPEnrolment(t *testing.T) {
+	ctx := context.Background()
+	secret, err := auth.NewTOTPSecret()
+	assert.NoError(t, err)
+
+	t.Run("should enable TOTP and return hashed recovery codes once", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+		code, err := auth.GenerateTOTPCode(secret, time.Now())
+		assert.NoError(t, err)
+
+		var stored []models.RecoveryCode
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, TOTPSecret: secret}, nil)
+		mockMFARepo.On("EnableTOTP", ctx, uint(1), mock.AnythingOfType("time.Time"), mock.AnythingOfType("int64"), mock.Anything).
+			Run(func(args mock.Arguments) { stored = args.Get(4).([]models.RecoveryCode) }).
+			Return(nil)
+
+		codes, err := service.ConfirmTOTPEnrolment(ctx, 1, code[:3]+" "+code[3:])
+
+		assert.NoError(t, err)
+		assert.Len(t, codes, auth.RecoveryCodeCount)
+		if assert.Len(t, stored, len(codes)) {
+			assert.Equal(t, auth.HashRecoveryCode(codes[0]), stored[0].CodeHash)
+			assert.NotContains(t, stored[0].CodeHash, codes[0])
+		}
+	})
+
+	t.Run("should reject a wrong code", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewMFAService(mockUserRepo, new(MockMFARepository), new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, TOTPSecret: secret}, nil)
+
+		_, err := service.ConfirmTOTPEnrolment(ctx, 1, "000000x")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+	})
+
+	t.Run("should refuse when enrolment was not started", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewMFAService(mockUserRepo, new(MockMFARepository), new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil)
+
+		_, err := service.ConfirmTOTPEnrolment(ctx, 1, "123456")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+	})
+}
+
+func TestDisableTOTP(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	enabledAt := time.Now()
+	user := &models.User{ID: 1, Password: string(hashedPassword), TOTPSecret: "SECRET", TOTPEnabledAt: &enabledAt}
+
+	t.Run("should disable TOTP with the password and a recovery code", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseRecoveryCode", ctx, uint(1), auth.HashRecoveryCode("abcde-fghij"), mock.AnythingOfType("time.Time")).Return(true, nil)
+		mockMFARepo.On("DisableTOTP", ctx, uint(1)).Return(nil)
+
+		err := service.DisableTOTP(ctx, 1, "password123", "abcde-fghij")
+
+		assert.NoError(t, err)
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("should refuse a wrong password", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+
+		err := service.DisableTOTP(ctx, 1, "wrong", "abcde-fghij")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockMFARepo.AssertNotCalled(t, "DisableTOTP", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse a used recovery code", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), auth.NewJWTManager("secret", time.Minute))
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseRecoveryCode", ctx, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+
+		err := service.DisableTOTP(ctx, 1, "password123", "abcde-fghij")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockMFARepo.AssertNotCalled(t, "DisableTOTP", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMFAChallenge(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	secret, err := auth.NewTOTPSecret()
+	assert.NoError(t, err)
+	enabledAt := time.Now()
+	user := &models.User{ID: 1, Email: "john@example.com", TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+
+	t.Run("should exchange a challenge and a code for the user", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, mockRevokedRepo, tokenManager)
+
+		challenge, err := service.StartMFAChallenge(ctx, user)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(auth.MFATokenTTL), challenge.ExpiresAt, 2*time.Second)
+
+		code, err := auth.GenerateTOTPCode(secret, time.Now())
+		assert.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseTOTPStep", ctx, uint(1), mock.AnythingOfType("int64")).Return(true, nil)
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.MatchedBy(func(token *models.RevokedToken) bool {
+			return token.TokenID != "" && token.UserID == 1
+		})).Return(true, nil).Once()
+
+		verified, err := service.VerifyMFAChallenge(ctx, challenge.Token, code)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), verified.ID)
+		mockRevokedRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a challenge that was already used", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, mockRevokedRepo, tokenManager)
+
+		challenge, err := service.StartMFAChallenge(ctx, user)
+		assert.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseRecoveryCode", ctx, uint(1), mock.Anything, mock.Anything).Return(true, nil)
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.Anything).Return(true, nil).Once()
+		mockRevokedRepo.On("ConsumeToken", ctx, mock.Anything).Return(false, nil).Once()
+
+		_, err = service.VerifyMFAChallenge(ctx, challenge.Token, "abcde-fghij")
+		assert.NoError(t, err)
+
+		_, err = service.VerifyMFAChallenge(ctx, challenge.Token, "klmno-pqrst")
+
+		assertAppErrorCode(t, err, "invalid_mfa_token")
+	})
+
+	t.Run("should reject a code that was already used", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), tokenManager)
+
+		challenge, err := service.StartMFAChallenge(ctx, user)
+		assert.NoError(t, err)
+		code, err := auth.GenerateTOTPCode(secret, time.Now())
+		assert.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseTOTPStep", ctx, uint(1), mock.AnythingOfType("int64")).Return(false, nil)
+
+		_, err = service.VerifyMFAChallenge(ctx, challenge.Token, code)
+
+		appErr, ok := apperrors.As(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, "invalid_mfa_code", appErr.Code)
+		}
+	})
+
+	t.Run("should reject an access token in place of the challenge", func(t *testing.T) {
+		service := NewMFAService(new(MockUserRepository), new(MockMFARepository), new(MockRevokedTokenRepository), tokenManager)
+		accessToken, err := tokenManager.GenerateToken(user, auth.Scopes)
+		assert.NoError(t, err)
+
+		_, err = service.VerifyMFAChallenge(ctx, accessToken, "123456")
+
+		appErr, ok := apperrors.As(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, "invalid_mfa_token", appErr.Code)
+		}
+	})
+
+	t.Run("should fail when the code cannot be recorded", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockMFARepo := new(MockMFARepository)
+		service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), tokenManager)
+
+		challenge, err := service.StartMFAChallenge(ctx, user)
+		assert.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockMFARepo.On("UseRecoveryCode", ctx, uint(1), mock.Anything, mock.Anything).Return(false, errors.New("db down"))
+
+		_, err = service.VerifyMFAChallenge(ctx, challenge.Token, "abcde-fghij")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
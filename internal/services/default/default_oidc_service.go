@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/oidc"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// oidcLoginTTL is how long the user has to log in at the provider and
+// come back.
+const oidcLoginTTL = 10 * time.Minute
+
+// maxUserNameLength matches the size of models.User.Name.
+const maxUserNameLength = 100
+
+// DefaultOIDCService logs users in through an OpenID Connect provider.
+// A user is found by the provider's subject once they logged in before,
+// or else by the email address the provider verified: an existing account
+// is linked if its own address was verified too, and otherwise a new one
+// is created.
+type DefaultOIDCService struct {
+	provider *oidc.Provider
+	oidcRepo repositories.OIDCRepository
+	userRepo repositories.UserRepository
+}
+
+func NewOIDCService(provider *oidc.Provider, oidcRepo repositories.OIDCRepository, userRepo repositories.UserRepository) *DefaultOIDCService {
+	return &DefaultOIDCService{
+		provider: provider,
+		oidcRepo: oidcRepo,
+		userRepo: userRepo,
+	}
+}
+
+// StartLogin returns the provider's login page to send the user to and the
+// state it was given, which the caller ties to the user's browser. Only a
+// hash of the state is kept, together with the nonce and PKCE verifier the
+// callback needs.
+func (s *DefaultOIDCService) StartLogin(ctx context.Context) (string, string, error) {
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := auth.NewOpaqueToken()
+		if err != nil {
+			return "", "", apperrors.Internal("oidc_login_failed", "failed to start login", err)
+		}
+		secrets[i] = secret
+	}
+	state, nonce, codeVerifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", providerUnavailable(err)
+	}
+
+	request := &models.OIDCLoginRequest{
+		StateHash:    auth.HashOpaqueToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().UTC().Add(oidcLoginTTL),
+	}
+	if err := s.oidcRepo.CreateLoginRequest(ctx, request); err != nil {
+		return "", "", apperrors.Internal("oidc_login_failed", "failed to start login", err)
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteLogin handles the provider's redirect back: it checks that state
+// belongs to a login started here, trades the code for an ID token and
+// returns the user it names. Two-factor authentication set up here is left
+// to the caller, as after a password login.
+func (s *DefaultOIDCService) CompleteLogin(ctx context.Context, code, state string) (*models.User, error) {
+	if code == "" || state == "" {
+		return nil, apperrors.Validation("invalid_oidc_callback", "code and state are required")
+	}
+
+	request, err := s.oidcRepo.ConsumeLoginRequest(ctx, auth.HashOpaqueToken(state))
+	if err != nil || !time.Now().Before(request.ExpiresAt) {
+		return nil, apperrors.Unauthorized("invalid_oidc_state", "login request is unknown or has expired")
+	}
+
+	idToken, err := s.provider.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	switch {
+	case errors.Is(err, oidc.ErrInvalidIDToken):
+		return nil, apperrors.Wrap(apperrors.KindUnauthorized, "invalid_id_token", "identity provider returned an invalid ID token", err)
+	case errors.Is(err, oidc.ErrCodeRejected):
+		return nil, apperrors.Unauthorized("oidc_code_rejected", "authorization code was rejected")
+	case err != nil:
+		return nil, providerUnavailable(err)
+	}
+
+	return s.userFor(ctx, idToken)
+}
+
+// PurgeExpiredLoginRequests deletes logins that were started but never
+// finished in time.
+func (s *DefaultOIDCService) PurgeExpiredLoginRequests(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := s.oidcRepo.DeleteExpiredLoginRequests(ctx, now)
+	if err != nil {
+		return 0, apperrors.Internal("oidc_login_purge_failed", "failed to purge login requests", err)
+	}
+
+	return deleted, nil
+}
+
+func (s *DefaultOIDCService) userFor(ctx context.Context, idToken *oidc.IDToken) (*models.User, error) {
+	if identity, err := s.oidcRepo.GetIdentity(ctx, idToken.Issuer, idToken.Subject); err == nil {
+		user, err := s.userRepo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, apperrors.Internal("oidc_login_failed", "failed to log in", err)
+		}
+		return user, nil
+	}
+
+	email := strings.TrimSpace(idToken.Email)
+	if email == "" || !idToken.EmailVerified {
+		return nil, apperrors.Forbidden("oidc_email_unverified", "identity provider has not verified the email address")
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err == nil {
+		// Whoever registered an unverified address may not own it, and
+		// linking would let them into the account the provider vouches for.
+		if !user.EmailVerified() {
+			return nil, apperrors.Conflict("oidc_account_unverified", "verify the email address of the existing account before using single sign-on")
+		}
+	} else if user, err = s.provisionUser(ctx, idToken, email); err != nil {
+		return nil, err
+	}
+
+	identity := &models.UserIdentity{UserID: user.ID, Issuer: idToken.Issuer, Subject: idToken.Subject}
+	if err := s.oidcRepo.CreateIdentity(ctx, identity); err != nil {
+		return nil, apperrors.Internal("oidc_login_failed", "failed to log in", err)
+	}
+
+	return user, nil
+}
+
+// provisionUser creates the account of someone who logs in for the first
+// time. It gets a random password nobody knows, which a password reset
+// can replace if the user also wants to log in without the provider.
+func (s *DefaultOIDCService) provisionUser(ctx context.Context, idToken *oidc.IDToken, email string) (*models.User, error) {
+	password, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, apperrors.Internal("oidc_login_failed", "failed to log in", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.Internal("oidc_login_failed", "failed to log in", err)
+	}
+
+	name := strings.TrimSpace(idToken.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	if runes := []rune(name); len(runes) > maxUserNameLength {
+		name = string(runes[:maxUserNameLength])
+	}
+
+	now := time.Now().UTC()
+	user := &models.User{Name: name, Email: email, Password: string(hashedPassword), EmailVerifiedAt: &now}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, apperrors.Conflict("email_already_registered", "email already registered")
+		}
+
+		return nil, apperrors.Internal("oidc_login_failed", "failed to log in", err)
+	}
+
+	return user, nil
+}
+
+// providerUnavailable keeps the cause, which tells a misconfigured
+// provider apart from one that is down.
+func providerUnavailable(err error) error {
+	return apperrors.Wrap(apperrors.KindUnavailable, "oidc_provider_unavailable", "identity provider is unavailable", err)
+}
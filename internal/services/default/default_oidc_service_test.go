@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/oidc"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOIDCRepository implements the OIDCRepository interface
+type MockOIDCRepository struct {
+	mock.Mock
+}
+
+func (m *MockOIDCRepository) CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) ConsumeLoginRequest(ctx context.Context, stateHash string) (*models.OIDCLoginRequest, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.OIDCLoginRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOIDCRepository) DeleteExpiredLoginRequests(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	args := m.Called(ctx, issuer, subject)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.UserIdentity), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOIDCRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+const oidcTestRedirectURL = "https://app.example.com/api/v1/oidc/callback"
+
+func newOIDCTestService(t *testing.T) (*DefaultOIDCService, *oidctest.Server, *MockOIDCRepository, *MockUserRepository) {
+	t.Helper()
+
+	server := oidctest.NewServer(t, "finance-tracker", "secret")
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    server.URL,
+		ClientID:     "finance-tracker",
+		ClientSecret: "secret",
+		RedirectURL:  oidcTestRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+	oidcRepo := new(MockOIDCRepository)
+	userRepo := new(MockUserRepository)
+	return NewOIDCService(provider, oidcRepo, userRepo), server, oidcRepo, userRepo
+}
+
+// startOIDCLogin starts a login and lets the provider approve it. It
+// returns the code and state the callback receives, with the repository
+// set up to hand back the stored login request once.
+func startOIDCLogin(t *testing.T, service *DefaultOIDCService, server *oidctest.Server, oidcRepo *MockOIDCRepository) (string, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	var stored *models.OIDCLoginRequest
+	oidcRepo.On("CreateLoginRequest", ctx, mock.AnythingOfType("*models.OIDCLoginRequest")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.OIDCLoginRequest) }).
+		Return(nil).Once()
+
+	authURL, _, err := service.StartLogin(ctx)
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	code, state, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	oidcRepo.On("ConsumeLoginRequest", ctx, auth.HashOpaqueToken(state)).Return(stored, nil).Once()
+	return code, state
+}
+
+func TestOIDCStartLogin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Store the login request without the state", func(t *testing.T) {
+		service, server, oidcRepo, _ := newOIDCTestService(t)
+		var stored *models.OIDCLoginRequest
+		oidcRepo.On("CreateLoginRequest", ctx, mock.AnythingOfType("*models.OIDCLoginRequest")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*models.OIDCLoginRequest) }).
+			Return(nil)
+
+		authURL, state, err := service.StartLogin(ctx)
+
+		if assert.NoError(t, err) {
+			_, sentState, err := server.Authorize(authURL)
+			assert.NoError(t, err)
+			assert.Equal(t, state, sentState)
+			assert.Equal(t, auth.HashOpaqueToken(state), stored.StateHash)
+			assert.NotEmpty(t, stored.Nonce)
+			assert.GreaterOrEqual(t, len(stored.CodeVerifier), 43, "RFC 7636 asks for at least 43 characters")
+			assert.WithinDuration(t, time.Now().Add(oidcLoginTTL), stored.ExpiresAt, time.Minute)
+		}
+	})
+
+	t.Run("Report an unreachable provider", func(t *testing.T) {
+		service, server, oidcRepo, _ := newOIDCTestService(t)
+		server.Close()
+
+		_, _, err := service.StartLogin(ctx)
+
+		assertAppErrorCode(t, err, "oidc_provider_unavailable")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnavailable))
+		oidcRepo.AssertNotCalled(t, "CreateLoginRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOIDCCompleteLogin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Log in a user who logged in before", func(t *testing.T) {
+		service, server, oidcRepo, userRepo := newOIDCTestService(t)
+		server.SetUser(oidctest.User{Subject: "sub-1", Email: "changed@example.com", EmailVerified: true})
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		oidcRepo.On("GetIdentity", ctx, server.URL, "sub-1").Return(&models.UserIdentity{UserID: 7, Issuer: server.URL, Subject: "sub-1"}, nil)
+		userRepo.On("GetUserByID", ctx, uint(7)).Return(&models.User{ID: 7, Email: "ann@example.com"}, nil)
+
+		user, err := service.CompleteLogin(ctx, code, state)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(7), user.ID)
+		}
+		userRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+		oidcRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Link a verified account with the same email", func(t *testing.T) {
+		service, server, oidcRepo, userRepo := newOIDCTestService(t)
+		server.SetUser(oidctest.User{Subject: "sub-1", Email: "ann@example.com", EmailVerified: true})
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		verifiedAt := time.Now()
+		oidcRepo.On("GetIdentity", ctx, server.URL, "sub-1").Return(nil, errors.New("record not found"))
+		userRepo.On("GetUserByEmail", ctx, "ann@example.com").Return(&models.User{ID: 7, Email: "ann@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		oidcRepo.On("CreateIdentity", ctx, &models.UserIdentity{UserID: 7, Issuer: server.URL, Subject: "sub-1"}).Return(nil)
+
+		user, err := service.CompleteLogin(ctx, code, state)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(7), user.ID)
+		}
+		oidcRepo.AssertExpectations(t)
+		userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Refuse to link an account whose email is not verified", func(t *testing.T) {
+		service, server, oidcRepo, userRepo := newOIDCTestService(t)
+		server.SetUser(oidctest.User{Subject: "sub-1", Email: "ann@example.com", EmailVerified: true})
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		oidcRepo.On("GetIdentity", ctx, server.URL, "sub-1").Return(nil, errors.New("record not found"))
+		userRepo.On("GetUserByEmail", ctx, "ann@example.com").Return(&models.User{ID: 7, Email: "ann@example.com"}, nil)
+
+		_, err := service.CompleteLogin(ctx, code, state)
+
+		assertAppErrorCode(t, err, "oidc_account_unverified")
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+		oidcRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create an account for a new user", func(t *testing.T) {
+		service, server, oidcRepo, userRepo := newOIDCTestService(t)
+		server.SetUser(oidctest.User{Subject: "sub-1", Email: "ann@example.com", EmailVerified: true, Name: "Ann"})
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		oidcRepo.On("GetIdentity", ctx, server.URL, "sub-1").Return(nil, errors.New("record not found"))
+		userRepo.On("GetUserByEmail", ctx, "ann@example.com").Return(nil, errors.New("record not found"))
+		userRepo.On("CreateUser", ctx, mock.AnythingOfType("*models.User")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.User).ID = 9 }).
+			Return(nil)
+		oidcRepo.On("CreateIdentity", ctx, &models.UserIdentity{UserID: 9, Issuer: server.URL, Subject: "sub-1"}).Return(nil)
+
+		user, err := service.CompleteLogin(ctx, code, state)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint(9), user.ID)
+			assert.Equal(t, "Ann", user.Name)
+			assert.Equal(t, "ann@example.com", user.Email)
+			assert.True(t, user.EmailVerified())
+			assert.NotEmpty(t, user.Password)
+		}
+		oidcRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refuse an email the provider did not verify", func(t *testing.T) {
+		service, server, oidcRepo, userRepo := newOIDCTestService(t)
+		server.SetUser(oidctest.User{Subject: "sub-1", Email: "ann@example.com", EmailVerified: false})
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		oidcRepo.On("GetIdentity", ctx, server.URL, "sub-1").Return(nil, errors.New("record not found"))
+
+		_, err := service.CompleteLogin(ctx, code, state)
+
+		assertAppErrorCode(t, err, "oidc_email_unverified")
+		userRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Refuse an unknown state", func(t *testing.T) {
+		service, _, oidcRepo, _ := newOIDCTestService(t)
+		oidcRepo.On("ConsumeLoginRequest", ctx, auth.HashOpaqueToken("forged")).Return(nil, errors.New("record not found"))
+
+		_, err := service.CompleteLogin(ctx, "code", "forged")
+
+		assertAppErrorCode(t, err, "invalid_oidc_state")
+	})
+
+	t.Run("Refuse an expired login request", func(t *testing.T) {
+		service, _, oidcRepo, _ := newOIDCTestService(t)
+		oidcRepo.On("ConsumeLoginRequest", ctx, auth.HashOpaqueToken("old")).
+			Return(&models.OIDCLoginRequest{ExpiresAt: time.Now().Add(-time.Second)}, nil)
+
+		_, err := service.CompleteLogin(ctx, "code", "old")
+
+		assertAppErrorCode(t, err, "invalid_oidc_state")
+	})
+
+	t.Run("Require code and state", func(t *testing.T) {
+		service, _, _, _ := newOIDCTestService(t)
+
+		_, err := service.CompleteLogin(ctx, "", "state")
+
+		assertAppErrorCode(t, err, "invalid_oidc_callback")
+	})
+
+	t.Run("Refuse an invalid ID token", func(t *testing.T) {
+		service, server, oidcRepo, _ := newOIDCTestService(t)
+		server.ModifyClaims(func(claims map[string]any) { claims["aud"] = "someone-else" })
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+
+		_, err := service.CompleteLogin(ctx, code, state)
+
+		assertAppErrorCode(t, err, "invalid_id_token")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+	})
+
+	t.Run("Refuse a code that was already used", func(t *testing.T) {
+		service, server, oidcRepo, _ := newOIDCTestService(t)
+		code, state := startOIDCLogin(t, service, server, oidcRepo)
+		oidcRepo.On("GetIdentity", ctx, server.URL, mock.Anything).Return(&models.UserIdentity{UserID: 7}, nil)
+		service.userRepo.(*MockUserRepository).On("GetUserByID", ctx, uint(7)).Return(&models.User{ID: 7}, nil)
+		_, err := service.CompleteLogin(ctx, code, state)
+		assert.NoError(t, err)
+
+		oidcRepo.On("ConsumeLoginRequest", ctx, auth.HashOpaqueToken(state)).
+			Return(&models.OIDCLoginRequest{Nonce: "n", CodeVerifier: "v", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		_, err = service.CompleteLogin(ctx, code, state)
+
+		assertAppErrorCode(t, err, "oidc_code_rejected")
+	})
+}
+
+func TestOIDCPurgeExpiredLoginRequests(t *testing.T) {
+	ctx := context.Background()
+	service, _, oidcRepo, _ := newOIDCTestService(t)
+	now := time.Now().UTC()
+	oidcRepo.On("DeleteExpiredLoginRequests", ctx, now).Return(int64(3), nil)
+
+	deleted, err := service.PurgeExpiredLoginRequests(ctx, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+type DefaultReportService struct {
+	transactionRepo repositories.TransactionRepository
+}
+
+func NewReportService(transactionRepo repositories.TransactionRepository) *DefaultReportService {
+	return &DefaultReportService{transactionRepo: transactionRepo}
+}
+
+// GetSummaryByUser totals income and expenses per category and per tag for a user.
+func (s *DefaultReportService) GetSummaryByUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) (*models.TransactionSummary, error) {
+	categoryTotals, err := s.transactionRepo.GetCategoryTotalsByUserID(ctx, userID, transactionFilters)
+	if err != nil {
+		return nil, apperrors.Internal("report_generation_failed", "failed to generate report", err)
+	}
+
+	tagTotals, err := s.transactionRepo.GetTagTotalsByUserID(ctx, userID, transactionFilters)
+	if err != nil {
+		return nil, apperrors.Internal("report_generation_failed", "failed to generate report", err)
+	}
+
+	summary := &models.TransactionSummary{Categories: categoryTotals, Tags: tagTotals}
+	for _, categoryTotal := range categoryTotals {
+		switch categoryTotal.Type {
+		case "income":
+			summary.Income += categoryTotal.Total
+		case "expense":
+			summary.Expense += categoryTotal.Total
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSummaryByUser(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Totals income and expenses", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewReportService(mockTransactionRepo)
+
+		categoryTotals := []models.CategoryTotal{
+			{CategoryID: 1, Type: "expense", Total: 40},
+			{CategoryID: 2, Type: "expense", Total: 60},
+			{CategoryID: 3, Type: "income", Total: 500},
+		}
+		tagTotals := []models.TagTotal{
+			{TagID: 1, Name: "vacation-2026", Type: "expense", Total: 60},
+		}
+		mockTransactionRepo.On("GetCategoryTotalsByUserID", ctx, uint(1), filters.TransactionFilters{}).Return(categoryTotals, nil)
+		mockTransactionRepo.On("GetTagTotalsByUserID", ctx, uint(1), filters.TransactionFilters{}).Return(tagTotals, nil)
+
+		summary, err := service.GetSummaryByUser(ctx, 1, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Equal(t, 500.0, summary.Income)
+		assert.Equal(t, 100.0, summary.Expense)
+		assert.Len(t, summary.Categories, 3)
+		assert.Equal(t, tagTotals, summary.Tags)
+	})
+
+	t.Run("Fail when totals cannot be loaded", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewReportService(mockTransactionRepo)
+
+		mockTransactionRepo.On("GetCategoryTotalsByUserID", ctx, uint(1), filters.TransactionFilters{}).Return([]models.CategoryTotal(nil), errors.New("db down"))
+
+		summary, err := service.GetSummaryByUser(ctx, 1, filters.TransactionFilters{})
+		assert.Nil(t, summary)
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/rules"
+)
+
+// maxRuleTagsLength mirrors the size of the rules.tags column.
+const maxRuleTagsLength = 500
+
+type DefaultRuleService struct {
+	ruleRepo repositories.RuleRepository
+}
+
+func NewRuleService(ruleRepo repositories.RuleRepository) *DefaultRuleService {
+	return &DefaultRuleService{ruleRepo: ruleRepo}
+}
+
+// CreateRule validates and adds a rule
+func (s *DefaultRuleService) CreateRule(ctx context.Context, rule *models.Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.CreateRule(ctx, rule); err != nil {
+		return apperrors.Internal("rule_create_failed", "failed to create rule", err)
+	}
+
+	return nil
+}
+
+// GetRulesByUser retrieves a user's rules in evaluation order
+func (s *DefaultRuleService) GetRulesByUser(ctx context.Context, userID uint) ([]models.Rule, error) {
+	rules, err := s.ruleRepo.GetRulesByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("rules_fetch_failed", "failed to retrieve rules", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRuleForUser validates and saves changes to a rule that belongs to the authenticated user.
+func (s *DefaultRuleService) UpdateRuleForUser(ctx context.Context, userID uint, rule *models.Rule) error {
+	existing, err := s.ruleRepo.GetRuleByID(ctx, rule.ID)
+	if err != nil || existing.UserID != userID {
+		return apperrors.NotFound("rule_not_found", "rule not found")
+	}
+
+	rule.UserID = userID
+	rule.CreatedAt = existing.CreatedAt
+
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.UpdateRule(ctx, rule); err != nil {
+		return apperrors.Internal("rule_update_failed", "failed to update rule", err)
+	}
+
+	return nil
+}
+
+// DeleteRuleForUser removes a rule that belongs to the authenticated user.
+func (s *DefaultRuleService) DeleteRuleForUser(ctx context.Context, userID, ruleID uint) error {
+	rule, err := s.ruleRepo.GetRuleByID(ctx, ruleID)
+	if err != nil || rule.UserID != userID {
+		return apperrors.NotFound("rule_not_found", "rule not found")
+	}
+
+	if err := s.ruleRepo.DeleteRule(ctx, ruleID); err != nil {
+		return apperrors.Internal("rule_delete_failed", "failed to delete rule", err)
+	}
+
+	return nil
+}
+
+// validateRule checks that a rule has at least one condition and one action,
+// that its patterns compile and normalises its tag names.
+func validateRule(rule *models.Rule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return apperrors.Validation("invalid_rule_name", "rule name is required")
+	}
+
+	if rule.Type != "" && rule.Type != "income" && rule.Type != "expense" {
+		return apperrors.Validation("invalid_transaction_type", "type must be either income or expense")
+	}
+
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return apperrors.Validation("invalid_amount_range", "min_amount must not be greater than max_amount")
+	}
+
+	hasCondition := rule.NoteContains != "" || rule.NotePattern != "" ||
+		rule.PayeeContains != "" || rule.PayeePattern != "" ||
+		rule.MinAmount != nil || rule.MaxAmount != nil || rule.Type != ""
+	if !hasCondition {
+		return apperrors.Validation("missing_rule_condition", "a rule needs at least one condition")
+	}
+
+	if err := rules.Validate(*rule); err != nil {
+		return apperrors.Validation("invalid_rule_pattern", err.Error())
+	}
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(rule.Tags, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		normalized, err := normalizeTagName(name)
+		if err != nil {
+			return err
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			names = append(names, normalized)
+		}
+	}
+	rule.Tags = strings.Join(names, ",")
+	if len(rule.Tags) > maxRuleTagsLength {
+		return apperrors.Validation("invalid_rule_tags", "rule tags must not exceed 500 characters in total")
+	}
+
+	rule.Payee = strings.TrimSpace(rule.Payee)
+	if rule.CategoryID == nil && rule.Payee == "" && rule.Tags == "" {
+		return apperrors.Validation("missing_rule_action", "a rule needs at least one action")
+	}
+
+	return nil
+}
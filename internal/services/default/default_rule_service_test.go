@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRuleRepository implements the RuleRepository interface
+type MockRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRuleRepository) CreateRule(ctx context.Context, rule *models.Rule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockRuleRepository) GetRuleByID(ctx context.Context, id uint) (*models.Rule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Rule), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRuleRepository) GetRulesByUserID(ctx context.Context, userID uint) ([]models.Rule, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Rule), args.Error(1)
+}
+
+func (m *MockRuleRepository) UpdateRule(ctx context.Context, rule *models.Rule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockRuleRepository) DeleteRule(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateRule(t *testing.T) {
+	ctx := context.Background()
+	categoryID := uint(4)
+
+	t.Run("Create rule with normalised tags", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+		rule := &models.Rule{UserID: 1, Name: " Groceries ", NoteContains: "supermarket", CategoryID: &categoryID, Tags: "Food, food,weekly"}
+
+		mockRepo.On("CreateRule", ctx, rule).Return(nil)
+
+		err := service.CreateRule(ctx, rule)
+		assert.NoError(t, err)
+		assert.Equal(t, "Groceries", rule.Name)
+		assert.Equal(t, "food,weekly", rule.Tags)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail without a condition", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		err := service.CreateRule(ctx, &models.Rule{UserID: 1, Name: "Everything", CategoryID: &categoryID})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "CreateRule")
+	})
+
+	t.Run("Fail without an action", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		err := service.CreateRule(ctx, &models.Rule{UserID: 1, Name: "Nothing", NoteContains: "rent"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "CreateRule")
+	})
+
+	t.Run("Fail with invalid pattern", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		err := service.CreateRule(ctx, &models.Rule{UserID: 1, Name: "Broken", NotePattern: "(", Payee: "Acme"})
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_rule_pattern", appErr.Code)
+		mockRepo.AssertNotCalled(t, "CreateRule")
+	})
+
+	t.Run("Fail with inverted amount range", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+		minAmount, maxAmount := 50.0, 10.0
+
+		err := service.CreateRule(ctx, &models.Rule{UserID: 1, Name: "Range", MinAmount: &minAmount, MaxAmount: &maxAmount, Payee: "Acme"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "CreateRule")
+	})
+}
+
+func TestUpdateRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Update own rule", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+		rule := &models.Rule{ID: 3, Name: "Rent", NoteContains: "rent", Payee: "Landlord"}
+
+		mockRepo.On("GetRuleByID", ctx, uint(3)).Return(&models.Rule{ID: 3, UserID: 1}, nil)
+		mockRepo.On("UpdateRule", ctx, rule).Return(nil)
+
+		assert.NoError(t, service.UpdateRuleForUser(ctx, 1, rule))
+		assert.Equal(t, uint(1), rule.UserID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail to update another user's rule", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		mockRepo.On("GetRuleByID", ctx, uint(3)).Return(&models.Rule{ID: 3, UserID: 99}, nil)
+
+		err := service.UpdateRuleForUser(ctx, 1, &models.Rule{ID: 3, Name: "Rent", NoteContains: "rent", Payee: "Landlord"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockRepo.AssertNotCalled(t, "UpdateRule")
+	})
+}
+
+func TestDeleteRule(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Delete own rule", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		mockRepo.On("GetRuleByID", ctx, uint(4)).Return(&models.Rule{ID: 4, UserID: 1}, nil)
+		mockRepo.On("DeleteRule", ctx, uint(4)).Return(nil)
+
+		assert.NoError(t, service.DeleteRuleForUser(ctx, 1, 4))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when rule is missing", func(t *testing.T) {
+		mockRepo := new(MockRuleRepository)
+		service := NewRuleService(mockRepo)
+
+		mockRepo.On("GetRuleByID", ctx, uint(6)).Return(nil, errors.New("record not found"))
+
+		err := service.DeleteRuleForUser(ctx, 1, 6)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockRepo.AssertNotCalled(t, "DeleteRule")
+	})
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+const maxTagNameLength = 50
+
+type DefaultTagService struct {
+	tagRepo repositories.TagRepository
+}
+
+func NewTagService(tagRepo repositories.TagRepository) *DefaultTagService {
+	return &DefaultTagService{tagRepo: tagRepo}
+}
+
+// CreateTag validates and adds a tag
+func (s *DefaultTagService) CreateTag(ctx context.Context, tag *models.Tag) error {
+	name, err := normalizeTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	tag.Name = name
+
+	existing, err := s.tagRepo.GetTagsByNames(ctx, tag.UserID, []string{name})
+	if err != nil {
+		return apperrors.Internal("tag_create_failed", "failed to create tag", err)
+	}
+
+	if len(existing) > 0 {
+		return apperrors.Conflict("tag_exists", "a tag with this name already exists")
+	}
+
+	if err := s.tagRepo.CreateTag(ctx, tag); err != nil {
+		return apperrors.Internal("tag_create_failed", "failed to create tag", err)
+	}
+
+	return nil
+}
+
+// GetTagsByUser retrieves tags for a user
+func (s *DefaultTagService) GetTagsByUser(ctx context.Context, userID uint) ([]models.Tag, error) {
+	tags, err := s.tagRepo.GetTagsByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("tags_fetch_failed", "failed to retrieve tags", err)
+	}
+
+	return tags, nil
+}
+
+// DeleteTagForUser removes a tag that belongs to the authenticated user and detaches it from transactions.
+func (s *DefaultTagService) DeleteTagForUser(ctx context.Context, userID, tagID uint) error {
+	tag, err := s.tagRepo.GetTagByID(ctx, tagID)
+	if err != nil {
+		return apperrors.NotFound("tag_not_found", "tag not found")
+	}
+
+	if tag.UserID != userID {
+		return apperrors.NotFound("tag_not_found", "tag not found")
+	}
+
+	if err := s.tagRepo.DeleteTag(ctx, tagID); err != nil {
+		return apperrors.Internal("tag_delete_failed", "failed to delete tag", err)
+	}
+
+	return nil
+}
+
+// normalizeTagName trims and lowercases a tag name so "Vacation-2026" and
+// "vacation-2026" refer to the same tag.
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > maxTagNameLength {
+		return "", apperrors.Validation("invalid_tag_name", "tag names must be between 1 and 50 characters")
+	}
+
+	if strings.Contains(name, ",") {
+		return "", apperrors.Validation("invalid_tag_name", "tag names cannot contain commas")
+	}
+
+	return name, nil
+}
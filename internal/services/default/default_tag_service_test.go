@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTagRepository implements the TagRepository interface
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	args := m.Called(ctx, tag)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) GetTagByID(ctx context.Context, id uint) (*models.Tag, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Tag), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTagRepository) GetTagsByUserID(ctx context.Context, userID uint) ([]models.Tag, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) GetTagsByNames(ctx context.Context, userID uint, names []string) ([]models.Tag, error) {
+	args := m.Called(ctx, userID, names)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) DeleteTag(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateTag(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create normalised tag", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+		tag := &models.Tag{UserID: 1, Name: " Vacation-2026 "}
+
+		mockRepo.On("GetTagsByNames", ctx, uint(1), []string{"vacation-2026"}).Return([]models.Tag{}, nil)
+		mockRepo.On("CreateTag", ctx, tag).Return(nil)
+
+		err := service.CreateTag(ctx, tag)
+		assert.NoError(t, err)
+		assert.Equal(t, "vacation-2026", tag.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when tag already exists", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+
+		mockRepo.On("GetTagsByNames", ctx, uint(1), []string{"reimbursable"}).Return([]models.Tag{{ID: 3, UserID: 1, Name: "reimbursable"}}, nil)
+
+		err := service.CreateTag(ctx, &models.Tag{UserID: 1, Name: "Reimbursable"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+		mockRepo.AssertNotCalled(t, "CreateTag")
+	})
+
+	t.Run("Fail with comma in name", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+
+		err := service.CreateTag(ctx, &models.Tag{UserID: 1, Name: "a,b"})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "GetTagsByNames")
+	})
+}
+
+func TestDeleteTag(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Delete own tag", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+
+		mockRepo.On("GetTagByID", ctx, uint(4)).Return(&models.Tag{ID: 4, UserID: 1}, nil)
+		mockRepo.On("DeleteTag", ctx, uint(4)).Return(nil)
+
+		assert.NoError(t, service.DeleteTagForUser(ctx, 1, 4))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail to delete another user's tag", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+
+		mockRepo.On("GetTagByID", ctx, uint(5)).Return(&models.Tag{ID: 5, UserID: 99}, nil)
+
+		err := service.DeleteTagForUser(ctx, 1, 5)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockRepo.AssertNotCalled(t, "DeleteTag")
+	})
+
+	t.Run("Fail when tag is missing", func(t *testing.T) {
+		mockRepo := new(MockTagRepository)
+		service := NewTagService(mockRepo)
+
+		mockRepo.On("GetTagByID", ctx, uint(6)).Return(nil, errors.New("record not found"))
+
+		err := service.DeleteTagForUser(ctx, 1, 6)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+	})
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+// defaultRevocationCacheTTL is how long revocation lookups are cached
+// unless WithRevocationCacheTTL says otherwise.
+const defaultRevocationCacheTTL = 30 * time.Second
+
+type DefaultTokenService struct {
+	tokenManager     auth.TokenManager
+	refreshTokenRepo repositories.RefreshTokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	userRepo         repositories.UserRepository
+	refreshTokenTTL  time.Duration
+	revocations      *revocationCache
+}
+
+// TokenServiceOption configures optional behaviour of the token service.
+type TokenServiceOption func(*DefaultTokenService)
+
+// WithRevocationCacheTTL sets how long revocation lookups are cached in
+// memory, which is how long a logout on another instance can go unnoticed.
+func WithRevocationCacheTTL(ttl time.Duration) TokenServiceOption {
+	return func(s *DefaultTokenService) {
+		if ttl > 0 {
+			s.revocations = newRevocationCache(ttl)
+		}
+	}
+}
+
+func NewTokenService(tokenManager auth.TokenManager, refreshTokenRepo repositories.RefreshTokenRepository, revokedTokenRepo repositories.RevokedTokenRepository, userRepo repositories.UserRepository, refreshTokenTTL time.Duration, options ...TokenServiceOption) *DefaultTokenService {
+	service := &DefaultTokenService{
+		tokenManager:     tokenManager,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		userRepo:         userRepo,
+		refreshTokenTTL:  refreshTokenTTL,
+		revocations:      newRevocationCache(defaultRevocationCacheTTL),
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+// IssueTokens starts a new session for the user with an access token and
+// the first refresh token of a new token family. scope lists the scopes
+// the session asks for, separated by spaces; an empty scope asks for all
+// of them.
+func (s *DefaultTokenService) IssueTokens(ctx context.Context, user *models.User, scope string) (*auth.TokenPair, error) {
+	scopes, err := sessionScopes(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	pair, refreshToken, err := s.newTokenPair(user, familyID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	return pair, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new token pair. Every
+// refresh token works once: presenting one that was already exchanged
+// means it leaked, so the whole family is revoked and the user has to log
+// in again.
+func (s *DefaultTokenService) RefreshTokens(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	stored, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return nil, invalidRefreshToken()
+	}
+
+	if stored.UsedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, stored)
+	}
+	if stored.RevokedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return nil, invalidRefreshToken()
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, invalidRefreshToken()
+	}
+
+	// The new pair keeps the scopes the session was started with.
+	pair, next, err := s.newTokenPair(user, stored.FamilyID, strings.Fields(stored.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	usedAt := time.Now().UTC()
+	stored.UsedAt = &usedAt
+	if err := s.refreshTokenRepo.RotateRefreshToken(ctx, stored, next); err != nil {
+		// Another request may have exchanged the same token first.
+		current, getErr := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, stored.TokenHash)
+		if getErr == nil && current.UsedAt != nil {
+			return nil, s.revokeReusedFamily(ctx, current)
+		}
+		if getErr == nil && current.RevokedAt != nil {
+			return nil, invalidRefreshToken()
+		}
+
+		return nil, apperrors.Internal("token_refresh_failed", "failed to refresh token", err)
+	}
+
+	return pair, nil
+}
+
+// Logout revokes the access token described by claims and, when given,
+// the refresh token family it was issued with. A refresh token that is
+// unknown or belongs to someone else is ignored.
+func (s *DefaultTokenService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	now := time.Now().UTC()
+
+	if claims.ID != "" {
+		expiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+		revoked := &models.RevokedToken{TokenID: claims.ID, UserID: claims.UserID, ExpiresAt: expiresAt}
+		if err := s.revokedTokenRepo.RevokeToken(ctx, revoked); err != nil {
+			return apperrors.Internal("logout_failed", "failed to log out", err)
+		}
+		s.revocations.setTokenRevoked(claims.ID, true, expiresAt, now)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if err != nil || stored.UserID != claims.UserID {
+		return nil
+	}
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID, now); err != nil {
+		return apperrors.Internal("logout_failed", "failed to log out", err)
+	}
+
+	return nil
+}
+
+// LogoutAll invalidates every access token issued to the user so far and
+// revokes all their refresh tokens. Tokens carry their issue time in whole
+// seconds, so the watermark is truncated to the second to keep tokens
+// issued right after it valid.
+func (s *DefaultTokenService) LogoutAll(ctx context.Context, userID uint) error {
+	now := time.Now().UTC()
+	before := now.Truncate(time.Second)
+
+	if err := s.userRepo.InvalidateUserTokens(ctx, userID, before); err != nil {
+		return apperrors.Internal("logout_failed", "failed to log out", err)
+	}
+	s.revocations.setWatermark(userID, &before, now)
+
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID, now); err != nil {
+		return apperrors.Internal("logout_failed", "failed to log out", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether a valid access token was logged out,
+// was issued before the user's watermark or belongs to a user who no
+// longer exists.
+func (s *DefaultTokenService) IsTokenRevoked(ctx context.Context, claims *auth.Claims) (bool, error) {
+	now := time.Now().UTC()
+
+	before, found := s.revocations.watermark(claims.UserID, now)
+	if !found {
+		user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+		if err != nil {
+			return true, nil
+		}
+		before = user.TokensInvalidBefore
+		s.revocations.setWatermark(claims.UserID, before, now)
+	}
+	if before != nil && claims.IssuedAt < before.Unix() {
+		return true, nil
+	}
+
+	if claims.ID == "" {
+		return false, nil
+	}
+
+	revoked, found := s.revocations.tokenRevoked(claims.ID, now)
+	if found {
+		return revoked, nil
+	}
+
+	revoked, err := s.revokedTokenRepo.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return false, apperrors.Internal("token_revocation_check_failed", "failed to check token", err)
+	}
+	s.revocations.setTokenRevoked(claims.ID, revoked, time.Unix(claims.ExpiresAt, 0).UTC(), now)
+
+	return revoked, nil
+}
+
+// PurgeExpiredRevocations deletes revoked access tokens that have expired
+// anyway.
+func (s *DefaultTokenService) PurgeExpiredRevocations(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := s.revokedTokenRepo.DeleteExpiredRevokedTokens(ctx, now)
+	if err != nil {
+		return 0, apperrors.Internal("revocation_purge_failed", "failed to purge revoked tokens", err)
+	}
+
+	return deleted, nil
+}
+
+func (s *DefaultTokenService) newTokenPair(user *models.User, familyID string, scopes []string) (*auth.TokenPair, *models.RefreshToken, error) {
+	accessToken, err := s.tokenManager.GenerateToken(user, scopes)
+	if err != nil {
+		return nil, nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	claims, err := s.tokenManager.ParseToken(accessToken)
+	if err != nil {
+		return nil, nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, nil, apperrors.Internal("token_generation_failed", "failed to generate token", err)
+	}
+
+	pair := &auth.TokenPair{
+		AccessToken:  accessToken,
+		ExpiresAt:    time.Unix(claims.ExpiresAt, 0).UTC(),
+		RefreshToken: refreshToken,
+	}
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashOpaqueToken(refreshToken),
+		Scope:     strings.Join(scopes, " "),
+		ExpiresAt: time.Now().UTC().Add(s.refreshTokenTTL),
+	}
+
+	return pair, stored, nil
+}
+
+// sessionScopes checks the scopes a login asked for and puts them in the
+// order auth.Scopes lists them, without repeats.
+func sessionScopes(scope string) ([]string, error) {
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		return auth.Scopes, nil
+	}
+
+	for _, scope := range requested {
+		if !auth.KnownScope(scope) {
+			return nil, apperrors.Validation("invalid_scope", "unknown scope "+scope)
+		}
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, known := range auth.Scopes {
+		if slices.Contains(requested, known) {
+			scopes = append(scopes, known)
+		}
+	}
+	return scopes, nil
+}
+
+func (s *DefaultTokenService) revokeReusedFamily(ctx context.Context, token *models.RefreshToken) error {
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID, time.Now().UTC()); err != nil {
+		return apperrors.Internal("token_refresh_failed", "failed to refresh token", err)
+	}
+
+	return apperrors.Unauthorized("refresh_token_reused", "refresh token was already used; log in again")
+}
+
+func invalidRefreshToken() error {
+	return apperrors.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+}
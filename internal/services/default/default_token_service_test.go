@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRefreshTokenRepository implements the RefreshTokenRepository interface
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.RefreshToken), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RotateRefreshToken(ctx context.Context, used *models.RefreshToken, next *models.RefreshToken) error {
+	args := m.Called(ctx, used, next)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string, at time.Time) error {
+	args := m.Called(ctx, familyID, at)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uint, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+// MockRevokedTokenRepository implements the RevokedTokenRepository interface
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) RevokeToken(ctx context.Context, token *models.RevokedToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRevokedTokenRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRevokedTokenRepository) DeleteExpiredRevokedTokens(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRevokedTokenRepository) ConsumeToken(ctx context.Context, token *models.RevokedToken) (bool, error) {
+	args := m.Called(ctx, token)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestIssueTokens(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Email: "john@example.com"}
+
+	mockRepo := new(MockRefreshTokenRepository)
+	service := NewTokenService(auth.NewJWTManager("secret", 15*time.Minute), mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+
+	var stored *models.RefreshToken
+	mockRepo.On("CreateRefreshToken", ctx, mock.AnythingOfType("*models.RefreshToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.RefreshToken)
+	}).Return(nil)
+
+	pair, err := service.IssueTokens(ctx, user, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), pair.ExpiresAt, 2*time.Second)
+	assert.Equal(t, uint(1), stored.UserID)
+	assert.Equal(t, strings.Join(auth.Scopes, " "), stored.Scope)
+	assert.NotEmpty(t, stored.FamilyID)
+	assert.Equal(t, auth.HashOpaqueToken(pair.RefreshToken), stored.TokenHash)
+	assert.NotEqual(t, pair.RefreshToken, stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, 2*time.Second)
+}
+
+func TestIssueTokensWithScope(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", 15*time.Minute)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+
+	t.Run("Limit the session to the scopes asked for", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+
+		mockRepo.On("CreateRefreshToken", ctx, mock.MatchedBy(func(token *models.RefreshToken) bool {
+			return token.Scope == "reports:read transactions:read"
+		})).Return(nil)
+
+		pair, err := service.IssueTokens(ctx, user, "transactions:read reports:read transactions:read")
+		if assert.NoError(t, err) {
+			claims, err := tokenManager.ParseToken(pair.AccessToken)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{auth.ScopeReportsRead, auth.ScopeTransactionsRead}, claims.Scopes())
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail with an unknown scope", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+
+		_, err := service.IssueTokens(ctx, user, "reports:read admin")
+		assertAppErrorCode(t, err, "invalid_scope")
+		mockRepo.AssertNotCalled(t, "CreateRefreshToken")
+	})
+}
+
+func TestRefreshTokens(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", 15*time.Minute)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	hash := auth.HashOpaqueToken("refresh-abc")
+
+	t.Run("Rotate an unused token", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, Scope: "reports:read", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockRepo.On("RotateRefreshToken", ctx, mock.MatchedBy(func(used *models.RefreshToken) bool {
+			return used.ID == 3 && used.UsedAt != nil
+		}), mock.MatchedBy(func(next *models.RefreshToken) bool {
+			return next.FamilyID == "family" && next.UserID == 1 && next.TokenHash != hash && next.Scope == "reports:read"
+		})).Return(nil)
+
+		pair, err := service.RefreshTokens(ctx, "refresh-abc")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, pair.AccessToken)
+		assert.NotEqual(t, "refresh-abc", pair.RefreshToken)
+		claims, err := tokenManager.ParseToken(pair.AccessToken)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{auth.ScopeReportsRead}, claims.Scopes())
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Revoke the family when a used token is presented", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+		usedAt := time.Now().Add(-time.Minute)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+		_, err := service.RefreshTokens(ctx, "refresh-abc")
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "refresh_token_reused", appErr.Code)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RotateRefreshToken")
+	})
+
+	t.Run("Revoke the family when a concurrent refresh won", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+		usedAt := time.Now()
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+		mockRepo.On("RotateRefreshToken", ctx, mock.Anything, mock.Anything).Return(errors.New("record not found"))
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil).Once()
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+		_, err := service.RefreshTokens(ctx, "refresh-abc")
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "refresh_token_reused", appErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject an unknown token", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).Return(nil, errors.New("record not found"))
+
+		_, err := service.RefreshTokens(ctx, "refresh-abc")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+	})
+
+	t.Run("Reject an expired token", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), new(MockUserRepository), time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		_, err := service.RefreshTokens(ctx, "refresh-abc")
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "invalid_refresh_token", appErr.Code)
+		mockRepo.AssertNotCalled(t, "RotateRefreshToken")
+	})
+
+	t.Run("Reject a token of a deleted user", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(tokenManager, mockRepo, new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).
+			Return(&models.RefreshToken{ID: 3, UserID: 1, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(nil, errors.New("record not found"))
+
+		_, err := service.RefreshTokens(ctx, "refresh-abc")
+		assert.True(t, isAppErrorKind(err, apperrors.KindUnauthorized))
+		mockRepo.AssertNotCalled(t, "RotateRefreshToken")
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(15 * time.Minute).Unix()
+	claims := &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: time.Now().Unix(), ExpiresAt: expiresAt}
+	hash := auth.HashOpaqueToken("refresh-abc")
+
+	t.Run("Revoke the access token and its refresh token family", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), mockRepo, mockRevokedRepo, mockUserRepo, time.Hour)
+
+		mockRevokedRepo.On("RevokeToken", ctx, mock.MatchedBy(func(token *models.RevokedToken) bool {
+			return token.TokenID == "jti-1" && token.UserID == 1 && token.ExpiresAt.Unix() == expiresAt
+		})).Return(nil)
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).Return(&models.RefreshToken{UserID: 1, FamilyID: "family"}, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil)
+
+		assert.NoError(t, service.Logout(ctx, claims, "refresh-abc"))
+		mockRepo.AssertExpectations(t)
+
+		// The revocation is cached, so the database is not asked again.
+		revoked, err := service.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		mockRevokedRepo.AssertNotCalled(t, "IsTokenRevoked", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Ignore a refresh token of another user", func(t *testing.T) {
+		mockRepo := new(MockRefreshTokenRepository)
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), mockRepo, mockRevokedRepo, new(MockUserRepository), time.Hour)
+
+		mockRevokedRepo.On("RevokeToken", ctx, mock.Anything).Return(nil)
+		mockRepo.On("GetRefreshTokenByHash", ctx, hash).Return(&models.RefreshToken{UserID: 2, FamilyID: "family"}, nil)
+
+		assert.NoError(t, service.Logout(ctx, claims, "refresh-abc"))
+		mockRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily")
+	})
+
+	t.Run("Fail when the revocation cannot be stored", func(t *testing.T) {
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), new(MockRefreshTokenRepository), mockRevokedRepo, new(MockUserRepository), time.Hour)
+
+		mockRevokedRepo.On("RevokeToken", ctx, mock.Anything).Return(errors.New("db down"))
+
+		err := service.Logout(ctx, claims, "")
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestLogoutAll(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockRefreshTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := NewTokenService(auth.NewJWTManager("secret", time.Minute), mockRepo, new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+
+	mockUserRepo.On("InvalidateUserTokens", ctx, uint(1), mock.MatchedBy(func(before time.Time) bool {
+		return before.Equal(before.Truncate(time.Second))
+	})).Return(nil)
+	mockRepo.On("RevokeUserRefreshTokens", ctx, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+	assert.NoError(t, service.LogoutAll(ctx, 1))
+	mockUserRepo.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+
+	// Tokens issued earlier are rejected straight from the cache.
+	revoked, err := service.IsTokenRevoked(ctx, &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: time.Now().Add(-time.Minute).Unix()})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockUserRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Accept a token that was not revoked and cache the answer", func(t *testing.T) {
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), new(MockRefreshTokenRepository), mockRevokedRepo, mockUserRepo, time.Hour)
+		claims := &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil).Once()
+		mockRevokedRepo.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil).Once()
+
+		for i := 0; i < 2; i++ {
+			revoked, err := service.IsTokenRevoked(ctx, claims)
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+		mockUserRepo.AssertExpectations(t)
+		mockRevokedRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject a token issued before the user's watermark", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+		before := now.Truncate(time.Second)
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, TokensInvalidBefore: &before}, nil)
+
+		revoked, err := service.IsTokenRevoked(ctx, &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: before.Add(-time.Second).Unix()})
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Reject a token of a deleted user", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), new(MockRefreshTokenRepository), new(MockRevokedTokenRepository), mockUserRepo, time.Hour)
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(nil, errors.New("record not found"))
+
+		revoked, err := service.IsTokenRevoked(ctx, &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: now.Unix()})
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Fail when the revocation store is unavailable", func(t *testing.T) {
+		mockRevokedRepo := new(MockRevokedTokenRepository)
+		mockUserRepo := new(MockUserRepository)
+		service := NewTokenService(auth.NewJWTManager("secret", time.Minute), new(MockRefreshTokenRepository), mockRevokedRepo, mockUserRepo, time.Hour)
+
+		mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil)
+		mockRevokedRepo.On("IsTokenRevoked", ctx, "jti-1").Return(false, errors.New("db down"))
+
+		_, err := service.IsTokenRevoked(ctx, &auth.Claims{UserID: 1, ID: "jti-1", IssuedAt: now.Unix()})
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
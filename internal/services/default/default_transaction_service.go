@@ -2,46 +2,389 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/classifier"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/pagination"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/rules"
 )
 
+// splitTolerance absorbs floating point rounding when comparing split totals.
+const splitTolerance = 0.005
+
+// defaultDuplicateWindow is how far apart two transactions may be dated and
+// still be flagged as duplicates when no window is configured.
+const defaultDuplicateWindow = 72 * time.Hour
+
+// maxCategorySuggestions caps how many categories SuggestCategories returns.
+const maxCategorySuggestions = 5
+
 type DefaultTransactionService struct {
 	transactionRepo repositories.TransactionRepository
 	budgetRepo      repositories.BudgetRepository
+	accountRepo     repositories.AccountRepository
+	tagRepo         repositories.TagRepository
+	ruleRepo        repositories.RuleRepository
+	classifierRepo  repositories.ClassifierRepository
+	duplicateWindow time.Duration
+}
+
+// TransactionServiceOption configures optional behaviour of the transaction service.
+type TransactionServiceOption func(*DefaultTransactionService)
+
+// WithDuplicateWindow sets how far apart two transactions may be dated and
+// still be flagged as duplicates.
+func WithDuplicateWindow(window time.Duration) TransactionServiceOption {
+	return func(s *DefaultTransactionService) {
+		if window > 0 {
+			s.duplicateWindow = window
+		}
+	}
 }
 
-func NewTransactionService(transactionRepo repositories.TransactionRepository, budgetRepo repositories.BudgetRepository) *DefaultTransactionService {
-	return &DefaultTransactionService{transactionRepo: transactionRepo, budgetRepo: budgetRepo}
+// WithRuleRepository enables the user's categorisation rules on new
+// transactions and on demand.
+func WithRuleRepository(ruleRepo repositories.RuleRepository) TransactionServiceOption {
+	return func(s *DefaultTransactionService) {
+		s.ruleRepo = ruleRepo
+	}
 }
 
-// AddTransaction validates and saves a transaction
+// WithClassifierRepository enables category suggestions, training the
+// user's classifier as their transactions change.
+func WithClassifierRepository(classifierRepo repositories.ClassifierRepository) TransactionServiceOption {
+	return func(s *DefaultTransactionService) {
+		s.classifierRepo = classifierRepo
+	}
+}
+
+func NewTransactionService(transactionRepo repositories.TransactionRepository, budgetRepo repositories.BudgetRepository, accountRepo repositories.AccountRepository, tagRepo repositories.TagRepository, options ...TransactionServiceOption) *DefaultTransactionService {
+	service := &DefaultTransactionService{
+		transactionRepo: transactionRepo,
+		budgetRepo:      budgetRepo,
+		accountRepo:     accountRepo,
+		tagRepo:         tagRepo,
+		duplicateWindow: defaultDuplicateWindow,
+	}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
+}
+
+// AddTransaction applies the user's rules, then validates and saves a transaction
 func (s *DefaultTransactionService) AddTransaction(ctx context.Context, transaction *models.Transaction) error {
+	transactions := []models.Transaction{*transaction}
+	if err := s.ApplyRules(ctx, transaction.UserID, transactions); err != nil {
+		return err
+	}
+	*transaction = transactions[0]
+
+	if err := s.prepareTransaction(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := s.transactionRepo.CreateTransaction(ctx, transaction); err != nil {
+		return apperrors.Internal("transaction_create_failed", "failed to create transaction", err)
+	}
+
+	s.train(ctx, nil, []models.Transaction{*transaction})
+	return nil
+}
+
+// AddTransactions validates every transaction and saves them all at once, or
+// none of them if any is invalid. Rules are not applied; callers that want
+// them run ApplyRules first.
+func (s *DefaultTransactionService) AddTransactions(ctx context.Context, transactions []models.Transaction) error {
+	for i := range transactions {
+		if err := s.prepareTransaction(ctx, &transactions[i]); err != nil {
+			if appErr, ok := apperrors.As(err); ok && appErr.Kind == apperrors.KindValidation {
+				return apperrors.Validation(appErr.Code, fmt.Sprintf("transaction %d: %s", i+1, appErr.Message))
+			}
+			return err
+		}
+	}
+
+	if err := s.transactionRepo.CreateTransactions(ctx, transactions); err != nil {
+		return apperrors.Internal("transaction_create_failed", "failed to create transactions", err)
+	}
+
+	s.train(ctx, nil, transactions)
+	return nil
+}
+
+// UpdateTransactionForUser validates and saves changes to a transaction that
+// belongs to the authenticated user, replacing its split lines and tags.
+func (s *DefaultTransactionService) UpdateTransactionForUser(ctx context.Context, userID uint, transaction *models.Transaction) error {
+	existing, err := s.transactionRepo.GetTransactionByID(ctx, transaction.ID)
+	if err != nil {
+		return apperrors.NotFound("transaction_not_found", "transaction not found")
+	}
+
+	if existing.UserID != userID {
+		return apperrors.NotFound("transaction_not_found", "transaction not found")
+	}
+
+	if existing.TransferID != nil {
+		return apperrors.Validation("transfer_not_editable", "transfer legs cannot be edited; delete and recreate the transfer")
+	}
+
+	transaction.UserID = userID
+	transaction.CreatedAt = existing.CreatedAt
+	// Keep the bank identifier so a re-import still recognises the entry.
+	transaction.ExternalID = existing.ExternalID
+
+	if err := s.prepareTransaction(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := s.transactionRepo.UpdateTransaction(ctx, transaction); err != nil {
+		return apperrors.Internal("transaction_update_failed", "failed to update transaction", err)
+	}
+
+	s.train(ctx, []models.Transaction{*existing}, []models.Transaction{*transaction})
+	return nil
+}
+
+// prepareTransaction runs the checks shared by creating and updating a
+// transaction and resolves its tag names to the user's tags.
+func (s *DefaultTransactionService) prepareTransaction(ctx context.Context, transaction *models.Transaction) error {
+	if transaction.Type == "transfer" {
+		return apperrors.Validation("invalid_transaction_type", "transfers must be created through the transfers endpoint")
+	}
+
+	if transaction.Type != "income" && transaction.Type != "expense" {
+		return apperrors.Validation("invalid_transaction_type", "type must be either income or expense")
+	}
+
+	if err := validateSplits(transaction); err != nil {
+		return err
+	}
+
+	if transaction.CategoryID == 0 {
+		return apperrors.Validation("missing_category", "a category is required when no split lines are given and no rule sets one")
+	}
+
+	if transaction.AccountID != nil {
+		if _, err := s.accountForUser(ctx, transaction.UserID, *transaction.AccountID); err != nil {
+			return err
+		}
+	}
+
 	// Check if transaction exceeds budget
 	budgets, err := s.budgetRepo.GetBudgetsByUserID(ctx, transaction.UserID)
 	if err != nil {
 		return apperrors.Internal("budget_lookup_failed", "failed to validate transaction budget", err)
 	}
 
-	for _, budget := range budgets {
-		if budget.CategoryID == transaction.CategoryID && transaction.Type == "expense" {
-			if transaction.Amount > budget.Limit {
+	if transaction.Type == "expense" {
+		categoryAmounts := amountsByCategory(transaction)
+		for _, budget := range budgets {
+			if amount, ok := categoryAmounts[budget.CategoryID]; ok && amount > budget.Limit {
 				return apperrors.Validation("budget_limit_exceeded", "transaction exceeds budget limit")
 			}
 		}
 	}
 
-	if err := s.transactionRepo.CreateTransaction(ctx, transaction); err != nil {
-		return apperrors.Internal("transaction_create_failed", "failed to create transaction", err)
+	tags, err := s.resolveTags(ctx, transaction.UserID, transaction.Tags)
+	if err != nil {
+		return err
+	}
+	transaction.Tags = tags
+
+	return nil
+}
+
+// ApplyRules runs the user's rules over transactions that are about to be
+// created. Rules only fill in a category or payee the transaction does not
+// have yet, and add their tags.
+func (s *DefaultTransactionService) ApplyRules(ctx context.Context, userID uint, transactions []models.Transaction) error {
+	engine, err := s.rulesEngine(ctx, userID)
+	if err != nil || engine == nil {
+		return err
+	}
+
+	for i := range transactions {
+		engine.Apply(&transactions[i], false)
 	}
 
 	return nil
 }
 
+// ReapplyRulesForUser runs the user's rules over their stored transactions
+// matching the filters. Matching rules replace the category and payee and
+// add their tags; transfers and split categories are left alone. Changed
+// transactions are saved together and their number is returned.
+func (s *DefaultTransactionService) ReapplyRulesForUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) (int, error) {
+	engine, err := s.rulesEngine(ctx, userID)
+	if err != nil || engine == nil {
+		return 0, err
+	}
+
+	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID, transactionFilters)
+	if err != nil {
+		return 0, apperrors.Internal("transactions_fetch_failed", "failed to retrieve transactions", err)
+	}
+
+	changed := make([]models.Transaction, 0, len(transactions))
+	previous := make([]models.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		before := transaction
+		if !engine.Apply(&transaction, true) {
+			continue
+		}
+
+		tags, err := s.resolveTags(ctx, userID, transaction.Tags)
+		if err != nil {
+			return 0, err
+		}
+		transaction.Tags = tags
+		changed = append(changed, transaction)
+		previous = append(previous, before)
+	}
+
+	if err := s.transactionRepo.UpdateTransactions(ctx, changed); err != nil {
+		return 0, apperrors.Internal("rules_apply_failed", "failed to apply rules", err)
+	}
+
+	s.train(ctx, previous, changed)
+
+	return len(changed), nil
+}
+
+// rulesEngine compiles the user's rules, returning nil when there are none
+// or no rule repository is configured.
+func (s *DefaultTransactionService) rulesEngine(ctx context.Context, userID uint) (*rules.Engine, error) {
+	if s.ruleRepo == nil {
+		return nil, nil
+	}
+
+	userRules, err := s.ruleRepo.GetRulesByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("rules_fetch_failed", "failed to retrieve rules", err)
+	}
+	if len(userRules) == 0 {
+		return nil, nil
+	}
+
+	engine, err := rules.New(userRules)
+	if err != nil {
+		return nil, apperrors.Internal("rules_fetch_failed", "failed to compile rules", err)
+	}
+
+	return engine, nil
+}
+
+// SuggestCategories ranks the user's categories by how likely a transaction
+// with the given note and payee belongs to each, using a naive Bayes
+// classifier trained on their own categorised transactions. A user's first
+// suggestion trains the classifier from their whole history.
+func (s *DefaultTransactionService) SuggestCategories(ctx context.Context, userID uint, note, payee string) ([]models.CategorySuggestion, error) {
+	tokens := classifier.Tokenize(note, payee)
+	if s.classifierRepo == nil || len(tokens) == 0 {
+		return nil, nil
+	}
+
+	categories, err := s.classifierRepo.GetClassifierCategories(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("classifier_fetch_failed", "failed to retrieve classifier", err)
+	}
+
+	if len(categories) == 0 {
+		if categories, err = s.bootstrapClassifier(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	tokenCounts, err := s.classifierRepo.GetClassifierTokens(ctx, userID, tokens)
+	if err != nil {
+		return nil, apperrors.Internal("classifier_fetch_failed", "failed to retrieve classifier", err)
+	}
+
+	vocabulary, err := s.classifierRepo.CountClassifierVocabulary(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("classifier_fetch_failed", "failed to retrieve classifier", err)
+	}
+
+	return classifier.Rank(categories, tokenCounts, vocabulary, tokens, maxCategorySuggestions), nil
+}
+
+// bootstrapClassifier trains an empty classifier from all of the user's
+// stored transactions and returns the resulting category totals.
+func (s *DefaultTransactionService) bootstrapClassifier(ctx context.Context, userID uint) ([]models.ClassifierCategory, error) {
+	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID, filters.TransactionFilters{})
+	if err != nil {
+		return nil, apperrors.Internal("transactions_fetch_failed", "failed to retrieve transactions", err)
+	}
+
+	examples := make([]models.ClassifierExample, 0, len(transactions))
+	for _, transaction := range transactions {
+		if example, ok := classifier.Example(transaction, 1); ok {
+			examples = append(examples, example)
+		}
+	}
+	if len(examples) == 0 {
+		return nil, nil
+	}
+
+	if err := s.classifierRepo.UpdateClassifier(ctx, userID, examples); err != nil {
+		return nil, apperrors.Internal("classifier_train_failed", "failed to train classifier", err)
+	}
+
+	categories, err := s.classifierRepo.GetClassifierCategories(ctx, userID)
+	if err != nil {
+		return nil, apperrors.Internal("classifier_fetch_failed", "failed to retrieve classifier", err)
+	}
+
+	return categories, nil
+}
+
+// train forgets and learns the given transactions in their owners'
+// classifiers. A classifier that has not been trained yet is left empty,
+// since its first suggestion learns the whole history anyway. Suggestions
+// are advisory, so a failure here never fails the change that triggered it.
+func (s *DefaultTransactionService) train(ctx context.Context, forget, learn []models.Transaction) {
+	if s.classifierRepo == nil {
+		return
+	}
+
+	examples := make(map[uint][]models.ClassifierExample)
+	userIDs := make([]uint, 0, 1)
+	add := func(transactions []models.Transaction, weight int) {
+		for _, transaction := range transactions {
+			example, ok := classifier.Example(transaction, weight)
+			if !ok {
+				continue
+			}
+			if _, seen := examples[transaction.UserID]; !seen {
+				userIDs = append(userIDs, transaction.UserID)
+			}
+			examples[transaction.UserID] = append(examples[transaction.UserID], example)
+		}
+	}
+	add(forget, -1)
+	add(learn, 1)
+
+	for _, userID := range userIDs {
+		categories, err := s.classifierRepo.GetClassifierCategories(ctx, userID)
+		if err != nil || len(categories) == 0 {
+			continue
+		}
+		_ = s.classifierRepo.UpdateClassifier(ctx, userID, examples[userID])
+	}
+}
+
 // GetTransactionsByUser retrieves all transactions for a user
 func (s *DefaultTransactionService) GetTransactionsByUser(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.Transaction, error) {
 	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID, transactionFilters)
@@ -63,6 +406,7 @@ func (s *DefaultTransactionService) GetTransactionsPageByUser(ctx context.Contex
 }
 
 // DeleteTransactionForUser removes a transaction that belongs to the authenticated user.
+// Deleting either leg of a transfer removes the whole transfer.
 func (s *DefaultTransactionService) DeleteTransactionForUser(ctx context.Context, userID, transactionID uint) error {
 	transaction, err := s.transactionRepo.GetTransactionByID(ctx, transactionID)
 	if err != nil {
@@ -73,9 +417,406 @@ func (s *DefaultTransactionService) DeleteTransactionForUser(ctx context.Context
 		return apperrors.NotFound("transaction_not_found", "transaction not found")
 	}
 
+	if transaction.TransferID != nil {
+		return s.DeleteTransferForUser(ctx, userID, *transaction.TransferID)
+	}
+
 	if err := s.transactionRepo.DeleteTransaction(ctx, transactionID); err != nil {
 		return apperrors.Internal("transaction_delete_failed", "failed to delete transaction", err)
 	}
 
+	s.train(ctx, []models.Transaction{*transaction}, nil)
 	return nil
 }
+
+// FindDuplicates returns, for each of the given transactions, the user's
+// stored transactions that look like duplicates of it. The given
+// transactions may already be stored as one batch; they are never
+// candidates for each other, so only rows stored before the batch match.
+// Transactions without candidates are left out of the result.
+func (s *DefaultTransactionService) FindDuplicates(ctx context.Context, userID uint, transactions []models.Transaction) ([]models.DuplicateMatch, error) {
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	from, to := transactions[0].Date, transactions[0].Date
+	for _, transaction := range transactions[1:] {
+		if transaction.Date.Before(from) {
+			from = transaction.Date
+		}
+		if transaction.Date.After(to) {
+			to = transaction.Date
+		}
+	}
+	from, to = from.Add(-s.duplicateWindow), to.Add(s.duplicateWindow)
+
+	stored, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID, filters.TransactionFilters{From: &from, To: &to})
+	if err != nil {
+		return nil, apperrors.Internal("duplicates_fetch_failed", "failed to look up duplicate transactions", err)
+	}
+
+	batch := make(map[uint]bool, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.ID != 0 {
+			batch[transaction.ID] = true
+		}
+	}
+
+	byKey := make(map[string][]models.Transaction)
+	for _, transaction := range stored {
+		if batch[transaction.ID] {
+			continue
+		}
+		if key, ok := duplicateKey(transaction); ok {
+			byKey[key] = append(byKey[key], transaction)
+		}
+	}
+
+	var matches []models.DuplicateMatch
+	for _, transaction := range transactions {
+		key, ok := duplicateKey(transaction)
+		if !ok {
+			continue
+		}
+
+		var candidates []models.Transaction
+		for _, candidate := range byKey[key] {
+			if s.withinDuplicateWindow(candidate.Date, transaction.Date) {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) > 0 {
+			matches = append(matches, models.DuplicateMatch{Transaction: transaction, Candidates: candidates})
+		}
+	}
+
+	return matches, nil
+}
+
+// GetDuplicateGroupsByUser groups the user's transactions that look like
+// copies of each other, newest group first. Transactions join a group when
+// they are dated within the duplicate window of its previous member.
+func (s *DefaultTransactionService) GetDuplicateGroupsByUser(ctx context.Context, userID uint) ([]models.DuplicateGroup, error) {
+	transactions, err := s.transactionRepo.GetTransactionsByUserID(ctx, userID, filters.TransactionFilters{})
+	if err != nil {
+		return nil, apperrors.Internal("duplicates_fetch_failed", "failed to look up duplicate transactions", err)
+	}
+
+	byKey := make(map[string][]models.Transaction)
+	for _, transaction := range transactions {
+		if key, ok := duplicateKey(transaction); ok {
+			byKey[key] = append(byKey[key], transaction)
+		}
+	}
+
+	groups := []models.DuplicateGroup{}
+	for _, candidates := range byKey {
+		if len(candidates) < 2 {
+			continue
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].Date.Equal(candidates[j].Date) {
+				return candidates[i].ID < candidates[j].ID
+			}
+			return candidates[i].Date.Before(candidates[j].Date)
+		})
+
+		group := []models.Transaction{candidates[0]}
+		for _, transaction := range candidates[1:] {
+			if s.withinDuplicateWindow(group[len(group)-1].Date, transaction.Date) {
+				group = append(group, transaction)
+				continue
+			}
+			if len(group) > 1 {
+				groups = append(groups, models.DuplicateGroup{Transactions: group})
+			}
+			group = []models.Transaction{transaction}
+		}
+		if len(group) > 1 {
+			groups = append(groups, models.DuplicateGroup{Transactions: group})
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		first, second := groups[i].Transactions[0], groups[j].Transactions[0]
+		if first.Date.Equal(second.Date) {
+			return first.ID > second.ID
+		}
+		return first.Date.After(second.Date)
+	})
+
+	return groups, nil
+}
+
+// MergeDuplicatesForUser keeps one transaction and deletes its duplicates.
+// The kept transaction gains the duplicates' tags, and their bank reference,
+// payee and account when it has none, so a re-import still recognises it.
+func (s *DefaultTransactionService) MergeDuplicatesForUser(ctx context.Context, userID, keepID uint, duplicateIDs []uint) (*models.Transaction, error) {
+	if len(duplicateIDs) == 0 {
+		return nil, apperrors.Validation("invalid_merge", "at least one duplicate is required")
+	}
+
+	kept, err := s.transactionForMerge(ctx, userID, keepID)
+	if err != nil {
+		return nil, err
+	}
+	removed := []models.Transaction{*kept}
+
+	seen := map[uint]bool{keepID: true}
+	tagIDs := make(map[uint]bool, len(kept.Tags))
+	for _, tag := range kept.Tags {
+		tagIDs[tag.ID] = true
+	}
+
+	ids := make([]uint, 0, len(duplicateIDs))
+	for _, duplicateID := range duplicateIDs {
+		if duplicateID == keepID {
+			return nil, apperrors.Validation("invalid_merge", "the kept transaction cannot also be a duplicate")
+		}
+		if seen[duplicateID] {
+			continue
+		}
+		seen[duplicateID] = true
+
+		duplicate, err := s.transactionForMerge(ctx, userID, duplicateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if duplicate.Type != kept.Type || amountInCents(duplicate.Amount) != amountInCents(kept.Amount) {
+			return nil, apperrors.Validation("merge_mismatch", "only transactions with the same type and amount can be merged")
+		}
+
+		for _, tag := range duplicate.Tags {
+			if !tagIDs[tag.ID] {
+				tagIDs[tag.ID] = true
+				kept.Tags = append(kept.Tags, tag)
+			}
+		}
+		if kept.ExternalID == "" {
+			kept.ExternalID = duplicate.ExternalID
+		}
+		if kept.Payee == "" {
+			kept.Payee = duplicate.Payee
+		}
+		if kept.AccountID == nil {
+			kept.AccountID = duplicate.AccountID
+		}
+
+		ids = append(ids, duplicateID)
+		removed = append(removed, *duplicate)
+	}
+
+	if err := s.transactionRepo.MergeTransactions(ctx, kept, ids); err != nil {
+		return nil, apperrors.Internal("transaction_merge_failed", "failed to merge transactions", err)
+	}
+
+	s.train(ctx, removed, []models.Transaction{*kept})
+
+	return kept, nil
+}
+
+func (s *DefaultTransactionService) transactionForMerge(ctx context.Context, userID, transactionID uint) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, transactionID)
+	if err != nil || transaction.UserID != userID {
+		return nil, apperrors.NotFound("transaction_not_found", "transaction not found")
+	}
+
+	if transaction.TransferID != nil {
+		return nil, apperrors.Validation("transfer_not_mergeable", "transfer legs cannot be merged")
+	}
+
+	return transaction, nil
+}
+
+func (s *DefaultTransactionService) withinDuplicateWindow(a, b time.Time) bool {
+	difference := a.Sub(b)
+	if difference < 0 {
+		difference = -difference
+	}
+	return difference <= s.duplicateWindow
+}
+
+// duplicateKey identifies transactions that may be copies of each other.
+// Transfers are never reported as duplicates.
+func duplicateKey(transaction models.Transaction) (string, bool) {
+	if transaction.Type == "transfer" {
+		return "", false
+	}
+	return fmt.Sprintf("%s|%d|%s", transaction.Type, amountInCents(transaction.Amount), normalizeDescription(transaction.Note)), true
+}
+
+// normalizeDescription lowercases a note and reduces it to its words, so
+// "CARD PAYMENT - Corner Shop" and "card payment corner shop" compare equal.
+func normalizeDescription(note string) string {
+	words := strings.FieldsFunc(strings.ToLower(note), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+func amountInCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// CreateTransfer validates a transfer between two of the user's accounts and
+// books it as a linked debit and credit leg.
+func (s *DefaultTransactionService) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	if transfer.Amount <= 0 {
+		return apperrors.Validation("invalid_transfer_amount", "transfer amount must be greater than zero")
+	}
+
+	if transfer.FromAccountID == transfer.ToAccountID {
+		return apperrors.Validation("invalid_transfer_accounts", "transfer source and destination accounts must differ")
+	}
+
+	fromAccount, err := s.accountForUser(ctx, transfer.UserID, transfer.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	toAccount, err := s.accountForUser(ctx, transfer.UserID, transfer.ToAccountID)
+	if err != nil {
+		return err
+	}
+
+	if fromAccount.Currency != toAccount.Currency {
+		return apperrors.Validation("transfer_currency_mismatch", "transfer accounts must use the same currency")
+	}
+
+	transfer.Legs = []models.Transaction{
+		{
+			UserID:    transfer.UserID,
+			Type:      "transfer",
+			Amount:    -transfer.Amount,
+			AccountID: &fromAccount.ID,
+			Date:      transfer.Date,
+			Note:      transfer.Note,
+		},
+		{
+			UserID:    transfer.UserID,
+			Type:      "transfer",
+			Amount:    transfer.Amount,
+			AccountID: &toAccount.ID,
+			Date:      transfer.Date,
+			Note:      transfer.Note,
+		},
+	}
+
+	if err := s.transactionRepo.CreateTransfer(ctx, transfer); err != nil {
+		return apperrors.Internal("transfer_create_failed", "failed to create transfer", err)
+	}
+
+	return nil
+}
+
+// DeleteTransferForUser removes a transfer and both of its legs.
+func (s *DefaultTransactionService) DeleteTransferForUser(ctx context.Context, userID, transferID uint) error {
+	transfer, err := s.transactionRepo.GetTransferByID(ctx, transferID)
+	if err != nil {
+		return apperrors.NotFound("transfer_not_found", "transfer not found")
+	}
+
+	if transfer.UserID != userID {
+		return apperrors.NotFound("transfer_not_found", "transfer not found")
+	}
+
+	if err := s.transactionRepo.DeleteTransfer(ctx, transferID); err != nil {
+		return apperrors.Internal("transfer_delete_failed", "failed to delete transfer", err)
+	}
+
+	return nil
+}
+
+func (s *DefaultTransactionService) accountForUser(ctx context.Context, userID, accountID uint) (*models.Account, error) {
+	account, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil || account.UserID != userID {
+		return nil, apperrors.Validation("invalid_account_id", "account does not exist")
+	}
+
+	return account, nil
+}
+
+// resolveTags maps requested tag names onto the user's tags. Names without
+// a tag are returned as new, unsaved tags, which the repository creates in
+// the same database transaction as the transactions naming them, so a
+// failed save leaves no tags behind.
+func (s *DefaultTransactionService) resolveTags(ctx context.Context, userID uint, requested []models.Tag) ([]models.Tag, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, tag := range requested {
+		name, err := normalizeTagName(tag.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	tags, err := s.tagRepo.GetTagsByNames(ctx, userID, names)
+	if err != nil {
+		return nil, apperrors.Internal("tags_fetch_failed", "failed to retrieve tags", err)
+	}
+
+	existing := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		existing[tag.Name] = true
+	}
+
+	for _, name := range names {
+		if existing[name] {
+			continue
+		}
+
+		tags = append(tags, models.Tag{UserID: userID, Name: name})
+	}
+
+	return tags, nil
+}
+
+// validateSplits checks that split lines are positive and add up to the
+// transaction amount, and points the transaction at its first split's category.
+func validateSplits(transaction *models.Transaction) error {
+	if len(transaction.Splits) == 0 {
+		return nil
+	}
+
+	var total float64
+	for _, split := range transaction.Splits {
+		if split.CategoryID == 0 {
+			return apperrors.Validation("invalid_split_category", "each split line needs a category")
+		}
+		if split.Amount <= 0 {
+			return apperrors.Validation("invalid_split_amount", "split amounts must be greater than zero")
+		}
+		total += split.Amount
+	}
+
+	if math.Abs(total-transaction.Amount) > splitTolerance {
+		return apperrors.Validation("split_total_mismatch", "split amounts must add up to the transaction amount")
+	}
+
+	transaction.CategoryID = transaction.Splits[0].CategoryID
+	return nil
+}
+
+// amountsByCategory returns how much of a transaction falls into each category.
+func amountsByCategory(transaction *models.Transaction) map[uint]float64 {
+	if len(transaction.Splits) == 0 {
+		return map[uint]float64{transaction.CategoryID: transaction.Amount}
+	}
+
+	amounts := make(map[uint]float64, len(transaction.Splits))
+	for _, split := range transaction.Splits {
+		amounts[split.CategoryID] += split.Amount
+	}
+	return amounts
+}
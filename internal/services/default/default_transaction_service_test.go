@@ -24,6 +24,11 @@ func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, trans
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) CreateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepository) GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) != nil {
@@ -32,6 +37,16 @@ func (m *MockTransactionRepository) GetTransactionByID(ctx context.Context, id u
 	return nil, args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetExistingExternalIDs(ctx context.Context, userID uint, externalIDs []string) ([]string, error) {
+	args := m.Called(ctx, userID, externalIDs)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTransactionRepository) MergeTransactions(ctx context.Context, kept *models.Transaction, duplicateIDs []uint) error {
+	args := m.Called(ctx, kept, duplicateIDs)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepository) GetTransactionsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.Transaction, error) {
 	args := m.Called(ctx, userID, transactionFilters)
 	return args.Get(0).([]models.Transaction), args.Error(1)
@@ -47,15 +62,60 @@ func (m *MockTransactionRepository) UpdateTransaction(ctx context.Context, trans
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) StreamTransactionsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters, fn func(models.Transaction) error) error {
+	args := m.Called(ctx, userID, transactionFilters, fn)
+	if transactions, ok := args.Get(0).([]models.Transaction); ok {
+		for _, transaction := range transactions {
+			if err := fn(transaction); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTransactionRepository) UpdateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepository) DeleteTransaction(ctx context.Context, id uint) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) CreateTransfer(ctx context.Context, transfer *models.Transfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetTransferByID(ctx context.Context, id uint) (*models.Transfer, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.Transfer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionRepository) DeleteTransfer(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetTagTotalsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.TagTotal, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	return args.Get(0).([]models.TagTotal), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetCategoryTotalsByUserID(ctx context.Context, userID uint, transactionFilters filters.TransactionFilters) ([]models.CategoryTotal, error) {
+	args := m.Called(ctx, userID, transactionFilters)
+	return args.Get(0).([]models.CategoryTotal), args.Error(1)
+}
+
 func TestAddTransaction(t *testing.T) {
 	mockTransactionRepo := new(MockTransactionRepository)
 	mockBudgetRepo := new(MockBudgetRepository)
-	service := NewTransactionService(mockTransactionRepo, mockBudgetRepo)
+	service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil)
 	ctx := context.Background()
 
 	t.Run("Create valid transaction", func(t *testing.T) {
@@ -128,11 +188,140 @@ func TestAddTransaction(t *testing.T) {
 		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 	})
 
+	t.Run("Create split transaction", func(t *testing.T) {
+		mockTransactionRepo.ExpectedCalls = nil // Reset expectations
+		mockBudgetRepo.ExpectedCalls = nil      // Reset expectations
+
+		transaction := &models.Transaction{
+			UserID: 1,
+			Type:   "expense",
+			Amount: 80.00,
+			Date:   time.Now(),
+			Splits: []models.TransactionSplit{
+				{CategoryID: 3, Amount: 50.00},
+				{CategoryID: 4, Amount: 30.00},
+			},
+		}
+
+		// The household budget is only checked against its own split line.
+		budgets := []models.Budget{
+			{UserID: 1, CategoryID: 4, Limit: 40.00},
+		}
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return(budgets, nil)
+		mockTransactionRepo.On("CreateTransaction", ctx, transaction).Return(nil)
+
+		err := service.AddTransaction(ctx, transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), transaction.CategoryID)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when split line exceeds budget", func(t *testing.T) {
+		mockTransactionRepo.ExpectedCalls = nil // Reset expectations
+		mockBudgetRepo.ExpectedCalls = nil      // Reset expectations
+
+		transaction := &models.Transaction{
+			UserID: 1,
+			Type:   "expense",
+			Amount: 80.00,
+			Date:   time.Now(),
+			Splits: []models.TransactionSplit{
+				{CategoryID: 3, Amount: 20.00},
+				{CategoryID: 4, Amount: 60.00},
+			},
+		}
+
+		budgets := []models.Budget{
+			{UserID: 1, CategoryID: 4, Limit: 40.00},
+		}
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return(budgets, nil)
+
+		err := service.AddTransaction(ctx, transaction)
+		assert.Error(t, err)
+		assert.Equal(t, "transaction exceeds budget limit", err.Error())
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+	})
+
+	t.Run("Fail when splits do not add up", func(t *testing.T) {
+		mockTransactionRepo.ExpectedCalls = nil // Reset expectations
+		mockBudgetRepo.ExpectedCalls = nil      // Reset expectations
+
+		transaction := &models.Transaction{
+			UserID: 1,
+			Type:   "expense",
+			Amount: 80.00,
+			Date:   time.Now(),
+			Splits: []models.TransactionSplit{
+				{CategoryID: 3, Amount: 50.00},
+				{CategoryID: 4, Amount: 20.00},
+			},
+		}
+
+		err := service.AddTransaction(ctx, transaction)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockBudgetRepo.AssertNotCalled(t, "GetBudgetsByUserID")
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+	})
+
+	t.Run("Create transaction with new and existing tags", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTagRepo := new(MockTagRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, mockTagRepo)
+		mockBudgetRepo.ExpectedCalls = nil // Reset expectations
+
+		transaction := &models.Transaction{
+			UserID:     1,
+			Type:       "expense",
+			Amount:     120.00,
+			CategoryID: 2,
+			Date:       time.Now(),
+			Tags:       []models.Tag{{Name: "Vacation-2026"}, {Name: "reimbursable"}, {Name: "vacation-2026"}},
+		}
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTagRepo.On("GetTagsByNames", ctx, uint(1), []string{"vacation-2026", "reimbursable"}).
+			Return([]models.Tag{{ID: 7, UserID: 1, Name: "reimbursable"}}, nil)
+		mockTransactionRepo.On("CreateTransaction", ctx, transaction).Return(nil)
+
+		err := service.AddTransaction(ctx, transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Tag{{ID: 7, UserID: 1, Name: "reimbursable"}, {UserID: 1, Name: "vacation-2026"}}, transaction.Tags,
+			"new tags are left for the repository to create with the transaction")
+		mockTagRepo.AssertExpectations(t)
+		mockTagRepo.AssertNotCalled(t, "CreateTag", mock.Anything, mock.Anything)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when account belongs to another user", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockAccountRepo := new(MockAccountRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, mockAccountRepo, nil)
+
+		accountID := uint(7)
+		transaction := &models.Transaction{
+			UserID:     1,
+			Type:       "expense",
+			Amount:     20.00,
+			CategoryID: 2,
+			AccountID:  &accountID,
+			Date:       time.Now(),
+		}
+
+		mockAccountRepo.On("GetAccountByID", ctx, accountID).Return(&models.Account{ID: accountID, UserID: 99}, nil)
+
+		err := service.AddTransaction(ctx, transaction)
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+	})
 }
 
 func TestGetTransactionsByUser(t *testing.T) {
 	mockTransactionRepo := new(MockTransactionRepository)
-	service := NewTransactionService(mockTransactionRepo, nil)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
 	ctx := context.Background()
 
 	t.Run("Retrieve transactions for user", func(t *testing.T) {
@@ -153,7 +342,7 @@ func TestGetTransactionsByUser(t *testing.T) {
 
 func TestGetTransactionsPageByUser(t *testing.T) {
 	mockTransactionRepo := new(MockTransactionRepository)
-	service := NewTransactionService(mockTransactionRepo, nil)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
 	ctx := context.Background()
 	params := pagination.New(2, 1)
 	transactionFilters := filters.TransactionFilters{Type: "expense"}
@@ -175,7 +364,7 @@ func TestGetTransactionsPageByUser(t *testing.T) {
 
 func TestDeleteTransaction(t *testing.T) {
 	mockTransactionRepo := new(MockTransactionRepository)
-	service := NewTransactionService(mockTransactionRepo, nil)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
 	ctx := context.Background()
 
 	t.Run("Delete existing transaction", func(t *testing.T) {
@@ -207,3 +396,573 @@ func TestDeleteTransaction(t *testing.T) {
 		mockTransactionRepo.AssertNotCalled(t, "DeleteTransaction", uint(2))
 	})
 }
+
+func TestCreateTransfer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create transfer with balanced legs", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockAccountRepo := new(MockAccountRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, mockAccountRepo, nil)
+
+		transfer := &models.Transfer{UserID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 150, Date: time.Now()}
+
+		mockAccountRepo.On("GetAccountByID", ctx, uint(1)).Return(&models.Account{ID: 1, UserID: 1, Currency: "EUR"}, nil)
+		mockAccountRepo.On("GetAccountByID", ctx, uint(2)).Return(&models.Account{ID: 2, UserID: 1, Currency: "EUR"}, nil)
+		mockTransactionRepo.On("CreateTransfer", ctx, transfer).Return(nil)
+
+		err := service.CreateTransfer(ctx, transfer)
+		assert.NoError(t, err)
+		assert.Len(t, transfer.Legs, 2)
+		assert.Equal(t, -150.0, transfer.Legs[0].Amount)
+		assert.Equal(t, 150.0, transfer.Legs[1].Amount)
+		assert.Equal(t, "transfer", transfer.Legs[0].Type)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when accounts are the same", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, new(MockAccountRepository), nil)
+
+		err := service.CreateTransfer(ctx, &models.Transfer{UserID: 1, FromAccountID: 1, ToAccountID: 1, Amount: 10})
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransfer")
+	})
+
+	t.Run("Fail when currencies differ", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockAccountRepo := new(MockAccountRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, mockAccountRepo, nil)
+
+		mockAccountRepo.On("GetAccountByID", ctx, uint(1)).Return(&models.Account{ID: 1, UserID: 1, Currency: "EUR"}, nil)
+		mockAccountRepo.On("GetAccountByID", ctx, uint(2)).Return(&models.Account{ID: 2, UserID: 1, Currency: "USD"}, nil)
+
+		err := service.CreateTransfer(ctx, &models.Transfer{UserID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 10})
+		assert.Error(t, err)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransfer")
+	})
+}
+
+func TestDeleteTransfer(t *testing.T) {
+	mockTransactionRepo := new(MockTransactionRepository)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+	ctx := context.Background()
+
+	t.Run("Delete a transfer through one of its legs", func(t *testing.T) {
+		transferID := uint(5)
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(10)).Return(&models.Transaction{ID: 10, UserID: 1, TransferID: &transferID}, nil)
+		mockTransactionRepo.On("GetTransferByID", ctx, transferID).Return(&models.Transfer{ID: transferID, UserID: 1}, nil)
+		mockTransactionRepo.On("DeleteTransfer", ctx, transferID).Return(nil)
+
+		err := service.DeleteTransactionForUser(ctx, 1, 10)
+		assert.NoError(t, err)
+		mockTransactionRepo.AssertNotCalled(t, "DeleteTransaction", ctx, uint(10))
+	})
+
+	t.Run("Fail to delete another user's transfer", func(t *testing.T) {
+		mockTransactionRepo.On("GetTransferByID", ctx, uint(6)).Return(&models.Transfer{ID: 6, UserID: 99}, nil)
+
+		err := service.DeleteTransferForUser(ctx, 1, 6)
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockTransactionRepo.AssertNotCalled(t, "DeleteTransfer", ctx, uint(6))
+	})
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Update own transaction", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil)
+
+		createdAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		transaction := &models.Transaction{ID: 3, Type: "expense", Amount: 30, CategoryID: 2, Date: time.Now()}
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(3)).Return(&models.Transaction{ID: 3, UserID: 1, ExternalID: "FIT-3", CreatedAt: createdAt}, nil)
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTransactionRepo.On("UpdateTransaction", ctx, transaction).Return(nil)
+
+		err := service.UpdateTransactionForUser(ctx, 1, transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), transaction.UserID)
+		assert.Equal(t, createdAt, transaction.CreatedAt)
+		assert.Equal(t, "FIT-3", transaction.ExternalID)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail to update a transfer leg", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		transferID := uint(8)
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(4)).Return(&models.Transaction{ID: 4, UserID: 1, TransferID: &transferID}, nil)
+
+		err := service.UpdateTransactionForUser(ctx, 1, &models.Transaction{ID: 4, Type: "expense", Amount: 10})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "UpdateTransaction")
+	})
+
+	t.Run("Fail to update another user's transaction", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(5)).Return(&models.Transaction{ID: 5, UserID: 99}, nil)
+
+		err := service.UpdateTransactionForUser(ctx, 1, &models.Transaction{ID: 5, Type: "expense", Amount: 10})
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockTransactionRepo.AssertNotCalled(t, "UpdateTransaction")
+	})
+}
+
+func TestAddTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create all transactions together", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil)
+
+		transactions := []models.Transaction{
+			{UserID: 1, Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now()},
+			{UserID: 1, Type: "income", Amount: 20, CategoryID: 2, Date: time.Now()},
+		}
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTransactionRepo.On("CreateTransactions", ctx, transactions).Return(nil).Once()
+
+		err := service.AddTransactions(ctx, transactions)
+		assert.NoError(t, err)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject the batch when one transaction is invalid", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil)
+
+		transactions := []models.Transaction{
+			{UserID: 1, Type: "expense", Amount: 10, CategoryID: 1, Date: time.Now()},
+			{UserID: 1, Type: "refund", Amount: 20, CategoryID: 2, Date: time.Now()},
+		}
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+
+		err := service.AddTransactions(ctx, transactions)
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		assert.Contains(t, err.Error(), "transaction 2")
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransactions")
+	})
+}
+
+func TestFindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mockTransactionRepo := new(MockTransactionRepository)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithDuplicateWindow(48*time.Hour))
+
+	created := models.Transaction{ID: 9, UserID: 1, Type: "expense", Amount: 12.5, Date: day, Note: "Corner Shop"}
+	stored := []models.Transaction{
+		created,
+		{ID: 3, UserID: 1, Type: "expense", Amount: 12.50, Date: day.Add(-24 * time.Hour), Note: "CORNER-SHOP"},
+		{ID: 4, UserID: 1, Type: "expense", Amount: 12.50, Date: day.Add(-24 * time.Hour), Note: "Bakery"},
+		{ID: 5, UserID: 1, Type: "income", Amount: 12.50, Date: day, Note: "Corner shop"},
+		{ID: 6, UserID: 1, Type: "expense", Amount: 12.51, Date: day, Note: "Corner shop"},
+	}
+
+	from, to := day.Add(-48*time.Hour), day.Add(48*time.Hour)
+	mockTransactionRepo.On("GetTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{From: &from, To: &to}).Return(stored, nil).Once()
+
+	matches, err := service.FindDuplicates(ctx, 1, []models.Transaction{created})
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, uint(9), matches[0].Transaction.ID)
+	assert.Len(t, matches[0].Candidates, 1)
+	assert.Equal(t, uint(3), matches[0].Candidates[0].ID)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestFindDuplicatesIgnoresOwnBatch(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mockTransactionRepo := new(MockTransactionRepository)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithDuplicateWindow(48*time.Hour))
+
+	batch := []models.Transaction{
+		{ID: 9, UserID: 1, Type: "expense", Amount: 4, Date: day, Note: "Coffee"},
+		{ID: 10, UserID: 1, Type: "expense", Amount: 4, Date: day.Add(time.Hour), Note: "coffee"},
+		{ID: 11, UserID: 1, Type: "expense", Amount: 30, Date: day, Note: "Groceries"},
+	}
+	stored := append([]models.Transaction{
+		{ID: 3, UserID: 1, Type: "expense", Amount: 30, Date: day.Add(-24 * time.Hour), Note: "GROCERIES"},
+	}, batch...)
+
+	from, to := day.Add(-48*time.Hour), day.Add(49*time.Hour)
+	mockTransactionRepo.On("GetTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{From: &from, To: &to}).Return(stored, nil).Once()
+
+	matches, err := service.FindDuplicates(ctx, 1, batch)
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, uint(11), matches[0].Transaction.ID)
+		assert.Len(t, matches[0].Candidates, 1)
+		assert.Equal(t, uint(3), matches[0].Candidates[0].ID)
+	}
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestGetDuplicateGroupsByUser(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	mockTransactionRepo := new(MockTransactionRepository)
+	service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithDuplicateWindow(24*time.Hour))
+
+	transferID := uint(1)
+	mockTransactionRepo.On("GetTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{}).Return([]models.Transaction{
+		{ID: 1, Type: "expense", Amount: 4, Date: day, Note: "Coffee"},
+		{ID: 2, Type: "expense", Amount: 4, Date: day.Add(12 * time.Hour), Note: "coffee"},
+		{ID: 3, Type: "expense", Amount: 4, Date: day.Add(30 * time.Hour), Note: "Coffee!"},
+		{ID: 4, Type: "expense", Amount: 4, Date: day.Add(30 * 24 * time.Hour), Note: "Coffee"},
+		{ID: 5, Type: "income", Amount: 900, Date: day.Add(40 * 24 * time.Hour), Note: "Salary"},
+		{ID: 6, Type: "income", Amount: 900, Date: day.Add(40 * 24 * time.Hour), Note: "Salary"},
+		{ID: 7, Type: "transfer", Amount: 50, Date: day, TransferID: &transferID},
+		{ID: 8, Type: "transfer", Amount: 50, Date: day, TransferID: &transferID},
+	}, nil).Once()
+
+	groups, err := service.GetDuplicateGroupsByUser(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, uint(5), groups[0].Transactions[0].ID)
+	assert.Len(t, groups[0].Transactions, 2)
+	assert.Len(t, groups[1].Transactions, 3)
+	assert.Equal(t, uint(3), groups[1].Transactions[2].ID)
+}
+
+func TestMergeDuplicatesForUser(t *testing.T) {
+	ctx := context.Background()
+	accountID := uint(2)
+
+	t.Run("Keep one transaction and adopt the duplicate's details", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(3)).Return(&models.Transaction{
+			ID: 3, UserID: 1, Type: "expense", Amount: 12.5, Note: "Lunch", Tags: []models.Tag{{ID: 1, Name: "food"}},
+		}, nil).Once()
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(4)).Return(&models.Transaction{
+			ID: 4, UserID: 1, Type: "expense", Amount: 12.5, ExternalID: "FIT-4", Payee: "Corner Shop", AccountID: &accountID,
+			Tags: []models.Tag{{ID: 1, Name: "food"}, {ID: 2, Name: "imported"}},
+		}, nil).Once()
+		mockTransactionRepo.On("MergeTransactions", ctx, mock.MatchedBy(func(kept *models.Transaction) bool {
+			return kept.ID == 3 &&
+				kept.Note == "Lunch" &&
+				kept.ExternalID == "FIT-4" &&
+				kept.Payee == "Corner Shop" &&
+				kept.AccountID != nil &&
+				len(kept.Tags) == 2
+		}), []uint{4}).Return(nil).Once()
+
+		kept, err := service.MergeDuplicatesForUser(ctx, 1, 3, []uint{4, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), kept.ID)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail to merge different amounts", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(3)).Return(&models.Transaction{ID: 3, UserID: 1, Type: "expense", Amount: 12.5}, nil).Once()
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(4)).Return(&models.Transaction{ID: 4, UserID: 1, Type: "expense", Amount: 13}, nil).Once()
+
+		_, err := service.MergeDuplicatesForUser(ctx, 1, 3, []uint{4})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockTransactionRepo.AssertNotCalled(t, "MergeTransactions")
+	})
+
+	t.Run("Fail to merge another user's transaction", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(3)).Return(&models.Transaction{ID: 3, UserID: 1, Type: "expense", Amount: 12.5}, nil).Once()
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(4)).Return(&models.Transaction{ID: 4, UserID: 99, Type: "expense", Amount: 12.5}, nil).Once()
+
+		_, err := service.MergeDuplicatesForUser(ctx, 1, 3, []uint{4})
+		assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+		mockTransactionRepo.AssertNotCalled(t, "MergeTransactions")
+	})
+
+	t.Run("Fail to keep a transaction that is also a duplicate", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil)
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(3)).Return(&models.Transaction{ID: 3, UserID: 1, Type: "expense", Amount: 12.5}, nil).Once()
+
+		_, err := service.MergeDuplicatesForUser(ctx, 1, 3, []uint{3})
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+	})
+}
+
+func TestAddTransactionWithRules(t *testing.T) {
+	ctx := context.Background()
+	groceries := uint(7)
+
+	t.Run("Categorise with the first matching rule", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockTagRepo := new(MockTagRepository)
+		mockRuleRepo := new(MockRuleRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, mockTagRepo, WithRuleRepository(mockRuleRepo))
+
+		mockRuleRepo.On("GetRulesByUserID", ctx, uint(1)).Return([]models.Rule{
+			{ID: 1, UserID: 1, NoteContains: "supermarket", CategoryID: &groceries, Tags: "food"},
+		}, nil)
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTagRepo.On("GetTagsByNames", ctx, uint(1), []string{"food"}).Return([]models.Tag{{ID: 2, UserID: 1, Name: "food"}}, nil)
+		mockTransactionRepo.On("CreateTransaction", ctx, mock.Anything).Return(nil)
+
+		transaction := &models.Transaction{UserID: 1, Type: "expense", Amount: 42, Date: time.Now(), Note: "SUPERMARKET 123"}
+		err := service.AddTransaction(ctx, transaction)
+		assert.NoError(t, err)
+		assert.Equal(t, groceries, transaction.CategoryID)
+		assert.Equal(t, []models.Tag{{ID: 2, UserID: 1, Name: "food"}}, transaction.Tags)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when no rule sets a missing category", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockRuleRepo := new(MockRuleRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithRuleRepository(mockRuleRepo))
+
+		mockRuleRepo.On("GetRulesByUserID", ctx, uint(1)).Return([]models.Rule{}, nil)
+
+		err := service.AddTransaction(ctx, &models.Transaction{UserID: 1, Type: "expense", Amount: 42, Date: time.Now(), Note: "Unknown"})
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "missing_category", appErr.Code)
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+	})
+}
+
+func TestReapplyRulesForUser(t *testing.T) {
+	ctx := context.Background()
+	utilities := uint(8)
+
+	t.Run("Update matching transactions", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockRuleRepo := new(MockRuleRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithRuleRepository(mockRuleRepo))
+
+		transactionFilters := filters.TransactionFilters{Type: "expense"}
+		mockRuleRepo.On("GetRulesByUserID", ctx, uint(1)).Return([]models.Rule{
+			{ID: 1, UserID: 1, PayeeContains: "stadtwerke", CategoryID: &utilities, Payee: "Stadtwerke"},
+		}, nil)
+		mockTransactionRepo.On("GetTransactionsByUserID", ctx, uint(1), transactionFilters).Return([]models.Transaction{
+			{ID: 1, UserID: 1, Type: "expense", Amount: 80, CategoryID: 1, Payee: "STADTWERKE MUENCHEN"},
+			{ID: 2, UserID: 1, Type: "expense", Amount: 12, CategoryID: 1, Payee: "Bakery"},
+			{ID: 3, UserID: 1, Type: "expense", Amount: 60, CategoryID: utilities, Payee: "Stadtwerke"},
+		}, nil)
+		mockTransactionRepo.On("UpdateTransactions", ctx, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 1 &&
+				transactions[0].ID == 1 &&
+				transactions[0].CategoryID == utilities &&
+				transactions[0].Payee == "Stadtwerke"
+		})).Return(nil).Once()
+
+		updated, err := service.ReapplyRulesForUser(ctx, 1, transactionFilters)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, updated)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Do nothing without rules", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockRuleRepo := new(MockRuleRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithRuleRepository(mockRuleRepo))
+
+		mockRuleRepo.On("GetRulesByUserID", ctx, uint(1)).Return([]models.Rule{}, nil)
+
+		updated, err := service.ReapplyRulesForUser(ctx, 1, filters.TransactionFilters{})
+		assert.NoError(t, err)
+		assert.Zero(t, updated)
+		mockTransactionRepo.AssertNotCalled(t, "GetTransactionsByUserID")
+	})
+
+	t.Run("Fail when rules cannot be loaded", func(t *testing.T) {
+		mockRuleRepo := new(MockRuleRepository)
+		service := NewTransactionService(new(MockTransactionRepository), nil, nil, nil, WithRuleRepository(mockRuleRepo))
+
+		mockRuleRepo.On("GetRulesByUserID", ctx, uint(1)).Return([]models.Rule{}, errors.New("db down"))
+
+		_, err := service.ReapplyRulesForUser(ctx, 1, filters.TransactionFilters{})
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+// MockClassifierRepository implements the ClassifierRepository interface
+type MockClassifierRepository struct {
+	mock.Mock
+}
+
+func (m *MockClassifierRepository) UpdateClassifier(ctx context.Context, userID uint, examples []models.ClassifierExample) error {
+	args := m.Called(ctx, userID, examples)
+	return args.Error(0)
+}
+
+func (m *MockClassifierRepository) GetClassifierCategories(ctx context.Context, userID uint) ([]models.ClassifierCategory, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.ClassifierCategory), args.Error(1)
+}
+
+func (m *MockClassifierRepository) GetClassifierTokens(ctx context.Context, userID uint, tokens []string) ([]models.ClassifierToken, error) {
+	args := m.Called(ctx, userID, tokens)
+	return args.Get(0).([]models.ClassifierToken), args.Error(1)
+}
+
+func (m *MockClassifierRepository) CountClassifierVocabulary(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestTrainClassifier(t *testing.T) {
+	ctx := context.Background()
+	trained := []models.ClassifierCategory{{UserID: 1, CategoryID: 2, Documents: 1, Tokens: 1}}
+
+	t.Run("Learn a new transaction", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTransactionRepo.On("CreateTransaction", ctx, mock.Anything).Return(nil)
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return(trained, nil)
+		mockClassifierRepo.On("UpdateClassifier", ctx, uint(1), []models.ClassifierExample{
+			{CategoryID: 3, Tokens: []string{"coffee", "bean", "bar"}, Weight: 1},
+		}).Return(nil).Once()
+
+		err := service.AddTransaction(ctx, &models.Transaction{UserID: 1, Type: "expense", Amount: 4, CategoryID: 3, Date: time.Now(), Note: "Coffee", Payee: "Bean Bar"})
+		assert.NoError(t, err)
+		mockClassifierRepo.AssertExpectations(t)
+	})
+
+	t.Run("Move an updated transaction to its new category", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(mockTransactionRepo, mockBudgetRepo, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(5)).Return(&models.Transaction{ID: 5, UserID: 1, Type: "expense", CategoryID: 2, Note: "Coffee"}, nil)
+		mockBudgetRepo.On("GetBudgetsByUserID", ctx, uint(1)).Return([]models.Budget{}, nil)
+		mockTransactionRepo.On("UpdateTransaction", ctx, mock.Anything).Return(nil)
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return(trained, nil)
+		mockClassifierRepo.On("UpdateClassifier", ctx, uint(1), []models.ClassifierExample{
+			{CategoryID: 2, Tokens: []string{"coffee"}, Weight: -1},
+			{CategoryID: 3, Tokens: []string{"coffee"}, Weight: 1},
+		}).Return(nil).Once()
+
+		err := service.UpdateTransactionForUser(ctx, 1, &models.Transaction{ID: 5, Type: "expense", Amount: 4, CategoryID: 3, Date: time.Now(), Note: "Coffee"})
+		assert.NoError(t, err)
+		mockClassifierRepo.AssertExpectations(t)
+	})
+
+	t.Run("Leave an untrained classifier alone", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(5)).Return(&models.Transaction{ID: 5, UserID: 1, Type: "expense", CategoryID: 2, Note: "Coffee"}, nil)
+		mockTransactionRepo.On("DeleteTransaction", ctx, uint(5)).Return(nil)
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return([]models.ClassifierCategory{}, nil)
+
+		assert.NoError(t, service.DeleteTransactionForUser(ctx, 1, 5))
+		mockClassifierRepo.AssertNotCalled(t, "UpdateClassifier")
+	})
+
+	t.Run("Ignore training failures", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockTransactionRepo.On("GetTransactionByID", ctx, uint(5)).Return(&models.Transaction{ID: 5, UserID: 1, Type: "expense", CategoryID: 2, Note: "Coffee"}, nil)
+		mockTransactionRepo.On("DeleteTransaction", ctx, uint(5)).Return(nil)
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return(trained, nil)
+		mockClassifierRepo.On("UpdateClassifier", ctx, uint(1), mock.Anything).Return(errors.New("db down"))
+
+		assert.NoError(t, service.DeleteTransactionForUser(ctx, 1, 5))
+	})
+}
+
+func TestSuggestCategories(t *testing.T) {
+	ctx := context.Background()
+	categories := []models.ClassifierCategory{
+		{UserID: 1, CategoryID: 2, Documents: 3, Tokens: 6},
+		{UserID: 1, CategoryID: 5, Documents: 1, Tokens: 2},
+	}
+
+	t.Run("Rank learned categories", func(t *testing.T) {
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(new(MockTransactionRepository), nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return(categories, nil)
+		mockClassifierRepo.On("GetClassifierTokens", ctx, uint(1), []string{"rewe", "markt"}).Return([]models.ClassifierToken{
+			{UserID: 1, CategoryID: 2, Token: "rewe", Occurrences: 3},
+		}, nil)
+		mockClassifierRepo.On("CountClassifierVocabulary", ctx, uint(1)).Return(int64(5), nil)
+
+		suggestions, err := service.SuggestCategories(ctx, 1, "REWE Markt", "")
+		assert.NoError(t, err)
+		assert.Len(t, suggestions, 2)
+		assert.Equal(t, uint(2), suggestions[0].CategoryID)
+		assert.Greater(t, suggestions[0].Confidence, suggestions[1].Confidence)
+	})
+
+	t.Run("Train from history on first use", func(t *testing.T) {
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(mockTransactionRepo, nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return([]models.ClassifierCategory{}, nil).Once()
+		mockTransactionRepo.On("GetTransactionsByUserID", ctx, uint(1), filters.TransactionFilters{}).Return([]models.Transaction{
+			{ID: 1, UserID: 1, Type: "expense", CategoryID: 2, Note: "Rewe"},
+			{ID: 2, UserID: 1, Type: "transfer", CategoryID: 9, Note: "Savings"},
+		}, nil)
+		mockClassifierRepo.On("UpdateClassifier", ctx, uint(1), []models.ClassifierExample{
+			{CategoryID: 2, Tokens: []string{"rewe"}, Weight: 1},
+		}).Return(nil).Once()
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return(categories[:1], nil).Once()
+		mockClassifierRepo.On("GetClassifierTokens", ctx, uint(1), []string{"rewe"}).Return([]models.ClassifierToken{
+			{UserID: 1, CategoryID: 2, Token: "rewe", Occurrences: 1},
+		}, nil)
+		mockClassifierRepo.On("CountClassifierVocabulary", ctx, uint(1)).Return(int64(1), nil)
+
+		suggestions, err := service.SuggestCategories(ctx, 1, "Rewe", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []models.CategorySuggestion{{CategoryID: 2, Confidence: 1}}, suggestions)
+		mockClassifierRepo.AssertExpectations(t)
+	})
+
+	t.Run("Suggest nothing without words", func(t *testing.T) {
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(new(MockTransactionRepository), nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		suggestions, err := service.SuggestCategories(ctx, 1, "42.50", "")
+		assert.NoError(t, err)
+		assert.Empty(t, suggestions)
+		mockClassifierRepo.AssertNotCalled(t, "GetClassifierCategories")
+	})
+
+	t.Run("Fail when the classifier cannot be loaded", func(t *testing.T) {
+		mockClassifierRepo := new(MockClassifierRepository)
+		service := NewTransactionService(new(MockTransactionRepository), nil, nil, nil, WithClassifierRepository(mockClassifierRepo))
+
+		mockClassifierRepo.On("GetClassifierCategories", ctx, uint(1)).Return([]models.ClassifierCategory{}, errors.New("db down"))
+
+		_, err := service.SuggestCategories(ctx, 1, "Rewe", "")
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
@@ -11,15 +12,57 @@ import (
 )
 
 type DefaultUserService struct {
-	userRepo repositories.UserRepository
+	userRepo            repositories.UserRepository
+	deletionGracePeriod time.Duration
+	loginThrottle       *LoginThrottle
+	passwordPolicy      PasswordPolicy
 }
 
-func NewUserService(userRepo repositories.UserRepository) *DefaultUserService {
-	return &DefaultUserService{userRepo: userRepo}
+// UserServiceOption configures optional behaviour of the user service.
+type UserServiceOption func(*DefaultUserService)
+
+// WithDeletionGracePeriod keeps accounts for the given period after their
+// deletion is requested, so the user can still cancel it.
+func WithDeletionGracePeriod(period time.Duration) UserServiceOption {
+	return func(s *DefaultUserService) {
+		if period > 0 {
+			s.deletionGracePeriod = period
+		}
+	}
+}
+
+// WithLoginThrottle counts failed logins and locks out accounts and client
+// addresses that fail too often.
+func WithLoginThrottle(throttle *LoginThrottle) UserServiceOption {
+	return func(s *DefaultUserService) {
+		s.loginThrottle = throttle
+	}
+}
+
+// WithPasswordPolicy sets which passwords users may register with or
+// change to. Without it any password of eight characters or more is taken.
+func WithPasswordPolicy(policy PasswordPolicy) UserServiceOption {
+	return func(s *DefaultUserService) {
+		s.passwordPolicy = policy
+	}
+}
+
+func NewUserService(userRepo repositories.UserRepository, options ...UserServiceOption) *DefaultUserService {
+	service := &DefaultUserService{userRepo: userRepo}
+
+	for _, option := range options {
+		option(service)
+	}
+
+	return service
 }
 
 // RegisterUser creates a new user with a hashed password
 func (s *DefaultUserService) RegisterUser(ctx context.Context, name, email, password string) (*models.User, error) {
+	if err := s.passwordPolicy.check(password, name, email); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -39,21 +82,203 @@ func (s *DefaultUserService) RegisterUser(ctx context.Context, name, email, pass
 	return user, nil
 }
 
-// AuthenticateUser checks email & password for login
-func (s *DefaultUserService) AuthenticateUser(ctx context.Context, email, password string) (*models.User, error) {
+// AuthenticateUser checks email & password for login. Accounts and client
+// addresses with too many recent failures are refused before the password
+// is looked at. The failure count of an account with two-factor
+// authentication is only cleared once its second factor is checked too.
+func (s *DefaultUserService) AuthenticateUser(ctx context.Context, email, password, clientAddress string) (*models.User, error) {
+	now := time.Now().UTC()
+	keys := s.loginThrottle.keys(email, clientAddress)
+	if err := s.loginThrottle.check(ctx, keys, now); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, apperrors.Unauthorized("invalid_credentials", "invalid credentials")
+		return nil, s.loginFailed(ctx, keys, now)
 	}
 
 	// Compare hashed password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, apperrors.Unauthorized("invalid_credentials", "invalid credentials")
+		return nil, s.loginFailed(ctx, keys, now)
+	}
+
+	if !user.MFAEnabled() {
+		if err := s.loginThrottle.reset(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (s *DefaultUserService) loginFailed(ctx context.Context, keys []loginKey, now time.Time) error {
+	if err := s.loginThrottle.recordFailure(ctx, keys, loginFailureInvalidCredentials, now); err != nil {
+		return err
+	}
+
+	return apperrors.Unauthorized("invalid_credentials", "invalid credentials")
+}
+
+// GetUser fetches the user's profile
+func (s *DefaultUserService) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NotFound("user_not_found", "user not found")
+	}
+	return user, nil
+}
+
+// UpdateUser changes the user's name and email; fields that are nil are
+// left as they are. Whoever controls the email address can reset the
+// password, so changing it needs the current password as well.
+func (s *DefaultUserService) UpdateUser(ctx context.Context, userID uint, name, email *string, currentPassword string) (*models.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" || len([]rune(trimmed)) > 100 {
+			return nil, apperrors.Validation("invalid_name", "name must be between 1 and 100 characters")
+		}
+		user.Name = trimmed
+	}
+	if email != nil {
+		trimmed := strings.TrimSpace(*email)
+		if trimmed == "" {
+			return nil, apperrors.Validation("invalid_email", "email must not be empty")
+		}
+		if !strings.EqualFold(trimmed, user.Email) {
+			if currentPassword == "" {
+				return nil, apperrors.Validation("current_password_required", "current password is required to change the email")
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+				return nil, apperrors.Forbidden("invalid_password", "password is incorrect")
+			}
+			// A new address has to be verified again.
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = trimmed
+	}
+
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, apperrors.Conflict("email_already_registered", "email already registered")
+		}
+
+		return nil, apperrors.Internal("user_update_failed", "failed to update user", err)
 	}
 
 	return user, nil
 }
 
+// ChangePassword replaces the user's password once the current one is
+// confirmed.
+func (s *DefaultUserService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return apperrors.Forbidden("invalid_password", "password is incorrect")
+	}
+
+	if err := s.passwordPolicy.check(newPassword, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return apperrors.Internal("password_change_failed", "failed to change password", err)
+	}
+
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		return apperrors.Internal("password_change_failed", "failed to change password", err)
+	}
+
+	return nil
+}
+
+// DeleteUser erases the user's account and everything they own once
+// their password is confirmed. With a grace period the deletion is only
+// scheduled and the time it will happen is returned; otherwise the data is
+// erased straight away and the returned time is nil.
+func (s *DefaultUserService) DeleteUser(ctx context.Context, userID uint, password string) (*time.Time, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, apperrors.Forbidden("invalid_password", "password is incorrect")
+	}
+
+	if s.deletionGracePeriod == 0 {
+		if err := s.userRepo.EraseUser(ctx, userID); err != nil {
+			return nil, apperrors.Internal("user_deletion_failed", "failed to delete account", err)
+		}
+		return nil, nil
+	}
+
+	// Asking again does not push back a deletion that is already scheduled.
+	if user.DeletionScheduledAt != nil {
+		return user.DeletionScheduledAt, nil
+	}
+
+	scheduledAt := time.Now().UTC().Add(s.deletionGracePeriod)
+	if err := s.userRepo.ScheduleUserDeletion(ctx, userID, &scheduledAt); err != nil {
+		return nil, apperrors.Internal("user_deletion_failed", "failed to delete account", err)
+	}
+
+	return &scheduledAt, nil
+}
+
+// CancelUserDeletion keeps an account whose deletion is scheduled.
+func (s *DefaultUserService) CancelUserDeletion(ctx context.Context, userID uint) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return apperrors.Conflict("user_deletion_not_scheduled", "account deletion is not scheduled")
+	}
+
+	if err := s.userRepo.ScheduleUserDeletion(ctx, userID, nil); err != nil {
+		return apperrors.Internal("user_deletion_cancel_failed", "failed to cancel account deletion", err)
+	}
+
+	return nil
+}
+
+// EraseDueUsers erases every account whose deletion was scheduled for
+// now or earlier and returns how many were erased. It carries on past
+// accounts that fail and reports the first error.
+func (s *DefaultUserService) EraseDueUsers(ctx context.Context, now time.Time) (int, error) {
+	users, err := s.userRepo.GetUsersDueForDeletion(ctx, now)
+	if err != nil {
+		return 0, apperrors.Internal("user_deletion_failed", "failed to delete accounts", err)
+	}
+
+	var firstErr error
+	erased := 0
+	for _, user := range users {
+		if err := s.userRepo.EraseUser(ctx, user.ID); err != nil {
+			if firstErr == nil {
+				firstErr = apperrors.Internal("user_deletion_failed", "failed to delete accounts", err)
+			}
+			continue
+		}
+		erased++
+	}
+
+	return erased, firstErr
+}
+
 func isUniqueConstraintError(err error) bool {
 	if err == nil {
 		return false
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
@@ -30,6 +31,44 @@ func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (
 	return nil, args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*models.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ScheduleUserDeletion(ctx context.Context, id uint, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) EraseUser(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) InvalidateUserTokens(ctx context.Context, id uint, before time.Time) error {
+	args := m.Called(ctx, id, before)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uint, email string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, email, at)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestRegisterUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	service := NewUserService(mockRepo)
@@ -95,7 +134,7 @@ func TestAuthenticateUser(t *testing.T) {
 
 		mockRepo.On("GetUserByEmail", ctx, "john@example.com").Return(user, nil)
 
-		authenticatedUser, err := service.AuthenticateUser(ctx, "john@example.com", "mypassword")
+		authenticatedUser, err := service.AuthenticateUser(ctx, "john@example.com", "mypassword", "")
 		assert.NoError(t, err)
 		assert.NotNil(t, authenticatedUser)
 		assert.Equal(t, "John Doe", authenticatedUser.Name)
@@ -105,7 +144,7 @@ func TestAuthenticateUser(t *testing.T) {
 	t.Run("Fail when user does not exist", func(t *testing.T) {
 		mockRepo.On("GetUserByEmail", ctx, "nonexistent@example.com").Return(nil, errors.New("not found"))
 
-		authenticatedUser, err := service.AuthenticateUser(ctx, "nonexistent@example.com", "password")
+		authenticatedUser, err := service.AuthenticateUser(ctx, "nonexistent@example.com", "password", "")
 		assert.Error(t, err)
 		assert.Nil(t, authenticatedUser)
 		assert.Equal(t, "invalid credentials", err.Error())
@@ -119,7 +158,7 @@ func TestAuthenticateUser(t *testing.T) {
 
 		mockRepo.On("GetUserByEmail", ctx, "john@example.com").Return(user, nil)
 
-		authenticatedUser, err := service.AuthenticateUser(ctx, "john@example.com", "wrongpassword")
+		authenticatedUser, err := service.AuthenticateUser(ctx, "john@example.com", "wrongpassword", "")
 		assert.Error(t, err)
 		assert.Nil(t, authenticatedUser)
 		assert.Equal(t, "invalid credentials", err.Error())
@@ -128,6 +167,277 @@ func TestAuthenticateUser(t *testing.T) {
 	})
 }
 
+func TestGetUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo)
+
+	mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Name: "John Doe"}, nil)
+	mockRepo.On("GetUserByID", ctx, uint(2)).Return(nil, errors.New("record not found"))
+
+	user, err := service.GetUser(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", user.Name)
+
+	_, err = service.GetUser(ctx, 2)
+	assert.True(t, isAppErrorKind(err, apperrors.KindNotFound))
+}
+
+func TestUpdateUser(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("mypassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	t.Run("Update the name only", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		name := "  Johnny  "
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Name: "John", Email: "john@example.com"}, nil)
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.Name == "Johnny" && u.Email == "john@example.com"
+		})).Return(nil)
+
+		user, err := service.UpdateUser(ctx, 1, &name, nil, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "Johnny", user.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Changing the email clears its verification", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		email := "jane@example.com"
+		verifiedAt := time.Now()
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword), EmailVerifiedAt: &verifiedAt}, nil)
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.Email == "jane@example.com" && u.EmailVerifiedAt == nil
+		})).Return(nil)
+
+		_, err := service.UpdateUser(ctx, 1, nil, &email, "mypassword")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Changing only the case of the email needs no password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		email := "John@Example.com"
+		verifiedAt := time.Now()
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.Email == "John@Example.com" && u.EmailVerifiedAt != nil
+		})).Return(nil)
+
+		_, err := service.UpdateUser(ctx, 1, nil, &email, "")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail to change the email without the current password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		email := "jane@example.com"
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+
+		_, err := service.UpdateUser(ctx, 1, nil, &email, "")
+		assertAppErrorCode(t, err, "current_password_required")
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+
+		_, err = service.UpdateUser(ctx, 1, nil, &email, "guess")
+		assertAppErrorCode(t, err, "invalid_password")
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockRepo.AssertNotCalled(t, "UpdateUser")
+	})
+
+	t.Run("Fail with a blank email", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		email := "  "
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+
+		_, err := service.UpdateUser(ctx, 1, nil, &email, "mypassword")
+		assertAppErrorCode(t, err, "invalid_email")
+		mockRepo.AssertNotCalled(t, "UpdateUser")
+	})
+
+	t.Run("Fail with a blank name", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		name := "   "
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Name: "John"}, nil)
+
+		_, err := service.UpdateUser(ctx, 1, &name, nil, "")
+		assert.True(t, isAppErrorKind(err, apperrors.KindValidation))
+		mockRepo.AssertNotCalled(t, "UpdateUser")
+	})
+
+	t.Run("Fail when the email is taken", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+		email := "jane@example.com"
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+		mockRepo.On("UpdateUser", ctx, mock.Anything).Return(errors.New("UNIQUE constraint failed: users.email"))
+
+		_, err := service.UpdateUser(ctx, 1, nil, &email, "mypassword")
+		appErr, ok := apperrors.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, "email_already_registered", appErr.Code)
+		assert.Equal(t, apperrors.KindConflict, appErr.Kind)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	t.Run("Change with the current password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+		mockRepo.On("UpdateUser", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("newpassword")) == nil
+		})).Return(nil)
+
+		assert.NoError(t, service.ChangePassword(ctx, 1, "oldpassword", "newpassword"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail with the wrong current password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+		err := service.ChangePassword(ctx, 1, "guess", "newpassword")
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockRepo.AssertNotCalled(t, "UpdateUser")
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("mypassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	t.Run("Erase straight away without a grace period", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+		mockRepo.On("EraseUser", ctx, uint(1)).Return(nil)
+
+		scheduledAt, err := service.DeleteUser(ctx, 1, "mypassword")
+		assert.NoError(t, err)
+		assert.Nil(t, scheduledAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Schedule the deletion with a grace period", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithDeletionGracePeriod(30*24*time.Hour))
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+		mockRepo.On("ScheduleUserDeletion", ctx, uint(1), mock.MatchedBy(func(at *time.Time) bool {
+			return at != nil && at.After(time.Now().Add(29*24*time.Hour))
+		})).Return(nil)
+
+		scheduledAt, err := service.DeleteUser(ctx, 1, "mypassword")
+		assert.NoError(t, err)
+		assert.NotNil(t, scheduledAt)
+		mockRepo.AssertNotCalled(t, "EraseUser")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Keep an existing schedule", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithDeletionGracePeriod(time.Hour))
+		existing := time.Now().Add(10 * time.Minute)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword), DeletionScheduledAt: &existing}, nil)
+
+		scheduledAt, err := service.DeleteUser(ctx, 1, "mypassword")
+		assert.NoError(t, err)
+		assert.Equal(t, existing, *scheduledAt)
+		mockRepo.AssertNotCalled(t, "ScheduleUserDeletion")
+	})
+
+	t.Run("Fail with the wrong password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+		_, err := service.DeleteUser(ctx, 1, "wrongpassword")
+		assert.True(t, isAppErrorKind(err, apperrors.KindForbidden))
+		mockRepo.AssertNotCalled(t, "EraseUser")
+	})
+
+	t.Run("Fail when erasing fails", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+		mockRepo.On("EraseUser", ctx, uint(1)).Return(errors.New("db error"))
+
+		_, err := service.DeleteUser(ctx, 1, "mypassword")
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestCancelUserDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cancel a scheduled deletion", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithDeletionGracePeriod(time.Hour))
+		scheduledAt := time.Now().Add(time.Hour)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, DeletionScheduledAt: &scheduledAt}, nil)
+		mockRepo.On("ScheduleUserDeletion", ctx, uint(1), (*time.Time)(nil)).Return(nil)
+
+		assert.NoError(t, service.CancelUserDeletion(ctx, 1))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail when nothing is scheduled", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithDeletionGracePeriod(time.Hour))
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1}, nil)
+
+		err := service.CancelUserDeletion(ctx, 1)
+		assert.True(t, isAppErrorKind(err, apperrors.KindConflict))
+		mockRepo.AssertNotCalled(t, "ScheduleUserDeletion")
+	})
+}
+
+func TestEraseDueUsers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, WithDeletionGracePeriod(time.Hour))
+
+	mockRepo.On("GetUsersDueForDeletion", ctx, now).Return([]models.User{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+	mockRepo.On("EraseUser", ctx, uint(1)).Return(nil)
+	mockRepo.On("EraseUser", ctx, uint(2)).Return(errors.New("db error"))
+	mockRepo.On("EraseUser", ctx, uint(3)).Return(nil)
+
+	erased, err := service.EraseDueUsers(ctx, now)
+	assert.Equal(t, 2, erased)
+	assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	mockRepo.AssertExpectations(t)
+}
+
 func isAppErrorKind(err error, expected apperrors.Kind) bool {
 	appErr, ok := apperrors.As(err)
 	return ok && appErr.Kind == expected
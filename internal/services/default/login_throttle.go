@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/repositories"
+)
+
+// Defaults for the fields of LoginThrottlePolicy that are left zero.
+const (
+	defaultMaxAccountFailures = 5
+	defaultMaxAddressFailures = 20
+	defaultLoginLockout       = time.Minute
+	defaultMaxLoginLockout    = time.Hour
+)
+
+// loginFailureWindow is how long a key has to go without a failed login
+// before its count starts again from zero.
+const loginFailureWindow = 24 * time.Hour
+
+// Reasons a login is counted as failed, used as the metric label.
+const (
+	loginFailureInvalidCredentials = "invalid_credentials"
+	loginFailureInvalidMFACode     = "invalid_mfa_code"
+	loginFailureLockedOut          = "locked_out"
+)
+
+// LoginThrottlePolicy decides when failed logins lock an account or a
+// client address out, and for how long.
+type LoginThrottlePolicy struct {
+	// MaxAccountFailures and MaxAddressFailures are how many failed logins
+	// an account or a client address gets before it is locked.
+	MaxAccountFailures int
+	MaxAddressFailures int
+	// Lockout is how long the first lockout lasts. Every failure after it
+	// doubles the lockout, up to MaxLockout.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// LoginMetrics is told about failed logins and lockouts so they can be
+// counted.
+type LoginMetrics interface {
+	FailedLogin(reason string)
+	LoginLockout(scope string)
+}
+
+// LoginThrottle slows down password and code guessing. Failed logins are
+// counted per account and per client address in the database, so every
+// instance sees the same counts, and a key that reaches its limit is locked
+// for a time that grows exponentially with each further failure.
+type LoginThrottle struct {
+	repo    repositories.LoginThrottleRepository
+	policy  LoginThrottlePolicy
+	metrics LoginMetrics
+}
+
+// LoginThrottleOption configures optional behaviour of the login throttle.
+type LoginThrottleOption func(*LoginThrottle)
+
+// WithLoginMetrics reports failed logins and lockouts to metrics.
+func WithLoginMetrics(metrics LoginMetrics) LoginThrottleOption {
+	return func(t *LoginThrottle) {
+		if metrics != nil {
+			t.metrics = metrics
+		}
+	}
+}
+
+func NewLoginThrottle(repo repositories.LoginThrottleRepository, policy LoginThrottlePolicy, options ...LoginThrottleOption) *LoginThrottle {
+	if policy.MaxAccountFailures <= 0 {
+		policy.MaxAccountFailures = defaultMaxAccountFailures
+	}
+	if policy.MaxAddressFailures <= 0 {
+		policy.MaxAddressFailures = defaultMaxAddressFailures
+	}
+	if policy.Lockout <= 0 {
+		policy.Lockout = defaultLoginLockout
+	}
+	if policy.MaxLockout < policy.Lockout {
+		policy.MaxLockout = max(defaultMaxLoginLockout, policy.Lockout)
+	}
+
+	throttle := &LoginThrottle{repo: repo, policy: policy, metrics: noLoginMetrics{}}
+
+	for _, option := range options {
+		option(throttle)
+	}
+
+	return throttle
+}
+
+// PurgeStaleEntries deletes counters that have gone a whole failure window
+// without a failed login and no longer lock anything out.
+func (t *LoginThrottle) PurgeStaleEntries(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := t.repo.DeleteStaleLoginThrottles(ctx, now.Add(-t.window()))
+	if err != nil {
+		return 0, apperrors.Internal("login_throttle_purge_failed", "failed to purge login throttles", err)
+	}
+
+	return deleted, nil
+}
+
+// loginKey is one thing failed logins are counted against.
+type loginKey struct {
+	scope       string
+	hash        string
+	maxFailures int
+}
+
+// keys returns the keys for a login attempt, leaving out the client address
+// when it is not known. The account is identified by its email address
+// whether or not it exists, so locking out an address that is not
+// registered looks the same as locking out one that is.
+func (t *LoginThrottle) keys(email, clientAddress string) []loginKey {
+	if t == nil {
+		return nil
+	}
+
+	keys := []loginKey{{
+		scope:       "account",
+		hash:        auth.AccountThrottleKey(email),
+		maxFailures: t.policy.MaxAccountFailures,
+	}}
+	if clientAddress != "" {
+		keys = append(keys, loginKey{
+			scope:       "address",
+			hash:        auth.HashOpaqueToken("address:" + clientAddress),
+			maxFailures: t.policy.MaxAddressFailures,
+		})
+	}
+	return keys
+}
+
+// check refuses the attempt while any of its keys is locked.
+func (t *LoginThrottle) check(ctx context.Context, keys []loginKey, now time.Time) error {
+	if t == nil || len(keys) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, len(keys))
+	for i, key := range keys {
+		hashes[i] = key.hash
+	}
+
+	throttles, err := t.repo.GetLoginThrottles(ctx, hashes)
+	if err != nil {
+		return apperrors.Internal("login_failed", "failed to log in", err)
+	}
+
+	var lockedUntil time.Time
+	for _, throttle := range throttles {
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(lockedUntil) {
+			lockedUntil = *throttle.LockedUntil
+		}
+	}
+	if !lockedUntil.After(now) {
+		return nil
+	}
+
+	t.metrics.FailedLogin(loginFailureLockedOut)
+	return loginLocked(lockedUntil.Sub(now))
+}
+
+// recordFailure counts a failed attempt against every key and locks the
+// ones that reached their limit. It returns the lockout error when the
+// attempt locked any key, so the client hears about it straight away.
+func (t *LoginThrottle) recordFailure(ctx context.Context, keys []loginKey, reason string, now time.Time) error {
+	if t == nil {
+		return nil
+	}
+	t.metrics.FailedLogin(reason)
+
+	var lockout time.Duration
+	for _, key := range keys {
+		throttle, err := t.repo.RecordLoginFailure(ctx, key.hash, now, now.Add(-t.window()))
+		if err != nil {
+			return apperrors.Internal("login_failed", "failed to log in", err)
+		}
+
+		excess := throttle.Failures - key.maxFailures
+		if excess < 0 {
+			continue
+		}
+
+		duration := t.lockoutFor(excess)
+		if err := t.repo.LockLoginThrottle(ctx, key.hash, now.Add(duration)); err != nil {
+			return apperrors.Internal("login_failed", "failed to log in", err)
+		}
+		t.metrics.LoginLockout(key.scope)
+		lockout = max(lockout, duration)
+	}
+
+	if lockout > 0 {
+		return loginLocked(lockout)
+	}
+	return nil
+}
+
+// reset forgets the failures counted against the account after a login
+// that passed every check. Counts for the client address are left alone,
+// or someone guessing passwords could clear them by logging in to an
+// account of their own now and then.
+func (t *LoginThrottle) reset(ctx context.Context, keys []loginKey) error {
+	if t == nil {
+		return nil
+	}
+
+	for _, key := range keys {
+		if key.scope != "account" {
+			continue
+		}
+		if err := t.repo.DeleteLoginThrottle(ctx, key.hash); err != nil {
+			return apperrors.Internal("login_failed", "failed to log in", err)
+		}
+	}
+	return nil
+}
+
+// lockoutFor doubles the first lockout for every failure past the limit.
+func (t *LoginThrottle) lockoutFor(excess int) time.Duration {
+	lockout := t.policy.Lockout
+	for i := 0; i < excess && lockout < t.policy.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, t.policy.MaxLockout)
+}
+
+// window is how long failures are remembered, which is never shorter than
+// the longest lockout.
+func (t *LoginThrottle) window() time.Duration {
+	return max(loginFailureWindow, t.policy.MaxLockout)
+}
+
+func loginLocked(retryAfter time.Duration) error {
+	minutes := int(math.Ceil(retryAfter.Minutes()))
+	unit := "minutes"
+	if minutes == 1 {
+		unit = "minute"
+	}
+
+	return apperrors.TooManyRequests(
+		"too_many_login_attempts",
+		fmt.Sprintf("too many failed login attempts; try again in %d %s", minutes, unit),
+		retryAfter,
+	)
+}
+
+type noLoginMetrics struct{}
+
+func (noLoginMetrics) FailedLogin(string)  {}
+func (noLoginMetrics) LoginLockout(string) {}
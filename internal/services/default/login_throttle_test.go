@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeLoginThrottleRepository keeps login throttles in memory, so tests
+// can follow the counts over several attempts.
+type fakeLoginThrottleRepository struct {
+	mu        sync.Mutex
+	throttles map[string]*models.LoginThrottle
+	err       error
+}
+
+func newFakeLoginThrottleRepository() *fakeLoginThrottleRepository {
+	return &fakeLoginThrottleRepository{throttles: make(map[string]*models.LoginThrottle)}
+}
+
+func (r *fakeLoginThrottleRepository) GetLoginThrottles(_ context.Context, keyHashes []string) ([]models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var throttles []models.LoginThrottle
+	for _, keyHash := range keyHashes {
+		if throttle, ok := r.throttles[keyHash]; ok {
+			throttles = append(throttles, *throttle)
+		}
+	}
+	return throttles, nil
+}
+
+func (r *fakeLoginThrottleRepository) RecordLoginFailure(_ context.Context, keyHash string, at, windowStart time.Time) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	throttle, ok := r.throttles[keyHash]
+	if !ok || throttle.LastFailureAt.Before(windowStart) {
+		throttle = &models.LoginThrottle{KeyHash: keyHash}
+		r.throttles[keyHash] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = at
+
+	stored := *throttle
+	return &stored, nil
+}
+
+func (r *fakeLoginThrottleRepository) LockLoginThrottle(_ context.Context, keyHash string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.throttles[keyHash].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeLoginThrottleRepository) DeleteLoginThrottle(_ context.Context, keyHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.throttles, keyHash)
+	return nil
+}
+
+func (r *fakeLoginThrottleRepository) DeleteStaleLoginThrottles(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for keyHash, throttle := range r.throttles {
+		if throttle.LastFailureAt.Before(before) {
+			delete(r.throttles, keyHash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// recordingLoginMetrics counts what the throttle reports.
+type recordingLoginMetrics struct {
+	failures map[string]int
+	lockouts map[string]int
+}
+
+func newRecordingLoginMetrics() *recordingLoginMetrics {
+	return &recordingLoginMetrics{failures: make(map[string]int), lockouts: make(map[string]int)}
+}
+
+func (m *recordingLoginMetrics) FailedLogin(reason string) { m.failures[reason]++ }
+func (m *recordingLoginMetrics) LoginLockout(scope string) { m.lockouts[scope]++ }
+
+func loginThrottleCode(err error) (string, time.Duration) {
+	appErr, ok := apperrors.As(err)
+	if !ok {
+		return "", 0
+	}
+	return appErr.Code, appErr.RetryAfter
+}
+
+func TestAuthenticateUserLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := &models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}
+
+	newService := func() (*DefaultUserService, *fakeLoginThrottleRepository, *recordingLoginMetrics) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("GetUserByEmail", mock.Anything, "john@example.com").Return(user, nil)
+		mockRepo.On("GetUserByEmail", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+
+		throttleRepo := newFakeLoginThrottleRepository()
+		metrics := newRecordingLoginMetrics()
+		throttle := NewLoginThrottle(throttleRepo, LoginThrottlePolicy{
+			MaxAccountFailures: 3,
+			MaxAddressFailures: 5,
+			Lockout:            time.Minute,
+			MaxLockout:         5 * time.Minute,
+		}, WithLoginMetrics(metrics))
+		return NewUserService(mockRepo, WithLoginThrottle(throttle)), throttleRepo, metrics
+	}
+
+	t.Run("should lock the account once the limit is reached", func(t *testing.T) {
+		service, _, metrics := newService()
+
+		for range 2 {
+			_, err := service.AuthenticateUser(ctx, "john@example.com", "wrong", "192.0.2.1")
+			code, _ := loginThrottleCode(err)
+			assert.Equal(t, "invalid_credentials", code)
+		}
+
+		_, err := service.AuthenticateUser(ctx, "john@example.com", "wrong", "192.0.2.1")
+		code, retryAfter := loginThrottleCode(err)
+		assert.Equal(t, "too_many_login_attempts", code)
+		assert.Equal(t, time.Minute, retryAfter)
+		assert.True(t, isAppErrorKind(err, apperrors.KindTooManyRequests))
+		assert.Equal(t, "too many failed login attempts; try again in 1 minute", err.Error())
+
+		// The right password does not help while the account is locked,
+		// from any address.
+		_, err = service.AuthenticateUser(ctx, "JOHN@example.com ", "correctpassword", "198.51.100.7")
+		code, _ = loginThrottleCode(err)
+		assert.Equal(t, "too_many_login_attempts", code)
+
+		assert.Equal(t, 3, metrics.failures["invalid_credentials"])
+		assert.Equal(t, 1, metrics.failures["locked_out"])
+		assert.Equal(t, 1, metrics.lockouts["account"])
+	})
+
+	t.Run("should double the lockout for every further failure", func(t *testing.T) {
+		service, throttleRepo, _ := newService()
+
+		for range 3 {
+			_, _ = service.AuthenticateUser(ctx, "john@example.com", "wrong", "")
+		}
+		for _, throttle := range throttleRepo.throttles {
+			past := time.Now().Add(-time.Second)
+			throttle.LockedUntil = &past
+		}
+
+		_, err := service.AuthenticateUser(ctx, "john@example.com", "wrong", "")
+		_, retryAfter := loginThrottleCode(err)
+		assert.Equal(t, 2*time.Minute, retryAfter)
+	})
+
+	t.Run("should lock an address that guesses across accounts", func(t *testing.T) {
+		service, _, metrics := newService()
+
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+			_, err := service.AuthenticateUser(ctx, email, "wrong", "192.0.2.1")
+			code, _ := loginThrottleCode(err)
+			assert.Equal(t, "invalid_credentials", code)
+		}
+
+		_, err := service.AuthenticateUser(ctx, "e@example.com", "wrong", "192.0.2.1")
+		code, _ := loginThrottleCode(err)
+		assert.Equal(t, "too_many_login_attempts", code)
+		assert.Equal(t, 1, metrics.lockouts["address"])
+
+		// Another address can still log in to the account.
+		authenticated, err := service.AuthenticateUser(ctx, "john@example.com", "correctpassword", "198.51.100.7")
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), authenticated.ID)
+	})
+
+	t.Run("should forget account failures after a successful login", func(t *testing.T) {
+		service, throttleRepo, _ := newService()
+
+		for range 2 {
+			_, _ = service.AuthenticateUser(ctx, "john@example.com", "wrong", "192.0.2.1")
+		}
+		_, err := service.AuthenticateUser(ctx, "john@example.com", "correctpassword", "192.0.2.1")
+		assert.NoError(t, err)
+
+		// Only the address counter is left.
+		if assert.Len(t, throttleRepo.throttles, 1) {
+			for _, throttle := range throttleRepo.throttles {
+				assert.Equal(t, 2, throttle.Failures)
+			}
+		}
+	})
+
+	t.Run("should keep account failures until the second factor is checked", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		enabledAt := time.Now()
+		mfaUser := &models.User{ID: 2, Email: "mfa@example.com", Password: string(hashedPassword), TOTPEnabledAt: &enabledAt}
+		mockRepo.On("GetUserByEmail", mock.Anything, "mfa@example.com").Return(mfaUser, nil)
+		throttleRepo := newFakeLoginThrottleRepository()
+		service := NewUserService(mockRepo, WithLoginThrottle(NewLoginThrottle(throttleRepo, LoginThrottlePolicy{})))
+
+		_, _ = service.AuthenticateUser(ctx, "mfa@example.com", "wrong", "")
+		_, err := service.AuthenticateUser(ctx, "mfa@example.com", "correctpassword", "")
+
+		assert.NoError(t, err)
+		assert.Len(t, throttleRepo.throttles, 1)
+	})
+
+	t.Run("should fail when the counts cannot be read", func(t *testing.T) {
+		service, throttleRepo, _ := newService()
+		throttleRepo.err = errors.New("db down")
+
+		_, err := service.AuthenticateUser(ctx, "john@example.com", "correctpassword", "192.0.2.1")
+
+		assert.True(t, isAppErrorKind(err, apperrors.KindInternal))
+	})
+}
+
+func TestVerifyMFAChallengeLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	tokenManager := auth.NewJWTManager("secret", time.Minute)
+	secret, err := auth.NewTOTPSecret()
+	assert.NoError(t, err)
+	enabledAt := time.Now()
+	user := &models.User{ID: 1, Email: "john@example.com", TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByID", ctx, uint(1)).Return(user, nil)
+	mockMFARepo := new(MockMFARepository)
+	mockMFARepo.On("UseRecoveryCode", ctx, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+	throttle := NewLoginThrottle(newFakeLoginThrottleRepository(), LoginThrottlePolicy{MaxAccountFailures: 2})
+	service := NewMFAService(mockUserRepo, mockMFARepo, new(MockRevokedTokenRepository), tokenManager, WithMFALoginThrottle(throttle))
+
+	challenge, err := service.StartMFAChallenge(ctx, user)
+	assert.NoError(t, err)
+
+	_, err = service.VerifyMFAChallenge(ctx, challenge.Token, "wrong-code")
+	code, _ := loginThrottleCode(err)
+	assert.Equal(t, "invalid_mfa_code", code)
+
+	_, err = service.VerifyMFAChallenge(ctx, challenge.Token, "wrong-code")
+	code, _ = loginThrottleCode(err)
+	assert.Equal(t, "too_many_login_attempts", code)
+
+	// A right code is not even checked while the account is locked.
+	totpCode, err := auth.GenerateTOTPCode(secret, time.Now())
+	assert.NoError(t, err)
+	_, err = service.VerifyMFAChallenge(ctx, challenge.Token, totpCode)
+	code, _ = loginThrottleCode(err)
+	assert.Equal(t, "too_many_login_attempts", code)
+	mockMFARepo.AssertNotCalled(t, "UseTOTPStep", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginThrottleLockoutFor(t *testing.T) {
+	throttle := NewLoginThrottle(newFakeLoginThrottleRepository(), LoginThrottlePolicy{Lockout: time.Minute, MaxLockout: 10 * time.Minute})
+
+	assert.Equal(t, time.Minute, throttle.lockoutFor(0))
+	assert.Equal(t, 2*time.Minute, throttle.lockoutFor(1))
+	assert.Equal(t, 8*time.Minute, throttle.lockoutFor(3))
+	assert.Equal(t, 10*time.Minute, throttle.lockoutFor(4))
+	assert.Equal(t, 10*time.Minute, throttle.lockoutFor(1000))
+}
+
+func TestLoginThrottlePurgeStaleEntries(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeLoginThrottleRepository()
+	throttle := NewLoginThrottle(repo, LoginThrottlePolicy{})
+	now := time.Now().UTC()
+
+	_, _ = repo.RecordLoginFailure(ctx, "old", now.Add(-2*loginFailureWindow), now.Add(-loginFailureWindow))
+	_, _ = repo.RecordLoginFailure(ctx, "recent", now, now.Add(-loginFailureWindow))
+
+	deleted, err := throttle.PurgeStaleEntries(ctx, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Contains(t, repo.throttles, "recent")
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/passwords"
+)
+
+// defaultMinPasswordLength is used when PasswordPolicy.MinLength is zero.
+const defaultMinPasswordLength = 8
+
+// maxPasswordLength is the most bytes bcrypt hashes. Longer passwords are
+// refused rather than cut short without the user knowing.
+const maxPasswordLength = 72
+
+// BreachedPasswords tells whether a password is known from a data breach.
+type BreachedPasswords interface {
+	Contains(password string) (bool, error)
+}
+
+// PasswordPolicy decides which new passwords are accepted, when users
+// register, change their password or reset it.
+type PasswordPolicy struct {
+	// MinLength is the fewest characters a password may have.
+	MinLength int
+	// MinScore is the lowest passwords.Strength score accepted, from 0,
+	// which accepts any password of the right length, to 4.
+	MinScore int
+	// Breached, when set, refuses passwords known from data breaches.
+	Breached BreachedPasswords
+}
+
+// check refuses a password that breaks the policy. userInputs are details
+// of the user, such as their name and email address, that make a password
+// built from them easy to guess.
+func (p PasswordPolicy) check(password string, userInputs ...string) error {
+	minLength := p.MinLength
+	if minLength <= 0 {
+		minLength = defaultMinPasswordLength
+	}
+
+	if utf8.RuneCountInString(password) < minLength {
+		return apperrors.Validation("password_too_short", fmt.Sprintf("password must be at least %d characters long", minLength))
+	}
+	if len(password) > maxPasswordLength {
+		return apperrors.Validation("password_too_long", fmt.Sprintf("password must be at most %d bytes long", maxPasswordLength))
+	}
+	if p.MinScore > 0 && passwords.Strength(password, userInputs...) < p.MinScore {
+		return apperrors.Validation("password_too_weak", "password is too easy to guess; use a longer one, for example a few unrelated words")
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return apperrors.Internal("password_check_failed", "failed to check password", err)
+		}
+		if breached {
+			return apperrors.Validation("password_breached", "password has appeared in a data breach; choose a different one")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/apperrors"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeBreachedPasswords lists breached passwords in memory.
+type fakeBreachedPasswords struct {
+	passwords []string
+	err       error
+}
+
+func (f fakeBreachedPasswords) Contains(password string) (bool, error) {
+	for _, breached := range f.passwords {
+		if breached == password {
+			return true, f.err
+		}
+	}
+	return false, f.err
+}
+
+func assertAppErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	appErr, ok := apperrors.As(err)
+	if assert.True(t, ok, "expected an app error, got %v", err) {
+		assert.Equal(t, code, appErr.Code)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 10, MinScore: 3, Breached: fakeBreachedPasswords{passwords: []string{"uncommon-but-leaked"}}}
+
+	assertAppErrorCode(t, policy.check("short"), "password_too_short")
+	assertAppErrorCode(t, policy.check(strings.Repeat("x", 73)), "password_too_long")
+	assertAppErrorCode(t, policy.check("password123"), "password_too_weak")
+	assertAppErrorCode(t, policy.check("AliceSmith99", "Alice Smith", "alice@example.com"), "password_too_weak")
+	assertAppErrorCode(t, policy.check("uncommon-but-leaked"), "password_breached")
+	assert.NoError(t, policy.check("correct horse battery staple"))
+
+	t.Run("zero policy only asks for eight characters", func(t *testing.T) {
+		assertAppErrorCode(t, PasswordPolicy{}.check("1234567"), "password_too_short")
+		assert.NoError(t, PasswordPolicy{}.check("12345678"))
+	})
+
+	t.Run("fails when the breach list cannot be read", func(t *testing.T) {
+		policy := PasswordPolicy{Breached: fakeBreachedPasswords{err: errors.New("disk error")}}
+		assert.True(t, isAppErrorKind(policy.check("correct horse battery staple"), apperrors.KindInternal))
+	})
+}
+
+func TestPasswordPolicyAppliesToUserService(t *testing.T) {
+	ctx := context.Background()
+	policy := PasswordPolicy{MinScore: 3, Breached: fakeBreachedPasswords{passwords: []string{"uncommon-but-leaked"}}}
+
+	t.Run("register refuses a weak password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithPasswordPolicy(policy))
+
+		_, err := service.RegisterUser(ctx, "John Doe", "john@example.com", "johndoe1")
+
+		assertAppErrorCode(t, err, "password_too_weak")
+		mockRepo.AssertNotCalled(t, "CreateUser")
+	})
+
+	t.Run("change refuses a breached password", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, WithPasswordPolicy(policy))
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.MinCost)
+		assert.NoError(t, err)
+
+		mockRepo.On("GetUserByID", ctx, uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+		err = service.ChangePassword(ctx, 1, "oldpassword", "uncommon-but-leaked")
+
+		assertAppErrorCode(t, err, "password_breached")
+		mockRepo.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+}
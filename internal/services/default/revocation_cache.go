@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// revocationCachePruneInterval is how often entries that no longer matter
+// are dropped from the cache.
+const revocationCachePruneInterval = time.Minute
+
+// revocationCache keeps the outcome of revocation lookups in memory so the
+// database is not queried on every authenticated request. Revocations made
+// by this process are cached until the token expires. Lookups, including
+// ones that found nothing, are trusted for ttl, which bounds how long a
+// logout on another instance takes to be noticed here.
+type revocationCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	revoked    map[string]time.Time
+	notRevoked map[string]time.Time
+	watermarks map[uint]cachedWatermark
+	nextPrune  time.Time
+}
+
+type cachedWatermark struct {
+	before    *time.Time
+	expiresAt time.Time
+}
+
+func newRevocationCache(ttl time.Duration) *revocationCache {
+	return &revocationCache{
+		ttl:        ttl,
+		revoked:    make(map[string]time.Time),
+		notRevoked: make(map[string]time.Time),
+		watermarks: make(map[uint]cachedWatermark),
+	}
+}
+
+// tokenRevoked reports whether the token is known to be revoked and whether
+// the cache knows the answer at all.
+func (c *revocationCache) tokenRevoked(tokenID string, now time.Time) (revoked, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.revoked[tokenID]; ok {
+		return true, true
+	}
+	if expiresAt, ok := c.notRevoked[tokenID]; ok && now.Before(expiresAt) {
+		return false, true
+	}
+	return false, false
+}
+
+func (c *revocationCache) setTokenRevoked(tokenID string, revoked bool, tokenExpiresAt, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneIfDue(now)
+
+	if revoked {
+		delete(c.notRevoked, tokenID)
+		c.revoked[tokenID] = tokenExpiresAt
+		return
+	}
+	c.notRevoked[tokenID] = now.Add(c.ttl)
+}
+
+// watermark returns the cached time before which the user's tokens are
+// invalid, which is nil when there is none.
+func (c *revocationCache) watermark(userID uint, now time.Time) (before *time.Time, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.watermarks[userID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.before, true
+}
+
+func (c *revocationCache) setWatermark(userID uint, before *time.Time, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneIfDue(now)
+
+	c.watermarks[userID] = cachedWatermark{before: before, expiresAt: now.Add(c.ttl)}
+}
+
+// pruneIfDue drops entries that no longer affect any decision. The caller
+// must hold c.mu.
+func (c *revocationCache) pruneIfDue(now time.Time) {
+	if now.Before(c.nextPrune) {
+		return
+	}
+	c.nextPrune = now.Add(revocationCachePruneInterval)
+
+	for tokenID, tokenExpiresAt := range c.revoked {
+		if !now.Before(tokenExpiresAt) {
+			delete(c.revoked, tokenID)
+		}
+	}
+	for tokenID, expiresAt := range c.notRevoked {
+		if !now.Before(expiresAt) {
+			delete(c.notRevoked, tokenID)
+		}
+	}
+	for userID, entry := range c.watermarks {
+		if !now.Before(entry.expiresAt) {
+			delete(c.watermarks, userID)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/exports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+)
+
+// ExportService defines the interface for data export operations
+type ExportService interface {
+	ExportTransactions(ctx context.Context, userID uint, format exports.Format, filters filters.TransactionFilters, w io.Writer) error
+	ExportArchive(ctx context.Context, userID uint, w io.Writer) error
+}
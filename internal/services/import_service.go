@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/archive"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/imports"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// ImportService defines the interface for statement import operations
+type ImportService interface {
+	PreviewCSV(ctx context.Context, userID uint, file io.Reader, mapping imports.CSVMapping) (*imports.Preview, error)
+	PreviewStatement(ctx context.Context, userID uint, format imports.Format, file io.Reader) (*imports.Preview, error)
+	ConfirmImport(ctx context.Context, userID, fallbackCategoryID uint, transactions []models.Transaction) (*imports.Result, error)
+	ImportArchive(ctx context.Context, userID uint, file io.ReaderAt, size int64) (*archive.Summary, error)
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// MFAService defines the interface for two-factor authentication
+type MFAService interface {
+	BeginTOTPEnrolment(ctx context.Context, userID uint, password string) (*auth.TOTPEnrolment, error)
+	ConfirmTOTPEnrolment(ctx context.Context, userID uint, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uint, password, code string) error
+	StartMFAChallenge(ctx context.Context, user *models.User) (*auth.MFAChallenge, error)
+	VerifyMFAChallenge(ctx context.Context, mfaToken, code string) (*models.User, error)
+}
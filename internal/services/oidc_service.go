@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// OIDCService defines the interface for logging in through an OpenID
+// Connect provider
+type OIDCService interface {
+	StartLogin(ctx context.Context) (authURL, state string, err error)
+	CompleteLogin(ctx context.Context, code, state string) (*models.User, error)
+}
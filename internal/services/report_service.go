@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/filters"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// ReportService defines the interface for reporting operations
+type ReportService interface {
+	GetSummaryByUser(ctx context.Context, userID uint, filters filters.TransactionFilters) (*models.TransactionSummary, error)
+}
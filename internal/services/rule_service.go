@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// RuleService defines the interface for categorisation rule operations
+type RuleService interface {
+	CreateRule(ctx context.Context, rule *models.Rule) error
+	GetRulesByUser(ctx context.Context, userID uint) ([]models.Rule, error)
+	UpdateRuleForUser(ctx context.Context, userID uint, rule *models.Rule) error
+	DeleteRuleForUser(ctx context.Context, userID, ruleID uint) error
+}
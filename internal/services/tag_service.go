@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// TagService defines the interface for tag operations
+type TagService interface {
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTagsByUser(ctx context.Context, userID uint) ([]models.Tag, error)
+	DeleteTagForUser(ctx context.Context, userID, tagID uint) error
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/auth"
+	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
+)
+
+// TokenService defines the interface for issuing and renewing auth tokens
+type TokenService interface {
+	IssueTokens(ctx context.Context, user *models.User, scope string) (*auth.TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*auth.TokenPair, error)
+	Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error
+	LogoutAll(ctx context.Context, userID uint) error
+}
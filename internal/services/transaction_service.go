@@ -11,7 +11,17 @@ import (
 // TransactionService defines the interface for transaction operations
 type TransactionService interface {
 	AddTransaction(ctx context.Context, transaction *models.Transaction) error
+	AddTransactions(ctx context.Context, transactions []models.Transaction) error
 	GetTransactionsByUser(ctx context.Context, userID uint, filters filters.TransactionFilters) ([]models.Transaction, error)
 	GetTransactionsPageByUser(ctx context.Context, userID uint, params pagination.Params, filters filters.TransactionFilters) ([]models.Transaction, int64, error)
+	UpdateTransactionForUser(ctx context.Context, userID uint, transaction *models.Transaction) error
 	DeleteTransactionForUser(ctx context.Context, userID, transactionID uint) error
+	CreateTransfer(ctx context.Context, transfer *models.Transfer) error
+	DeleteTransferForUser(ctx context.Context, userID, transferID uint) error
+	FindDuplicates(ctx context.Context, userID uint, transactions []models.Transaction) ([]models.DuplicateMatch, error)
+	GetDuplicateGroupsByUser(ctx context.Context, userID uint) ([]models.DuplicateGroup, error)
+	MergeDuplicatesForUser(ctx context.Context, userID, keepID uint, duplicateIDs []uint) (*models.Transaction, error)
+	ApplyRules(ctx context.Context, userID uint, transactions []models.Transaction) error
+	ReapplyRulesForUser(ctx context.Context, userID uint, filters filters.TransactionFilters) (int, error)
+	SuggestCategories(ctx context.Context, userID uint, note, payee string) ([]models.CategorySuggestion, error)
 }
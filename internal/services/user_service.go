@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/TsonasIoannis/go-personal-finance-tracker/internal/models"
 )
@@ -9,5 +10,10 @@ import (
 // UserService defines the interface for user operations
 type UserService interface {
 	RegisterUser(ctx context.Context, name, email, password string) (*models.User, error)
-	AuthenticateUser(ctx context.Context, email, password string) (*models.User, error)
+	AuthenticateUser(ctx context.Context, email, password, clientAddress string) (*models.User, error)
+	GetUser(ctx context.Context, userID uint) (*models.User, error)
+	UpdateUser(ctx context.Context, userID uint, name, email *string, currentPassword string) (*models.User, error)
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error
+	DeleteUser(ctx context.Context, userID uint, password string) (*time.Time, error)
+	CancelUserDeletion(ctx context.Context, userID uint) error
 }